@@ -5,16 +5,35 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/skwol/wallet/pkg/bankstatement"
 	"github.com/skwol/wallet/pkg/clock"
 	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/receipt"
+	"github.com/skwol/wallet/pkg/scheduler"
 
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerexport "github.com/skwol/wallet/internal/adapters/api/export"
+	handlerjournal "github.com/skwol/wallet/internal/adapters/api/journal"
+	handlerreport "github.com/skwol/wallet/internal/adapters/api/report"
+	handlertransaction "github.com/skwol/wallet/internal/adapters/api/transaction"
+	handlerwallet "github.com/skwol/wallet/internal/adapters/api/wallet"
 	"github.com/skwol/wallet/internal/composites"
+	"github.com/skwol/wallet/internal/domain/approval"
+	"github.com/skwol/wallet/internal/domain/escrow"
+	"github.com/skwol/wallet/internal/domain/outbox"
+	"github.com/skwol/wallet/internal/domain/report"
+	"github.com/skwol/wallet/internal/domain/risk"
+	"github.com/skwol/wallet/internal/domain/screening"
 )
 
+const requestTimeout = 5 * time.Second
+
 func main() {
 	logging.Init()
 	logger := logging.GetLogger()
@@ -54,20 +73,92 @@ func main() {
 	}
 	transactionComposite.Handler.Register(router)
 
+	if path := os.Getenv("RECEIPT_KEY_FILE"); path != "" {
+		keyring, err := receipt.LoadKeyring(path)
+		if err != nil {
+			logger.Fatal("error loading RECEIPT_KEY_FILE:", err.Error())
+		}
+		logger.Info("create receipt composite")
+		receiptComposite, err := composites.NewReceiptComposite(db, keyring, logger)
+		if err != nil {
+			logger.Fatal("receipt composite failed:", err.Error())
+		}
+		receiptComposite.Handler.Register(router)
+	} else {
+		logger.Info("RECEIPT_KEY_FILE is not set, receipts are disabled")
+	}
+
+	approvalRules := approval.DefaultRules
+	if value := os.Getenv("APPROVAL_RULES"); value != "" {
+		if approvalRules, err = approval.ParseRules([]byte(value)); err != nil {
+			logger.Fatal("error parsing APPROVAL_RULES:", err.Error())
+		}
+	}
+	logger.Info("create approval composite")
+	approvalComposite, err := composites.NewApprovalComposite(db, logger, clock.Real{}, approvalRules)
+	if err != nil {
+		logger.Fatal("approval composite failed:", err.Error())
+	}
+	approvalComposite.Handler.Register(router)
+
+	var riskEngine *risk.Engine
+	if path := os.Getenv("RISK_RULES_FILE"); path != "" {
+		if riskEngine, err = risk.LoadConfig(path); err != nil {
+			logger.Fatal("error loading RISK_RULES_FILE:", err.Error())
+		}
+	}
+	logger.Info("create risk composite")
+	riskComposite, err := composites.NewRiskComposite(db, logger, clock.Real{}, riskEngine)
+	if err != nil {
+		logger.Fatal("risk composite failed:", err.Error())
+	}
+	riskComposite.Handler.Register(router)
+
+	screeningThreshold := screening.DefaultThreshold
+	if value := os.Getenv("SCREENING_THRESHOLD"); value != "" {
+		if screeningThreshold, err = strconv.ParseFloat(value, 64); err != nil {
+			logger.Fatal("error parsing SCREENING_THRESHOLD:", err.Error())
+		}
+	}
+	logger.Info("create screening composite")
+	screeningComposite, err := composites.NewScreeningComposite(db, logger, clock.Real{}, screeningThreshold)
+	if err != nil {
+		logger.Fatal("screening composite failed:", err.Error())
+	}
+	screeningComposite.Handler.Register(router)
+
 	logger.Info("create transfer composite")
-	transferComposite, err := composites.NewTransferComposite(db, logger, clock.Real{})
+	transferComposite, err := composites.NewTransferComposite(db, approvalComposite, riskComposite, logger, clock.Real{})
 	if err != nil {
 		logger.Fatal("transfer composite failed:", err.Error())
 	}
 	transferComposite.Handler.Register(router)
 
+	statementCurrency := os.Getenv("STATEMENT_CURRENCY")
+	if statementCurrency == "" {
+		statementCurrency = bankstatement.DefaultCurrency
+	}
 	logger.Info("create wallet composite")
-	walletComposite, err := composites.NewWalletComposite(db, logger)
+	walletComposite, err := composites.NewWalletComposite(db, approvalComposite, riskComposite, screeningComposite, logger, statementCurrency)
 	if err != nil {
 		logger.Fatal("wallet composite failed:", err.Error())
 	}
 	walletComposite.Handler.Register(router)
 
+	logger.Info("create export composite")
+	exportComposite, err := composites.NewExportComposite(transactionComposite, walletComposite, logger, clock.Real{}, statementCurrency)
+	if err != nil {
+		logger.Fatal("export composite failed:", err.Error())
+	}
+	exportComposite.Handler.Register(router)
+
+	logger.Info("create journal composite")
+	journalComposite, err := composites.NewJournalComposite(walletComposite, logger, clock.Real{}, statementCurrency)
+	if err != nil {
+		logger.Fatal("journal composite failed:", err.Error())
+	}
+	journalComposite.Handler.Register(router)
+
 	logger.Info("create common composite")
 	commonComposite, err := composites.NewCommonComposite(db, logger)
 	if err != nil {
@@ -75,14 +166,126 @@ func main() {
 	}
 	commonComposite.Handler.Register(router)
 
+	escrowTimeout := escrow.DefaultTimeout
+	if value := os.Getenv("ESCROW_TIMEOUT"); value != "" {
+		if escrowTimeout, err = time.ParseDuration(value); err != nil {
+			logger.Fatal("error parsing ESCROW_TIMEOUT:", err.Error())
+		}
+	}
+	logger.Info("create escrow composite")
+	escrowComposite, err := composites.NewEscrowComposite(db, approvalComposite, riskComposite, logger, clock.Real{}, escrowTimeout)
+	if err != nil {
+		logger.Fatal("escrow composite failed:", err.Error())
+	}
+	escrowComposite.Handler.Register(router)
+
+	logger.Info("create dispute composite")
+	disputeComposite, err := composites.NewDisputeComposite(db, riskComposite, logger, clock.Real{})
+	if err != nil {
+		logger.Fatal("dispute composite failed:", err.Error())
+	}
+	disputeComposite.Handler.Register(router)
+
+	reportsDir := os.Getenv("REPORTS_DIR")
+	if reportsDir == "" {
+		reportsDir = filepath.Join(os.TempDir(), "wallet-reports")
+	}
+	reportRetention := report.DefaultRetention
+	if value := os.Getenv("REPORT_RETENTION"); value != "" {
+		if reportRetention, err = time.ParseDuration(value); err != nil {
+			logger.Fatal("error parsing REPORT_RETENTION:", err.Error())
+		}
+	}
+	reportWorkers := report.DefaultWorkers
+	if value := os.Getenv("REPORT_WORKERS"); value != "" {
+		if reportWorkers, err = strconv.Atoi(value); err != nil {
+			logger.Fatal("error parsing REPORT_WORKERS:", err.Error())
+		}
+	}
+	logger.Info("create report composite")
+	reportComposite, err := composites.NewReportComposite(db, transactionComposite, logger, clock.Real{}, reportsDir, reportRetention)
+	if err != nil {
+		logger.Fatal("report composite failed:", err.Error())
+	}
+	reportComposite.Handler.Register(router)
+	reportComposite.Service.Run(ctx, reportWorkers)
+
+	logger.Info("create reconciliation composite")
+	reconciliationComposite, err := composites.NewReconciliationComposite(db, logger, clock.Real{})
+	if err != nil {
+		logger.Fatal("reconciliation composite failed:", err.Error())
+	}
+	reconciliationComposite.Handler.Register(router)
+
+	logger.Info("create closing composite")
+	closingComposite, err := composites.NewClosingComposite(db, logger, clock.Real{})
+	if err != nil {
+		logger.Fatal("closing composite failed:", err.Error())
+	}
+
+	logger.Info("create chain composite")
+	chainComposite, err := composites.NewChainComposite(db, logger, clock.Real{})
+	if err != nil {
+		logger.Fatal("chain composite failed:", err.Error())
+	}
+
+	logger.Info("create liability composite")
+	liabilityComposite, err := composites.NewLiabilityComposite(db, logger, clock.Real{})
+	if err != nil {
+		logger.Fatal("liability composite failed:", err.Error())
+	}
+	liabilityComposite.Handler.Register(router)
+
+	var outboxComposite *composites.OutboxComposite
+	if value := os.Getenv("OUTBOX_PUBLISHER"); value != "" {
+		outboxPublisher, err := outbox.NewPublisher(value)
+		if err != nil {
+			logger.Fatal("error parsing OUTBOX_PUBLISHER:", err.Error())
+		}
+		logger.Info("create outbox composite")
+		if outboxComposite, err = composites.NewOutboxComposite(db, outboxPublisher, logger); err != nil {
+			logger.Fatal("outbox composite failed:", err.Error())
+		}
+	} else {
+		logger.Info("OUTBOX_PUBLISHER is not set, events are kept in the outbox")
+	}
+
+	logger.Info("start scheduler")
+	jobs := scheduler.New(logger)
+	jobs.Every("refund expired escrows", time.Minute, escrowComposite.Service.RefundExpired)
+	jobs.Every("remove expired reports", 10*time.Minute, reportComposite.Service.RemoveExpired)
+	jobs.Every("close ended days", time.Hour, closingComposite.Service.CloseDays)
+	jobs.Every("anchor transaction chain", time.Hour, chainComposite.Service.Anchor)
+	jobs.Every("publish liability tree", time.Hour, liabilityComposite.Service.Build)
+	if outboxComposite != nil {
+		jobs.Every("relay outbox events", time.Second, outboxComposite.Service.Relay)
+	}
+	jobs.Start(ctx)
+
+	var apiTokens adapters.Tokens
+	if value := os.Getenv("API_TOKENS"); value != "" {
+		if apiTokens, err = adapters.ParseTokens([]byte(value)); err != nil {
+			logger.Fatal("error parsing API_TOKENS:", err.Error())
+		}
+	} else {
+		logger.Info("API_TOKENS is not set, requests are anonymous and can not make or decide approvals")
+	}
+
 	listener, err := net.Listen("tcp", os.Getenv("HTTP_LISTEN_ADDRESS"))
 	if err != nil {
 		logger.Fatal("Error occurred:", err.Error())
 	}
 
+	// reports, statements and exports are streamed for as long as they take, the rest is cut after requestTimeout
+	var streaming []string
+	for _, urls := range [][]string{
+		handlertransaction.StreamingURLs, handlerreport.StreamingURLs, handlerwallet.StreamingURLs,
+		handlerexport.StreamingURLs, handlerjournal.StreamingURLs,
+	} {
+		streaming = append(streaming, urls...)
+	}
 	server := &http.Server{
-		Handler:           router,
-		WriteTimeout:      5 * time.Second,
+		Handler:           adapters.WithAuthentication(adapters.WithTimeout(router, requestTimeout, streaming...), apiTokens),
 		ReadTimeout:       5 * time.Second,
 		ReadHeaderTimeout: 2 * time.Second,
 	}
@@ -1,13 +1,42 @@
 package main
 
 import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 
+	"github.com/skwol/wallet/pkg/bankstatement"
 	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/clock"
 	"github.com/skwol/wallet/pkg/fixtures"
 	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/merklesum"
+	"github.com/skwol/wallet/pkg/receipt"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	apireconciliation "github.com/skwol/wallet/internal/adapters/api/reconciliation"
+	dbchain "github.com/skwol/wallet/internal/adapters/db/chain"
+	dbclosing "github.com/skwol/wallet/internal/adapters/db/closing"
+	dbreconciliation "github.com/skwol/wallet/internal/adapters/db/reconciliation"
+	dbreplay "github.com/skwol/wallet/internal/adapters/db/replay"
+	dbscreening "github.com/skwol/wallet/internal/adapters/db/screening"
+	dbwallet "github.com/skwol/wallet/internal/adapters/db/wallet"
+	"github.com/skwol/wallet/internal/domain/chain"
+	"github.com/skwol/wallet/internal/domain/closing"
+	"github.com/skwol/wallet/internal/domain/journal"
+	"github.com/skwol/wallet/internal/domain/reconciliation"
+	"github.com/skwol/wallet/internal/domain/replay"
+	"github.com/skwol/wallet/internal/domain/screening"
+	"github.com/skwol/wallet/internal/domain/wallet"
 )
 
 func main() {
@@ -24,6 +53,52 @@ type config struct {
 	storage struct {
 		dsn string
 	}
+	screen struct {
+		watchlist string
+		threshold float64
+	}
+	journal struct {
+		format   string
+		from     string
+		to       string
+		timeZone string
+		wallets  []int64
+		currency string
+		output   string
+	}
+	closeDay struct {
+		date string
+	}
+	reconcile struct {
+		fix    bool
+		reason string
+		actor  string
+		output string
+	}
+	anchors struct {
+		from   string
+		to     string
+		output string
+	}
+	verifyReceipt struct {
+		file string
+		keys string
+	}
+	verifyLiability struct {
+		file  string
+		root  string
+		total string
+	}
+	rebuild struct {
+		chunkSize int
+		dryRun    bool
+	}
+	parquet struct {
+		output      string
+		filter      string
+		timeZone    string
+		incremental bool
+	}
 }
 
 func run(logger logging.Logger) (err error) {
@@ -41,8 +116,107 @@ func run(logger logging.Logger) (err error) {
 
 	loadFixturesCmd := flagParser.Command("load-fixtures", "Load flagParser 'fake' set of data into flagParser database.")
 
+	screenCmd := flagParser.Command("screen", "Rescreen all wallets against the watchlist, frozen wallets wait for review.")
+	screenCmd.Flag("watchlist", "CSV or XML file replacing the watchlist before rescreening").
+		StringVar(&cfg.screen.watchlist)
+	screenCmd.Flag("threshold", "Similarity score from which the name is considered a match").
+		Default("0.85").
+		Envar("SCREENING_THRESHOLD").
+		Float64Var(&cfg.screen.threshold)
+
+	journalCmd := flagParser.Command("export-journal", "Export the double entry journal of wallets for ledger, hledger or beancount.")
+	journalCmd.Flag("format", "Syntax of the journal: ledger, hledger or beancount").
+		Default(string(journal.FormatLedger)).
+		EnumVar(&cfg.journal.format, string(journal.FormatLedger), string(journal.FormatHledger), string(journal.FormatBeancount))
+	journalCmd.Flag("from", "Start of the period, RFC 3339 timestamp or date").
+		Required().
+		StringVar(&cfg.journal.from)
+	journalCmd.Flag("to", "End of the period, RFC 3339 timestamp which is not included or date which is included").
+		Required().
+		StringVar(&cfg.journal.to)
+	journalCmd.Flag("time-zone", "IANA time zone of dates, UTC by default").
+		StringVar(&cfg.journal.timeZone)
+	journalCmd.Flag("wallet", "Wallet of the journal, can be repeated, all wallets by default").
+		Int64ListVar(&cfg.journal.wallets)
+	journalCmd.Flag("currency", "Currency of the amounts").
+		Default(bankstatement.DefaultCurrency).
+		Envar("STATEMENT_CURRENCY").
+		StringVar(&cfg.journal.currency)
+	journalCmd.Flag("output", "File the journal is written to").
+		Required().
+		StringVar(&cfg.journal.output)
+
+	parquetCmd := flagParser.Command("export-parquet", "Export filtered transactions as parquet files partitioned by date.")
+	parquetCmd.Flag("output", "Dir the partitions are written to").
+		Required().
+		StringVar(&cfg.parquet.output)
+	parquetCmd.Flag("filter", "Filter of transactions, the same json as the body of POST /api/v1/transactions").
+		StringVar(&cfg.parquet.filter)
+	parquetCmd.Flag("time-zone", "IANA time zone of partition dates, UTC by default").
+		StringVar(&cfg.parquet.timeZone)
+	parquetCmd.Flag("incremental", "Export only transactions after the last exported one").
+		BoolVar(&cfg.parquet.incremental)
+
+	closeDayCmd := flagParser.Command("close-day", "Snapshot and verify balances of all wallets at the end of the UTC day and close it.")
+	closeDayCmd.Flag("date", "Day to close, the last ended day by default").
+		StringVar(&cfg.closeDay.date)
+
+	reconcileCmd := flagParser.Command("reconcile", "Compare balances of all wallets with their transactions and report discrepancies as json.")
+	reconcileCmd.Flag("fix", "Post a deposit or withdrawal for every discrepancy, so that transactions add up to the balance").
+		BoolVar(&cfg.reconcile.fix)
+	reconcileCmd.Flag("reason", "Audit reason of the adjustments, required with --fix").
+		StringVar(&cfg.reconcile.reason)
+	reconcileCmd.Flag("actor", "Person making the adjustments, required with --fix").
+		Envar("USER").
+		StringVar(&cfg.reconcile.actor)
+	reconcileCmd.Flag("output", "File the report is written to, stdout by default").
+		StringVar(&cfg.reconcile.output)
+
+	rebuildCmd := flagParser.Command("rebuild", "Rebuild wallet balances and daily snapshots by replaying the transaction log.")
+	rebuildCmd.Flag("chunk-size", "Number of transactions replayed at once").
+		Default(strconv.Itoa(replay.DefaultChunkSize)).
+		IntVar(&cfg.rebuild.chunkSize)
+	rebuildCmd.Flag("dry-run", "Report what the rebuild would change without changing it").
+		BoolVar(&cfg.rebuild.dryRun)
+
+	verifyChainCmd := flagParser.Command("verify-chain", "Walk the hash chain of the transaction log and report the first broken link.")
+
+	anchorsCmd := flagParser.Command("export-anchors", "Export anchor hashes of the transaction chain as json lines for notarisation.")
+	anchorsCmd.Flag("from", "Start of the period anchors were made in, a date or RFC3339 time, all anchors by default").
+		StringVar(&cfg.anchors.from)
+	anchorsCmd.Flag("to", "End of the period anchors were made in, a date or RFC3339 time").
+		StringVar(&cfg.anchors.to)
+	anchorsCmd.Flag("output", "File anchors are written to, stdout by default").
+		StringVar(&cfg.anchors.output)
+
+	verifyReceiptCmd := flagParser.Command("verify-receipt", "Verify the signature of a transfer receipt, without the database.")
+	verifyReceiptCmd.Arg("file", "Receipt json file").
+		Required().
+		StringVar(&cfg.verifyReceipt.file)
+	verifyReceiptCmd.Flag("keys", "Public keys of receipts as JWKS, a file or the URL of the service").
+		Default("http://localhost:8080/api/v1/receipt-keys").
+		StringVar(&cfg.verifyReceipt.keys)
+
+	verifyLiabilityCmd := flagParser.Command("verify-liability-proof", "Verify that the balance of a wallet is included in the published liabilities, without the database.")
+	verifyLiabilityCmd.Arg("file", "Liability proof json file of the wallet").
+		Required().
+		StringVar(&cfg.verifyLiability.file)
+	verifyLiabilityCmd.Flag("root", "Published hex encoded root the proof is checked against").
+		Required().
+		StringVar(&cfg.verifyLiability.root)
+	verifyLiabilityCmd.Flag("total", "Published total the root commits to, it is reported when not given").
+		StringVar(&cfg.verifyLiability.total)
+
 	command := kingpin.MustParse(flagParser.Parse(os.Args[1:]))
 
+	// receipts and proofs are verified offline, the database is not needed
+	if verifyReceiptCmd.FullCommand() == command {
+		return verifyReceipt(logger, cfg.verifyReceipt.file, cfg.verifyReceipt.keys)
+	}
+	if verifyLiabilityCmd.FullCommand() == command {
+		return verifyLiabilityProof(logger, cfg.verifyLiability.file, cfg.verifyLiability.root, cfg.verifyLiability.total)
+	}
+
 	db, err := pgdb.NewClient("production")
 	if err != nil {
 		return err
@@ -55,5 +229,362 @@ func run(logger logging.Logger) (err error) {
 		}
 	}
 
+	if screenCmd.FullCommand() == command {
+		if err := screen(db, logger, cfg.screen.watchlist, cfg.screen.threshold); err != nil {
+			return err
+		}
+	}
+
+	if journalCmd.FullCommand() == command {
+		if err := exportJournal(db, logger, cfg.journal.format, cfg.journal.from, cfg.journal.to, cfg.journal.timeZone,
+			cfg.journal.wallets, cfg.journal.currency, cfg.journal.output); err != nil {
+			return err
+		}
+	}
+
+	if parquetCmd.FullCommand() == command {
+		if err := exportParquet(db, logger, cfg.parquet.output, cfg.parquet.filter, cfg.parquet.timeZone, cfg.parquet.incremental); err != nil {
+			return err
+		}
+	}
+
+	if closeDayCmd.FullCommand() == command {
+		if err := closeDay(db, logger, cfg.closeDay.date); err != nil {
+			return err
+		}
+	}
+
+	if reconcileCmd.FullCommand() == command {
+		if err := reconcile(db, logger, cfg.reconcile.fix, cfg.reconcile.reason, cfg.reconcile.actor, cfg.reconcile.output); err != nil {
+			return err
+		}
+	}
+
+	if verifyChainCmd.FullCommand() == command {
+		if err := verifyChain(db, logger); err != nil {
+			return err
+		}
+	}
+
+	if anchorsCmd.FullCommand() == command {
+		if err := exportAnchors(db, logger, cfg.anchors.from, cfg.anchors.to, cfg.anchors.output); err != nil {
+			return err
+		}
+	}
+
+	if rebuildCmd.FullCommand() == command {
+		if err := rebuild(db, logger, cfg.rebuild.chunkSize, cfg.rebuild.dryRun); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func screen(db *pgdb.PGDB, logger logging.Logger, watchlist string, threshold float64) error {
+	ctx := context.Background()
+	storage, err := dbscreening.NewStorage(db, logger)
+	if err != nil {
+		return err
+	}
+	service, err := screening.NewService(storage, logger, clock.Real{}, threshold)
+	if err != nil {
+		return err
+	}
+	if watchlist != "" {
+		entries, err := service.LoadWatchlist(ctx, watchlist)
+		if err != nil {
+			return err
+		}
+		logger.Infof("watchlist replaced with %d names", entries)
+	}
+	flagged, err := service.Rescreen(ctx)
+	if err != nil {
+		return err
+	}
+	logger.Infof("rescreening found %d new matches", flagged)
+	return nil
+}
+
+func closeDay(db *pgdb.PGDB, logger logging.Logger, date string) error {
+	ctx := context.Background()
+	storage, err := dbclosing.NewStorage(db, logger)
+	if err != nil {
+		return err
+	}
+	service, err := closing.NewService(storage, logger, clock.Real{})
+	if err != nil {
+		return err
+	}
+	day := closing.LastEndedDay(time.Now())
+	if date != "" {
+		if day, err = time.Parse("2006-01-02", date); err != nil {
+			return errors.Wrap(err, "error parsing date")
+		}
+	}
+	closed, err := service.CloseDay(ctx, day)
+	if err != nil {
+		return err
+	}
+	logger.Infof("%s is closed with %d wallets at %s", closed.Day.Format("2006-01-02"), closed.Wallets, closed.ClosedAt.Format(time.RFC3339))
+	return nil
+}
+
+// reconcile fails when discrepancies are left, so that scripts can rely on the exit code.
+func reconcile(db *pgdb.PGDB, logger logging.Logger, fix bool, reason, actor, output string) error {
+	ctx := context.Background()
+	storage, err := dbreconciliation.NewStorage(db, logger)
+	if err != nil {
+		return err
+	}
+	service, err := reconciliation.NewService(storage, logger, clock.Real{})
+	if err != nil {
+		return err
+	}
+	var report reconciliation.ReportDTO
+	if fix {
+		report, err = service.Fix(ctx, reconciliation.FixDTO{Reason: reason, Actor: actor})
+	} else {
+		report, err = service.Reconcile(ctx)
+	}
+	// adjustments made before the failure are reported as well
+	if report.CreatedAt.IsZero() {
+		return err
+	}
+
+	out := os.Stdout
+	if output != "" {
+		if out, err = os.Create(output); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(apireconciliation.NewReport(report)); encodeErr != nil {
+		return encodeErr
+	}
+	if err != nil {
+		return err
+	}
+	if !fix && len(report.Discrepancies) > 0 {
+		return errors.Errorf("%d of %d wallets do not match their transactions", len(report.Discrepancies), report.Wallets)
+	}
+	logger.Infof("%d wallets reconciled, %d adjusted", report.Wallets, len(report.Adjustments))
+	return nil
+}
+
+// verifyChain fails when the chain is broken, so that scripts can rely on the exit code.
+func verifyChain(db *pgdb.PGDB, logger logging.Logger) error {
+	ctx := context.Background()
+	storage, err := dbchain.NewStorage(db, logger)
+	if err != nil {
+		return err
+	}
+	service, err := chain.NewService(storage, logger, clock.Real{})
+	if err != nil {
+		return err
+	}
+	result, err := service.Verify(ctx)
+	if err != nil {
+		return err
+	}
+	if broken := result.Broken; broken != nil {
+		return errors.Wrapf(chain.ErrChainBroken, "link %d of transaction %d: %s", broken.Seq, broken.TransactionID, broken.Reason)
+	}
+	logger.Infof("%d links and %d anchors verified, head %x", result.Links, result.Anchors, result.Head.Hash)
+	return nil
+}
+
+func verifyReceipt(logger logging.Logger, file, keys string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	signed, err := receipt.Parse(f)
+	if err != nil {
+		return err
+	}
+	keySet, err := readKeySet(keys)
+	if err != nil {
+		return err
+	}
+	if err := receipt.Verify(signed, keySet); err != nil {
+		return err
+	}
+	r := signed.Receipt
+	logger.Infof("receipt of transaction %d is valid: %s from wallet %d to wallet %d at %s, signed with key %s",
+		r.TransactionID, r.Amount, r.SenderID, r.ReceiverID, r.Date, signed.KeyID)
+	return nil
+}
+
+// verifyLiabilityProof checks the proof served by the API against the published root,
+// the proof carries the tree as well, but only the published root is trusted.
+func verifyLiabilityProof(logger logging.Logger, file, root, total string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var document struct {
+		Proof merklesum.Proof `json:"proof"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return errors.Wrap(err, "error parsing liability proof")
+	}
+	var rootHash merklesum.Hash
+	if err := rootHash.UnmarshalText([]byte(root)); err != nil {
+		return err
+	}
+	node, err := merklesum.Verify(document.Proof, rootHash)
+	if err != nil {
+		return err
+	}
+	if total != "" {
+		want, err := merklesum.ParseSum(total)
+		if err != nil {
+			return err
+		}
+		if node.Sum != want {
+			return errors.Errorf("root commits to total %s, not %s", node.Sum, want)
+		}
+	}
+	logger.Infof("balance %s of wallet %d is included in total %s of root %s", document.Proof.Sum, document.Proof.ID, node.Sum, rootHash)
+	return nil
+}
+
+// readKeySet reads keys from the file or fetches them from the URL.
+func readKeySet(source string) (receipt.KeySet, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := ioutil.ReadFile(source)
+		if err != nil {
+			return receipt.KeySet{}, err
+		}
+		return receipt.ParseKeySet(data)
+	}
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(source)
+	if err != nil {
+		return receipt.KeySet{}, errors.Wrap(err, "error fetching receipt keys")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return receipt.KeySet{}, errors.Errorf("error fetching receipt keys: %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return receipt.KeySet{}, errors.Wrap(err, "error fetching receipt keys")
+	}
+	return receipt.ParseKeySet(data)
+}
+
+// anchor is the exported anchor, the hash is hex encoded.
+type anchor struct {
+	Seq           int64     `json:"seq"`
+	TransactionID int64     `json:"transaction_id"`
+	Hash          string    `json:"hash"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func exportAnchors(db *pgdb.PGDB, logger logging.Logger, from, to, output string) error {
+	ctx := context.Background()
+	var period struct{ from, to time.Time }
+	if from != "" || to != "" {
+		var err error
+		if period.from, period.to, _, err = adapters.ParsePeriodValues(from, to, ""); err != nil {
+			return err
+		}
+	}
+	storage, err := dbchain.NewStorage(db, logger)
+	if err != nil {
+		return err
+	}
+	service, err := chain.NewService(storage, logger, clock.Real{})
+	if err != nil {
+		return err
+	}
+	anchors, err := service.GetAnchors(ctx, period.from, period.to)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if output != "" {
+		if out, err = os.Create(output); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	encoder := json.NewEncoder(out)
+	for _, a := range anchors {
+		if err := encoder.Encode(anchor{Seq: a.Seq, TransactionID: a.TransactionID, Hash: hex.EncodeToString(a.Hash), CreatedAt: a.CreatedAt.UTC()}); err != nil {
+			return err
+		}
+	}
+	logger.Infof("%d anchors exported", len(anchors))
+	return nil
+}
+
+func rebuild(db *pgdb.PGDB, logger logging.Logger, chunkSize int, dryRun bool) error {
+	ctx := context.Background()
+	storage, err := dbreplay.NewStorage(db, logger)
+	if err != nil {
+		return err
+	}
+	service, err := replay.NewService(storage, logger, clock.Real{})
+	if err != nil {
+		return err
+	}
+	result, err := service.Rebuild(ctx, replay.RebuildDTO{ChunkSize: chunkSize, DryRun: dryRun}, func(p replay.ProgressDTO) {
+		logger.Infof("%s", p)
+	})
+	if err != nil {
+		return err
+	}
+	for _, change := range result.Diff.Changes {
+		logger.Infof("wallet %d balance %.4f rebuilt %.4f", change.WalletID, change.Balance, change.Rebuilt)
+	}
+	verb := "changed"
+	if !result.Swapped {
+		verb = "would change"
+	}
+	logger.Infof("%d transactions replayed in %s, rebuild %s %d wallet balances and %d snapshots",
+		result.Transactions, result.FinishedAt.Sub(result.StartedAt).Round(time.Millisecond), verb, result.Diff.Wallets, result.Diff.Snapshots)
+	return nil
+}
+
+func exportJournal(db *pgdb.PGDB, logger logging.Logger, format, from, to, timeZone string, wallets []int64, currency, output string) error {
+	ctx := context.Background()
+	dto := journal.ExportDTO{WalletIDs: wallets, Format: journal.Format(format)}
+	var err error
+	if dto.From, dto.To, dto.Location, err = adapters.ParsePeriodValues(from, to, timeZone); err != nil {
+		return err
+	}
+	storage, err := dbwallet.NewStorage(db, logger)
+	if err != nil {
+		return err
+	}
+	// statements need neither risk nor screening
+	walletService, err := wallet.NewService(storage, nil, nil, nil, logger, clock.Real{})
+	if err != nil {
+		return err
+	}
+	service, err := journal.NewService(walletService, logger, clock.Real{}, currency)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := service.Export(ctx, file, &dto); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	logger.Infof("journal written to %s", output)
 	return nil
 }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/parquet"
+
+	apitransaction "github.com/skwol/wallet/internal/adapters/api/transaction"
+	dbtransaction "github.com/skwol/wallet/internal/adapters/db/transaction"
+	"github.com/skwol/wallet/internal/domain/report"
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+const (
+	// lastIDFile keeps the last exported transaction id of incremental exports
+	lastIDFile       = "_last_id"
+	partitionLayout  = "2006-01-02"
+	partitionPartExt = ".part"
+)
+
+// exportParquet writes the filtered transactions into parquet files partitioned by date, output/date=2022-05-01/part-{id}.parquet,
+// where id is the first transaction of the file. Incremental exports take only transactions after the last exported one,
+// a failed export can be run again, it writes the same files.
+func exportParquet(db *pgdb.PGDB, logger logging.Logger, output, filterJSON, timeZone string, incremental bool) error {
+	ctx := context.Background()
+	loc := time.UTC
+	if timeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			return errors.Wrapf(err, "unknown time zone %q", timeZone)
+		}
+	}
+	var request apitransaction.Filter
+	if filterJSON != "" {
+		if err := json.Unmarshal([]byte(filterJSON), &request); err != nil {
+			return errors.Wrap(err, "error parsing filter")
+		}
+	}
+	filter, err := request.ToFilterRequest()
+	if err != nil {
+		return errors.Wrap(err, "error parsing filter")
+	}
+
+	var lastID int64
+	if incremental {
+		if lastID, err = readLastID(filepath.Join(output, lastIDFile)); err != nil {
+			return err
+		}
+		after := transaction.Expression{Condition: &transaction.Condition{Field: transaction.FieldID, Op: transaction.OpGt, ID: lastID}}
+		if filter.Where != nil {
+			after = transaction.Expression{And: []transaction.Expression{*filter.Where, after}}
+		}
+		filter.Where = &after
+	}
+	// rows are read by date, so that every partition file is written at once
+	filter.Sort = []transaction.Sort{{Field: transaction.SortDate}, {Field: transaction.SortID}}
+
+	storage, err := dbtransaction.NewStorage(db, logger)
+	if err != nil {
+		return err
+	}
+	service, err := transaction.NewService(storage, logger)
+	if err != nil {
+		return err
+	}
+
+	var current *partitionFile
+	var rows, files int64
+	maxID := lastID
+	err = service.StreamFiltered(ctx, &filter, 0, 0, func(dto transaction.DTO) error {
+		day := dto.Timestamp.In(loc).Format(partitionLayout)
+		if current == nil || current.day != day {
+			if err := current.close(); err != nil {
+				return err
+			}
+			var err error
+			if current, err = newPartitionFile(output, day, dto.ID); err != nil {
+				return err
+			}
+			files++
+		}
+		if err := current.writer.Write(report.ParquetRow(dto)...); err != nil {
+			return errors.Wrap(err, "error writing parquet row")
+		}
+		rows++
+		if dto.ID > maxID {
+			maxID = dto.ID
+		}
+		return nil
+	})
+	if err == nil {
+		err = current.close()
+	}
+	if err != nil {
+		current.abort()
+		return err
+	}
+	if incremental && maxID > lastID {
+		if err := writeLastID(filepath.Join(output, lastIDFile), maxID); err != nil {
+			return err
+		}
+	}
+	logger.Infof("exported %d transactions into %d parquet files, last transaction id %d", rows, files, maxID)
+	return nil
+}
+
+// partitionFile is written under a temporary name until it is complete.
+type partitionFile struct {
+	day    string
+	path   string
+	file   *os.File
+	writer *parquet.Writer
+}
+
+func newPartitionFile(output, day string, firstID int64) (*partitionFile, error) {
+	dir := filepath.Join(output, "date="+day)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, errors.Wrap(err, "error creating partition dir")
+	}
+	path := filepath.Join(dir, fmt.Sprintf("part-%d.parquet", firstID))
+	file, err := os.Create(path + partitionPartExt)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating partition file")
+	}
+	writer, err := parquet.NewWriter(file, report.ParquetColumns)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &partitionFile{day: day, path: path, file: file, writer: writer}, nil
+}
+
+func (p *partitionFile) close() error {
+	if p == nil {
+		return nil
+	}
+	err := p.writer.Close()
+	if closeErr := p.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(p.path+partitionPartExt, p.path)
+	}
+	return errors.Wrapf(err, "error writing partition file %s", p.path)
+}
+
+func (p *partitionFile) abort() {
+	if p == nil {
+		return
+	}
+	p.file.Close()
+	os.Remove(p.path + partitionPartExt)
+}
+
+func readLastID(path string) (int64, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "error reading last exported id")
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error parsing last exported id in %s", path)
+	}
+	return id, nil
+}
+
+func writeLastID(path string, id int64) error {
+	if err := os.WriteFile(path+partitionPartExt, []byte(strconv.FormatInt(id, 10)+"\n"), 0o640); err != nil {
+		return errors.Wrap(err, "error writing last exported id")
+	}
+	return errors.Wrap(os.Rename(path+partitionPartExt, path), "error writing last exported id")
+}
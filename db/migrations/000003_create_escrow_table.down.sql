@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "escrow";
+DROP TYPE IF EXISTS "escrow_status";
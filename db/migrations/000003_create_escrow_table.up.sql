@@ -0,0 +1,29 @@
+CREATE TYPE escrow_status AS ENUM ('funded', 'released', 'refunded', 'disputed');
+
+CREATE TABLE "escrow" (
+	"id" serial NOT NULL,
+	"buyer_id" bigint NOT NULL,
+	"seller_id" bigint NOT NULL,
+	"holding_id" bigint NOT NULL,
+	"amount" numeric(8,4) NOT NULL,
+	"status" escrow_status NOT NULL DEFAULT 'funded',
+	"buyer_confirmed" boolean NOT NULL DEFAULT false,
+	"seller_confirmed" boolean NOT NULL DEFAULT false,
+	"created_at" timestamp NOT NULL,
+	"expires_at" timestamp NOT NULL,
+	"settled_at" timestamp,
+	"fund_transaction_id" bigint NOT NULL,
+	"settle_transaction_id" bigint,
+	CONSTRAINT "escrow_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "escrow" ADD CONSTRAINT "escrow_fk_buyer" FOREIGN KEY ("buyer_id") REFERENCES "wallet"("id");
+ALTER TABLE "escrow" ADD CONSTRAINT "escrow_fk_seller" FOREIGN KEY ("seller_id") REFERENCES "wallet"("id");
+ALTER TABLE "escrow" ADD CONSTRAINT "escrow_fk_holding" FOREIGN KEY ("holding_id") REFERENCES "wallet"("id");
+ALTER TABLE "escrow" ADD CONSTRAINT "escrow_fk_fund_transaction" FOREIGN KEY ("fund_transaction_id") REFERENCES "transaction"("id");
+ALTER TABLE "escrow" ADD CONSTRAINT "escrow_fk_settle_transaction" FOREIGN KEY ("settle_transaction_id") REFERENCES "transaction"("id");
+ALTER TABLE "escrow" ADD CONSTRAINT "escrow_amount_morethenzero" CHECK ("amount" > 0);
+
+CREATE INDEX "escrow_status_expires_at_idx" ON "escrow" ("status", "expires_at");
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "dispute_evidence";
+DROP TABLE IF EXISTS "dispute";
+DROP TYPE IF EXISTS "dispute_reason";
+DROP TYPE IF EXISTS "dispute_status";
+-- postgres can not drop a value from enum, 'reversal' stays in transaction_type
@@ -0,0 +1,39 @@
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'reversal';
+
+CREATE TYPE dispute_status AS ENUM ('opened', 'under_review', 'won', 'lost');
+CREATE TYPE dispute_reason AS ENUM ('fraud', 'not_received', 'not_as_described', 'duplicate', 'cancelled', 'other');
+
+CREATE TABLE "dispute" (
+	"id" serial NOT NULL,
+	"transaction_id" bigint NOT NULL,
+	"reason" dispute_reason NOT NULL,
+	"status" dispute_status NOT NULL DEFAULT 'opened',
+	"hold_wallet_id" bigint,
+	"hold_amount" numeric(8,4) NOT NULL DEFAULT 0,
+	"opened_at" timestamp NOT NULL,
+	"resolved_at" timestamp,
+	"reversal_transaction_id" bigint,
+	CONSTRAINT "dispute_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "dispute" ADD CONSTRAINT "dispute_fk_transaction" FOREIGN KEY ("transaction_id") REFERENCES "transaction"("id");
+ALTER TABLE "dispute" ADD CONSTRAINT "dispute_fk_hold_wallet" FOREIGN KEY ("hold_wallet_id") REFERENCES "wallet"("id");
+ALTER TABLE "dispute" ADD CONSTRAINT "dispute_fk_reversal_transaction" FOREIGN KEY ("reversal_transaction_id") REFERENCES "transaction"("id");
+ALTER TABLE "dispute" ADD CONSTRAINT "dispute_hold_amount_nonnegative" CHECK ("hold_amount" >= 0);
+
+CREATE INDEX "dispute_transaction_id_idx" ON "dispute" ("transaction_id");
+CREATE INDEX "dispute_hold_wallet_id_idx" ON "dispute" ("hold_wallet_id") WHERE "status" IN ('opened', 'under_review');
+
+CREATE TABLE "dispute_evidence" (
+	"id" serial NOT NULL,
+	"dispute_id" bigint NOT NULL,
+	"note" TEXT NOT NULL,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "dispute_evidence_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "dispute_evidence" ADD CONSTRAINT "dispute_evidence_fk_dispute" FOREIGN KEY ("dispute_id") REFERENCES "dispute"("id");
@@ -0,0 +1,2 @@
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS "parent_payment_id";
+DROP TABLE IF EXISTS "payment";
@@ -0,0 +1,17 @@
+CREATE TABLE "payment" (
+	"id" serial NOT NULL,
+	"sender_id" bigint NOT NULL,
+	"amount" numeric(8,4) NOT NULL,
+	"date" timestamp NOT NULL,
+	CONSTRAINT "payment_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "payment" ADD CONSTRAINT "payment_fk_sender" FOREIGN KEY ("sender_id") REFERENCES "wallet"("id");
+ALTER TABLE "payment" ADD CONSTRAINT "payment_amount_morethenzero" CHECK ("amount" > 0);
+
+ALTER TABLE "transaction" ADD COLUMN "parent_payment_id" bigint;
+ALTER TABLE "transaction" ADD CONSTRAINT "transaction_fk_parent_payment" FOREIGN KEY ("parent_payment_id") REFERENCES "payment"("id");
+
+CREATE INDEX "transaction_parent_payment_id_idx" ON "transaction" ("parent_payment_id");
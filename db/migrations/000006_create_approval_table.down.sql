@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "audit_log";
+DROP TABLE IF EXISTS "approval_request";
+DROP TYPE IF EXISTS "approval_status";
+DROP TYPE IF EXISTS "approval_operation";
+ALTER TABLE "wallet" DROP COLUMN IF EXISTS "status";
+DROP TYPE IF EXISTS "wallet_status";
@@ -0,0 +1,42 @@
+CREATE TYPE wallet_status AS ENUM ('active', 'closed');
+
+ALTER TABLE "wallet" ADD COLUMN "status" wallet_status NOT NULL DEFAULT 'active';
+
+CREATE TYPE approval_operation AS ENUM ('transfer', 'split_payment', 'wallet_update', 'wallet_close');
+CREATE TYPE approval_status AS ENUM ('pending', 'approved', 'rejected', 'failed');
+
+CREATE TABLE "approval_request" (
+	"id" serial NOT NULL,
+	"operation" approval_operation NOT NULL,
+	"amount" numeric(8,4) NOT NULL DEFAULT 0,
+	"payload" jsonb NOT NULL,
+	"maker" TEXT NOT NULL,
+	"checker" TEXT,
+	"status" approval_status NOT NULL DEFAULT 'pending',
+	"reason" TEXT,
+	"created_at" timestamp NOT NULL,
+	"decided_at" timestamp,
+	CONSTRAINT "approval_request_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "approval_request" ADD CONSTRAINT "approval_request_checker_not_maker" CHECK ("checker" IS NULL OR "checker" <> "maker");
+
+CREATE INDEX "approval_request_status_idx" ON "approval_request" ("status");
+
+CREATE TABLE "audit_log" (
+	"id" serial NOT NULL,
+	"actor" TEXT NOT NULL,
+	"action" TEXT NOT NULL,
+	"approval_id" bigint,
+	"details" TEXT,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "audit_log_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "audit_log" ADD CONSTRAINT "audit_log_fk_approval" FOREIGN KEY ("approval_id") REFERENCES "approval_request"("id");
+
+CREATE INDEX "audit_log_approval_id_idx" ON "audit_log" ("approval_id");
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "transaction_sender_id_date_idx";
+DROP TABLE IF EXISTS "risk_decision";
+DROP TYPE IF EXISTS "risk_outcome";
+DROP TYPE IF EXISTS "risk_movement";
@@ -0,0 +1,22 @@
+CREATE TYPE risk_movement AS ENUM ('transfer', 'split_payment', 'deposit', 'withdraw');
+CREATE TYPE risk_outcome AS ENUM ('allow', 'review', 'deny');
+
+CREATE TABLE "risk_decision" (
+	"id" serial NOT NULL,
+	"kind" risk_movement NOT NULL,
+	"wallet_id" bigint NOT NULL,
+	"receiver_ids" bigint[] NOT NULL DEFAULT '{}',
+	"amount" numeric(8,4) NOT NULL,
+	"decision" risk_outcome NOT NULL,
+	"score" numeric(8,2) NOT NULL DEFAULT 0,
+	"hits" jsonb NOT NULL DEFAULT '[]',
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "risk_decision_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "risk_decision" ADD CONSTRAINT "risk_decision_fk_wallet" FOREIGN KEY ("wallet_id") REFERENCES "wallet"("id");
+
+CREATE INDEX "risk_decision_decision_idx" ON "risk_decision" ("decision");
+CREATE INDEX "transaction_sender_id_date_idx" ON "transaction" ("sender_id", "date");
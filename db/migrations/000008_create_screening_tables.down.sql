@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "screening_match";
+DROP TABLE IF EXISTS "watchlist_entry";
+DROP TYPE IF EXISTS "screening_status";
+-- postgres can not drop a value from enum, 'frozen' stays in wallet_status
+UPDATE "wallet" SET "status" = 'active' WHERE "status" = 'frozen';
@@ -0,0 +1,35 @@
+ALTER TYPE wallet_status ADD VALUE IF NOT EXISTS 'frozen';
+
+CREATE TYPE screening_status AS ENUM ('pending', 'confirmed', 'cleared');
+
+CREATE TABLE "watchlist_entry" (
+	"id" serial NOT NULL,
+	"name" TEXT NOT NULL,
+	"normalized" TEXT NOT NULL,
+	"reference" TEXT,
+	CONSTRAINT "watchlist_entry_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+CREATE TABLE "screening_match" (
+	"id" serial NOT NULL,
+	"wallet_id" bigint NOT NULL,
+	"wallet_name" TEXT NOT NULL,
+	"entry_name" TEXT NOT NULL,
+	"entry_reference" TEXT,
+	"normalized" TEXT NOT NULL,
+	"score" numeric(5,4) NOT NULL,
+	"status" screening_status NOT NULL DEFAULT 'pending',
+	"created_at" timestamp NOT NULL,
+	"reviewed_at" timestamp,
+	"reviewer" TEXT,
+	UNIQUE("wallet_id", "normalized"),
+	CONSTRAINT "screening_match_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "screening_match" ADD CONSTRAINT "screening_match_fk_wallet" FOREIGN KEY ("wallet_id") REFERENCES "wallet"("id");
+
+CREATE INDEX "screening_match_status_idx" ON "screening_match" ("status");
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "transaction_receiver_id_id_idx";
+DROP INDEX IF EXISTS "transaction_sender_id_id_idx";
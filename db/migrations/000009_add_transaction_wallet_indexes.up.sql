@@ -0,0 +1,2 @@
+CREATE INDEX "transaction_sender_id_id_idx" ON "transaction" ("sender_id", "id");
+CREATE INDEX "transaction_receiver_id_id_idx" ON "transaction" ("receiver_id", "id");
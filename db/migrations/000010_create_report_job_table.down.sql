@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "report_job";
+DROP TYPE IF EXISTS "report_status";
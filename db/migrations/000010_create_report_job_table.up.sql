@@ -0,0 +1,24 @@
+CREATE TYPE report_status AS ENUM ('queued', 'running', 'done', 'failed', 'expired');
+
+CREATE TABLE "report_job" (
+	"id" serial NOT NULL,
+	"filter" jsonb NOT NULL,
+	"format" TEXT NOT NULL,
+	"time_zone" TEXT NOT NULL,
+	"status" report_status NOT NULL DEFAULT 'queued',
+	"attempt" integer NOT NULL DEFAULT 0,
+	"row_count" bigint NOT NULL DEFAULT 0,
+	"total" bigint NOT NULL DEFAULT 0,
+	"file" TEXT,
+	"error" TEXT,
+	"created_at" timestamp NOT NULL,
+	"started_at" timestamp,
+	"updated_at" timestamp NOT NULL,
+	"finished_at" timestamp,
+	"expires_at" timestamp,
+	CONSTRAINT "report_job_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+CREATE INDEX "report_job_status_idx" ON "report_job" ("status", "id");
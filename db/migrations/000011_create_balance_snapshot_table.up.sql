@@ -0,0 +1,12 @@
+CREATE TABLE "balance_snapshot" (
+	"wallet_id" bigint NOT NULL,
+	"day" date NOT NULL,
+	"balance" numeric(8,4) NOT NULL,
+	"last_transaction_id" bigint NOT NULL,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "balance_snapshot_pk" PRIMARY KEY ("wallet_id", "day")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "balance_snapshot" ADD CONSTRAINT "balance_snapshot_fk_wallet" FOREIGN KEY ("wallet_id") REFERENCES "wallet"("id");
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS "transaction_closed_period" ON "transaction";
+DROP FUNCTION IF EXISTS reject_closed_period();
+DROP TABLE IF EXISTS "day_close";
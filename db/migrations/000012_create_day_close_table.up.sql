@@ -0,0 +1,21 @@
+CREATE TABLE "day_close" (
+	"day" date NOT NULL,
+	"wallets" bigint NOT NULL,
+	"closed_at" timestamp NOT NULL,
+	CONSTRAINT "day_close_pk" PRIMARY KEY ("day")
+) WITH (
+  OIDS=FALSE
+);
+
+-- everything up to the end of the last closed day is the closed period
+CREATE FUNCTION reject_closed_period() RETURNS trigger AS $$
+BEGIN
+	IF NEW.date < (SELECT MAX(day) + 1 FROM day_close) THEN
+		RAISE EXCEPTION 'transaction date % is within closed period', NEW.date USING ERRCODE = 'check_violation';
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "transaction_closed_period" BEFORE INSERT OR UPDATE OF "date" ON "transaction"
+	FOR EACH ROW EXECUTE PROCEDURE reject_closed_period();
@@ -0,0 +1,16 @@
+CREATE TABLE "adjustment" (
+	"id" serial NOT NULL,
+	"wallet_id" bigint NOT NULL,
+	"transaction_id" bigint NOT NULL,
+	"balance" numeric(8,4) NOT NULL,
+	"computed" numeric(8,4) NOT NULL,
+	"reason" TEXT NOT NULL,
+	"actor" TEXT NOT NULL,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "adjustment_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "adjustment" ADD CONSTRAINT "adjustment_fk_wallet" FOREIGN KEY ("wallet_id") REFERENCES "wallet"("id");
+ALTER TABLE "adjustment" ADD CONSTRAINT "adjustment_fk_transaction" FOREIGN KEY ("transaction_id") REFERENCES "transaction"("id");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "balance_snapshot_rebuild";
+DROP TABLE IF EXISTS "wallet_balance_rebuild";
+DROP TABLE IF EXISTS "movement_rebuild";
@@ -0,0 +1,20 @@
+-- shadow tables of the rebuild, they are refilled by every run and are not worth the write ahead log
+CREATE UNLOGGED TABLE "movement_rebuild" (
+	"wallet_id" bigint NOT NULL,
+	"day" date NOT NULL,
+	"amount" numeric NOT NULL,
+	CONSTRAINT "movement_rebuild_pk" PRIMARY KEY ("wallet_id", "day")
+);
+
+CREATE UNLOGGED TABLE "wallet_balance_rebuild" (
+	"wallet_id" bigint NOT NULL,
+	"balance" numeric NOT NULL,
+	CONSTRAINT "wallet_balance_rebuild_pk" PRIMARY KEY ("wallet_id")
+);
+
+CREATE UNLOGGED TABLE "balance_snapshot_rebuild" (
+	"wallet_id" bigint NOT NULL,
+	"day" date NOT NULL,
+	"balance" numeric NOT NULL,
+	CONSTRAINT "balance_snapshot_rebuild_pk" PRIMARY KEY ("wallet_id", "day")
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "chain_anchor";
+DROP TABLE IF EXISTS "chain_head";
+DROP INDEX IF EXISTS "transaction_unchained";
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS "hash";
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS "chain_seq";
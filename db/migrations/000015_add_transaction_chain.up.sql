@@ -0,0 +1,31 @@
+ALTER TABLE "transaction" ADD COLUMN "chain_seq" bigint;
+ALTER TABLE "transaction" ADD COLUMN "hash" bytea;
+ALTER TABLE "transaction" ADD CONSTRAINT "transaction_chain_seq" UNIQUE ("chain_seq");
+
+-- rows waiting to be chained, the next write chains them in id order
+CREATE INDEX "transaction_unchained" ON "transaction" ("id") WHERE "chain_seq" IS NULL;
+
+-- the last link of the chain, writers of transactions lock it to append to the chain one at a time
+CREATE TABLE "chain_head" (
+	"id" boolean NOT NULL DEFAULT true,
+	"seq" bigint NOT NULL,
+	"hash" bytea NOT NULL,
+	CONSTRAINT "chain_head_pk" PRIMARY KEY ("id"),
+	CONSTRAINT "chain_head_single" CHECK ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+INSERT INTO "chain_head" ("seq", "hash") VALUES (0, decode(repeat('00', 32), 'hex'));
+
+CREATE TABLE "chain_anchor" (
+	"seq" bigint NOT NULL,
+	"transaction_id" bigint NOT NULL,
+	"hash" bytea NOT NULL,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "chain_anchor_pk" PRIMARY KEY ("seq")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "chain_anchor" ADD CONSTRAINT "chain_anchor_fk_transaction" FOREIGN KEY ("transaction_id") REFERENCES "transaction"("id");
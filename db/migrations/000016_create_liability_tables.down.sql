@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "liability_node";
+DROP TABLE IF EXISTS "liability_tree";
@@ -0,0 +1,29 @@
+CREATE TABLE "liability_tree" (
+	"id" serial NOT NULL,
+	"root" bytea NOT NULL,
+	"total" bigint NOT NULL,
+	"wallets" bigint NOT NULL,
+	"depth" integer NOT NULL,
+	"created_at" timestamp NOT NULL,
+	CONSTRAINT "liability_tree_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+-- nodes of recent trees, leaves are level 0 and keep the wallet and the salt of its leaf, sums are in 1/10000
+CREATE TABLE "liability_node" (
+	"tree_id" bigint NOT NULL,
+	"level" integer NOT NULL,
+	"position" bigint NOT NULL,
+	"hash" bytea NOT NULL,
+	"sum" bigint NOT NULL,
+	"wallet_id" bigint,
+	"salt" bytea,
+	CONSTRAINT "liability_node_pk" PRIMARY KEY ("tree_id", "level", "position")
+) WITH (
+  OIDS=FALSE
+);
+
+ALTER TABLE "liability_node" ADD CONSTRAINT "liability_node_fk_tree" FOREIGN KEY ("tree_id") REFERENCES "liability_tree"("id") ON DELETE CASCADE;
+
+CREATE INDEX "liability_node_wallet" ON "liability_node" ("tree_id", "wallet_id") WHERE "wallet_id" IS NOT NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE "outbox" (
+	"id" bigserial NOT NULL,
+	"type" TEXT NOT NULL,
+	"aggregate_id" bigint NOT NULL,
+	"payload" jsonb NOT NULL,
+	"created_at" timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+	"published_at" timestamp,
+	CONSTRAINT "outbox_pk" PRIMARY KEY ("id")
+) WITH (
+  OIDS=FALSE
+);
+
+CREATE INDEX "outbox_unpublished" ON "outbox" ("id") WHERE "published_at" IS NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE "adjustment" ALTER COLUMN "computed" TYPE numeric(8,4);
+ALTER TABLE "adjustment" ALTER COLUMN "balance" TYPE numeric(8,4);
+ALTER TABLE "balance_snapshot" ALTER COLUMN "balance" TYPE numeric(8,4);
+ALTER TABLE "wallet" ALTER COLUMN "balance" TYPE numeric(8,4);
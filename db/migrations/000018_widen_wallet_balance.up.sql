@@ -0,0 +1,5 @@
+-- the escrow holding wallet keeps money of every funded escrow and outgrows a single wallet limit
+ALTER TABLE "wallet" ALTER COLUMN "balance" TYPE numeric(16,4);
+ALTER TABLE "balance_snapshot" ALTER COLUMN "balance" TYPE numeric(16,4);
+ALTER TABLE "adjustment" ALTER COLUMN "balance" TYPE numeric(16,4);
+ALTER TABLE "adjustment" ALTER COLUMN "computed" TYPE numeric(16,4);
@@ -0,0 +1,8 @@
+-- reserved wallets belong to the service, customers can not create, move or close them
+ALTER TABLE "wallet" ADD COLUMN "reserved" boolean NOT NULL DEFAULT false;
+
+-- the wallet escrows were funded into keeps their money, a customer wallet which only took the name is renamed
+UPDATE "wallet" SET "reserved" = true WHERE "id" IN (SELECT "holding_id" FROM "escrow");
+UPDATE "wallet" SET "name" = "name" || '-' || "id" WHERE "name" = '__escrow__' AND NOT "reserved";
+INSERT INTO "wallet" ("name", "balance", "reserved")
+	SELECT '__escrow__', 0, true WHERE NOT EXISTS (SELECT 1 FROM "wallet" WHERE "name" = '__escrow__');
@@ -0,0 +1,2 @@
+-- postgres can not drop a value from enum, 'escrow_release' and 'reversal' stay in risk_movement
+DELETE FROM "risk_decision" WHERE "kind" IN ('escrow_release', 'reversal');
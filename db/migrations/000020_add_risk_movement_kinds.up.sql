@@ -0,0 +1,2 @@
+ALTER TYPE risk_movement ADD VALUE IF NOT EXISTS 'escrow_release';
+ALTER TYPE risk_movement ADD VALUE IF NOT EXISTS 'reversal';
@@ -0,0 +1,2 @@
+//go:generate go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen --old-config-style  --package=approval --generate=types -alias-types -o openapi.gen.go openapi.yaml
+package approval
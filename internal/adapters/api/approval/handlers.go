@@ -0,0 +1,174 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/approval"
+)
+
+const (
+	approvalURL        = "/api/v1/approvals/{record_id}"
+	approvalsURL       = "/api/v1/approvals"
+	approvalApproveURL = "/api/v1/approvals/{record_id}/approve"
+	approvalRejectURL  = "/api/v1/approvals/{record_id}/reject"
+)
+
+type handler struct {
+	approvalService approval.Service
+	logger          logging.Logger
+}
+
+func NewHandler(service approval.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{approvalService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(approvalsURL, h.getApprovals).Methods(http.MethodGet)
+	router.HandleFunc(approvalURL, h.getApproval).Methods(http.MethodGet)
+
+	router.HandleFunc(approvalApproveURL, h.approve).Methods(http.MethodPost)
+	router.HandleFunc(approvalRejectURL, h.reject).Methods(http.MethodPost)
+}
+
+func (h *handler) getApproval(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	approvalDTO, err := h.approvalService.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if approvalDTO.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	writeApproval(w, h.logger, http.StatusOK, approvalDTO)
+}
+
+func (h *handler) getApprovals(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		h.logger.Errorf("error parsing limit query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing limit query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		h.logger.Errorf("error parsing offset query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing offset query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	status := approval.StatusPending
+	if value := r.FormValue("status"); value != "" {
+		status = approval.Status(value)
+	}
+
+	approvalDTOs, err := h.approvalService.GetByStatus(r.Context(), status, limit, offset)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if len(approvalDTOs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	approvals := make([]Approval, 0, len(approvalDTOs))
+	for _, dto := range approvalDTOs {
+		approvals = append(approvals, newApproval(dto))
+	}
+	response, err := json.Marshal(approvals)
+	if err != nil {
+		h.logger.Errorf("error marshaling approvals: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling approvals: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *handler) approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.approvalService.Approve)
+}
+
+func (h *handler) reject(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request RejectRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	h.decide(w, r, func(ctx context.Context, id int64) (approval.DTO, error) {
+		return h.approvalService.Reject(ctx, id, request.Reason)
+	})
+}
+
+func (h *handler) decide(w http.ResponseWriter, r *http.Request, decide func(context.Context, int64) (approval.DTO, error)) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	approvalDTO, err := decide(r.Context(), id)
+	if err != nil {
+		h.logger.Errorf("error deciding approval request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error deciding approval request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	writeApproval(w, h.logger, http.StatusOK, approvalDTO)
+}
+
+// WriteIfPending responds to the gated operation that was put into the approval queue instead of being
+// executed. It returns false and writes nothing when err comes from anything else.
+func WriteIfPending(w http.ResponseWriter, logger logging.Logger, err error) bool {
+	dto, ok := approval.AsPending(err)
+	if ok {
+		writeApproval(w, logger, http.StatusAccepted, dto)
+	}
+	return ok
+}
+
+func writeApproval(w http.ResponseWriter, logger logging.Logger, status int, dto approval.DTO) {
+	response, err := json.Marshal(newApproval(dto))
+	if err != nil {
+		logger.Errorf("error marshaling approval request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling approval request: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	if _, err := w.Write(response); err != nil {
+		logger.Errorf("error writing response: %s", err.Error())
+		return
+	}
+}
@@ -0,0 +1,49 @@
+package approval
+
+import (
+	"encoding/json"
+
+	"github.com/skwol/wallet/internal/domain/approval"
+)
+
+func newApproval(dto approval.DTO) Approval {
+	a := Approval{
+		Id:        int(dto.ID),
+		Operation: ApprovalOperation(dto.Operation),
+		Amount:    float32(dto.Amount),
+		Maker:     dto.Maker,
+		Status:    ApprovalStatus(dto.Status),
+		CreatedAt: dto.CreatedAt,
+	}
+	if err := json.Unmarshal(dto.Payload, &a.Payload); err != nil {
+		a.Payload = map[string]interface{}{}
+	}
+	if dto.Checker != "" {
+		a.Checker = &dto.Checker
+	}
+	if dto.Reason != "" {
+		a.Reason = &dto.Reason
+	}
+	if !dto.DecidedAt.IsZero() {
+		a.DecidedAt = &dto.DecidedAt
+	}
+	if len(dto.Audit) == 0 {
+		return a
+	}
+	audit := make([]AuditRecord, 0, len(dto.Audit))
+	for _, record := range dto.Audit {
+		r := AuditRecord{
+			Id:        int(record.ID),
+			Actor:     record.Actor,
+			Action:    AuditRecordAction(record.Action),
+			CreatedAt: record.CreatedAt,
+		}
+		if record.Details != "" {
+			details := record.Details
+			r.Details = &details
+		}
+		audit = append(audit, r)
+	}
+	a.Audit = &audit
+	return a
+}
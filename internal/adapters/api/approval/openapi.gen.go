@@ -0,0 +1,120 @@
+// Package approval provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package approval
+
+import (
+	"time"
+)
+
+const (
+	ApiTokenScopes = "ApiToken.Scopes"
+)
+
+// Defines values for ApprovalOperation.
+const (
+	EscrowFund    ApprovalOperation = "escrow_fund"
+	EscrowRelease ApprovalOperation = "escrow_release"
+	SplitPayment  ApprovalOperation = "split_payment"
+	Transfer      ApprovalOperation = "transfer"
+	WalletClose   ApprovalOperation = "wallet_close"
+	WalletUpdate  ApprovalOperation = "wallet_update"
+)
+
+// Defines values for ApprovalStatus.
+const (
+	Approved ApprovalStatus = "approved"
+	Failed   ApprovalStatus = "failed"
+	Pending  ApprovalStatus = "pending"
+	Rejected ApprovalStatus = "rejected"
+)
+
+// Defines values for AuditRecordAction.
+const (
+	Approve AuditRecordAction = "approve"
+	Denied  AuditRecordAction = "denied"
+	Reject  AuditRecordAction = "reject"
+	Submit  AuditRecordAction = "submit"
+)
+
+// operation waiting for approval, gated endpoints respond with it and 202 status instead of executing the operation
+type Approval struct {
+	// amount the approval rule was applied to
+	Amount float32        `json:"amount"`
+	Audit  *[]AuditRecord `json:"audit,omitempty"`
+
+	// actor who approved or rejected the operation
+	Checker   *string    `json:"checker,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+
+	// approval request id
+	Id int `json:"id"`
+
+	// actor who requested the operation
+	Maker     string            `json:"maker"`
+	Operation ApprovalOperation `json:"operation"`
+
+	// captured request of the operation
+	Payload map[string]interface{} `json:"payload"`
+
+	// reject reason or error of the failed execution
+	Reason *string        `json:"reason,omitempty"`
+	Status ApprovalStatus `json:"status"`
+}
+
+// ApprovalOperation defines model for Approval.Operation.
+type ApprovalOperation string
+
+// ApprovalStatus defines model for ApprovalStatus.
+type ApprovalStatus string
+
+// AuditRecord defines model for AuditRecord.
+type AuditRecord struct {
+	Action    AuditRecordAction `json:"action"`
+	Actor     string            `json:"actor"`
+	CreatedAt time.Time         `json:"created_at"`
+	Details   *string           `json:"details,omitempty"`
+	Id        int               `json:"id"`
+}
+
+// AuditRecordAction defines model for AuditRecord.Action.
+type AuditRecordAction string
+
+// Error defines model for Error.
+type Error struct {
+	Code      *int    `json:"code,omitempty"`
+	Error     string  `json:"error"`
+	ErrorType *string `json:"errorType,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// RejectRequest defines model for RejectRequest.
+type RejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PathParamApprovalID defines model for PathParamApprovalID.
+type PathParamApprovalID = float32
+
+// QueryParamLimit defines model for QueryParamLimit.
+type QueryParamLimit = float32
+
+// QueryParamOffset defines model for QueryParamOffset.
+type QueryParamOffset = float32
+
+// QueryParamStatus defines model for QueryParamStatus.
+type QueryParamStatus = ApprovalStatus
+
+// GetApprovalsParams defines parameters for GetApprovals.
+type GetApprovalsParams struct {
+	// Limit of how many records returned
+	Limit QueryParamLimit `form:"limit" json:"limit"`
+
+	// Offset of returned records
+	Offset QueryParamOffset  `form:"offset" json:"offset"`
+	Status *QueryParamStatus `form:"status,omitempty" json:"status,omitempty"`
+}
+
+// RejectRequestJSONRequestBody defines body for RejectRequest for application/json ContentType.
+type RejectRequestJSONRequestBody = RejectRequest
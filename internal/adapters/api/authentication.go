@@ -0,0 +1,63 @@
+package adapters
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/actor"
+)
+
+// Tokens maps api token to the name of the actor it is issued to.
+type Tokens map[string]string
+
+// ParseTokens reads tokens from json object like {"<token>": "alice"}.
+func ParseTokens(data []byte) (Tokens, error) {
+	var tokens Tokens
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, errors.Wrap(err, "error parsing api tokens")
+	}
+	for token, name := range tokens {
+		if token == "" || name == "" {
+			return nil, errors.New("api token and its actor can not be empty")
+		}
+	}
+	return tokens, nil
+}
+
+func (t Tokens) actor(token string) (string, bool) {
+	var (
+		name  string
+		found bool
+	)
+	// every token is compared, so that response time does not tell how close the guess is
+	for known, knownName := range t {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			name, found = knownName, true
+		}
+	}
+	return name, found
+}
+
+// WithAuthentication puts the actor of the bearer token into the request context. Request with unknown
+// token is rejected, request without token is served anonymously and can not make or decide approvals.
+func WithAuthentication(handler http.Handler, tokens Tokens) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		token := strings.TrimPrefix(header, "Bearer ")
+		name, ok := tokens.actor(token)
+		if token == header || !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid api token", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r.WithContext(actor.NewContext(r.Context(), name)))
+	})
+}
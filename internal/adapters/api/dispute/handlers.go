@@ -0,0 +1,214 @@
+package dispute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/dispute"
+)
+
+const (
+	disputeURL         = "/api/v1/disputes/{record_id}"
+	disputesURL        = "/api/v1/disputes"
+	disputeReviewURL   = "/api/v1/disputes/{record_id}/review"
+	disputeEvidenceURL = "/api/v1/disputes/{record_id}/evidence"
+	disputeResolveURL  = "/api/v1/disputes/{record_id}/resolve"
+)
+
+type handler struct {
+	disputeService dispute.Service
+	logger         logging.Logger
+}
+
+func NewHandler(service dispute.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{disputeService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(disputesURL, h.getDisputes).Methods(http.MethodGet)
+	router.HandleFunc(disputeURL, h.getDispute).Methods(http.MethodGet)
+
+	router.HandleFunc(disputesURL, h.openDispute).Methods(http.MethodPost)
+	router.HandleFunc(disputeReviewURL, h.reviewDispute).Methods(http.MethodPost)
+	router.HandleFunc(disputeEvidenceURL, h.addEvidence).Methods(http.MethodPost)
+	router.HandleFunc(disputeResolveURL, h.resolveDispute).Methods(http.MethodPost)
+}
+
+func (h *handler) getDispute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	disputeDTO, err := h.disputeService.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if disputeDTO.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	h.writeDispute(w, http.StatusOK, disputeDTO)
+}
+
+func (h *handler) getDisputes(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		h.logger.Errorf("error parsing limit query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing limit query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		h.logger.Errorf("error parsing offset query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing offset query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	filter, err := newFilterRequest(r.URL.Query())
+	if err != nil {
+		h.logger.Errorf("error parsing filter query params: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing filter query params: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	disputeDTOs, err := h.disputeService.GetFiltered(r.Context(), &filter, limit, offset)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	if len(disputeDTOs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	disputes := make([]Dispute, 0, len(disputeDTOs))
+	for _, dto := range disputeDTOs {
+		disputes = append(disputes, newDispute(dto))
+	}
+	response, err := json.Marshal(disputes)
+	if err != nil {
+		h.logger.Errorf("error marshaling disputes: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling disputes: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *handler) openDispute(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request OpenDisputeRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	openRequest := request.toOpenRequest()
+	disputeDTO, err := h.disputeService.Open(r.Context(), &openRequest)
+	if err != nil {
+		h.logger.Errorf("error opening dispute: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error opening dispute: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	h.writeDispute(w, http.StatusCreated, disputeDTO)
+}
+
+func (h *handler) reviewDispute(w http.ResponseWriter, r *http.Request) {
+	h.changeDispute(w, r, h.disputeService.Review)
+}
+
+func (h *handler) addEvidence(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request AddEvidenceRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	h.changeDispute(w, r, func(ctx context.Context, id int64) (dispute.DTO, error) {
+		return h.disputeService.AddEvidence(ctx, id, request.Note)
+	})
+}
+
+func (h *handler) resolveDispute(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request ResolveDisputeRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	h.changeDispute(w, r, func(ctx context.Context, id int64) (dispute.DTO, error) {
+		return h.disputeService.Resolve(ctx, id, dispute.Status(request.Outcome))
+	})
+}
+
+func (h *handler) changeDispute(w http.ResponseWriter, r *http.Request, change func(context.Context, int64) (dispute.DTO, error)) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	disputeDTO, err := change(r.Context(), id)
+	if err != nil {
+		h.logger.Errorf("error updating dispute: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error updating dispute: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	h.writeDispute(w, http.StatusOK, disputeDTO)
+}
+
+func (h *handler) writeDispute(w http.ResponseWriter, status int, dto dispute.DTO) {
+	response, err := json.Marshal(newDispute(dto))
+	if err != nil {
+		h.logger.Errorf("error marshaling dispute: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling dispute: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		return
+	}
+}
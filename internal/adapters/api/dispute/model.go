@@ -0,0 +1,81 @@
+package dispute
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/skwol/wallet/internal/domain/dispute"
+)
+
+func newDispute(dto dispute.DTO) Dispute {
+	d := Dispute{
+		Id:            int(dto.ID),
+		TransactionId: int(dto.TransactionID),
+		Reason:        DisputeReason(dto.Reason),
+		Status:        DisputeStatus(dto.Status),
+		HoldAmount:    float32(dto.HoldAmount),
+		OpenedAt:      dto.OpenedAt,
+		Evidence:      make([]Evidence, 0, len(dto.Evidence)),
+	}
+	if dto.HoldWalletID != 0 {
+		holdWalletID := int(dto.HoldWalletID)
+		d.HoldWalletId = &holdWalletID
+	}
+	if !dto.ResolvedAt.IsZero() {
+		d.ResolvedAt = &dto.ResolvedAt
+	}
+	if dto.ReversalTransactionID != 0 {
+		reversalTransactionID := int(dto.ReversalTransactionID)
+		d.ReversalTransactionId = &reversalTransactionID
+	}
+	for _, e := range dto.Evidence {
+		d.Evidence = append(d.Evidence, Evidence{Id: int(e.ID), Note: e.Note, CreatedAt: e.CreatedAt})
+	}
+	return d
+}
+
+func (r OpenDisputeRequest) toOpenRequest() dispute.OpenDisputeDTO {
+	dto := dispute.OpenDisputeDTO{
+		TransactionID: int64(r.TransactionId),
+		Reason:        dispute.Reason(r.Reason),
+	}
+	if r.Note != nil {
+		dto.Note = *r.Note
+	}
+	if r.Hold != nil {
+		dto.Hold = *r.Hold
+	}
+	return dto
+}
+
+func newFilterRequest(query url.Values) (dispute.FilterDisputesDTO, error) {
+	var (
+		filter dispute.FilterDisputesDTO
+		err    error
+	)
+	if filter.TransactionIDs, err = parseIDs(query["transaction_id"]); err != nil {
+		return filter, err
+	}
+	if filter.WalletIDs, err = parseIDs(query["wallet_id"]); err != nil {
+		return filter, err
+	}
+	for _, status := range query["status"] {
+		filter.Statuses = append(filter.Statuses, dispute.Status(status))
+	}
+	for _, reason := range query["reason"] {
+		filter.Reasons = append(filter.Reasons, dispute.Reason(reason))
+	}
+	return filter, nil
+}
+
+func parseIDs(values []string) ([]int64, error) {
+	var ids []int64
+	for _, value := range values {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
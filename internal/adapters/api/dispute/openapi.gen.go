@@ -0,0 +1,150 @@
+// Package dispute provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package dispute
+
+import (
+	"time"
+)
+
+// Defines values for DisputeReason.
+const (
+	Cancelled      DisputeReason = "cancelled"
+	Duplicate      DisputeReason = "duplicate"
+	Fraud          DisputeReason = "fraud"
+	NotAsDescribed DisputeReason = "not_as_described"
+	NotReceived    DisputeReason = "not_received"
+	Other          DisputeReason = "other"
+)
+
+// Defines values for DisputeStatus.
+const (
+	DisputeStatusLost        DisputeStatus = "lost"
+	DisputeStatusOpened      DisputeStatus = "opened"
+	DisputeStatusUnderReview DisputeStatus = "under_review"
+	DisputeStatusWon         DisputeStatus = "won"
+)
+
+// Defines values for ResolveDisputeRequestOutcome.
+const (
+	ResolveDisputeRequestOutcomeLost ResolveDisputeRequestOutcome = "lost"
+	ResolveDisputeRequestOutcomeWon  ResolveDisputeRequestOutcome = "won"
+)
+
+// AddEvidenceRequest defines model for AddEvidenceRequest.
+type AddEvidenceRequest struct {
+	Note string `json:"note"`
+}
+
+// Dispute defines model for Dispute.
+type Dispute struct {
+	Evidence []Evidence `json:"evidence"`
+
+	// amount held on the wallet
+	HoldAmount float32 `json:"hold_amount"`
+
+	// wallet with funds held while dispute is active
+	HoldWalletId *int `json:"hold_wallet_id,omitempty"`
+
+	// dispute id
+	Id         int           `json:"id"`
+	OpenedAt   time.Time     `json:"opened_at"`
+	Reason     DisputeReason `json:"reason"`
+	ResolvedAt *time.Time    `json:"resolved_at,omitempty"`
+
+	// id of the transaction reverting the disputed one
+	ReversalTransactionId *int          `json:"reversal_transaction_id,omitempty"`
+	Status                DisputeStatus `json:"status"`
+
+	// disputed transaction id
+	TransactionId int `json:"transaction_id"`
+}
+
+// DisputeReason defines model for DisputeReason.
+type DisputeReason string
+
+// DisputeStatus defines model for DisputeStatus.
+type DisputeStatus string
+
+// Error defines model for Error.
+type Error struct {
+	Code      *int    `json:"code,omitempty"`
+	Error     string  `json:"error"`
+	ErrorType *string `json:"errorType,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// Evidence defines model for Evidence.
+type Evidence struct {
+	CreatedAt time.Time `json:"created_at"`
+	Id        int       `json:"id"`
+	Note      string    `json:"note"`
+}
+
+// OpenDisputeRequest defines model for OpenDisputeRequest.
+type OpenDisputeRequest struct {
+	// hold disputed amount on receiver wallet until dispute is resolved
+	Hold          *bool         `json:"hold,omitempty"`
+	Note          *string       `json:"note,omitempty"`
+	Reason        DisputeReason `json:"reason"`
+	TransactionId int           `json:"transaction_id"`
+}
+
+// ResolveDisputeRequest defines model for ResolveDisputeRequest.
+type ResolveDisputeRequest struct {
+	Outcome ResolveDisputeRequestOutcome `json:"outcome"`
+}
+
+// ResolveDisputeRequestOutcome defines model for ResolveDisputeRequest.Outcome.
+type ResolveDisputeRequestOutcome string
+
+// PathParamDisputeID defines model for PathParamDisputeID.
+type PathParamDisputeID = float32
+
+// QueryParamLimit defines model for QueryParamLimit.
+type QueryParamLimit = float32
+
+// QueryParamOffset defines model for QueryParamOffset.
+type QueryParamOffset = float32
+
+// QueryParamReason defines model for QueryParamReason.
+type QueryParamReason = []DisputeReason
+
+// QueryParamStatus defines model for QueryParamStatus.
+type QueryParamStatus = []DisputeStatus
+
+// QueryParamTransactionID defines model for QueryParamTransactionID.
+type QueryParamTransactionID = []int
+
+// QueryParamWalletID defines model for QueryParamWalletID.
+type QueryParamWalletID = []int
+
+// GetDisputesParams defines parameters for GetDisputes.
+type GetDisputesParams struct {
+	// Limit of how many records returned
+	Limit QueryParamLimit `form:"limit" json:"limit"`
+
+	// Offset of returned records
+	Offset QueryParamOffset `form:"offset" json:"offset"`
+
+	// Filter by disputed transaction, can be repeated
+	TransactionId *QueryParamTransactionID `form:"transaction_id,omitempty" json:"transaction_id,omitempty"`
+
+	// Filter by sender or receiver of disputed transaction, can be repeated
+	WalletId *QueryParamWalletID `form:"wallet_id,omitempty" json:"wallet_id,omitempty"`
+
+	// Filter by status, can be repeated
+	Status *QueryParamStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Filter by reason, can be repeated
+	Reason *QueryParamReason `form:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// OpenDisputeJSONRequestBody defines body for OpenDispute for application/json ContentType.
+type OpenDisputeJSONRequestBody = OpenDisputeRequest
+
+// AddDisputeEvidenceJSONRequestBody defines body for AddDisputeEvidence for application/json ContentType.
+type AddDisputeEvidenceJSONRequestBody = AddEvidenceRequest
+
+// ResolveDisputeJSONRequestBody defines body for ResolveDispute for application/json ContentType.
+type ResolveDisputeJSONRequestBody = ResolveDisputeRequest
@@ -0,0 +1,197 @@
+package escrow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerapproval "github.com/skwol/wallet/internal/adapters/api/approval"
+	"github.com/skwol/wallet/internal/domain/escrow"
+)
+
+const (
+	escrowURL        = "/api/v1/escrows/{record_id}"
+	escrowsURL       = "/api/v1/escrows"
+	escrowConfirmURL = "/api/v1/escrows/{record_id}/confirm"
+	escrowDisputeURL = "/api/v1/escrows/{record_id}/dispute"
+	escrowRefundURL  = "/api/v1/escrows/{record_id}/refund"
+)
+
+type handler struct {
+	escrowService escrow.Service
+	logger        logging.Logger
+}
+
+func NewHandler(service escrow.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{escrowService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(escrowsURL, h.getAllEscrows).Methods(http.MethodGet)
+	router.HandleFunc(escrowURL, h.getEscrow).Methods(http.MethodGet)
+
+	router.HandleFunc(escrowsURL, h.createEscrow).Methods(http.MethodPost)
+	router.HandleFunc(escrowConfirmURL, h.confirmEscrow).Methods(http.MethodPost)
+	router.HandleFunc(escrowDisputeURL, h.disputeEscrow).Methods(http.MethodPost)
+	router.HandleFunc(escrowRefundURL, h.refundEscrow).Methods(http.MethodPost)
+}
+
+func (h *handler) getEscrow(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	escrowDTO, err := h.escrowService.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if escrowDTO.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	h.writeEscrow(w, http.StatusOK, escrowDTO)
+}
+
+func (h *handler) getAllEscrows(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		h.logger.Errorf("error parsing limit query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing limit query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		h.logger.Errorf("error parsing offset query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing offset query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	escrowDTOs, err := h.escrowService.GetAll(r.Context(), limit, offset)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if len(escrowDTOs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	escrows := make([]Escrow, 0, len(escrowDTOs))
+	for _, dto := range escrowDTOs {
+		escrows = append(escrows, newEscrow(dto))
+	}
+	response, err := json.Marshal(escrows)
+	if err != nil {
+		h.logger.Errorf("error marshaling escrows: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling escrows: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *handler) createEscrow(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request CreateEscrowRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	createRequest := request.toCreateRequest()
+	escrowDTO, err := h.escrowService.Create(r.Context(), &createRequest)
+	if err != nil {
+		if handlerapproval.WriteIfPending(w, h.logger, err) {
+			return
+		}
+		h.logger.Errorf("error creating escrow: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error creating escrow: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	h.writeEscrow(w, http.StatusCreated, escrowDTO)
+}
+
+func (h *handler) confirmEscrow(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request ConfirmEscrowRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	h.changeEscrow(w, r, func(ctx context.Context, id int64) (escrow.DTO, error) {
+		return h.escrowService.Confirm(ctx, id, escrow.Party(request.Party))
+	})
+}
+
+func (h *handler) disputeEscrow(w http.ResponseWriter, r *http.Request) {
+	h.changeEscrow(w, r, h.escrowService.Dispute)
+}
+
+func (h *handler) refundEscrow(w http.ResponseWriter, r *http.Request) {
+	h.changeEscrow(w, r, h.escrowService.Refund)
+}
+
+func (h *handler) changeEscrow(w http.ResponseWriter, r *http.Request, change func(context.Context, int64) (escrow.DTO, error)) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	escrowDTO, err := change(r.Context(), id)
+	if err != nil {
+		if handlerapproval.WriteIfPending(w, h.logger, err) {
+			return
+		}
+		h.logger.Errorf("error updating escrow: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error updating escrow: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	h.writeEscrow(w, http.StatusOK, escrowDTO)
+}
+
+func (h *handler) writeEscrow(w http.ResponseWriter, status int, dto escrow.DTO) {
+	response, err := json.Marshal(newEscrow(dto))
+	if err != nil {
+		h.logger.Errorf("error marshaling escrow: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling escrow: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		return
+	}
+}
@@ -0,0 +1,36 @@
+package escrow
+
+import (
+	"github.com/skwol/wallet/internal/domain/escrow"
+)
+
+func newEscrow(dto escrow.DTO) Escrow {
+	e := Escrow{
+		Id:                int(dto.ID),
+		BuyerId:           int(dto.Buyer.ID),
+		SellerId:          int(dto.Seller.ID),
+		Amount:            float32(dto.Amount),
+		Status:            EscrowStatus(dto.Status),
+		BuyerConfirmed:    dto.BuyerConfirmed,
+		SellerConfirmed:   dto.SellerConfirmed,
+		CreatedAt:         dto.CreatedAt,
+		ExpiresAt:         dto.ExpiresAt,
+		FundTransactionId: int(dto.FundTransactionID),
+	}
+	if !dto.SettledAt.IsZero() {
+		e.SettledAt = &dto.SettledAt
+	}
+	if dto.SettleTransactionID != 0 {
+		settleTransactionID := int(dto.SettleTransactionID)
+		e.SettleTransactionId = &settleTransactionID
+	}
+	return e
+}
+
+func (r CreateEscrowRequest) toCreateRequest() escrow.CreateEscrowDTO {
+	return escrow.CreateEscrowDTO{
+		BuyerID:  int64(r.BuyerId),
+		SellerID: int64(r.SellerId),
+		Amount:   float64(r.Amount),
+	}
+}
@@ -0,0 +1,103 @@
+// Package escrow provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package escrow
+
+import (
+	"time"
+)
+
+const (
+	ApiTokenScopes = "ApiToken.Scopes"
+)
+
+// Defines values for ConfirmEscrowRequestParty.
+const (
+	Buyer  ConfirmEscrowRequestParty = "buyer"
+	Seller ConfirmEscrowRequestParty = "seller"
+)
+
+// Defines values for EscrowStatus.
+const (
+	Disputed EscrowStatus = "disputed"
+	Funded   EscrowStatus = "funded"
+	Refunded EscrowStatus = "refunded"
+	Released EscrowStatus = "released"
+)
+
+// ConfirmEscrowRequest defines model for ConfirmEscrowRequest.
+type ConfirmEscrowRequest struct {
+	Party ConfirmEscrowRequestParty `json:"party"`
+}
+
+// ConfirmEscrowRequestParty defines model for ConfirmEscrowRequest.Party.
+type ConfirmEscrowRequestParty string
+
+// CreateEscrowRequest defines model for CreateEscrowRequest.
+type CreateEscrowRequest struct {
+	Amount   float32 `json:"amount"`
+	BuyerId  int     `json:"buyer_id"`
+	SellerId int     `json:"seller_id"`
+}
+
+// Error defines model for Error.
+type Error struct {
+	Code      *int    `json:"code,omitempty"`
+	Error     string  `json:"error"`
+	ErrorType *string `json:"errorType,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// Escrow defines model for Escrow.
+type Escrow struct {
+	// escrowed amount
+	Amount         float32 `json:"amount"`
+	BuyerConfirmed bool    `json:"buyer_confirmed"`
+
+	// buyer wallet id
+	BuyerId   int       `json:"buyer_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// id of the transaction moving money from buyer into escrow
+	FundTransactionId int `json:"fund_transaction_id"`
+
+	// escrow id
+	Id              int  `json:"id"`
+	SellerConfirmed bool `json:"seller_confirmed"`
+
+	// seller wallet id
+	SellerId int `json:"seller_id"`
+
+	// id of the transaction releasing or refunding the money
+	SettleTransactionId *int         `json:"settle_transaction_id,omitempty"`
+	SettledAt           *time.Time   `json:"settled_at,omitempty"`
+	Status              EscrowStatus `json:"status"`
+}
+
+// EscrowStatus defines model for Escrow.Status.
+type EscrowStatus string
+
+// PathParamEscrowID defines model for PathParamEscrowID.
+type PathParamEscrowID = float32
+
+// QueryParamLimit defines model for QueryParamLimit.
+type QueryParamLimit = float32
+
+// QueryParamOffset defines model for QueryParamOffset.
+type QueryParamOffset = float32
+
+// GetEscrowsParams defines parameters for GetEscrows.
+type GetEscrowsParams struct {
+	// Limit of how many records returned
+	Limit QueryParamLimit `form:"limit" json:"limit"`
+
+	// Offset of returned records
+	Offset QueryParamOffset `form:"offset" json:"offset"`
+}
+
+// CreateEscrowJSONRequestBody defines body for CreateEscrow for application/json ContentType.
+type CreateEscrowJSONRequestBody = CreateEscrowRequest
+
+// ConfirmEscrowJSONRequestBody defines body for ConfirmEscrow for application/json ContentType.
+type ConfirmEscrowJSONRequestBody = ConfirmEscrowRequest
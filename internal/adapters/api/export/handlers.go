@@ -0,0 +1,94 @@
+package export
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/export"
+)
+
+const walletExportURL = "/api/v1/wallets/{record_id}/export"
+
+// StreamingURLs are written while transactions are read, they are served without the request timeout.
+var StreamingURLs = []string{walletExportURL}
+
+type handler struct {
+	exportService export.Service
+	logger        logging.Logger
+}
+
+func NewHandler(service export.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{exportService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(walletExportURL, h.exportWallet).Methods(http.MethodGet)
+}
+
+func (h *handler) exportWallet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	from, to, loc, err := adapters.ParsePeriod(r)
+	if err != nil {
+		h.logger.Errorf("error reading export period: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading export period: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	dto := export.ExportDTO{WalletID: id, Format: readFormat(r), From: from, To: to, Location: loc}
+
+	body := adapters.NewFileWriter(w, dto.Format.ContentType(), fmt.Sprintf("wallet-%d.%s", id, dto.Format))
+	walletDTO, err := h.exportService.Export(r.Context(), body, &dto)
+	if err != nil && body.Started() {
+		h.logger.Errorf("error streaming export: %s", err.Error())
+		panic(http.ErrAbortHandler)
+	}
+	if errors.Is(err, export.ErrUnknownFormat) || errors.Is(err, export.ErrInvalidPeriod) {
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if walletDTO.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err := body.Close(); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+	}
+}
+
+// readFormat takes the format query param or, without it, the first export format in the Accept header,
+// export is ofx when the client accepts anything or none of the formats. Unknown params are left to the service.
+func readFormat(r *http.Request) export.Format {
+	if value := r.FormValue("format"); value != "" {
+		return export.Format(value)
+	}
+	for _, value := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		for format, contentType := range export.Formats {
+			if mediaType == contentType {
+				return format
+			}
+		}
+	}
+	return export.FormatOFX
+}
@@ -0,0 +1,33 @@
+// Package export provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package export
+
+// Error defines model for Error.
+type Error struct {
+	Code      *int    `json:"code,omitempty"`
+	Error     string  `json:"error"`
+	ErrorType *string `json:"errorType,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// PathParamWalletID defines model for PathParamWalletID.
+type PathParamWalletID = int
+
+// ExportWalletParams defines parameters for ExportWallet.
+type ExportWalletParams struct {
+	// Start of the period, RFC 3339 timestamp or a date which is the midnight in time_zone
+	From string `form:"from" json:"from"`
+
+	// End of the period, RFC 3339 timestamp which is not included or a date which is included as a whole day
+	To string `form:"to" json:"to"`
+
+	// IANA time zone of dates in the period and of QIF dates, UTC by default
+	TimeZone *string `form:"time_zone,omitempty" json:"time_zone,omitempty"`
+
+	// Format of the export, without it the format is taken from Accept header and is ofx by default. ofx is OFX 2.2 bank statement
+	Format *ExportWalletParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// ExportWalletParamsFormat defines parameters for ExportWallet.
+type ExportWalletParamsFormat string
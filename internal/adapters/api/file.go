@@ -0,0 +1,62 @@
+package adapters
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// fileFlushBytes is how much of the file is written before it is pushed to the client.
+const fileFlushBytes = 32 << 10
+
+// FileWriter writes the file straight into the response while it is rendered, headers are sent with
+// the first write so that errors before it still get a proper status. Once it is started an error can
+// only drop the connection, so that the client does not take a cut file as complete.
+type FileWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	// fileName makes the response an attachment, it is shown inline when empty
+	fileName  string
+	started   bool
+	unflushed int
+}
+
+func NewFileWriter(w http.ResponseWriter, contentType, fileName string) *FileWriter {
+	return &FileWriter{w: w, contentType: contentType, fileName: fileName}
+}
+
+func (fw *FileWriter) start() {
+	fw.started = true
+	fw.w.Header().Set("Content-Type", fw.contentType)
+	if fw.fileName != "" {
+		fw.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fw.fileName))
+	}
+	fw.w.WriteHeader(http.StatusOK)
+}
+
+func (fw *FileWriter) Write(p []byte) (int, error) {
+	if !fw.started {
+		fw.start()
+	}
+	n, err := fw.w.Write(p)
+	fw.unflushed += n
+	if err == nil && fw.unflushed >= fileFlushBytes {
+		fw.unflushed = 0
+		if flusher, ok := fw.w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	return n, err
+}
+
+// Started tells whether the status is already sent.
+func (fw *FileWriter) Started() bool {
+	return fw.started
+}
+
+// Close sends the headers of the empty file when nothing was written.
+func (fw *FileWriter) Close() error {
+	if !fw.started {
+		fw.start()
+	}
+	return nil
+}
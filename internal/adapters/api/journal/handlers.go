@@ -0,0 +1,78 @@
+package journal
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/journal"
+)
+
+const journalURL = "/api/v1/journal"
+
+// StreamingURLs are written while statements are read, they are served without the request timeout.
+var StreamingURLs = []string{journalURL}
+
+type handler struct {
+	journalService journal.Service
+	logger         logging.Logger
+}
+
+func NewHandler(service journal.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{journalService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(journalURL, h.exportJournal).Methods(http.MethodGet)
+}
+
+func (h *handler) exportJournal(w http.ResponseWriter, r *http.Request) {
+	from, to, loc, err := adapters.ParsePeriod(r)
+	if err != nil {
+		h.logger.Errorf("error reading journal period: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading journal period: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	dto := journal.ExportDTO{Format: journal.FormatLedger, From: from, To: to, Location: loc}
+	if value := r.FormValue("format"); value != "" {
+		dto.Format = journal.Format(value)
+	}
+	for _, value := range r.Form["wallet_id"] {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			h.logger.Errorf("error parsing wallet id: %s", err.Error())
+			http.Error(w, fmt.Sprintf("error parsing wallet id: %s", err.Error()), http.StatusUnprocessableEntity)
+			return
+		}
+		dto.WalletIDs = append(dto.WalletIDs, id)
+	}
+
+	fileName := fmt.Sprintf("journal-%s.%s", from.In(loc).Format("2006-01-02"), dto.Format.Extension())
+	body := adapters.NewFileWriter(w, "text/plain; charset=utf-8", fileName)
+	err = h.journalService.Export(r.Context(), body, &dto)
+	if err != nil && body.Started() {
+		h.logger.Errorf("error streaming journal: %s", err.Error())
+		panic(http.ErrAbortHandler)
+	}
+	switch {
+	case errors.Is(err, journal.ErrWalletNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, journal.ErrUnknownFormat), errors.Is(err, journal.ErrInvalidPeriod):
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if err := body.Close(); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+	}
+}
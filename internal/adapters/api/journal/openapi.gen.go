@@ -0,0 +1,33 @@
+// Package journal provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package journal
+
+// Error defines model for Error.
+type Error struct {
+	Code      *int    `json:"code,omitempty"`
+	Error     string  `json:"error"`
+	ErrorType *string `json:"errorType,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// ExportJournalParams defines parameters for ExportJournal.
+type ExportJournalParams struct {
+	// Start of the period, RFC 3339 timestamp or a date which is the midnight in time_zone
+	From string `form:"from" json:"from"`
+
+	// End of the period, RFC 3339 timestamp which is not included or a date which is included as a whole day
+	To string `form:"to" json:"to"`
+
+	// IANA time zone of dates in the period and in the journal, UTC by default
+	TimeZone *string `form:"time_zone,omitempty" json:"time_zone,omitempty"`
+
+	// Wallets of the journal, all wallets by default
+	WalletId *[]int64 `form:"wallet_id,omitempty" json:"wallet_id,omitempty"`
+
+	// Syntax of the journal
+	Format *ExportJournalParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// ExportJournalParamsFormat defines parameters for ExportJournal.
+type ExportJournalParamsFormat string
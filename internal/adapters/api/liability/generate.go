@@ -0,0 +1,2 @@
+//go:generate go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen --old-config-style  --package=liability --generate=types -alias-types -o openapi.gen.go openapi.yaml
+package liability
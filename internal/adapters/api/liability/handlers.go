@@ -0,0 +1,83 @@
+package liability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/liability"
+)
+
+const (
+	liabilitiesURL    = "/api/v1/liabilities"
+	liabilityProofURL = "/api/v1/wallets/{record_id}/liability-proof"
+)
+
+type handler struct {
+	liabilityService liability.Service
+	logger           logging.Logger
+}
+
+func NewHandler(service liability.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{liabilityService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(liabilitiesURL, h.getLiabilities).Methods(http.MethodGet)
+	router.HandleFunc(liabilityProofURL, h.getLiabilityProof).Methods(http.MethodGet)
+}
+
+func (h *handler) getLiabilities(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.liabilityService.GetLatest(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.write(w, newTree(tree))
+}
+
+func (h *handler) getLiabilityProof(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	proof, err := h.liabilityService.GetProof(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.write(w, newLiabilityProof(proof))
+}
+
+func (h *handler) writeError(w http.ResponseWriter, err error) {
+	h.logger.Errorf("error returned from service: %s", err.Error())
+	code := http.StatusInternalServerError
+	if errors.Is(err, liability.ErrNoTree) || errors.Is(err, liability.ErrWalletNotInTree) {
+		code = http.StatusNotFound
+	}
+	http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), code)
+}
+
+func (h *handler) write(w http.ResponseWriter, v interface{}) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		h.logger.Errorf("error marshaling response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
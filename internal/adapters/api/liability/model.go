@@ -0,0 +1,28 @@
+package liability
+
+import (
+	"github.com/skwol/wallet/internal/domain/liability"
+)
+
+func newTree(dto liability.TreeDTO) Tree {
+	return Tree{
+		CreatedAt: dto.CreatedAt,
+		Id:        dto.ID,
+		Root:      dto.Root.String(),
+		Total:     dto.Total.String(),
+		Wallets:   dto.Wallets,
+	}
+}
+
+func newLiabilityProof(dto liability.ProofDTO) LiabilityProof {
+	proof := Proof{
+		Balance:  dto.Proof.Sum.String(),
+		Path:     make([]Step, 0, len(dto.Proof.Path)),
+		Salt:     dto.Proof.Salt.String(),
+		WalletId: dto.Proof.ID,
+	}
+	for _, step := range dto.Proof.Path {
+		proof.Path = append(proof.Path, Step{Hash: step.Hash.String(), Left: step.Left, Sum: step.Sum.String()})
+	}
+	return LiabilityProof{Proof: proof, Tree: newTree(dto.Tree)}
+}
@@ -0,0 +1,52 @@
+// Package liability provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package liability
+
+import (
+	"time"
+)
+
+// LiabilityProof defines model for LiabilityProof.
+type LiabilityProof struct {
+	Proof Proof `json:"proof"`
+	Tree  Tree  `json:"tree"`
+}
+
+// Proof defines model for Proof.
+type Proof struct {
+	// balance of the wallet in the tree with four decimals
+	Balance string `json:"balance"`
+
+	// siblings from the leaf up to the root
+	Path []Step `json:"path"`
+
+	// hex encoded salt of the leaf
+	Salt     string `json:"salt"`
+	WalletId int64  `json:"wallet_id"`
+}
+
+// Step defines model for Step.
+type Step struct {
+	// hex encoded hash of the sibling
+	Hash string `json:"hash"`
+
+	// the sibling is the left child of the parent
+	Left bool `json:"left"`
+
+	// sum of the sibling with four decimals
+	Sum string `json:"sum"`
+}
+
+// Tree defines model for Tree.
+type Tree struct {
+	CreatedAt time.Time `json:"created_at"`
+	Id        int64     `json:"id"`
+
+	// hex encoded hash of the root
+	Root string `json:"root"`
+
+	// sum of all balances with four decimals
+	Total   string `json:"total"`
+	Wallets int64  `json:"wallets"`
+}
@@ -0,0 +1,37 @@
+package adapters
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/pagination"
+)
+
+// OffsetRequested is true for clients still paging with offset, they get the responses without cursors.
+func OffsetRequested(r *http.Request) bool {
+	return r.FormValue("offset") != ""
+}
+
+// ParsePage reads optional limit, cursor and total query params of the list endpoints.
+func ParsePage(r *http.Request) (pagination.Page, error) {
+	var limit int
+	if value := r.FormValue("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			return pagination.Page{}, errors.Wrap(err, "error parsing limit query param")
+		}
+		if limit <= 0 {
+			return pagination.Page{}, pagination.ErrInvalidLimit
+		}
+	}
+	var total bool
+	if value := r.FormValue("total"); value != "" {
+		var err error
+		if total, err = strconv.ParseBool(value); err != nil {
+			return pagination.Page{}, errors.Wrap(err, "error parsing total query param")
+		}
+	}
+	return pagination.New(limit, r.FormValue("cursor"), total)
+}
@@ -0,0 +1,64 @@
+package adapters
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const dateLayout = "2006-01-02"
+
+var ErrMissingPeriod = errors.New("from and to are required")
+
+// ParsePeriod reads the period [from, to) of from, to and optional time_zone query params, see ParsePeriodValues.
+func ParsePeriod(r *http.Request) (from, to time.Time, loc *time.Location, err error) {
+	return ParsePeriodValues(r.FormValue("from"), r.FormValue("to"), r.FormValue("time_zone"))
+}
+
+// ParsePeriodValues reads the period [from, to) in the time zone, UTC when it is empty.
+// Both are RFC 3339 timestamps or dates, dates are midnights in the time zone and the whole to date is included.
+func ParsePeriodValues(fromValue, toValue, timeZone string) (from, to time.Time, loc *time.Location, err error) {
+	loc = time.UTC
+	if timeZone != "" {
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			return from, to, nil, errors.Wrapf(err, "unknown time zone %q", timeZone)
+		}
+	}
+	if fromValue == "" || toValue == "" {
+		return from, to, nil, ErrMissingPeriod
+	}
+	if from, err = parsePeriodTime(fromValue, loc, false); err != nil {
+		return from, to, nil, errors.Wrap(err, "error parsing from")
+	}
+	if to, err = parsePeriodTime(toValue, loc, true); err != nil {
+		return from, to, nil, errors.Wrap(err, "error parsing to")
+	}
+	return from, to, loc, nil
+}
+
+// ParseMoment reads an RFC 3339 timestamp or a date which is the end of that day in the time zone, UTC when it is empty.
+func ParseMoment(value, timeZone string) (time.Time, error) {
+	loc := time.UTC
+	if timeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			return time.Time{}, errors.Wrapf(err, "unknown time zone %q", timeZone)
+		}
+	}
+	return parsePeriodTime(value, loc, true)
+}
+
+func parsePeriodTime(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+		return timestamp, nil
+	}
+	day, err := time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, errors.Errorf("%q is neither RFC 3339 timestamp nor date", value)
+	}
+	if endOfDay {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
@@ -0,0 +1,77 @@
+package receipt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/receipt"
+)
+
+const (
+	receiptURL     = "/api/v1/transactions/{record_id}/receipt"
+	receiptKeysURL = "/api/v1/receipt-keys"
+)
+
+type handler struct {
+	receiptService receipt.Service
+	logger         logging.Logger
+}
+
+func NewHandler(service receipt.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{receiptService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(receiptURL, h.getReceipt).Methods(http.MethodGet)
+	router.HandleFunc(receiptKeysURL, h.getKeys).Methods(http.MethodGet)
+}
+
+func (h *handler) getReceipt(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	signed, err := h.receiptService.GetReceipt(r.Context(), id)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, receipt.ErrTransactionNotFound):
+			code = http.StatusNotFound
+		case errors.Is(err, receipt.ErrNotTransfer):
+			code = http.StatusUnprocessableEntity
+		}
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), code)
+		return
+	}
+	h.write(w, newSignedReceipt(signed))
+}
+
+func (h *handler) getKeys(w http.ResponseWriter, r *http.Request) {
+	h.write(w, newKeySet(h.receiptService.GetKeys()))
+}
+
+func (h *handler) write(w http.ResponseWriter, v interface{}) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		h.logger.Errorf("error marshaling response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
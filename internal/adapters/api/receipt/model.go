@@ -0,0 +1,34 @@
+package receipt
+
+import (
+	"github.com/skwol/wallet/pkg/receipt"
+)
+
+func newSignedReceipt(signed receipt.Signed) SignedReceipt {
+	r := signed.Receipt
+	result := SignedReceipt{
+		KeyId: signed.KeyID,
+		Receipt: Receipt{
+			Amount:          r.Amount,
+			Date:            r.Date,
+			ParentPaymentId: r.ParentPaymentID,
+			ReceiverId:      r.ReceiverID,
+			SenderId:        r.SenderID,
+			TransactionId:   r.TransactionID,
+			Type:            r.Type,
+		},
+		Signature: signed.Signature,
+	}
+	if r.Hash != "" {
+		result.Receipt.Hash = &r.Hash
+	}
+	return result
+}
+
+func newKeySet(keys receipt.KeySet) KeySet {
+	result := KeySet{Keys: make([]Key, 0, len(keys.Keys))}
+	for _, k := range keys.Keys {
+		result.Keys = append(result.Keys, Key{Alg: k.Algorithm, Crv: k.Curve, Kid: k.KeyID, Kty: k.KeyType, Use: k.Use, X: k.X})
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+// Package receipt provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package receipt
+
+// Key defines model for Key.
+type Key struct {
+	// EdDSA
+	Alg string `json:"alg"`
+
+	// Ed25519
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+
+	// OKP
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+
+	// base64url encoded public key
+	X string `json:"x"`
+}
+
+// KeySet defines model for KeySet.
+type KeySet struct {
+	Keys []Key `json:"keys"`
+}
+
+// Receipt defines model for Receipt.
+type Receipt struct {
+	// amount with four decimals
+	Amount string `json:"amount"`
+
+	// RFC 3339 date in UTC with microseconds
+	Date string `json:"date"`
+
+	// hex encoded hash of the transaction in the chain of the transaction log
+	Hash            *string `json:"hash,omitempty"`
+	ParentPaymentId *int64  `json:"parent_payment_id,omitempty"`
+	ReceiverId      int64   `json:"receiver_id"`
+	SenderId        int64   `json:"sender_id"`
+	TransactionId   int64   `json:"transaction_id"`
+	Type            string  `json:"type"`
+}
+
+// SignedReceipt defines model for SignedReceipt.
+type SignedReceipt struct {
+	// id of the key in the key set
+	KeyId   string  `json:"key_id"`
+	Receipt Receipt `json:"receipt"`
+
+	// base64url encoded Ed25519 signature of the receipt as canonical json (RFC 8785)
+	Signature string `json:"signature"`
+}
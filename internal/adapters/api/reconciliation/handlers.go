@@ -0,0 +1,92 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/actor"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/reconciliation"
+)
+
+const (
+	reconciliationURL    = "/api/v1/reconciliation"
+	reconciliationFixURL = "/api/v1/reconciliation/fix"
+)
+
+type handler struct {
+	reconciliationService reconciliation.Service
+	logger                logging.Logger
+}
+
+func NewHandler(service reconciliation.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{reconciliationService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(reconciliationURL, h.reconcile).Methods(http.MethodGet)
+	router.HandleFunc(reconciliationFixURL, h.fix).Methods(http.MethodPost)
+}
+
+func (h *handler) reconcile(w http.ResponseWriter, r *http.Request) {
+	report, err := h.reconciliationService.Reconcile(r.Context())
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	h.writeReport(w, report)
+}
+
+func (h *handler) fix(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request FixRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	report, err := h.reconciliationService.Fix(r.Context(), reconciliation.FixDTO{Reason: request.Reason, Actor: actor.FromContext(r.Context())})
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		code := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, reconciliation.ErrMissingReason), errors.Is(err, reconciliation.ErrMissingActor):
+			code = http.StatusUnprocessableEntity
+		case errors.Is(err, reconciliation.ErrBalanceChanged):
+			code = http.StatusConflict
+		}
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), code)
+		return
+	}
+	h.writeReport(w, report)
+}
+
+func (h *handler) writeReport(w http.ResponseWriter, report reconciliation.ReportDTO) {
+	response, err := json.Marshal(NewReport(report))
+	if err != nil {
+		h.logger.Errorf("error marshaling reconciliation report: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling reconciliation report: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
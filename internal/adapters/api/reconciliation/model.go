@@ -0,0 +1,40 @@
+package reconciliation
+
+import (
+	"github.com/skwol/wallet/internal/domain/reconciliation"
+)
+
+// NewReport is the json report of the API and of walletctl reconcile.
+func NewReport(dto reconciliation.ReportDTO) Report {
+	report := Report{
+		CreatedAt:     dto.CreatedAt,
+		Wallets:       int(dto.Wallets),
+		Discrepancies: make([]Discrepancy, 0, len(dto.Discrepancies)),
+	}
+	for _, d := range dto.Discrepancies {
+		report.Discrepancies = append(report.Discrepancies, Discrepancy{
+			WalletId:   int(d.WalletID),
+			Balance:    d.Balance,
+			Computed:   d.Computed,
+			Difference: d.Difference,
+		})
+	}
+	if dto.Adjustments == nil {
+		return report
+	}
+	adjustments := make([]Adjustment, 0, len(dto.Adjustments))
+	for _, a := range dto.Adjustments {
+		adjustments = append(adjustments, Adjustment{
+			Id:            int(a.ID),
+			WalletId:      int(a.WalletID),
+			TransactionId: int(a.TransactionID),
+			Type:          AdjustmentType(a.Type),
+			Amount:        a.Amount,
+			Reason:        a.Reason,
+			Actor:         a.Actor,
+			CreatedAt:     a.CreatedAt,
+		})
+	}
+	report.Adjustments = &adjustments
+	return report
+}
@@ -0,0 +1,76 @@
+// Package reconciliation provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package reconciliation
+
+import (
+	"time"
+)
+
+const (
+	ApiTokenScopes = "ApiToken.Scopes"
+)
+
+// Defines values for AdjustmentType.
+const (
+	Deposit  AdjustmentType = "deposit"
+	Withdraw AdjustmentType = "withdraw"
+)
+
+// Adjustment defines model for Adjustment.
+type Adjustment struct {
+	Actor     string    `json:"actor"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+	Id        int       `json:"id"`
+	Reason    string    `json:"reason"`
+
+	// adjusting transaction
+	TransactionId int            `json:"transaction_id"`
+	Type          AdjustmentType `json:"type"`
+	WalletId      int            `json:"wallet_id"`
+}
+
+// AdjustmentType defines model for Adjustment.Type.
+type AdjustmentType string
+
+// Discrepancy defines model for Discrepancy.
+type Discrepancy struct {
+	// balance kept by the wallet
+	Balance float64 `json:"balance"`
+
+	// sum of deposits, withdrawals and transfers in and out of the wallet
+	Computed float64 `json:"computed"`
+
+	// balance minus computed
+	Difference float64 `json:"difference"`
+	WalletId   int     `json:"wallet_id"`
+}
+
+// Error defines model for Error.
+type Error struct {
+	Code      *int    `json:"code,omitempty"`
+	Error     string  `json:"error"`
+	ErrorType *string `json:"errorType,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// FixRequest defines model for FixRequest.
+type FixRequest struct {
+	// audit reason of the adjustments
+	Reason string `json:"reason"`
+}
+
+// Report defines model for Report.
+type Report struct {
+	// adjustments made by the fix
+	Adjustments   *[]Adjustment `json:"adjustments,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+
+	// number of reconciled wallets
+	Wallets int `json:"wallets"`
+}
+
+// FixReconciliationJSONRequestBody defines body for FixReconciliation for application/json ContentType.
+type FixReconciliationJSONRequestBody = FixRequest
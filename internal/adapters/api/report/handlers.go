@@ -0,0 +1,139 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/report"
+)
+
+const (
+	reportsURL    = "/api/v1/reports"
+	reportURL     = "/api/v1/reports/{record_id}"
+	reportFileURL = "/api/v1/reports/{record_id}/file"
+)
+
+// StreamingURLs are downloads of report files, they are served without the request timeout.
+var StreamingURLs = []string{reportFileURL}
+
+type handler struct {
+	reportService report.Service
+	logger        logging.Logger
+}
+
+func NewHandler(service report.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{reportService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(reportURL, h.getReport).Methods(http.MethodGet)
+	router.HandleFunc(reportFileURL, h.getReportFile).Methods(http.MethodGet)
+
+	router.HandleFunc(reportsURL, h.createReport).Methods(http.MethodPost)
+}
+
+func (h *handler) createReport(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request CreateReportRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	createRequest, err := request.toCreateRequest()
+	if err != nil {
+		h.logger.Errorf("error parsing filter: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing filter: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	reportDTO, err := h.reportService.Create(r.Context(), &createRequest)
+	if err != nil {
+		h.logger.Errorf("error creating report: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error creating report: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	h.writeReport(w, http.StatusAccepted, reportDTO)
+}
+
+func (h *handler) getReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	reportDTO, err := h.reportService.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if reportDTO.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	h.writeReport(w, http.StatusOK, reportDTO)
+}
+
+func (h *handler) getReportFile(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	reportDTO, file, err := h.reportService.Open(r.Context(), id)
+	switch {
+	case errors.Is(err, report.ErrNotDone), errors.Is(err, report.ErrReportFailed):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, report.ErrReportExpired):
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	case err != nil:
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	case reportDTO.ID == 0:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	name := fmt.Sprintf("report-%d.%s", reportDTO.ID, reportDTO.Format)
+	w.Header().Set("Content-Disposition", "attachment; filename="+name)
+	w.Header().Set("Content-Type", reportDTO.Format.ContentType())
+	// ServeContent handles range requests, so an interrupted download of a big file can be resumed
+	http.ServeContent(w, r, name, reportDTO.FinishedAt, file)
+}
+
+func (h *handler) writeReport(w http.ResponseWriter, status int, dto report.DTO) {
+	response, err := json.Marshal(newReport(dto))
+	if err != nil {
+		h.logger.Errorf("error marshaling report: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling report: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		return
+	}
+}
@@ -0,0 +1,49 @@
+package report
+
+import (
+	"github.com/skwol/wallet/internal/domain/report"
+)
+
+func newReport(dto report.DTO) Report {
+	r := Report{
+		Id:        int(dto.ID),
+		Format:    ReportFormat(dto.Format),
+		TimeZone:  dto.TimeZone,
+		Status:    ReportStatus(dto.Status),
+		Rows:      dto.Rows,
+		Total:     dto.Total,
+		Progress:  float32(dto.Progress()),
+		CreatedAt: dto.CreatedAt,
+	}
+	if dto.Error != "" {
+		r.Error = &dto.Error
+	}
+	if !dto.StartedAt.IsZero() {
+		r.StartedAt = &dto.StartedAt
+	}
+	if !dto.FinishedAt.IsZero() {
+		r.FinishedAt = &dto.FinishedAt
+	}
+	if !dto.ExpiresAt.IsZero() {
+		r.ExpiresAt = &dto.ExpiresAt
+	}
+	return r
+}
+
+func (r CreateReportRequest) toCreateRequest() (report.CreateReportDTO, error) {
+	dto := report.CreateReportDTO{Format: report.FormatCSV}
+	if r.Format != nil {
+		dto.Format = report.Format(*r.Format)
+	}
+	if r.TimeZone != nil {
+		dto.TimeZone = *r.TimeZone
+	}
+	if r.Filter != nil {
+		filter, err := r.Filter.ToFilterRequest()
+		if err != nil {
+			return dto, err
+		}
+		dto.Filter = filter
+	}
+	return dto, nil
+}
@@ -0,0 +1,94 @@
+// Package report provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package report
+
+import (
+	"time"
+
+	"github.com/skwol/wallet/internal/adapters/api/transaction"
+)
+
+// Defines values for CreateReportRequestFormat.
+const (
+	CreateReportRequestFormatCsv     CreateReportRequestFormat = "csv"
+	CreateReportRequestFormatJsonl   CreateReportRequestFormat = "jsonl"
+	CreateReportRequestFormatParquet CreateReportRequestFormat = "parquet"
+	CreateReportRequestFormatXlsx    CreateReportRequestFormat = "xlsx"
+)
+
+// Defines values for ReportFormat.
+const (
+	ReportFormatCsv     ReportFormat = "csv"
+	ReportFormatJsonl   ReportFormat = "jsonl"
+	ReportFormatParquet ReportFormat = "parquet"
+	ReportFormatXlsx    ReportFormat = "xlsx"
+)
+
+// Defines values for ReportStatus.
+const (
+	Done    ReportStatus = "done"
+	Expired ReportStatus = "expired"
+	Failed  ReportStatus = "failed"
+	Queued  ReportStatus = "queued"
+	Running ReportStatus = "running"
+)
+
+// CreateReportRequest defines model for CreateReportRequest.
+type CreateReportRequest struct {
+	// same filter as the body of POST /transactions
+	Filter *transaction.Filter        `json:"filter,omitempty"`
+	Format *CreateReportRequestFormat `json:"format,omitempty"`
+
+	// IANA time zone of the report timestamps, UTC by default
+	TimeZone *string `json:"time_zone,omitempty"`
+}
+
+// CreateReportRequestFormat defines model for CreateReportRequest.Format.
+type CreateReportRequestFormat string
+
+// Error defines model for Error.
+type Error struct {
+	Code      *int    `json:"code,omitempty"`
+	Error     string  `json:"error"`
+	ErrorType *string `json:"errorType,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// Report defines model for Report.
+type Report struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// reason of the failure
+	Error      *string      `json:"error,omitempty"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	Format     ReportFormat `json:"format"`
+
+	// report job id
+	Id int `json:"id"`
+
+	// share of rows written, from 0 to 1
+	Progress float32 `json:"progress"`
+
+	// rows written so far
+	Rows      int64        `json:"rows"`
+	StartedAt *time.Time   `json:"started_at,omitempty"`
+	Status    ReportStatus `json:"status"`
+	TimeZone  string       `json:"time_zone"`
+
+	// rows matched by the filter when the job started
+	Total int64 `json:"total"`
+}
+
+// ReportFormat defines model for Report.Format.
+type ReportFormat string
+
+// ReportStatus defines model for Report.Status.
+type ReportStatus string
+
+// PathParamReportID defines model for PathParamReportID.
+type PathParamReportID = int
+
+// CreateReportJSONRequestBody defines body for CreateReport for application/json ContentType.
+type CreateReportJSONRequestBody = CreateReportRequest
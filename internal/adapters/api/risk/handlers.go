@@ -0,0 +1,83 @@
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/risk"
+)
+
+const riskDecisionsURL = "/api/v1/risk/decisions"
+
+type handler struct {
+	riskService risk.Service
+	logger      logging.Logger
+}
+
+func NewHandler(service risk.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{riskService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(riskDecisionsURL, h.getDecisions).Methods(http.MethodGet)
+}
+
+func (h *handler) getDecisions(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		h.logger.Errorf("error parsing limit query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing limit query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		h.logger.Errorf("error parsing offset query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing offset query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	decision := risk.DecisionReview
+	if value := r.FormValue("decision"); value != "" {
+		decision = risk.Decision(value)
+	}
+
+	decisionDTOs, err := h.riskService.GetByDecision(r.Context(), decision, limit, offset)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		status := http.StatusInternalServerError
+		if errors.Is(err, risk.ErrUnknownDecision) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), status)
+		return
+	}
+	if len(decisionDTOs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	decisions := make([]RiskDecision, 0, len(decisionDTOs))
+	for _, dto := range decisionDTOs {
+		decisions = append(decisions, newRiskDecision(dto))
+	}
+	response, err := json.Marshal(decisions)
+	if err != nil {
+		h.logger.Errorf("error marshaling risk decisions: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling risk decisions: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,34 @@
+package risk
+
+import (
+	"github.com/skwol/wallet/internal/domain/risk"
+)
+
+func newRiskDecision(dto risk.DecisionDTO) RiskDecision {
+	d := RiskDecision{
+		Id:        int(dto.ID),
+		Kind:      RiskDecisionKind(dto.Kind),
+		WalletId:  int(dto.WalletID),
+		Amount:    float32(dto.Amount),
+		Decision:  Decision(dto.Decision),
+		Score:     float32(dto.Score),
+		Hits:      make([]RuleHit, 0, len(dto.Hits)),
+		CreatedAt: dto.CreatedAt,
+	}
+	for _, hit := range dto.Hits {
+		d.Hits = append(d.Hits, RuleHit{
+			Rule:    hit.Rule,
+			Action:  Decision(hit.Action),
+			Score:   float32(hit.Score),
+			Details: hit.Details,
+		})
+	}
+	if len(dto.Receivers) > 0 {
+		receivers := make([]int, len(dto.Receivers))
+		for i, id := range dto.Receivers {
+			receivers[i] = int(id)
+		}
+		d.ReceiverIds = &receivers
+	}
+	return d
+}
@@ -0,0 +1,87 @@
+// Package risk provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package risk
+
+import (
+	"time"
+)
+
+// Defines values for Decision.
+const (
+	Allow  Decision = "allow"
+	Deny   Decision = "deny"
+	Review Decision = "review"
+)
+
+// Defines values for RiskDecisionKind.
+const (
+	Deposit       RiskDecisionKind = "deposit"
+	EscrowRelease RiskDecisionKind = "escrow_release"
+	Reversal      RiskDecisionKind = "reversal"
+	SplitPayment  RiskDecisionKind = "split_payment"
+	Transfer      RiskDecisionKind = "transfer"
+	Withdraw      RiskDecisionKind = "withdraw"
+)
+
+// Decision defines model for Decision.
+type Decision string
+
+// Error defines model for Error.
+type Error struct {
+	Code      *int    `json:"code,omitempty"`
+	Error     string  `json:"error"`
+	ErrorType *string `json:"errorType,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// outcome of the rules evaluated on the money movement, denied movements are not executed
+type RiskDecision struct {
+	Amount    float32   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+	Decision  Decision  `json:"decision"`
+	Hits      []RuleHit `json:"hits"`
+
+	// risk decision id
+	Id          int              `json:"id"`
+	Kind        RiskDecisionKind `json:"kind"`
+	ReceiverIds *[]int           `json:"receiver_ids,omitempty"`
+
+	// sum of scores of the hit rules
+	Score float32 `json:"score"`
+
+	// wallet the money leaves or the deposited wallet, the seller of the released escrow
+	WalletId int `json:"wallet_id"`
+}
+
+// RiskDecisionKind defines model for RiskDecision.Kind.
+type RiskDecisionKind string
+
+// RuleHit defines model for RuleHit.
+type RuleHit struct {
+	Action  Decision `json:"action"`
+	Details string   `json:"details"`
+
+	// name of the rule from the config
+	Rule  string  `json:"rule"`
+	Score float32 `json:"score"`
+}
+
+// QueryParamDecision defines model for QueryParamDecision.
+type QueryParamDecision = Decision
+
+// QueryParamLimit defines model for QueryParamLimit.
+type QueryParamLimit = float32
+
+// QueryParamOffset defines model for QueryParamOffset.
+type QueryParamOffset = float32
+
+// GetRiskDecisionsParams defines parameters for GetRiskDecisions.
+type GetRiskDecisionsParams struct {
+	// Limit of how many records returned
+	Limit QueryParamLimit `form:"limit" json:"limit"`
+
+	// Offset of returned records
+	Offset   QueryParamOffset    `form:"offset" json:"offset"`
+	Decision *QueryParamDecision `form:"decision,omitempty" json:"decision,omitempty"`
+}
@@ -0,0 +1,132 @@
+package screening
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/actor"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	"github.com/skwol/wallet/internal/domain/screening"
+)
+
+const (
+	matchesURL     = "/api/v1/screening/matches"
+	matchReviewURL = "/api/v1/screening/matches/{record_id}/review"
+)
+
+type handler struct {
+	screeningService screening.Service
+	logger           logging.Logger
+}
+
+func NewHandler(service screening.Service, logger logging.Logger) (adapters.Handler, error) {
+	return &handler{screeningService: service, logger: logger}, nil
+}
+
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc(matchesURL, h.getMatches).Methods(http.MethodGet)
+	router.HandleFunc(matchReviewURL, h.review).Methods(http.MethodPost)
+}
+
+func (h *handler) getMatches(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		h.logger.Errorf("error parsing limit query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing limit query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		h.logger.Errorf("error parsing offset query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing offset query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	status := screening.StatusPending
+	if value := r.FormValue("status"); value != "" {
+		status = screening.Status(value)
+	}
+
+	matchDTOs, err := h.screeningService.GetMatches(r.Context(), status, limit, offset)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		code := http.StatusInternalServerError
+		if errors.Is(err, screening.ErrUnknownStatus) {
+			code = http.StatusUnprocessableEntity
+		}
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), code)
+		return
+	}
+	if len(matchDTOs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	matches := make([]ScreeningMatch, 0, len(matchDTOs))
+	for _, dto := range matchDTOs {
+		matches = append(matches, newMatch(dto))
+	}
+	response, err := json.Marshal(matches)
+	if err != nil {
+		h.logger.Errorf("error marshaling screening matches: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling screening matches: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *handler) review(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request ReviewRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	matchDTO, err := h.screeningService.Review(r.Context(), id, screening.Status(request.Outcome), actor.FromContext(r.Context()))
+	if err != nil {
+		h.logger.Errorf("error reviewing screening match: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reviewing screening match: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	response, err := json.Marshal(newMatch(matchDTO))
+	if err != nil {
+		h.logger.Errorf("error marshaling screening match: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling screening match: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,27 @@
+package screening
+
+import (
+	"github.com/skwol/wallet/internal/domain/screening"
+)
+
+func newMatch(dto screening.MatchDTO) ScreeningMatch {
+	m := ScreeningMatch{
+		Id:         int(dto.ID),
+		WalletId:   int(dto.WalletID),
+		WalletName: dto.WalletName,
+		EntryName:  dto.EntryName,
+		Score:      float32(dto.Score),
+		Status:     MatchStatus(dto.Status),
+		CreatedAt:  dto.CreatedAt,
+	}
+	if dto.EntryReference != "" {
+		m.EntryReference = &dto.EntryReference
+	}
+	if !dto.ReviewedAt.IsZero() {
+		m.ReviewedAt = &dto.ReviewedAt
+	}
+	if dto.Reviewer != "" {
+		m.Reviewer = &dto.Reviewer
+	}
+	return m
+}
@@ -0,0 +1,91 @@
+// Package screening provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
+package screening
+
+import (
+	"time"
+)
+
+const (
+	ApiTokenScopes = "ApiToken.Scopes"
+)
+
+// Defines values for MatchStatus.
+const (
+	MatchStatusCleared   MatchStatus = "cleared"
+	MatchStatusConfirmed MatchStatus = "confirmed"
+	MatchStatusPending   MatchStatus = "pending"
+)
+
+// Defines values for ReviewRequestOutcome.
+const (
+	ReviewRequestOutcomeCleared   ReviewRequestOutcome = "cleared"
+	ReviewRequestOutcomeConfirmed ReviewRequestOutcome = "confirmed"
+)
+
+// Error defines model for Error.
+type Error struct {
+	Code      *int    `json:"code,omitempty"`
+	Error     string  `json:"error"`
+	ErrorType *string `json:"errorType,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// MatchStatus defines model for MatchStatus.
+type MatchStatus string
+
+// ReviewRequest defines model for ReviewRequest.
+type ReviewRequest struct {
+	Outcome ReviewRequestOutcome `json:"outcome"`
+}
+
+// ReviewRequestOutcome defines model for ReviewRequest.Outcome.
+type ReviewRequestOutcome string
+
+// wallet name similar to the watchlist entry, wallet is frozen while the match is pending
+type ScreeningMatch struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// matched name from the watchlist
+	EntryName string `json:"entry_name"`
+
+	// reference of the watchlist entry
+	EntryReference *string `json:"entry_reference,omitempty"`
+
+	// screening match id
+	Id         int        `json:"id"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	Reviewer   *string    `json:"reviewer,omitempty"`
+
+	// similarity of the names from 0 to 1
+	Score      float32     `json:"score"`
+	Status     MatchStatus `json:"status"`
+	WalletId   int         `json:"wallet_id"`
+	WalletName string      `json:"wallet_name"`
+}
+
+// PathParamMatchID defines model for PathParamMatchID.
+type PathParamMatchID = float32
+
+// QueryParamLimit defines model for QueryParamLimit.
+type QueryParamLimit = float32
+
+// QueryParamOffset defines model for QueryParamOffset.
+type QueryParamOffset = float32
+
+// QueryParamStatus defines model for QueryParamStatus.
+type QueryParamStatus = MatchStatus
+
+// GetScreeningMatchesParams defines parameters for GetScreeningMatches.
+type GetScreeningMatchesParams struct {
+	// Limit of how many records returned
+	Limit QueryParamLimit `form:"limit" json:"limit"`
+
+	// Offset of returned records
+	Offset QueryParamOffset  `form:"offset" json:"offset"`
+	Status *QueryParamStatus `form:"status,omitempty" json:"status,omitempty"`
+}
+
+// ReviewScreeningMatchJSONRequestBody defines body for ReviewScreeningMatch for application/json ContentType.
+type ReviewScreeningMatchJSONRequestBody = ReviewRequest
@@ -0,0 +1,40 @@
+package adapters
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WithTimeout limits every request to timeout except the streaming paths. Streaming responses are
+// flushed while rows are read and can take minutes, while the timeout handler would buffer them whole.
+// Streaming paths are route templates, a {var} segment matches any single segment.
+func WithTimeout(handler http.Handler, timeout time.Duration, streaming ...string) http.Handler {
+	limited := http.TimeoutHandler(handler, timeout, "request timeout")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, path := range streaming {
+			if matchPath(path, r.URL.Path) {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		limited.ServeHTTP(w, r)
+	})
+}
+
+func matchPath(template, path string) bool {
+	templateSegments := strings.Split(template, "/")
+	pathSegments := strings.Split(path, "/")
+	if len(templateSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range templateSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && pathSegments[i] != "" {
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,16 +1,14 @@
 package transaction
 
 import (
-	"bytes"
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 
 	"github.com/skwol/wallet/pkg/logging"
 
@@ -24,6 +22,9 @@ const (
 	transactionsReportURL = "/api/v1/transactions-report"
 )
 
+// StreamingURLs are written while transactions are read, they are served without the request timeout.
+var StreamingURLs = []string{transactionsReportURL}
+
 type handler struct {
 	transactionService transaction.Service
 	logger             logging.Logger
@@ -73,6 +74,10 @@ func (h *handler) getTransaction(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) getAllTransactions(w http.ResponseWriter, r *http.Request) {
+	if !adapters.OffsetRequested(r) {
+		h.getTransactionsPage(w, r)
+		return
+	}
 	limit, err := strconv.Atoi(r.FormValue("limit"))
 	if err != nil {
 		h.logger.Errorf("error parsing limit query param: %s", err.Error())
@@ -115,7 +120,38 @@ func (h *handler) getAllTransactions(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *handler) getTransactionsPage(w http.ResponseWriter, r *http.Request) {
+	page, err := adapters.ParsePage(r)
+	if err != nil {
+		h.logger.Errorf("error parsing page: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing page: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	pageDTO, err := h.transactionService.GetPage(r.Context(), page)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	response, err := json.Marshal(newTransactionsPage(pageDTO))
+	if err != nil {
+		h.logger.Errorf("error marshaling transactions: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling transactions: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (h *handler) getFilteredTransactions(w http.ResponseWriter, r *http.Request) {
+	if !adapters.OffsetRequested(r) {
+		h.getFilteredTransactionsPage(w, r)
+		return
+	}
 	transactions := getFiltered(h.logger, h.transactionService, w, r)
 	if transactions == nil {
 		return
@@ -134,38 +170,113 @@ func (h *handler) getFilteredTransactions(w http.ResponseWriter, r *http.Request
 	}
 }
 
-func (h *handler) getFilteredTransactionsReport(w http.ResponseWriter, r *http.Request) {
-	transactions := getFiltered(h.logger, h.transactionService, w, r)
-	if transactions == nil {
+func (h *handler) getFilteredTransactionsPage(w http.ResponseWriter, r *http.Request) {
+	filterRequest, err := readFilter(r)
+	if err != nil {
+		h.logger.Errorf("%s", err.Error())
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
-
-	var buf bytes.Buffer
-	writer := csv.NewWriter(&buf)
-	if err := writer.Write(csvHeaders); err != nil {
-		h.logger.Errorf("error writing headers to csv file: %s", err.Error())
-		http.Error(w, fmt.Sprintf("error writing headers to csv file: %s", err.Error()), http.StatusInternalServerError)
+	page, err := adapters.ParsePage(r)
+	if err != nil {
+		h.logger.Errorf("error parsing page: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing page: %s", err.Error()), http.StatusUnprocessableEntity)
 		return
 	}
-	for _, tran := range transactions {
-		if err := writer.Write(tran.toCsv()); err != nil {
-			h.logger.Errorf("error writing transaction to csv file: %s", err.Error())
-			http.Error(w, fmt.Sprintf("error writing transaction to csv file: %s", err.Error()), http.StatusInternalServerError)
-			return
+	pageDTO, err := h.transactionService.GetFilteredPage(r.Context(), &filterRequest, page)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		status := http.StatusInternalServerError
+		if isFilterError(err) {
+			status = http.StatusUnprocessableEntity
 		}
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), status)
+		return
+	}
+	response, err := json.Marshal(newTransactionsPage(pageDTO))
+	if err != nil {
+		h.logger.Errorf("error marshaling transactions: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling transactions: %s", err.Error()), http.StatusInternalServerError)
+		return
 	}
-	writer.Flush()
-
-	w.Header().Set("Content-Disposition", "attachment; filename=report.csv")
-	w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
 
-	if _, err := io.Copy(w, &buf); err != nil {
+	if _, err := w.Write(response); err != nil {
 		h.logger.Errorf("error writing response: %s", err.Error())
 		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
 		return
 	}
 }
 
+func (h *handler) getFilteredTransactionsReport(w http.ResponseWriter, r *http.Request) {
+	// limit and offset are optional for the report, without them all filtered transactions are exported
+	var limit, offset int
+	if value := r.FormValue("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			h.logger.Errorf("error parsing limit query param: %s", err.Error())
+			http.Error(w, fmt.Sprintf("error parsing limit query param: %s", err.Error()), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+	if value := r.FormValue("offset"); value != "" {
+		var err error
+		if offset, err = strconv.Atoi(value); err != nil {
+			h.logger.Errorf("error parsing offset query param: %s", err.Error())
+			http.Error(w, fmt.Sprintf("error parsing offset query param: %s", err.Error()), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+	format, err := reportFormat(r)
+	if err != nil {
+		h.logger.Errorf("error choosing report format: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error choosing report format: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	filterRequest, err := readFilter(r)
+	if err != nil {
+		h.logger.Errorf("%s", err.Error())
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	report := newReportWriter(w, r, format)
+	if err := h.transactionService.StreamFiltered(r.Context(), &filterRequest, limit, offset, report.Write); err != nil {
+		if !report.started {
+			h.logger.Errorf("error returned from service: %s", err.Error())
+			status := http.StatusInternalServerError
+			if isFilterError(err) {
+				status = http.StatusUnprocessableEntity
+			}
+			http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), status)
+			return
+		}
+		// status is already sent, the connection is dropped so that the client does not take a cut report as complete
+		h.logger.Errorf("error streaming report after %d rows: %s", report.rows, err.Error())
+		panic(http.ErrAbortHandler)
+	}
+	if err := report.Close(); err != nil {
+		h.logger.Errorf("error finishing report after %d rows: %s", report.rows, err.Error())
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// readFilter reads the filter from the request body of the filter endpoints.
+func readFilter(r *http.Request) (transaction.FilterTransactionsDTO, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return transaction.FilterTransactionsDTO{}, errors.Wrap(err, "error reading request body")
+	}
+	var request Filter
+	if err := json.Unmarshal(body, &request); err != nil {
+		return transaction.FilterTransactionsDTO{}, errors.Wrap(err, "error unmarshaling request")
+	}
+	filterRequest, err := request.ToFilterRequest()
+	if err != nil {
+		return transaction.FilterTransactionsDTO{}, errors.Wrap(err, "error parsing filter")
+	}
+	return filterRequest, nil
+}
+
 func getFiltered(logger logging.Logger, service transaction.Service, w http.ResponseWriter, r *http.Request) []Transaction {
 	limit, err := strconv.Atoi(r.FormValue("limit"))
 	if err != nil {
@@ -181,25 +292,20 @@ func getFiltered(logger logging.Logger, service transaction.Service, w http.Resp
 		return nil
 	}
 
-	body, err := ioutil.ReadAll(r.Body)
+	filterRequest, err := readFilter(r)
 	if err != nil {
-		logger.Errorf("error reading request body: %s", err.Error())
-		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		logger.Errorf("%s", err.Error())
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return nil
 	}
-
-	var request Filter
-	if err := json.Unmarshal(body, &request); err != nil {
-		logger.Errorf("error unmarshaling request: %s", err.Error())
-		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
-		return nil
-	}
-
-	filterRequest := request.toFilterRequest()
 	transactionDTOs, err := service.GetFiltered(r.Context(), &filterRequest, limit, offset)
 	if err != nil {
 		logger.Errorf("error returned from service: %s", err.Error())
-		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if isFilterError(err) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), status)
 		return nil
 	}
 	if len(transactionDTOs) == 0 {
@@ -212,3 +318,23 @@ func getFiltered(logger logging.Logger, service transaction.Service, w http.Resp
 	}
 	return transactions
 }
+
+// isFilterError tells apart filters rejected by validation from storage failures.
+func isFilterError(err error) bool {
+	for _, target := range []error{
+		transaction.ErrUnknownTranType,
+		transaction.ErrUnknownField,
+		transaction.ErrUnsupportedOperator,
+		transaction.ErrInvalidExpression,
+		transaction.ErrMissingValues,
+		transaction.ErrFilterTooComplex,
+		transaction.ErrUnknownSortField,
+		transaction.ErrDuplicateSortField,
+		transaction.ErrSortNeedsOffset,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,18 +2,23 @@ package transaction
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -23,6 +28,7 @@ import (
 	"github.com/skwol/wallet/pkg/testdb"
 
 	dbtransaction "github.com/skwol/wallet/internal/adapters/db/transaction"
+	domainreport "github.com/skwol/wallet/internal/domain/report"
 	domaintransaction "github.com/skwol/wallet/internal/domain/transaction"
 )
 
@@ -433,6 +439,57 @@ func TestGetTransactions(t *testing.T) {
 			},
 			wantStatusCode: http.StatusOK,
 		},
+		{
+			name: "filtered transactions by wallet on either side sorted by id desc",
+			args: argsFilters{limit: 10, offset: 0},
+			request: Filter{
+				Where: &Expression{Field: "wallet_id", Op: "in", Values: []json.RawMessage{json.RawMessage(`1`)}},
+				Sort:  []Sort{{Field: "id", Order: "desc"}},
+			},
+			want: []Transaction{
+				{ID: 3, SenderID: 2, ReceiverID: 1, Amount: 100, Timestamp: tranDates[2], Type: string(domaintransaction.TranTypeTransfer)},
+				{ID: 1, SenderID: 1, ReceiverID: 1, Amount: 100, Timestamp: tranDates[0], Type: string(domaintransaction.TranTypeDeposit)},
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "filtered transactions by withdraw or amount from 200 inclusive",
+			args: argsFilters{limit: 10, offset: 0},
+			request: Filter{Where: &Expression{Or: []Expression{
+				{Field: "type", Op: "in", Values: []json.RawMessage{json.RawMessage(`"withdraw"`)}},
+				{Field: "amount", Op: "gte", Value: json.RawMessage(`200`)},
+			}}},
+			want: []Transaction{
+				{ID: 2, SenderID: 2, ReceiverID: 2, Amount: 200, Timestamp: tranDates[1], Type: string(domaintransaction.TranTypeDeposit)},
+				{ID: 4, SenderID: 2, ReceiverID: 2, Amount: 100, Timestamp: tranDates[3], Type: string(domaintransaction.TranTypeWithdraw)},
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "filtered transactions not deposit sorted by amount desc",
+			args: argsFilters{limit: 10, offset: 0},
+			request: Filter{
+				Where: &Expression{Not: &Expression{Field: "type", Op: "in", Values: []json.RawMessage{json.RawMessage(`"deposit"`)}}},
+				Sort:  []Sort{{Field: "amount", Order: "desc"}},
+			},
+			want: []Transaction{
+				{ID: 3, SenderID: 2, ReceiverID: 1, Amount: 100, Timestamp: tranDates[2], Type: string(domaintransaction.TranTypeTransfer)},
+				{ID: 4, SenderID: 2, ReceiverID: 2, Amount: 100, Timestamp: tranDates[3], Type: string(domaintransaction.TranTypeWithdraw)},
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "filtered transactions by unknown field",
+			args:           argsFilters{limit: 10, offset: 0},
+			request:        Filter{Where: &Expression{Field: "currency", Op: "in", Values: []json.RawMessage{json.RawMessage(`"EUR"`)}}},
+			wantStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "filtered transactions by unknown type",
+			args:           argsFilters{limit: 10, offset: 0},
+			request:        Filter{Types: []string{"deposit') OR 1=1; --"}},
+			wantStatusCode: http.StatusUnprocessableEntity,
+		},
 	}
 	for _, tt := range testsFilters {
 		t.Run(tt.name, func(t *testing.T) {
@@ -451,6 +508,9 @@ func TestGetTransactions(t *testing.T) {
 			if resp.StatusCode != tt.wantStatusCode {
 				t.Fatalf("test %s: expected status %d, got %d", tt.name, tt.wantStatusCode, resp.StatusCode)
 			}
+			if tt.wantStatusCode == http.StatusUnprocessableEntity {
+				return
+			}
 			result, err := ioutil.ReadAll(resp.Body)
 			if err != nil {
 				t.Fatalf("test %s: error reading request: %s", tt.name, err.Error())
@@ -472,3 +532,168 @@ func TestGetTransactions(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTransactionsPage(t *testing.T) {
+	setup(t)
+	ctx := context.Background()
+	tranDates := prepareAllTransactionsInDB(ctx, t)
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	get := func(query string, wantStatusCode int) TransactionsPage {
+		resp, err := http.DefaultClient.Do(newReq(t, http.MethodGet, fmt.Sprintf("%s/api/v1/transactions?%s", ts.URL, query), nil))
+		if err != nil {
+			t.Fatalf("query %s: error getting response: %s", query, err.Error())
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				t.Fatalf("error closing body")
+			}
+		}()
+		if resp.StatusCode != wantStatusCode {
+			t.Fatalf("query %s: expected status %d, got %d", query, wantStatusCode, resp.StatusCode)
+		}
+		var page TransactionsPage
+		if wantStatusCode != http.StatusOK {
+			return page
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			t.Fatalf("query %s: error unmarshaling response: %s", query, err.Error())
+		}
+		return page
+	}
+	ids := func(page TransactionsPage) []int64 {
+		var ids []int64
+		for _, tran := range page.Transactions {
+			ids = append(ids, tran.ID)
+		}
+		return ids
+	}
+
+	first := get("limit=2&total=true", http.StatusOK)
+	if !reflect.DeepEqual(ids(first), []int64{1, 2}) || first.PrevCursor != "" || first.NextCursor == "" {
+		t.Fatalf("wrong first page: %+v", first)
+	}
+	if first.Total == nil || *first.Total != 4 {
+		t.Fatalf("wrong total: %v", first.Total)
+	}
+	if first.Transactions[0].Timestamp != tranDates[0] {
+		t.Fatalf("wrong transaction returned: %+v", first.Transactions[0])
+	}
+
+	second := get("limit=2&cursor="+first.NextCursor, http.StatusOK)
+	if !reflect.DeepEqual(ids(second), []int64{3, 4}) || second.NextCursor != "" || second.PrevCursor == "" || second.Total != nil {
+		t.Fatalf("wrong second page: %+v", second)
+	}
+
+	back := get("limit=2&cursor="+second.PrevCursor, http.StatusOK)
+	if !reflect.DeepEqual(ids(back), []int64{1, 2}) || back.PrevCursor != "" {
+		t.Fatalf("wrong page back: %+v", back)
+	}
+
+	if all := get("", http.StatusOK); len(all.Transactions) != 4 {
+		t.Fatalf("wrong page with default limit: %+v", all)
+	}
+	get("limit=2&cursor=bogus", http.StatusUnprocessableEntity)
+	get("limit=-1", http.StatusUnprocessableEntity)
+}
+
+func TestReportWriter(t *testing.T) {
+	timestamp := time.Date(2021, 10, 11, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		rows           int
+		wantGzip       bool
+	}{
+		{name: "empty report has headers only", rows: 0},
+		{name: "report flushed on the way", rows: reportFlushRows + 1},
+		{name: "gzip report", acceptEncoding: "deflate, gzip;q=0.8", rows: 3, wantGzip: true},
+		{name: "gzip refused", acceptEncoding: "gzip;q=0", rows: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/transactions-report", nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			report := newReportWriter(w, r, domainreport.FormatCSV)
+			for i := 1; i <= tt.rows; i++ {
+				if err := report.Write(domaintransaction.DTO{ID: int64(i), SenderID: 1, ReceiverID: 2, Amount: 10, Timestamp: timestamp, Type: domaintransaction.TranTypeTransfer}); err != nil {
+					t.Fatalf("reportWriter.Write() unexpected error = %v", err)
+				}
+			}
+			if err := report.Close(); err != nil {
+				t.Fatalf("reportWriter.Close() unexpected error = %v", err)
+			}
+
+			resp := w.Result()
+			if got := resp.Trailer.Get(RowCountTrailer); got != strconv.Itoa(tt.rows) {
+				t.Errorf("row count trailer = %q, want %d", got, tt.rows)
+			}
+			var body io.Reader = resp.Body
+			if tt.wantGzip {
+				if resp.Header.Get("Content-Encoding") != "gzip" {
+					t.Fatalf("missing gzip content encoding")
+				}
+				gz, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatalf("error reading gzip: %s", err.Error())
+				}
+				body = gz
+			} else if resp.Header.Get("Content-Encoding") != "" {
+				t.Fatalf("unexpected content encoding %q", resp.Header.Get("Content-Encoding"))
+			}
+			records, err := csv.NewReader(body).ReadAll()
+			if err != nil {
+				t.Fatalf("error reading csv: %s", err.Error())
+			}
+			if len(records) != tt.rows+1 || !reflect.DeepEqual(records[0], domaintransaction.CSVHeaders) {
+				t.Fatalf("wrong report: %d records, headers %v", len(records), records[0])
+			}
+			if tt.rows > 0 && records[tt.rows][0] != strconv.Itoa(tt.rows) {
+				t.Errorf("wrong last row %v", records[tt.rows])
+			}
+		})
+	}
+}
+
+func Test_reportFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		accept   string
+		want     domainreport.Format
+		wantGzip bool
+		wantErr  error
+	}{
+		{name: "csv by default", want: domainreport.FormatCSV, wantGzip: true},
+		{name: "csv for any media type", accept: "*/*", want: domainreport.FormatCSV, wantGzip: true},
+		{name: "csv for unknown media types", accept: "application/json", want: domainreport.FormatCSV, wantGzip: true},
+		{name: "jsonl by accept", accept: "text/html;q=0.9, application/x-ndjson", want: domainreport.FormatJSONL, wantGzip: true},
+		{name: "refused media type is skipped", accept: "application/x-ndjson;q=0, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", want: domainreport.FormatXLSX},
+		{name: "query param wins", query: "format=xlsx", accept: "text/csv", want: domainreport.FormatXLSX},
+		{name: "unknown format", query: "format=pdf", wantErr: domainreport.ErrUnknownFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/transactions-report?"+tt.query, nil)
+			r.Header.Set("Accept-Encoding", "gzip")
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			got, err := reportFormat(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("reportFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("reportFormat() = %v, want %v", got, tt.want)
+			}
+			if err == nil && newReportWriter(httptest.NewRecorder(), r, got).gzip != tt.wantGzip {
+				t.Errorf("newReportWriter() gzip = %v, want %v", !tt.wantGzip, tt.wantGzip)
+			}
+		})
+	}
+}
@@ -1,23 +1,33 @@
 package transaction
 
 import (
-	"fmt"
+	"encoding/json"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/skwol/wallet/internal/domain/transaction"
 )
 
-var csvHeaders = []string{"Transaction ID", "Sender ID", "Receiver ID", "Amount", "Timestamp", "Type"}
-
 func newTransaction(dto transaction.DTO) Transaction {
-	return Transaction{
+	var disputes []Dispute
+	for _, d := range dto.Disputes {
+		disputes = append(disputes, newDispute(d))
+	}
+	t := Transaction{
 		ID:         dto.ID,
 		SenderID:   dto.SenderID,
 		ReceiverID: dto.ReceiverID,
 		Amount:     dto.Amount,
 		Timestamp:  dto.Timestamp,
 		Type:       string(dto.Type),
+		Disputes:   disputes,
 	}
+	if dto.ParentPaymentID != 0 {
+		parentPaymentID := dto.ParentPaymentID
+		t.ParentPaymentID = &parentPaymentID
+	}
+	return t
 }
 
 type Transaction struct {
@@ -27,10 +37,54 @@ type Transaction struct {
 	Amount     float64   `json:"amount"`
 	Timestamp  time.Time `json:"timestamp"`
 	Type       string    `json:"type"`
+	// ParentPaymentID is set for legs of a split payment.
+	ParentPaymentID *int64    `json:"parent_payment_id,omitempty"`
+	Disputes        []Dispute `json:"disputes,omitempty"`
 }
 
-func (t Transaction) toCsv() []string {
-	return []string{fmt.Sprintf("%d", t.ID), fmt.Sprintf("%d", t.SenderID), fmt.Sprintf("%d", t.ReceiverID), fmt.Sprintf("%f", t.Amount), t.Timestamp.Format("Mon, 02 Jan 2006 15:04:05 -0700"), t.Type}
+// TransactionsPage is a page of transactions with cursors of the pages around it.
+type TransactionsPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+	PrevCursor   string        `json:"prev_cursor,omitempty"`
+	Total        *int64        `json:"total,omitempty"`
+}
+
+func newTransactionsPage(dto transaction.PageDTO) TransactionsPage {
+	page := TransactionsPage{
+		Transactions: make([]Transaction, 0, len(dto.Transactions)),
+		NextCursor:   dto.Next,
+		PrevCursor:   dto.Prev,
+		Total:        dto.Total,
+	}
+	for _, t := range dto.Transactions {
+		page.Transactions = append(page.Transactions, newTransaction(t))
+	}
+	return page
+}
+
+func newDispute(dto transaction.DisputeDTO) Dispute {
+	d := Dispute{
+		ID:         dto.ID,
+		Reason:     dto.Reason,
+		Status:     dto.Status,
+		HoldAmount: dto.HoldAmount,
+		OpenedAt:   dto.OpenedAt,
+	}
+	if !dto.ResolvedAt.IsZero() {
+		resolvedAt := dto.ResolvedAt
+		d.ResolvedAt = &resolvedAt
+	}
+	return d
+}
+
+type Dispute struct {
+	ID         int64      `json:"id"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"`
+	HoldAmount float64    `json:"hold_amount"`
+	OpenedAt   time.Time  `json:"opened_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
 }
 
 type Filter struct {
@@ -39,16 +93,153 @@ type Filter struct {
 	Amount      FloatRangeFilter `json:"amount"`
 	Timestamp   DateRangeFilter  `json:"timestamp"`
 	Types       []string         `json:"types"`
+	Where       *Expression      `json:"where,omitempty"`
+	Sort        []Sort           `json:"sort,omitempty"`
 }
 
-func (f Filter) toFilterRequest() transaction.FilterTransactionsDTO {
-	return transaction.FilterTransactionsDTO{
+// ToFilterRequest converts the filter, report jobs take the same filter as the transaction endpoints.
+func (f Filter) ToFilterRequest() (transaction.FilterTransactionsDTO, error) {
+	dto := transaction.FilterTransactionsDTO{
 		SenderIDs:   f.SenderIDs,
 		ReceiverIDs: f.ReceiverIDs,
 		Amount:      f.Amount.toRequest(),
 		Timestamp:   f.Timestamp.toRequest(),
 		Types:       f.Types,
 	}
+	if f.Where != nil {
+		where, err := f.Where.toRequest()
+		if err != nil {
+			return dto, err
+		}
+		dto.Where = &where
+	}
+	for _, sort := range f.Sort {
+		s, err := sort.toRequest()
+		if err != nil {
+			return dto, err
+		}
+		dto.Sort = append(dto.Sort, s)
+	}
+	return dto, nil
+}
+
+// Expression is either a group (and, or, not) or a condition on a single field,
+// value is used by comparison operators and values by in and not_in.
+type Expression struct {
+	And    []Expression      `json:"and,omitempty"`
+	Or     []Expression      `json:"or,omitempty"`
+	Not    *Expression       `json:"not,omitempty"`
+	Field  string            `json:"field,omitempty"`
+	Op     string            `json:"op,omitempty"`
+	Value  json.RawMessage   `json:"value,omitempty"`
+	Values []json.RawMessage `json:"values,omitempty"`
+}
+
+func (e Expression) toRequest() (transaction.Expression, error) {
+	var dto transaction.Expression
+	if e.And != nil {
+		dto.And = make([]transaction.Expression, 0, len(e.And))
+		for _, child := range e.And {
+			c, err := child.toRequest()
+			if err != nil {
+				return dto, err
+			}
+			dto.And = append(dto.And, c)
+		}
+	}
+	if e.Or != nil {
+		dto.Or = make([]transaction.Expression, 0, len(e.Or))
+		for _, child := range e.Or {
+			c, err := child.toRequest()
+			if err != nil {
+				return dto, err
+			}
+			dto.Or = append(dto.Or, c)
+		}
+	}
+	if e.Not != nil {
+		not, err := e.Not.toRequest()
+		if err != nil {
+			return dto, err
+		}
+		dto.Not = &not
+	}
+	if e.Field != "" || e.Op != "" {
+		condition, err := e.toCondition()
+		if err != nil {
+			return dto, err
+		}
+		dto.Condition = &condition
+	}
+	return dto, nil
+}
+
+// toCondition decodes the values with the type of the field, the rest is validated by the service.
+func (e Expression) toCondition() (transaction.Condition, error) {
+	condition := transaction.Condition{Field: transaction.Field(e.Field), Op: transaction.Operator(e.Op)}
+	switch condition.Field {
+	case transaction.FieldID:
+		if len(e.Value) > 0 {
+			if err := json.Unmarshal(e.Value, &condition.ID); err != nil {
+				return condition, errors.Wrapf(err, "error parsing %s value", e.Field)
+			}
+		}
+		for _, raw := range e.Values {
+			var id int64
+			if err := json.Unmarshal(raw, &id); err != nil {
+				return condition, errors.Wrapf(err, "error parsing %s value", e.Field)
+			}
+			condition.IDs = append(condition.IDs, id)
+		}
+	case transaction.FieldSender, transaction.FieldReceiver, transaction.FieldWallet:
+		for _, raw := range e.Values {
+			var id int64
+			if err := json.Unmarshal(raw, &id); err != nil {
+				return condition, errors.Wrapf(err, "error parsing %s value", e.Field)
+			}
+			condition.IDs = append(condition.IDs, id)
+		}
+	case transaction.FieldType:
+		for _, raw := range e.Values {
+			var t string
+			if err := json.Unmarshal(raw, &t); err != nil {
+				return condition, errors.Wrapf(err, "error parsing %s value", e.Field)
+			}
+			condition.Types = append(condition.Types, transaction.TranType(t))
+		}
+	case transaction.FieldAmount:
+		if len(e.Value) == 0 {
+			return condition, errors.Wrapf(transaction.ErrMissingValues, "%s", e.Field)
+		}
+		if err := json.Unmarshal(e.Value, &condition.Amount); err != nil {
+			return condition, errors.Wrapf(err, "error parsing %s value", e.Field)
+		}
+	case transaction.FieldDate:
+		if len(e.Value) == 0 {
+			return condition, errors.Wrapf(transaction.ErrMissingValues, "%s", e.Field)
+		}
+		if err := json.Unmarshal(e.Value, &condition.Date); err != nil {
+			return condition, errors.Wrapf(err, "error parsing %s value", e.Field)
+		}
+	}
+	return condition, nil
+}
+
+type Sort struct {
+	Field string `json:"field"`
+	Order string `json:"order"`
+}
+
+func (s Sort) toRequest() (transaction.Sort, error) {
+	dto := transaction.Sort{Field: transaction.SortField(s.Field)}
+	switch s.Order {
+	case "", "asc":
+	case "desc":
+		dto.Desc = true
+	default:
+		return dto, errors.Errorf("sort order should be asc or desc, got %q", s.Order)
+	}
+	return dto, nil
 }
 
 type FloatRangeFilter struct {
@@ -0,0 +1,143 @@
+package transaction
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/report"
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+// RowCountTrailer is sent after the report body, clients can compare it with the rows they got.
+const RowCountTrailer = "X-Row-Count"
+
+// reportFlushRows is how often rows are pushed to the client.
+const reportFlushRows = 500
+
+// reportFormat takes the format query param or, without it, the first report format in the Accept header.
+// Report is csv when the client accepts anything or none of the formats, as it was before other formats.
+func reportFormat(r *http.Request) (report.Format, error) {
+	if value := r.FormValue("format"); value != "" {
+		format := report.Format(value)
+		if !format.Valid() {
+			return "", errors.Wrapf(report.ErrUnknownFormat, "%q", value)
+		}
+		return format, nil
+	}
+	for _, value := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		for format, contentType := range report.Formats {
+			if mediaType == contentType {
+				return format, nil
+			}
+		}
+	}
+	return report.FormatCSV, nil
+}
+
+// reportWriter writes transactions straight into the response while they are read from db,
+// headers are sent with the first row so that errors before it still get a proper status.
+type reportWriter struct {
+	w      http.ResponseWriter
+	format report.Format
+	// xlsx is a zip archive and parquet is snappy compressed already, they are not compressed again
+	gzip    bool
+	out     *gzip.Writer
+	report  report.Writer
+	rows    int64
+	started bool
+}
+
+func newReportWriter(w http.ResponseWriter, r *http.Request, format report.Format) *reportWriter {
+	return &reportWriter{w: w, format: format, gzip: acceptsGzip(r) && format != report.FormatXLSX && format != report.FormatParquet}
+}
+
+func (rw *reportWriter) start() error {
+	rw.started = true
+	header := rw.w.Header()
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=report.%s", rw.format))
+	header.Set("Content-Type", rw.format.ContentType())
+	header.Set("Trailer", RowCountTrailer)
+	var body io.Writer = rw.w
+	if rw.gzip {
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		rw.out = gzip.NewWriter(rw.w)
+		body = rw.out
+	}
+	rw.w.WriteHeader(http.StatusOK)
+	// timestamps are kept as they are read from db
+	var err error
+	rw.report, err = report.NewWriter(rw.format, body, nil)
+	return err
+}
+
+func (rw *reportWriter) Write(dto transaction.DTO) error {
+	if !rw.started {
+		if err := rw.start(); err != nil {
+			return err
+		}
+	}
+	if err := rw.report.Write(dto); err != nil {
+		return err
+	}
+	rw.rows++
+	if rw.rows%reportFlushRows == 0 {
+		return rw.flush()
+	}
+	return nil
+}
+
+func (rw *reportWriter) flush() error {
+	if err := rw.report.Flush(); err != nil {
+		return err
+	}
+	if rw.out != nil {
+		if err := rw.out.Flush(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := rw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// Close finishes the report, report without rows still has the headers of its format.
+func (rw *reportWriter) Close() error {
+	if !rw.started {
+		if err := rw.start(); err != nil {
+			return err
+		}
+	}
+	if err := rw.report.Close(); err != nil {
+		return err
+	}
+	if rw.out != nil {
+		if err := rw.out.Close(); err != nil {
+			return err
+		}
+	}
+	rw.w.Header().Set(RowCountTrailer, strconv.FormatInt(rw.rows, 10))
+	return nil
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.TrimSpace(name) == "gzip" && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
@@ -11,10 +11,14 @@ import (
 	"github.com/skwol/wallet/pkg/logging"
 
 	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerapproval "github.com/skwol/wallet/internal/adapters/api/approval"
 	"github.com/skwol/wallet/internal/domain/transfer"
 )
 
-const transferURL = "/api/v1/transfers"
+const (
+	transferURL     = "/api/v1/transfers"
+	splitPaymentURL = "/api/v1/transfers/split"
+)
 
 type handler struct {
 	transferService transfer.Service
@@ -27,6 +31,7 @@ func NewHandler(service transfer.Service, logger logging.Logger) (adapters.Handl
 
 func (h *handler) Register(router *mux.Router) {
 	router.HandleFunc(transferURL, h.createTransfer).Methods(http.MethodPost)
+	router.HandleFunc(splitPaymentURL, h.createSplitPayment).Methods(http.MethodPost)
 }
 
 func (h *handler) createTransfer(w http.ResponseWriter, r *http.Request) {
@@ -47,6 +52,9 @@ func (h *handler) createTransfer(w http.ResponseWriter, r *http.Request) {
 	createRequest := request.toCreateRequest()
 	transferDTO, err := h.transferService.Create(r.Context(), &createRequest)
 	if err != nil {
+		if handlerapproval.WriteIfPending(w, h.logger, err) {
+			return
+		}
 		h.logger.Errorf("error creating transfer: %s", err.Error())
 		http.Error(w, fmt.Sprintf("error creating wallet: %s", err.Error()), http.StatusUnprocessableEntity)
 		return
@@ -66,3 +74,44 @@ func (h *handler) createTransfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *handler) createSplitPayment(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Errorf("error reading request body: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading request body: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var request CreateSplitPaymentRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Errorf("error unmarshaling request: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	createRequest := request.toCreateRequest()
+	paymentDTO, err := h.transferService.CreateSplitPayment(r.Context(), &createRequest)
+	if err != nil {
+		if handlerapproval.WriteIfPending(w, h.logger, err) {
+			return
+		}
+		h.logger.Errorf("error creating split payment: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error creating split payment: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	response, err := json.Marshal(newPayment(paymentDTO))
+	if err != nil {
+		h.logger.Errorf("error marshaling payment: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling payment: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
@@ -22,7 +22,11 @@ import (
 	"github.com/skwol/wallet/pkg/logging"
 	"github.com/skwol/wallet/pkg/testdb"
 
+	dbapproval "github.com/skwol/wallet/internal/adapters/db/approval"
+	dbrisk "github.com/skwol/wallet/internal/adapters/db/risk"
 	dbtransfer "github.com/skwol/wallet/internal/adapters/db/transfer"
+	domainapproval "github.com/skwol/wallet/internal/domain/approval"
+	domainrisk "github.com/skwol/wallet/internal/domain/risk"
 	domaintransfer "github.com/skwol/wallet/internal/domain/transfer"
 )
 
@@ -52,7 +56,23 @@ func setup(t *testing.T) {
 			t.Fatalf("error creating transfer storage %s", err.Error())
 		}
 		clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
-		service, err := domaintransfer.NewService(storage, logging.GetLogger(), clk)
+		riskStorage, err := dbrisk.NewStorage(dbClient, logging.GetLogger())
+		if err != nil {
+			t.Fatalf("error creating risk storage %s", err.Error())
+		}
+		riskService, err := domainrisk.NewService(riskStorage, logging.GetLogger(), clk, nil)
+		if err != nil {
+			t.Fatalf("error creating risk service %s", err.Error())
+		}
+		approvalStorage, err := dbapproval.NewStorage(dbClient, logging.GetLogger())
+		if err != nil {
+			t.Fatalf("error creating approval storage %s", err.Error())
+		}
+		approvalService, err := domainapproval.NewService(approvalStorage, logging.GetLogger(), clk, domainapproval.Rules{})
+		if err != nil {
+			t.Fatalf("error creating approval service %s", err.Error())
+		}
+		service, err := domaintransfer.NewService(storage, approvalService, riskService, logging.GetLogger(), clk)
 		if err != nil {
 			t.Fatalf("error creating transfer service %s", err.Error())
 		}
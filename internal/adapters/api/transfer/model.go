@@ -34,3 +34,36 @@ func (w Wallet) toRequest() transfer.WalletDTO {
 		ID: int64(w.Id),
 	}
 }
+
+func newPayment(dto transfer.PaymentDTO) Payment {
+	legs := make([]Transfer, 0, len(dto.Legs))
+	for _, leg := range dto.Legs {
+		legs = append(legs, newTransfer(leg))
+	}
+	return Payment{
+		Id:        int(dto.ID),
+		Amount:    float32(dto.Amount),
+		Timestamp: &dto.Timestamp,
+		Sender:    newWallet(dto.Sender),
+		Legs:      legs,
+	}
+}
+
+func (p CreateSplitPaymentRequest) toCreateRequest() transfer.CreateSplitPaymentDTO {
+	legs := make([]transfer.SplitLegDTO, 0, len(p.Legs))
+	for _, leg := range p.Legs {
+		dto := transfer.SplitLegDTO{Receiver: transfer.WalletDTO{ID: int64(leg.ReceiverId)}}
+		if leg.Percent != nil {
+			dto.Percent = float64(*leg.Percent)
+		}
+		if leg.FixedAmount != nil {
+			dto.Fixed = float64(*leg.FixedAmount)
+		}
+		legs = append(legs, dto)
+	}
+	return transfer.CreateSplitPaymentDTO{
+		Amount: float64(p.Amount),
+		Sender: transfer.WalletDTO{ID: int64(p.SenderId)},
+		Legs:   legs,
+	}
+}
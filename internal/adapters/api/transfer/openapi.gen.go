@@ -7,6 +7,17 @@ import (
 	"time"
 )
 
+const (
+	ApiTokenScopes = "ApiToken.Scopes"
+)
+
+// CreateSplitPaymentRequest defines model for CreateSplitPaymentRequest.
+type CreateSplitPaymentRequest struct {
+	Amount   float32    `json:"amount"`
+	Legs     []SplitLeg `json:"legs"`
+	SenderId int        `json:"sender_id"`
+}
+
 // CreateTransferRequest defines model for CreateTransferRequest.
 type CreateTransferRequest struct {
 	Amount     float32 `json:"amount"`
@@ -22,6 +33,25 @@ type Error struct {
 	Status    string  `json:"status"`
 }
 
+// Payment defines model for Payment.
+type Payment struct {
+	// total payment amount
+	Amount float32 `json:"amount"`
+
+	// payment id, referenced by transactions of the legs as parent_payment_id
+	Id        int        `json:"id"`
+	Legs      []Transfer `json:"legs"`
+	Sender    Wallet     `json:"sender"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// share of the payment, either fixed amount or percent of what is left after fixed legs. Percentages must add up to 100, parts are rounded to 0.0001 and always sum to the payment amount.
+type SplitLeg struct {
+	FixedAmount *float32 `json:"fixed_amount,omitempty"`
+	Percent     *float32 `json:"percent,omitempty"`
+	ReceiverId  int      `json:"receiver_id"`
+}
+
 // Transfer defines model for Transfer.
 type Transfer struct {
 	// transfer amount
@@ -45,3 +75,6 @@ type Wallet struct {
 
 // CreateTransferJSONRequestBody defines body for CreateTransfer for application/json ContentType.
 type CreateTransferJSONRequestBody = CreateTransferRequest
+
+// CreateSplitPaymentJSONRequestBody defines body for CreateSplitPayment for application/json ContentType.
+type CreateSplitPaymentJSONRequestBody = CreateSplitPaymentRequest
@@ -6,36 +6,56 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 
+	"github.com/skwol/wallet/pkg/bankstatement"
 	"github.com/skwol/wallet/pkg/logging"
 
 	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerapproval "github.com/skwol/wallet/internal/adapters/api/approval"
 	"github.com/skwol/wallet/internal/domain/wallet"
 )
 
 const (
 	walletURL                 = "/api/v1/wallets/{record_id}"
 	walletWithTransactionsURL = "/api/v1/wallets/{record_id}/transactions"
+	walletCloseURL            = "/api/v1/wallets/{record_id}/close"
+	walletStatementsURL       = "/api/v1/wallets/{record_id}/statements"
+	walletBalanceURL          = "/api/v1/wallets/{record_id}/balance"
+	walletBalanceHistoryURL   = "/api/v1/wallets/{record_id}/balance-history"
 	walletsURL                = "/api/v1/wallets"
 )
 
+// StreamingURLs are written while statements are rendered, they are served without the request timeout.
+var StreamingURLs = []string{walletStatementsURL}
+
 type handler struct {
 	walletService wallet.Service
 	logger        logging.Logger
+	// currency of amounts in bank statement formats, wallets do not keep it
+	currency string
 }
 
-func NewHandler(service wallet.Service, logger logging.Logger) (adapters.Handler, error) {
-	return &handler{walletService: service, logger: logger}, nil
+func NewHandler(service wallet.Service, logger logging.Logger, currency string) (adapters.Handler, error) {
+	if err := bankstatement.ValidateCurrency(currency); err != nil {
+		return nil, errors.Wrap(err, "error validating statement currency")
+	}
+	return &handler{walletService: service, logger: logger, currency: currency}, nil
 }
 
 func (h *handler) Register(router *mux.Router) {
 	router.HandleFunc(walletsURL, h.getAllWallets).Methods(http.MethodGet)
 	router.HandleFunc(walletURL, h.getWallet).Methods(http.MethodGet)
 	router.HandleFunc(walletWithTransactionsURL, h.getWalletWithTransactions).Methods(http.MethodGet)
+	router.HandleFunc(walletStatementsURL, h.getWalletStatement).Methods(http.MethodGet)
+	router.HandleFunc(walletBalanceURL, h.getWalletBalance).Methods(http.MethodGet)
+	router.HandleFunc(walletBalanceHistoryURL, h.getWalletBalanceHistory).Methods(http.MethodGet)
 
 	router.HandleFunc(walletURL, h.updateWallet).Methods(http.MethodPatch)
+	router.HandleFunc(walletCloseURL, h.closeWallet).Methods(http.MethodPost)
 
 	router.HandleFunc(walletsURL, h.createWallet).Methods(http.MethodPost)
 }
@@ -72,6 +92,10 @@ func (h *handler) getWallet(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) getWalletWithTransactions(w http.ResponseWriter, r *http.Request) {
+	if !adapters.OffsetRequested(r) {
+		h.getWalletTransactionsPage(w, r)
+		return
+	}
 	limit, err := strconv.Atoi(r.FormValue("limit"))
 	if err != nil {
 		h.logger.Errorf("error parsing limit query param: %s", err.Error())
@@ -92,7 +116,13 @@ func (h *handler) getWalletWithTransactions(w http.ResponseWriter, r *http.Reque
 		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
 		return
 	}
-	walletDTO, err := h.walletService.GetByIDWithTransactions(r.Context(), id, limit, offset)
+	runningBalance, err := readRunningBalance(r)
+	if err != nil {
+		h.logger.Errorf("error parsing running_balance query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing running_balance query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	walletDTO, err := h.walletService.GetByIDWithTransactions(r.Context(), id, limit, offset, runningBalance)
 	if err != nil {
 		h.logger.Errorf("error returned from service: %s", err.Error())
 		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
@@ -137,6 +167,9 @@ func (h *handler) updateWallet(w http.ResponseWriter, r *http.Request) {
 	updateRequest := request.toUpdateRequest()
 	walletDTO, err := h.walletService.Update(r.Context(), id, &updateRequest)
 	if err != nil {
+		if handlerapproval.WriteIfPending(w, h.logger, err) {
+			return
+		}
 		h.logger.Errorf("error updating wallet: %s", err.Error())
 		http.Error(w, fmt.Sprintf("error updating wallet: %s", err.Error()), http.StatusUnprocessableEntity)
 		return
@@ -155,6 +188,36 @@ func (h *handler) updateWallet(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *handler) closeWallet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	walletDTO, err := h.walletService.Close(r.Context(), id)
+	if err != nil {
+		if handlerapproval.WriteIfPending(w, h.logger, err) {
+			return
+		}
+		h.logger.Errorf("error closing wallet: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error closing wallet: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	response, err := json.Marshal(newWallet(walletDTO))
+	if err != nil {
+		h.logger.Errorf("error marshaling wallet: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling wallet: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (h *handler) createWallet(w http.ResponseWriter, r *http.Request) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -194,6 +257,10 @@ func (h *handler) createWallet(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) getAllWallets(w http.ResponseWriter, r *http.Request) {
+	if !adapters.OffsetRequested(r) {
+		h.getWalletsPage(w, r)
+		return
+	}
 	limit, err := strconv.Atoi(r.FormValue("limit"))
 	if err != nil {
 		h.logger.Errorf("error parsing limit query param: %s", err.Error())
@@ -235,3 +302,231 @@ func (h *handler) getAllWallets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *handler) getWalletsPage(w http.ResponseWriter, r *http.Request) {
+	page, err := adapters.ParsePage(r)
+	if err != nil {
+		h.logger.Errorf("error parsing page: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing page: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	pageDTO, err := h.walletService.GetPage(r.Context(), page)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	response, err := json.Marshal(newWalletsPage(pageDTO))
+	if err != nil {
+		h.logger.Errorf("error marshaling wallets: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling wallets: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *handler) getWalletTransactionsPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	page, err := adapters.ParsePage(r)
+	if err != nil {
+		h.logger.Errorf("error parsing page: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing page: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	runningBalance, err := readRunningBalance(r)
+	if err != nil {
+		h.logger.Errorf("error parsing running_balance query param: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing running_balance query param: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	pageDTO, err := h.walletService.GetTransactionsPage(r.Context(), id, page, runningBalance)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if pageDTO.Wallet.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	response, err := json.Marshal(newWalletTransactionsPage(pageDTO))
+	if err != nil {
+		h.logger.Errorf("error marshaling wallet transactions: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling wallet transactions: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *handler) getWalletStatement(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	format, err := readStatementFormat(r)
+	if err != nil {
+		h.logger.Errorf("error reading statement format: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading statement format: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	from, to, loc, err := adapters.ParsePeriod(r)
+	if err != nil {
+		h.logger.Errorf("error reading statement period: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading statement period: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	statement, err := h.walletService.GetStatement(r.Context(), id, from, to)
+	if errors.Is(err, wallet.ErrInvalidPeriod) {
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if statement.Wallet.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var fileName string
+	if format != statementJSON {
+		fileName = statementFileName(statement, format, loc)
+	}
+	body := adapters.NewFileWriter(w, statementFormats[format].contentType, fileName)
+	switch format {
+	case statementCSV:
+		err = writeStatementCSV(body, statement, loc)
+	case statementPDF:
+		err = writeStatementPDF(body, statement, loc)
+	case statementCAMT053:
+		err = bankstatement.WriteCAMT053(body, newBankStatement(statement, h.currency, loc))
+	case statementMT940:
+		err = bankstatement.WriteMT940(body, newBankStatement(statement, h.currency, loc))
+	default:
+		err = json.NewEncoder(body).Encode(newStatement(statement))
+	}
+	if err != nil && body.Started() {
+		h.logger.Errorf("error streaming statement: %s", err.Error())
+		panic(http.ErrAbortHandler)
+	}
+	if err != nil {
+		h.logger.Errorf("error rendering statement: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error rendering statement: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if err := body.Close(); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+	}
+}
+
+func (h *handler) getWalletBalance(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	var at time.Time
+	if value := r.FormValue("at"); value != "" {
+		if at, err = adapters.ParseMoment(value, r.FormValue("time_zone")); err != nil {
+			h.logger.Errorf("error parsing at query param: %s", err.Error())
+			http.Error(w, fmt.Sprintf("error parsing at query param: %s", err.Error()), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+	balance, err := h.walletService.GetBalanceAt(r.Context(), id, at)
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if balance.Wallet.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	response, err := json.Marshal(newBalance(balance))
+	if err != nil {
+		h.logger.Errorf("error marshaling balance: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling balance: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *handler) getWalletBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["record_id"], 10, 64)
+	if err != nil {
+		h.logger.Errorf("error parsing id: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error parsing id: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	from, to, loc, err := adapters.ParsePeriod(r)
+	if err != nil {
+		h.logger.Errorf("error reading balance history period: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error reading balance history period: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	interval := wallet.IntervalDay
+	if value := r.FormValue("interval"); value != "" {
+		interval = wallet.Interval(value)
+	}
+	history, err := h.walletService.GetBalanceHistory(r.Context(), id, from, to, interval, loc)
+	if errors.Is(err, wallet.ErrInvalidPeriod) || errors.Is(err, wallet.ErrUnknownInterval) || errors.Is(err, wallet.ErrTooManyPoints) {
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		h.logger.Errorf("error returned from service: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error returned from service: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if history.Wallet.ID == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	response, err := json.Marshal(newBalanceHistory(history))
+	if err != nil {
+		h.logger.Errorf("error marshaling balance history: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error marshaling balance history: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := w.Write(response); err != nil {
+		h.logger.Errorf("error writing response: %s", err.Error())
+		http.Error(w, fmt.Sprintf("error writing response: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+// readRunningBalance reads optional running_balance query param of wallet transactions.
+func readRunningBalance(r *http.Request) (bool, error) {
+	value := r.FormValue("running_balance")
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
@@ -18,12 +18,19 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/gorilla/mux"
 
+	"github.com/skwol/wallet/pkg/bankstatement"
 	"github.com/skwol/wallet/pkg/client/pgdb"
 	"github.com/skwol/wallet/pkg/clock"
 	"github.com/skwol/wallet/pkg/logging"
 	"github.com/skwol/wallet/pkg/testdb"
 
+	dbapproval "github.com/skwol/wallet/internal/adapters/db/approval"
+	dbrisk "github.com/skwol/wallet/internal/adapters/db/risk"
+	dbscreening "github.com/skwol/wallet/internal/adapters/db/screening"
 	dbwallet "github.com/skwol/wallet/internal/adapters/db/wallet"
+	domainapproval "github.com/skwol/wallet/internal/domain/approval"
+	domainrisk "github.com/skwol/wallet/internal/domain/risk"
+	domainscreening "github.com/skwol/wallet/internal/domain/screening"
 	"github.com/skwol/wallet/internal/domain/wallet"
 )
 
@@ -53,11 +60,35 @@ func setup(t *testing.T) {
 			t.Fatalf("error creating wallet storage %s", err.Error())
 		}
 		clk := clock.NewFake(time.Date(2020, 10, 10, 0, 0, 0, 0, time.UTC))
-		service, err := wallet.NewService(storage, logging.GetLogger(), clk)
+		riskStorage, err := dbrisk.NewStorage(dbClient, logging.GetLogger())
+		if err != nil {
+			t.Fatalf("error creating risk storage %s", err.Error())
+		}
+		riskService, err := domainrisk.NewService(riskStorage, logging.GetLogger(), clk, nil)
+		if err != nil {
+			t.Fatalf("error creating risk service %s", err.Error())
+		}
+		screeningStorage, err := dbscreening.NewStorage(dbClient, logging.GetLogger())
+		if err != nil {
+			t.Fatalf("error creating screening storage %s", err.Error())
+		}
+		screeningService, err := domainscreening.NewService(screeningStorage, logging.GetLogger(), clk, domainscreening.DefaultThreshold)
+		if err != nil {
+			t.Fatalf("error creating screening service %s", err.Error())
+		}
+		approvalStorage, err := dbapproval.NewStorage(dbClient, logging.GetLogger())
+		if err != nil {
+			t.Fatalf("error creating approval storage %s", err.Error())
+		}
+		approvalService, err := domainapproval.NewService(approvalStorage, logging.GetLogger(), clk, domainapproval.Rules{})
+		if err != nil {
+			t.Fatalf("error creating approval service %s", err.Error())
+		}
+		service, err := wallet.NewService(storage, approvalService, riskService, screeningService, logging.GetLogger(), clk)
 		if err != nil {
 			t.Fatalf("error creating wallet service %s", err.Error())
 		}
-		handlerInterface, err := NewHandler(service, logging.GetLogger())
+		handlerInterface, err := NewHandler(service, logging.GetLogger(), bankstatement.DefaultCurrency)
 		if err != nil {
 			t.Fatalf("error creating wallet handler %s", err.Error())
 		}
@@ -82,6 +113,26 @@ func newReq(t *testing.T, method, url string, body interface{}) *http.Request {
 	return r
 }
 
+// transactionInDB is the part of the transaction row the wallet update is checked with.
+type transactionInDB struct {
+	SenderID   int
+	ReceiverID int
+	Amount     float32
+	Type       string
+}
+
+func newTestTransaction(id, senderID, receiverID int, amount float32, timestamp time.Time, tranType wallet.TranType) Transaction {
+	transactionType := TransactionType(tranType)
+	return Transaction{
+		Id:         &id,
+		SenderId:   &senderID,
+		ReceiverId: &receiverID,
+		Amount:     &amount,
+		Timestamp:  &timestamp,
+		Type:       &transactionType,
+	}
+}
+
 func TestGetWallets(t *testing.T) {
 	setup(t)
 	ctx := context.Background()
@@ -147,7 +198,7 @@ func TestGetWallets(t *testing.T) {
 			name: "wallet without transactions",
 			args: args{endpoint: fmt.Sprintf("%s/api/v1/wallets/1?test=1", ts.URL)},
 			want: []Wallet{
-				{ID: 1, Name: "test_wallet_one", Balance: 100},
+				{Id: 1, Name: "test_wallet_one", Balance: 100},
 			},
 			wantStatusCode: http.StatusOK,
 			singleValue:    true,
@@ -156,10 +207,10 @@ func TestGetWallets(t *testing.T) {
 			name: "all wallets",
 			args: args{endpoint: fmt.Sprintf("%s/api/v1/wallets?limit=10&offset=0&test=1", ts.URL)},
 			want: []Wallet{
-				{ID: 1, Name: "test_wallet_one", Balance: 100},
-				{ID: 2, Name: "test_wallet_two", Balance: 200},
-				{ID: 3, Name: "test_wallet_three", Balance: 300},
-				{ID: 4, Name: "test_wallet_four", Balance: 400},
+				{Id: 1, Name: "test_wallet_one", Balance: 100},
+				{Id: 2, Name: "test_wallet_two", Balance: 200},
+				{Id: 3, Name: "test_wallet_three", Balance: 300},
+				{Id: 4, Name: "test_wallet_four", Balance: 400},
 			},
 			wantStatusCode: http.StatusOK,
 		},
@@ -167,7 +218,7 @@ func TestGetWallets(t *testing.T) {
 			name: "all wallets limited",
 			args: args{endpoint: fmt.Sprintf("%s/api/v1/wallets?limit=1&offset=1&test=1", ts.URL)},
 			want: []Wallet{
-				{ID: 2, Name: "test_wallet_two", Balance: 200},
+				{Id: 2, Name: "test_wallet_two", Balance: 200},
 			},
 			wantStatusCode: http.StatusOK,
 		},
@@ -180,10 +231,10 @@ func TestGetWallets(t *testing.T) {
 			name: "wallet with all transactions",
 			args: args{endpoint: fmt.Sprintf("%s/api/v1/wallets-with-transactions/1?limit=10&offset=0&test=1", ts.URL)},
 			want: []Wallet{
-				{ID: 1, Name: "test_wallet_one", Balance: 100, Transactions: []Transaction{
-					{ID: 1, SenderID: 1, ReceiverID: 1, Amount: 100, Timestamp: tranOneDate, Type: string(wallet.TranTypeDeposit)},
-					{ID: 2, SenderID: 2, ReceiverID: 1, Amount: 100, Timestamp: tranTwoDate, Type: string(wallet.TranTypeTransfer)},
-					{ID: 3, SenderID: 1, ReceiverID: 1, Amount: 100, Timestamp: tranThreeDate, Type: string(wallet.TranTypeWithdraw)},
+				{Id: 1, Name: "test_wallet_one", Balance: 100, Transactions: &[]Transaction{
+					newTestTransaction(1, 1, 1, 100, tranOneDate, wallet.TranTypeDeposit),
+					newTestTransaction(2, 2, 1, 100, tranTwoDate, wallet.TranTypeTransfer),
+					newTestTransaction(3, 1, 1, 100, tranThreeDate, wallet.TranTypeWithdraw),
 				}},
 			},
 			wantStatusCode: http.StatusOK,
@@ -193,8 +244,8 @@ func TestGetWallets(t *testing.T) {
 			name: "wallet with all transactions limited with offset",
 			args: args{endpoint: fmt.Sprintf("%s/api/v1/wallets-with-transactions/1?limit=1&offset=1&test=1", ts.URL)},
 			want: []Wallet{
-				{ID: 1, Name: "test_wallet_one", Balance: 100, Transactions: []Transaction{
-					{ID: 2, SenderID: 2, ReceiverID: 1, Amount: 100, Timestamp: tranTwoDate, Type: string(wallet.TranTypeTransfer)},
+				{Id: 1, Name: "test_wallet_one", Balance: 100, Transactions: &[]Transaction{
+					newTestTransaction(2, 2, 1, 100, tranTwoDate, wallet.TranTypeTransfer),
 				}},
 			},
 			wantStatusCode: http.StatusOK,
@@ -204,7 +255,7 @@ func TestGetWallets(t *testing.T) {
 			name: "wallet with all transactions limited with offset out of values",
 			args: args{endpoint: fmt.Sprintf("%s/api/v1/wallets-with-transactions/1?limit=1&offset=10&test=1", ts.URL)},
 			want: []Wallet{
-				{ID: 1, Name: "test_wallet_one", Balance: 100},
+				{Id: 1, Name: "test_wallet_one", Balance: 100},
 			},
 			wantStatusCode: http.StatusOK,
 			singleValue:    true,
@@ -288,14 +339,14 @@ func TestUpdateWallet(t *testing.T) {
 		name             string
 		args             args
 		want             Wallet
-		wantTransactions []Transaction
+		wantTransactions []transactionInDB
 		wantStatusCode   int
 	}{
 		{
 			name:             "update wallet, withdraw 100 to become 0",
 			args:             args{request: Wallet{Name: "wallet_one", Balance: 0}, enpoint: "/api/v1/wallets/1?test=1"},
-			want:             Wallet{ID: 1, Name: "wallet_one", Balance: 0},
-			wantTransactions: []Transaction{{SenderID: 1, ReceiverID: 1, Amount: 100, Type: string(wallet.TranTypeWithdraw)}},
+			want:             Wallet{Id: 1, Name: "wallet_one", Balance: 0},
+			wantTransactions: []transactionInDB{{SenderID: 1, ReceiverID: 1, Amount: 100, Type: string(wallet.TranTypeWithdraw)}},
 			wantStatusCode:   http.StatusOK,
 		},
 		{
@@ -306,14 +357,14 @@ func TestUpdateWallet(t *testing.T) {
 		{
 			name:             "update wallet deposit 100 to become 300",
 			args:             args{request: Wallet{Name: "wallet_two", Balance: 300}, enpoint: "/api/v1/wallets/2?test=1"},
-			want:             Wallet{ID: 2, Name: "wallet_two", Balance: 300},
-			wantTransactions: []Transaction{{SenderID: 2, ReceiverID: 2, Amount: 100, Type: string(wallet.TranTypeDeposit)}},
+			want:             Wallet{Id: 2, Name: "wallet_two", Balance: 300},
+			wantTransactions: []transactionInDB{{SenderID: 2, ReceiverID: 2, Amount: 100, Type: string(wallet.TranTypeDeposit)}},
 			wantStatusCode:   http.StatusOK,
 		},
 		{
 			name:           "update non existing wallet",
 			args:           args{request: Wallet{Name: "wallet_three", Balance: 300}, enpoint: "/api/v1/wallets/3?test=1"},
-			wantStatusCode: http.StatusUnprocessableEntity,
+			wantStatusCode: http.StatusNotFound,
 		},
 	}
 	for _, tt := range tests {
@@ -351,15 +402,15 @@ func TestUpdateWallet(t *testing.T) {
 			if err := json.Unmarshal(result, &got); err != nil {
 				t.Fatalf("test %s: error unmarshaling response: %s", tt.name, err.Error())
 			}
-			tt.want.ID = got.ID
+			tt.want.Id = got.Id
 			if !reflect.DeepEqual(tt.want, got) {
 				t.Fatalf("test %s: wrong wallet returned, expected: %+v, got: %+v", tt.name, tt.want, got)
 			}
 
 			// test wallet in db
-			row := dbClient.Conn.QueryRowContext(ctx, `SELECT id, name, balance FROM wallet WHERE id = $1;`, got.ID)
+			row := dbClient.Conn.QueryRowContext(ctx, `SELECT id, name, balance FROM wallet WHERE id = $1;`, got.Id)
 			var gotInDB Wallet
-			switch err := row.Scan(&gotInDB.ID, &gotInDB.Name, &gotInDB.Balance); err {
+			switch err := row.Scan(&gotInDB.Id, &gotInDB.Name, &gotInDB.Balance); err {
 			case sql.ErrNoRows:
 				t.Fatalf("test %s: wallet was not created", tt.name)
 			default:
@@ -369,13 +420,13 @@ func TestUpdateWallet(t *testing.T) {
 			}
 
 			// test transactions in db
-			var transactionsInDB []Transaction
+			var transactionsInDB []transactionInDB
 
-			rows, err := dbClient.Conn.QueryContext(ctx, "SELECT sender_id, receiver_id, amount, tran_type FROM transaction WHERE sender_id = $1 OR receiver_id = $1 ORDER BY ID ASC", got.ID)
+			rows, err := dbClient.Conn.QueryContext(ctx, "SELECT sender_id, receiver_id, amount, tran_type FROM transaction WHERE sender_id = $1 OR receiver_id = $1 ORDER BY ID ASC", got.Id)
 			if err != nil {
 				t.Fatalf("test %s: error getting transactions from db: %s", tt.name, err.Error())
 			}
-			var tran Transaction
+			var tran transactionInDB
 			for rows.Next() {
 				if len(tt.wantTransactions) == 0 {
 					t.Fatalf("test %s: expeted 0 transactions, got some in db", tt.name)
@@ -413,7 +464,7 @@ func TestCreateWallet(t *testing.T) {
 		name             string
 		args             args
 		want             Wallet
-		wantTransactions []Transaction
+		wantTransactions []transactionInDB
 		wantStatusCode   int
 	}{
 		{
@@ -426,7 +477,7 @@ func TestCreateWallet(t *testing.T) {
 			name:             "create wallet with 100 balance",
 			args:             args{Wallet{Name: "wallet_two", Balance: 100}},
 			want:             Wallet{Name: "wallet_two", Balance: 100},
-			wantTransactions: []Transaction{{Amount: 100, Type: string(wallet.TranTypeDeposit)}},
+			wantTransactions: []transactionInDB{{Amount: 100, Type: string(wallet.TranTypeDeposit)}},
 			wantStatusCode:   http.StatusCreated,
 		},
 		{
@@ -469,15 +520,15 @@ func TestCreateWallet(t *testing.T) {
 			if err := json.Unmarshal(result, &got); err != nil {
 				t.Fatalf("test %s: error unmarshaling response: %s", tt.name, err.Error())
 			}
-			tt.want.ID = got.ID
+			tt.want.Id = got.Id
 			if !reflect.DeepEqual(tt.want, got) {
 				t.Fatalf("test %s: wrong wallet returned, expected: %+v, got: %+v", tt.name, tt.want, got)
 			}
 
 			// test wallet in db
-			row := dbClient.Conn.QueryRowContext(ctx, `SELECT id, name, balance FROM wallet WHERE id = $1;`, got.ID)
+			row := dbClient.Conn.QueryRowContext(ctx, `SELECT id, name, balance FROM wallet WHERE id = $1;`, got.Id)
 			var gotInDB Wallet
-			switch err := row.Scan(&gotInDB.ID, &gotInDB.Name, &gotInDB.Balance); err {
+			switch err := row.Scan(&gotInDB.Id, &gotInDB.Name, &gotInDB.Balance); err {
 			case sql.ErrNoRows:
 				t.Fatalf("test %s: wallet was not created", tt.name)
 			default:
@@ -487,13 +538,13 @@ func TestCreateWallet(t *testing.T) {
 			}
 
 			// test transactions in db
-			var transactionsInDB []Transaction
+			var transactionsInDB []transactionInDB
 
-			rows, err := dbClient.Conn.QueryContext(ctx, "SELECT amount, tran_type FROM transaction WHERE sender_id = $1 OR receiver_id = $1 ORDER BY ID ASC", got.ID)
+			rows, err := dbClient.Conn.QueryContext(ctx, "SELECT amount, tran_type FROM transaction WHERE sender_id = $1 OR receiver_id = $1 ORDER BY ID ASC", got.Id)
 			if err != nil {
 				t.Fatalf("test %s: error getting transactions from db: %s", tt.name, err.Error())
 			}
-			var tran Transaction
+			var tran transactionInDB
 			for rows.Next() {
 				if len(tt.wantTransactions) == 0 {
 					t.Fatalf("test %s: expeted 0 transactions, got some in db", tt.name)
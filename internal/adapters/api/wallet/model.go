@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"github.com/skwol/wallet/pkg/pagination"
+
 	"github.com/skwol/wallet/internal/domain/wallet"
 )
 
@@ -10,6 +12,10 @@ func newWallet(dto wallet.DTO) Wallet {
 		Name:    dto.Name,
 		Balance: float32(dto.Balance),
 	}
+	if dto.Status != "" {
+		status := WalletStatus(dto.Status)
+		w.Status = &status
+	}
 	if len(dto.Transactions) == 0 {
 		return w
 	}
@@ -40,7 +46,7 @@ func newTransaction(dto wallet.TransactionDTO) Transaction {
 	receiverID := int(dto.ReceiverID)
 	amount := float32(dto.Amount)
 	tranType := TransactionType(dto.Type)
-	return Transaction{
+	transaction := Transaction{
 		Id:         &id,
 		SenderId:   &senderID,
 		ReceiverId: &receiverID,
@@ -48,4 +54,99 @@ func newTransaction(dto wallet.TransactionDTO) Transaction {
 		Timestamp:  &dto.Timestamp,
 		Type:       &tranType,
 	}
+	if dto.Balance != nil {
+		balance := float32(*dto.Balance)
+		transaction.Balance = &balance
+	}
+	return transaction
+}
+
+func newStatement(dto wallet.StatementDTO) Statement {
+	statement := Statement{
+		Wallet:         newWallet(dto.Wallet),
+		From:           dto.From,
+		To:             dto.To,
+		CreatedAt:      dto.CreatedAt,
+		OpeningBalance: float32(dto.OpeningBalance),
+		ClosingBalance: float32(dto.ClosingBalance),
+		TotalIn:        float32(dto.TotalIn),
+		TotalOut:       float32(dto.TotalOut),
+		Lines:          make([]StatementLine, 0, len(dto.Lines)),
+	}
+	for _, l := range dto.Lines {
+		line := StatementLine{
+			Transaction: newTransaction(l.Transaction),
+			Amount:      float32(l.Amount),
+			Balance:     float32(l.Balance),
+		}
+		if l.CounterpartyID != 0 {
+			counterpartyID := int(l.CounterpartyID)
+			line.CounterpartyId = &counterpartyID
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+	return statement
+}
+
+func newBalance(dto wallet.BalanceDTO) Balance {
+	return Balance{
+		WalletId: int(dto.Wallet.ID),
+		At:       dto.At,
+		Balance:  float32(dto.Balance),
+	}
+}
+
+func newBalanceHistory(dto wallet.BalanceHistoryDTO) BalanceHistory {
+	history := BalanceHistory{
+		WalletId:       int(dto.Wallet.ID),
+		From:           dto.From,
+		To:             dto.To,
+		Interval:       string(dto.Interval),
+		OpeningBalance: float32(dto.OpeningBalance),
+		ClosingBalance: float32(dto.ClosingBalance),
+		Points:         make([]BalancePoint, 0, len(dto.Points)),
+	}
+	for _, p := range dto.Points {
+		history.Points = append(history.Points, BalancePoint{
+			From:    p.From,
+			To:      p.To,
+			Change:  float32(p.Change),
+			Balance: float32(p.Balance),
+		})
+	}
+	return history
+}
+
+func newWalletsPage(dto wallet.PageDTO) WalletsPage {
+	page := WalletsPage{Wallets: make([]Wallet, 0, len(dto.Wallets))}
+	for _, w := range dto.Wallets {
+		page.Wallets = append(page.Wallets, newWallet(w))
+	}
+	page.NextCursor, page.PrevCursor, page.Total = newCursors(dto.Result)
+	return page
+}
+
+func newWalletTransactionsPage(dto wallet.TransactionsPageDTO) WalletTransactionsPage {
+	page := WalletTransactionsPage{Transactions: make([]Transaction, 0, len(dto.Wallet.Transactions))}
+	for _, t := range dto.Wallet.Transactions {
+		page.Transactions = append(page.Transactions, newTransaction(t))
+	}
+	dto.Wallet.Transactions = nil
+	page.Wallet = newWallet(dto.Wallet)
+	page.NextCursor, page.PrevCursor, page.Total = newCursors(dto.Result)
+	return page
+}
+
+func newCursors(result pagination.Result) (next, prev *string, total *int) {
+	if result.Next != "" {
+		next = &result.Next
+	}
+	if result.Prev != "" {
+		prev = &result.Prev
+	}
+	if result.Total != nil {
+		t := int(*result.Total)
+		total = &t
+	}
+	return next, prev, total
 }
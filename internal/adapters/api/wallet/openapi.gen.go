@@ -7,13 +7,63 @@ import (
 	"time"
 )
 
+const (
+	ApiTokenScopes = "ApiToken.Scopes"
+)
+
 // Defines values for TransactionType.
 const (
 	Deposit  TransactionType = "deposit"
+	Reversal TransactionType = "reversal"
 	Transfer TransactionType = "transfer"
 	Withdraw TransactionType = "withdraw"
 )
 
+// Defines values for WalletStatus.
+const (
+	Active WalletStatus = "active"
+	Closed WalletStatus = "closed"
+	Frozen WalletStatus = "frozen"
+)
+
+// Balance defines model for Balance.
+type Balance struct {
+	At time.Time `json:"at"`
+
+	// sum of movements of the wallet before the moment
+	Balance  float32 `json:"balance"`
+	WalletId int     `json:"wallet_id"`
+}
+
+// BalanceHistory defines model for BalanceHistory.
+type BalanceHistory struct {
+	// balance at the end of the period
+	ClosingBalance float32 `json:"closing_balance"`
+
+	// start of the period, included
+	From     time.Time `json:"from"`
+	Interval string    `json:"interval"`
+
+	// balance at the start of the period
+	OpeningBalance float32        `json:"opening_balance"`
+	Points         []BalancePoint `json:"points"`
+
+	// end of the period, not included
+	To       time.Time `json:"to"`
+	WalletId int       `json:"wallet_id"`
+}
+
+// BalancePoint defines model for BalancePoint.
+type BalancePoint struct {
+	// balance at the end of the interval
+	Balance float32 `json:"balance"`
+
+	// sum of movements within the interval
+	Change float32   `json:"change"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	Code      *int    `json:"code,omitempty"`
@@ -22,11 +72,51 @@ type Error struct {
 	Status    string  `json:"status"`
 }
 
+// Statement defines model for Statement.
+type Statement struct {
+	// balance at the end of the period
+	ClosingBalance float32   `json:"closing_balance"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// start of the period, included
+	From  time.Time       `json:"from"`
+	Lines []StatementLine `json:"lines"`
+
+	// balance at the start of the period
+	OpeningBalance float32 `json:"opening_balance"`
+
+	// end of the period, not included
+	To time.Time `json:"to"`
+
+	// sum of money which came into the wallet within the period
+	TotalIn float32 `json:"total_in"`
+
+	// sum of money which left the wallet within the period
+	TotalOut float32 `json:"total_out"`
+	Wallet   Wallet  `json:"wallet"`
+}
+
+// StatementLine defines model for StatementLine.
+type StatementLine struct {
+	// movement of the wallet, negative when money left it
+	Amount float32 `json:"amount"`
+
+	// balance right after the transaction
+	Balance float32 `json:"balance"`
+
+	// other wallet of a transfer or reversal
+	CounterpartyId *int        `json:"counterparty_id,omitempty"`
+	Transaction    Transaction `json:"transaction"`
+}
+
 // Transaction defines model for Transaction.
 type Transaction struct {
 	// transfer amount
 	Amount *float32 `json:"amount,omitempty"`
 
+	// balance of the wallet right after the transaction, only when asked with running_balance param
+	Balance *float32 `json:"balance,omitempty"`
+
 	// transaction id
 	Id *int `json:"id,omitempty"`
 
@@ -51,38 +141,155 @@ type Wallet struct {
 	Id int `json:"id"`
 
 	// Wallet name
-	Name         string         `json:"name"`
+	Name string `json:"name"`
+
+	// frozen wallet has a watchlist match waiting for review
+	Status       *WalletStatus  `json:"status,omitempty"`
 	Transactions *[]Transaction `json:"transactions,omitempty"`
 }
 
+// frozen wallet has a watchlist match waiting for review
+type WalletStatus string
+
+// WalletTransactionsPage defines model for WalletTransactionsPage.
+type WalletTransactionsPage struct {
+	// cursor of the next page, missing on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
+
+	// cursor of the previous page, missing on the first page
+	PrevCursor *string `json:"prev_cursor,omitempty"`
+
+	// number of all transactions of the wallet, only when asked with total param
+	Total        *int          `json:"total,omitempty"`
+	Transactions []Transaction `json:"transactions"`
+	Wallet       Wallet        `json:"wallet"`
+}
+
 // Wallets defines model for Wallets.
 type Wallets struct {
 	Wallets *[]Wallet `json:"Wallets,omitempty"`
 }
 
+// WalletsPage defines model for WalletsPage.
+type WalletsPage struct {
+	// cursor of the next page, missing on the last page
+	NextCursor *string `json:"next_cursor,omitempty"`
+
+	// cursor of the previous page, missing on the first page
+	PrevCursor *string `json:"prev_cursor,omitempty"`
+
+	// number of all wallets, only when asked with total param
+	Total   *int     `json:"total,omitempty"`
+	Wallets []Wallet `json:"wallets"`
+}
+
 // PathParamWalletID defines model for PathParamWalletID.
 type PathParamWalletID = float32
 
+// QueryParamCursor defines model for QueryParamCursor.
+type QueryParamCursor = string
+
 // QueryParamLimit defines model for QueryParamLimit.
 type QueryParamLimit = float32
 
 // QueryParamOffset defines model for QueryParamOffset.
 type QueryParamOffset = float32
 
+// QueryParamTotal defines model for QueryParamTotal.
+type QueryParamTotal = bool
+
 // GetWalletsParams defines parameters for GetWallets.
 type GetWalletsParams struct {
-	// Limit of how many records returned
-	Limit QueryParamLimit `form:"limit" json:"limit"`
+	// Limit of how many records returned, 50 by default and 500 at most for pages
+	Limit *QueryParamLimit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// next_cursor or prev_cursor of the previous response, first page without it
+	Cursor *QueryParamCursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Count all records
+	Total *QueryParamTotal `form:"total,omitempty" json:"total,omitempty"`
+
+	// Deprecated: offset of returned records, switches to the responses without cursors
+	Offset *QueryParamOffset `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// CreateWalletJSONBody defines parameters for CreateWallet.
+type CreateWalletJSONBody struct {
+	Balance float32 `json:"balance"`
+	Name    string  `json:"name"`
+}
+
+// UpdateWalletJSONBody defines parameters for UpdateWallet.
+type UpdateWalletJSONBody struct {
+	Balance float32 `json:"balance"`
+	Name    string  `json:"name"`
+}
+
+// GetWalletBalanceParams defines parameters for GetWalletBalance.
+type GetWalletBalanceParams struct {
+	// RFC 3339 timestamp or a date which is the end of that day in time_zone, now by default
+	At *string `form:"at,omitempty" json:"at,omitempty"`
 
-	// Offset of returned records
-	Offset QueryParamOffset `form:"offset" json:"offset"`
+	// IANA time zone of the date, UTC by default
+	TimeZone *string `form:"time_zone,omitempty" json:"time_zone,omitempty"`
 }
 
+// GetWalletBalanceHistoryParams defines parameters for GetWalletBalanceHistory.
+type GetWalletBalanceHistoryParams struct {
+	// Start of the period, RFC 3339 timestamp or a date which is the midnight in time_zone
+	From string `form:"from" json:"from"`
+
+	// End of the period, RFC 3339 timestamp which is not included or a date which is included as a whole day
+	To string `form:"to" json:"to"`
+
+	// IANA time zone of dates and intervals, UTC by default
+	TimeZone *string `form:"time_zone,omitempty" json:"time_zone,omitempty"`
+
+	// Length of every point counted from the start of the period, day by default, 1000 points at most
+	Interval *GetWalletBalanceHistoryParamsInterval `form:"interval,omitempty" json:"interval,omitempty"`
+}
+
+// GetWalletBalanceHistoryParamsInterval defines parameters for GetWalletBalanceHistory.
+type GetWalletBalanceHistoryParamsInterval string
+
+// GetWalletStatementParams defines parameters for GetWalletStatement.
+type GetWalletStatementParams struct {
+	// Start of the period, RFC 3339 timestamp or a date which is the midnight in time_zone
+	From string `form:"from" json:"from"`
+
+	// End of the period, RFC 3339 timestamp which is not included or a date which is included as a whole day
+	To string `form:"to" json:"to"`
+
+	// IANA time zone of dates in the period and of timestamps in csv and pdf, UTC by default
+	TimeZone *string `form:"time_zone,omitempty" json:"time_zone,omitempty"`
+
+	// Format of the statement, without it the format is taken from Accept header and is json by default. camt053 is ISO 20022 camt.053.001.02 xml, mt940 is SWIFT MT940
+	Format *GetWalletStatementParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetWalletStatementParamsFormat defines parameters for GetWalletStatement.
+type GetWalletStatementParamsFormat string
+
 // GetWalletWithTransactionsParams defines parameters for GetWalletWithTransactions.
 type GetWalletWithTransactionsParams struct {
-	// Limit of how many records returned
-	Limit QueryParamLimit `form:"limit" json:"limit"`
+	// Limit of how many records returned, 50 by default and 500 at most for pages
+	Limit *QueryParamLimit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// next_cursor or prev_cursor of the previous response, first page without it
+	Cursor *QueryParamCursor `form:"cursor,omitempty" json:"cursor,omitempty"`
 
-	// Offset of returned records
-	Offset QueryParamOffset `form:"offset" json:"offset"`
+	// Count all records
+	Total *QueryParamTotal `form:"total,omitempty" json:"total,omitempty"`
+
+	// Deprecated: offset of returned records, switches to the responses without cursors
+	Offset *QueryParamOffset `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Add the balance of the wallet right after every transaction, transactions are in id order
+	RunningBalance *bool `form:"running_balance,omitempty" json:"running_balance,omitempty"`
 }
+
+// CreateWalletJSONRequestBody defines body for CreateWallet for application/json ContentType.
+type CreateWalletJSONRequestBody CreateWalletJSONBody
+
+// UpdateWalletJSONRequestBody defines body for UpdateWallet for application/json ContentType.
+type UpdateWalletJSONRequestBody UpdateWalletJSONBody
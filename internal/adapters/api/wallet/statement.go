@@ -0,0 +1,249 @@
+package wallet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/bankstatement"
+	"github.com/skwol/wallet/pkg/pdf"
+
+	"github.com/skwol/wallet/internal/domain/wallet"
+)
+
+type statementFormat string
+
+const (
+	statementJSON    statementFormat = "json"
+	statementCSV     statementFormat = "csv"
+	statementPDF     statementFormat = "pdf"
+	statementCAMT053 statementFormat = "camt053"
+	statementMT940   statementFormat = "mt940"
+)
+
+var statementFormats = map[statementFormat]struct{ contentType, extension string }{
+	statementJSON:    {"application/json", "json"},
+	statementCSV:     {"text/csv", "csv"},
+	statementPDF:     {"application/pdf", "pdf"},
+	statementCAMT053: {"application/xml", "xml"},
+	statementMT940:   {"text/plain", "sta"},
+}
+
+const dateLayout = "2006-01-02"
+
+var errUnknownStatementFormat = errors.New("unknown statement format")
+
+// readStatementFormat takes the format query param or, without it, the first statement format in the Accept header,
+// statement is json when the client accepts anything or none of the formats.
+func readStatementFormat(r *http.Request) (statementFormat, error) {
+	if value := r.FormValue("format"); value != "" {
+		format := statementFormat(value)
+		if _, ok := statementFormats[format]; !ok {
+			return "", errors.Wrapf(errUnknownStatementFormat, "%q", value)
+		}
+		return format, nil
+	}
+	for _, value := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		for format, spec := range statementFormats {
+			if mediaType == spec.contentType {
+				return format, nil
+			}
+		}
+	}
+	return statementJSON, nil
+}
+
+func statementFileName(dto wallet.StatementDTO, format statementFormat, loc *time.Location) string {
+	return fmt.Sprintf("statement-%d-%s.%s", dto.Wallet.ID, dto.From.In(loc).Format(dateLayout), statementFormats[format].extension)
+}
+
+// newBankStatement maps the statement to camt.053 and MT940 exports. Transaction id is the reference of the account servicer
+// and the split payment of a transfer is the reference of the account owner, transactions have no other external reference.
+func newBankStatement(dto wallet.StatementDTO, currency string, loc *time.Location) bankstatement.Statement {
+	lastDay := dto.To.Add(-time.Nanosecond).In(loc)
+	statement := bankstatement.Statement{
+		// MT940 keeps 16 characters of it
+		ID:             fmt.Sprintf("%d-%s-%s", dto.Wallet.ID, dto.From.In(loc).Format("060102"), lastDay.Format("0102")),
+		Account:        strconv.FormatInt(dto.Wallet.ID, 10),
+		AccountName:    dto.Wallet.Name,
+		Currency:       currency,
+		Created:        dto.CreatedAt.In(loc),
+		From:           dto.From.In(loc),
+		To:             dto.To.In(loc),
+		OpeningBalance: dto.OpeningBalance,
+		ClosingBalance: dto.ClosingBalance,
+		Entries:        make([]bankstatement.Entry, 0, len(dto.Lines)),
+	}
+	for _, line := range dto.Lines {
+		entry := bankstatement.Entry{
+			Reference: strconv.FormatInt(line.Transaction.ID, 10),
+			Amount:    line.Amount,
+			Booked:    line.Transaction.Timestamp.In(loc),
+			Kind:      bankstatement.KindTransfer,
+			Reversal:  line.Transaction.Type == wallet.TranTypeReversal,
+			Info:      string(line.Transaction.Type),
+		}
+		if line.Transaction.ParentPaymentID != 0 {
+			entry.ExternalReference = fmt.Sprintf("PAYMENT-%d", line.Transaction.ParentPaymentID)
+		}
+		switch {
+		case line.CounterpartyID == 0:
+			entry.Kind = bankstatement.KindCash
+		case line.Amount >= 0:
+			entry.Counterparty = strconv.FormatInt(line.CounterpartyID, 10)
+			entry.Info = fmt.Sprintf("%s from wallet %d", line.Transaction.Type, line.CounterpartyID)
+		default:
+			entry.Counterparty = strconv.FormatInt(line.CounterpartyID, 10)
+			entry.Info = fmt.Sprintf("%s to wallet %d", line.Transaction.Type, line.CounterpartyID)
+		}
+		statement.Entries = append(statement.Entries, entry)
+	}
+	return statement
+}
+
+// writeStatementCSV writes the summary of the statement, then its lines and totals, blank rows split the parts.
+func writeStatementCSV(w io.Writer, dto wallet.StatementDTO, loc *time.Location) error {
+	writer := csv.NewWriter(w)
+	records := [][]string{
+		{"Wallet ID", strconv.FormatInt(dto.Wallet.ID, 10)},
+		{"Wallet name", dto.Wallet.Name},
+		{"From", dto.From.In(loc).Format(time.RFC3339)},
+		{"To", dto.To.In(loc).Format(time.RFC3339)},
+		{"Opening balance", csvAmount(dto.OpeningBalance)},
+		{},
+		{"Transaction ID", "Timestamp", "Type", "Counterparty ID", "In", "Out", "Balance"},
+	}
+	for _, line := range dto.Lines {
+		in, out := lineAmounts(line, csvAmount)
+		var counterpartyID string
+		if line.CounterpartyID != 0 {
+			counterpartyID = strconv.FormatInt(line.CounterpartyID, 10)
+		}
+		records = append(records, []string{
+			strconv.FormatInt(line.Transaction.ID, 10),
+			line.Transaction.Timestamp.In(loc).Format(time.RFC3339),
+			string(line.Transaction.Type),
+			counterpartyID,
+			in,
+			out,
+			csvAmount(line.Balance),
+		})
+	}
+	records = append(records,
+		[]string{},
+		[]string{"Total in", csvAmount(dto.TotalIn)},
+		[]string{"Total out", csvAmount(dto.TotalOut)},
+		[]string{"Closing balance", csvAmount(dto.ClosingBalance)},
+	)
+	return writer.WriteAll(records)
+}
+
+// statement pdf layout in points
+const (
+	pdfMargin     = 40
+	pdfFooter     = 30
+	pdfLineHeight = 14
+	pdfFontSize   = 9
+)
+
+// pdfColumns are the left edges of text columns and the right edges of amount columns.
+var pdfColumns = struct {
+	date, id, kind, counterparty, in, out, balance float64
+}{date: 40, id: 130, kind: 190, counterparty: 250, in: 395, out: 475, balance: 555}
+
+// writeStatementPDF lays the statement out on A4 pages, the table header is repeated on every page.
+func writeStatementPDF(w io.Writer, dto wallet.StatementDTO, loc *time.Location) error {
+	doc := pdf.New()
+	y := pdf.PageHeight - pdfMargin - 10
+	doc.Text(pdfMargin, y, pdf.Bold, 16, "Account statement")
+	y -= 24
+	summary := [][2]string{
+		{"Wallet", fmt.Sprintf("%s (#%d)", dto.Wallet.Name, dto.Wallet.ID)},
+		{"Period", fmt.Sprintf("%s - %s (%s)", dto.From.In(loc).Format("2006-01-02 15:04"), dto.To.In(loc).Format("2006-01-02 15:04"), loc)},
+		{"Opening balance", pdfAmount(dto.OpeningBalance)},
+		{"Total in", pdfAmount(dto.TotalIn)},
+		{"Total out", pdfAmount(dto.TotalOut)},
+		{"Closing balance", pdfAmount(dto.ClosingBalance)},
+	}
+	for _, row := range summary {
+		doc.Text(pdfMargin, y, pdf.Bold, 10, row[0])
+		doc.Text(pdfMargin+100, y, pdf.Regular, 10, row[1])
+		y -= pdfLineHeight
+	}
+	y -= pdfLineHeight
+	y = pdfTableHeader(doc, y)
+
+	for _, line := range dto.Lines {
+		if y < pdfMargin+pdfFooter {
+			doc.AddPage()
+			y = pdf.PageHeight - pdfMargin
+			doc.Text(pdfMargin, y, pdf.Regular, pdfFontSize, fmt.Sprintf("Account statement of %s (#%d)", dto.Wallet.Name, dto.Wallet.ID))
+			y = pdfTableHeader(doc, y-2*pdfLineHeight)
+		}
+		in, out := lineAmounts(line, pdfAmount)
+		doc.Text(pdfColumns.date, y, pdf.Regular, pdfFontSize, line.Transaction.Timestamp.In(loc).Format("2006-01-02 15:04"))
+		doc.Text(pdfColumns.id, y, pdf.Regular, pdfFontSize, strconv.FormatInt(line.Transaction.ID, 10))
+		doc.Text(pdfColumns.kind, y, pdf.Regular, pdfFontSize, string(line.Transaction.Type))
+		if line.CounterpartyID != 0 {
+			doc.Text(pdfColumns.counterparty, y, pdf.Regular, pdfFontSize, fmt.Sprintf("#%d", line.CounterpartyID))
+		}
+		if in != "" {
+			doc.TextRight(pdfColumns.in, y, pdf.Regular, pdfFontSize, in)
+		} else {
+			doc.TextRight(pdfColumns.out, y, pdf.Regular, pdfFontSize, out)
+		}
+		doc.TextRight(pdfColumns.balance, y, pdf.Regular, pdfFontSize, pdfAmount(line.Balance))
+		y -= pdfLineHeight
+	}
+	if len(dto.Lines) == 0 {
+		doc.Text(pdfMargin, y, pdf.Regular, pdfFontSize, "No transactions in the period")
+	}
+
+	pages := doc.PageCount()
+	for i := 0; i < pages; i++ {
+		doc.SetPage(i)
+		doc.TextRight(pdf.PageWidth-pdfMargin, pdfFooter, pdf.Regular, 8, fmt.Sprintf("Page %d of %d", i+1, pages))
+	}
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// pdfTableHeader draws the column names at y and returns y of the first row.
+func pdfTableHeader(doc *pdf.Document, y float64) float64 {
+	doc.Text(pdfColumns.date, y, pdf.Bold, pdfFontSize, "Date")
+	doc.Text(pdfColumns.id, y, pdf.Bold, pdfFontSize, "Transaction")
+	doc.Text(pdfColumns.kind, y, pdf.Bold, pdfFontSize, "Type")
+	doc.Text(pdfColumns.counterparty, y, pdf.Bold, pdfFontSize, "Counterparty")
+	doc.TextRight(pdfColumns.in, y, pdf.Bold, pdfFontSize, "In")
+	doc.TextRight(pdfColumns.out, y, pdf.Bold, pdfFontSize, "Out")
+	doc.TextRight(pdfColumns.balance, y, pdf.Bold, pdfFontSize, "Balance")
+	doc.Line(pdfMargin, y-4, pdf.PageWidth-pdfMargin, y-4)
+	return y - pdfLineHeight - 2
+}
+
+// lineAmounts puts the movement of the line into the in or the out column.
+func lineAmounts(line wallet.StatementLineDTO, format func(float64) string) (in, out string) {
+	if line.Amount >= 0 {
+		return format(line.Amount), ""
+	}
+	return "", format(-line.Amount)
+}
+
+func csvAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 4, 64)
+}
+
+func pdfAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,217 @@
+package approval
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/approval"
+)
+
+const selectRequest = `SELECT id, operation, amount, payload, maker, checker, status, reason, created_at, decided_at FROM approval_request`
+
+type dbRequest struct {
+	ID        int64
+	Operation approval.Operation
+	Amount    float64
+	Payload   []byte
+	Maker     string
+	Checker   sql.NullString
+	Status    approval.Status
+	Reason    sql.NullString
+	CreatedAt time.Time
+	DecidedAt sql.NullTime
+}
+
+func (db dbRequest) ToDTO() approval.DTO {
+	return approval.DTO{
+		ID:        db.ID,
+		Operation: db.Operation,
+		Amount:    db.Amount,
+		Payload:   db.Payload,
+		Maker:     db.Maker,
+		Checker:   db.Checker.String,
+		Status:    db.Status,
+		Reason:    db.Reason.String,
+		CreatedAt: db.CreatedAt,
+		DecidedAt: db.DecidedAt.Time,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRequest(row scanner) (dbRequest, error) {
+	var r dbRequest
+	err := row.Scan(&r.ID, &r.Operation, &r.Amount, &r.Payload, &r.Maker, &r.Checker, &r.Status, &r.Reason, &r.CreatedAt, &r.DecidedAt)
+	return r, err
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
+
+type approvalStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (approval.Storage, error) {
+	return &approvalStorage{db: db, logger: logger}, nil
+}
+
+func (as *approvalStorage) Create(ctx context.Context, dto *approval.DTO, audit approval.AuditDTO) (approval.DTO, error) {
+	result := *dto
+	tx, err := as.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return result, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			as.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	row := tx.QueryRowContext(ctx, "INSERT INTO approval_request (operation, amount, payload, maker, status, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;",
+		dto.Operation, dto.Amount, dto.Payload, dto.Maker, dto.Status, dto.CreatedAt)
+	if err = row.Scan(&result.ID); err != nil {
+		rollback()
+		return result, errors.Wrap(err, "error inserting approval request")
+	}
+	audit.ApprovalID = result.ID
+	if err = addAudit(ctx, tx, audit); err != nil {
+		rollback()
+		return result, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return result, errors.Wrap(err, "error during commit")
+	}
+	return result, nil
+}
+
+func (as *approvalStorage) GetByID(ctx context.Context, id int64) (approval.DTO, error) {
+	row := as.db.Conn.QueryRowContext(ctx, selectRequest+" WHERE id = $1;", id)
+	r, err := scanRequest(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return approval.DTO{}, nil
+		}
+		return approval.DTO{}, err
+	}
+	result := r.ToDTO()
+
+	rows, err := as.db.Conn.QueryContext(ctx, "SELECT id, actor, action, details, created_at FROM audit_log WHERE approval_id = $1 ORDER BY id ASC;", id)
+	if err != nil {
+		return approval.DTO{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			audit   = approval.AuditDTO{ApprovalID: id}
+			details sql.NullString
+		)
+		if err := rows.Scan(&audit.ID, &audit.Actor, &audit.Action, &details, &audit.CreatedAt); err != nil {
+			return approval.DTO{}, err
+		}
+		audit.Details = details.String
+		result.Audit = append(result.Audit, audit)
+	}
+	return result, rows.Err()
+}
+
+func (as *approvalStorage) GetByStatus(ctx context.Context, status approval.Status, limit int, offset int) ([]approval.DTO, error) {
+	var list []approval.DTO
+	rows, err := as.db.Conn.QueryContext(ctx, selectRequest+" WHERE status = $1 ORDER BY id ASC LIMIT $2 OFFSET $3;", status, limit, offset)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r, err := scanRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r.ToDTO())
+	}
+	return list, rows.Err()
+}
+
+func (as *approvalStorage) Decide(ctx context.Context, dto *approval.DTO, audit approval.AuditDTO) (bool, error) {
+	tx, err := as.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			as.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE approval_request SET checker=$1, status=$2, reason=$3, decided_at=$4 WHERE id=$5 AND status=$6;",
+		nullString(dto.Checker), dto.Status, nullString(dto.Reason), nullTime(dto.DecidedAt), dto.ID, approval.StatusPending)
+	if err != nil {
+		rollback()
+		return false, errors.Wrap(err, "error updating approval request")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		rollback()
+		return false, errors.Wrap(err, "error updating approval request")
+	}
+	if affected == 0 {
+		rollback()
+		return false, nil
+	}
+	if err = addAudit(ctx, tx, audit); err != nil {
+		rollback()
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, errors.Wrap(err, "error during commit")
+	}
+	return true, nil
+}
+
+func (as *approvalStorage) Update(ctx context.Context, dto *approval.DTO) error {
+	_, err := as.db.Conn.ExecContext(ctx, "UPDATE approval_request SET checker=$1, status=$2, reason=$3, decided_at=$4 WHERE id=$5;",
+		nullString(dto.Checker), dto.Status, nullString(dto.Reason), nullTime(dto.DecidedAt), dto.ID)
+	if err != nil {
+		return errors.Wrap(err, "error updating approval request")
+	}
+	return nil
+}
+
+func (as *approvalStorage) AddAudit(ctx context.Context, audit approval.AuditDTO) error {
+	return addAudit(ctx, as.db.Conn, audit)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func addAudit(ctx context.Context, db execer, audit approval.AuditDTO) error {
+	var approvalID sql.NullInt64
+	if audit.ApprovalID != 0 {
+		approvalID = sql.NullInt64{Int64: audit.ApprovalID, Valid: true}
+	}
+	_, err := db.ExecContext(ctx, "INSERT INTO audit_log (actor, action, approval_id, details, created_at) VALUES ($1, $2, $3, $4, $5);",
+		audit.Actor, audit.Action, approvalID, nullString(audit.Details), audit.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, "error inserting audit record")
+	}
+	return nil
+}
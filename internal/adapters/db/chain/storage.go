@@ -0,0 +1,136 @@
+package chain
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/chain"
+)
+
+// sealPage is how many transactions are chained at once.
+const sealPage = 1000
+
+const selectLink = `SELECT chain_seq, id, sender_id, receiver_id, amount::text, date, tran_type::text, parent_payment_id, hash FROM transaction`
+
+type chainStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (chain.Storage, error) {
+	return &chainStorage{db: db, logger: logger}, nil
+}
+
+// Seal appends transactions written within tx to the chain, it is called right before tx is committed.
+// The head stays locked until then, so links are appended in the order transactions are committed.
+// Transactions written by other means are chained along with them in id order.
+func Seal(ctx context.Context, tx *sql.Tx) error {
+	var head chain.HeadDTO
+	if err := tx.QueryRowContext(ctx, "SELECT seq, hash FROM chain_head FOR UPDATE;").Scan(&head.Seq, &head.Hash); err != nil {
+		return errors.Wrap(err, "error locking chain head")
+	}
+	for {
+		rows, err := tx.QueryContext(ctx, selectLink+" WHERE chain_seq IS NULL ORDER BY id ASC LIMIT $1 FOR UPDATE;", sealPage)
+		if err != nil {
+			return errors.Wrap(err, "error getting transactions to chain")
+		}
+		links, err := scanLinks(rows)
+		if err != nil {
+			return errors.Wrap(err, "error getting transactions to chain")
+		}
+		for _, link := range links {
+			link.Seq = head.Seq + 1
+			link.Hash = chain.NextHash(head.Hash, link)
+			if _, err := tx.ExecContext(ctx, "UPDATE transaction SET chain_seq = $1, hash = $2 WHERE id = $3;", link.Seq, link.Hash, link.TransactionID); err != nil {
+				return errors.Wrap(err, "error chaining transaction")
+			}
+			head = chain.HeadDTO{Seq: link.Seq, Hash: link.Hash}
+		}
+		if len(links) < sealPage {
+			break
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE chain_head SET seq = $1, hash = $2;", head.Seq, head.Hash); err != nil {
+		return errors.Wrap(err, "error updating chain head")
+	}
+	return nil
+}
+
+func scanLinks(rows *sql.Rows) ([]chain.LinkDTO, error) {
+	defer rows.Close()
+	var links []chain.LinkDTO
+	for rows.Next() {
+		var link chain.LinkDTO
+		var seq, parent sql.NullInt64
+		if err := rows.Scan(&seq, &link.TransactionID, &link.SenderID, &link.ReceiverID, &link.Amount, &link.Date, &link.Type, &parent, &link.Hash); err != nil {
+			return nil, err
+		}
+		link.Seq = seq.Int64
+		if parent.Valid {
+			link.ParentPaymentID = &parent.Int64
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+func (cs *chainStorage) GetHead(ctx context.Context) (chain.HeadDTO, error) {
+	var head chain.HeadDTO
+	if err := cs.db.Conn.QueryRowContext(ctx, "SELECT seq, hash FROM chain_head;").Scan(&head.Seq, &head.Hash); err != nil {
+		return chain.HeadDTO{}, err
+	}
+	return head, nil
+}
+
+func (cs *chainStorage) GetLinks(ctx context.Context, afterSeq, lastSeq int64, limit int) ([]chain.LinkDTO, error) {
+	rows, err := cs.db.Conn.QueryContext(ctx, selectLink+" WHERE chain_seq > $1 AND chain_seq <= $2 ORDER BY chain_seq ASC LIMIT $3;",
+		afterSeq, lastSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLinks(rows)
+}
+
+func (cs *chainStorage) CreateAnchor(ctx context.Context, createdAt time.Time) (chain.AnchorDTO, bool, error) {
+	query := `INSERT INTO chain_anchor (seq, transaction_id, hash, created_at)
+		SELECT chain_head.seq, transaction.id, chain_head.hash, $1 FROM chain_head JOIN transaction ON transaction.chain_seq = chain_head.seq
+		ON CONFLICT (seq) DO NOTHING RETURNING seq, transaction_id, hash, created_at;`
+	var anchor chain.AnchorDTO
+	switch err := cs.db.Conn.QueryRowContext(ctx, query, createdAt.UTC()).Scan(&anchor.Seq, &anchor.TransactionID, &anchor.Hash, &anchor.CreatedAt); err {
+	case nil:
+		return anchor, true, nil
+	case sql.ErrNoRows:
+		return chain.AnchorDTO{}, false, nil
+	default:
+		return chain.AnchorDTO{}, false, err
+	}
+}
+
+func (cs *chainStorage) GetAnchors(ctx context.Context, from, to time.Time) ([]chain.AnchorDTO, error) {
+	query := `SELECT seq, transaction_id, hash, created_at FROM chain_anchor
+		WHERE ($1::timestamp IS NULL OR created_at >= $1) AND ($2::timestamp IS NULL OR created_at < $2) ORDER BY seq ASC;`
+	rows, err := cs.db.Conn.QueryContext(ctx, query, nullTime(from), nullTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var anchors []chain.AnchorDTO
+	for rows.Next() {
+		var anchor chain.AnchorDTO
+		if err := rows.Scan(&anchor.Seq, &anchor.TransactionID, &anchor.Hash, &anchor.CreatedAt); err != nil {
+			return nil, err
+		}
+		anchors = append(anchors, anchor)
+	}
+	return anchors, rows.Err()
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
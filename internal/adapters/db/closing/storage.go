@@ -0,0 +1,130 @@
+package closing
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/closing"
+)
+
+const dayLayout = "2006-01-02"
+
+// signedAmount is the movement of a transaction for the wallet of the outer query, it is negative when money leaves the wallet.
+const signedAmount = `CASE WHEN tran_type = 'deposit' THEN amount WHEN tran_type = 'withdraw' THEN -amount
+	WHEN receiver_id = wallet.id THEN amount ELSE -amount END`
+
+// computeSnapshots takes the balance of the wallet without movements dated after the day and what the previous snapshot
+// and movements of the wallet since it add up to. Transactions written after the previous snapshot with a date within
+// its day have greater id than any transaction it has seen, so they are added as well.
+const computeSnapshots = `SELECT wallet.id, COALESCE(wallet.balance, 0) - (SELECT COALESCE(SUM(` + signedAmount + `), 0) FROM transaction
+		WHERE (sender_id = wallet.id OR receiver_id = wallet.id) AND date >= $2),
+	COALESCE(previous.balance, 0) + (SELECT COALESCE(SUM(` + signedAmount + `), 0) FROM transaction
+		WHERE (sender_id = wallet.id OR receiver_id = wallet.id) AND date < $2
+		AND (previous.day IS NULL OR date >= (previous.day + 1)::timestamp OR id > previous.last_transaction_id))
+FROM wallet LEFT JOIN LATERAL (SELECT day, balance, last_transaction_id FROM balance_snapshot
+	WHERE wallet_id = wallet.id AND day < $1 ORDER BY day DESC LIMIT 1) previous ON true
+WHERE wallet.id > $3 AND NOT EXISTS (SELECT 1 FROM balance_snapshot WHERE wallet_id = wallet.id AND day = $1)
+ORDER BY wallet.id ASC LIMIT $4;`
+
+type closingStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (closing.Storage, error) {
+	return &closingStorage{db: db, logger: logger}, nil
+}
+
+func (cs *closingStorage) GetLastClose(ctx context.Context) (closing.CloseDTO, error) {
+	row := cs.db.Conn.QueryRowContext(ctx, "SELECT day, wallets, closed_at FROM day_close ORDER BY day DESC LIMIT 1;")
+	return scanClose(row)
+}
+
+func (cs *closingStorage) GetClose(ctx context.Context, day time.Time) (closing.CloseDTO, error) {
+	row := cs.db.Conn.QueryRowContext(ctx, "SELECT day, wallets, closed_at FROM day_close WHERE day = $1;", day.Format(dayLayout))
+	return scanClose(row)
+}
+
+func scanClose(row *sql.Row) (closing.CloseDTO, error) {
+	var dto closing.CloseDTO
+	switch err := row.Scan(&dto.Day, &dto.Wallets, &dto.ClosedAt); {
+	case errors.Is(err, sql.ErrNoRows):
+		return closing.CloseDTO{}, nil
+	case err != nil:
+		return closing.CloseDTO{}, err
+	}
+	dto.Day = time.Date(dto.Day.Year(), dto.Day.Month(), dto.Day.Day(), 0, 0, 0, 0, time.UTC)
+	return dto, nil
+}
+
+func (cs *closingStorage) ComputeSnapshots(ctx context.Context, day time.Time, afterWalletID int64, limit int) ([]closing.SnapshotDTO, error) {
+	tx, err := cs.db.Conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "error beginning transaction")
+	}
+	// read only transaction has nothing to commit, it only keeps the snapshot of both queries
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			cs.logger.Errorf("rollback transaction %s", err)
+		}
+	}()
+
+	var lastTransaction int64
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM transaction;").Scan(&lastTransaction); err != nil {
+		return nil, errors.Wrap(err, "error getting last transaction")
+	}
+	rows, err := tx.QueryContext(ctx, computeSnapshots, day.Format(dayLayout), day.AddDate(0, 0, 1), afterWalletID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "error computing balance snapshots")
+	}
+	defer rows.Close()
+	var list []closing.SnapshotDTO
+	for rows.Next() {
+		snapshot := closing.SnapshotDTO{Day: day, LastTransactionID: lastTransaction}
+		if err := rows.Scan(&snapshot.WalletID, &snapshot.Balance, &snapshot.Expected); err != nil {
+			return nil, err
+		}
+		list = append(list, snapshot)
+	}
+	return list, rows.Err()
+}
+
+func (cs *closingStorage) CreateSnapshots(ctx context.Context, snapshots []closing.SnapshotDTO, createdAt time.Time) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	tx, err := cs.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			cs.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+	for _, s := range snapshots {
+		_, err := tx.ExecContext(ctx, `INSERT INTO balance_snapshot (wallet_id, day, balance, last_transaction_id, created_at)
+			VALUES ($1, $2, $3, $4, $5) ON CONFLICT (wallet_id, day) DO NOTHING;`,
+			s.WalletID, s.Day.Format(dayLayout), s.Balance, s.LastTransactionID, createdAt)
+		if err != nil {
+			rollback()
+			return errors.Wrap(err, "error inserting balance snapshot")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transction")
+	}
+	return nil
+}
+
+func (cs *closingStorage) Create(ctx context.Context, day time.Time, closedAt time.Time) error {
+	_, err := cs.db.Conn.ExecContext(ctx, `INSERT INTO day_close (day, wallets, closed_at)
+		SELECT $1::date, COUNT(*), $2 FROM balance_snapshot WHERE day = $1::date ON CONFLICT (day) DO NOTHING;`, day.Format(dayLayout), closedAt)
+	return err
+}
@@ -0,0 +1,304 @@
+package dispute
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	dbchain "github.com/skwol/wallet/internal/adapters/db/chain"
+	dboutbox "github.com/skwol/wallet/internal/adapters/db/outbox"
+	dbrisk "github.com/skwol/wallet/internal/adapters/db/risk"
+	dbwallet "github.com/skwol/wallet/internal/adapters/db/wallet"
+	"github.com/skwol/wallet/internal/domain/dispute"
+	"github.com/skwol/wallet/internal/domain/outbox"
+)
+
+const selectDispute = `SELECT id, transaction_id, reason, status, hold_wallet_id, hold_amount, opened_at, resolved_at, reversal_transaction_id FROM dispute`
+
+type dbDispute struct {
+	ID                    int64
+	TransactionID         int64
+	Reason                dispute.Reason
+	Status                dispute.Status
+	HoldWalletID          sql.NullInt64
+	HoldAmount            float64
+	OpenedAt              time.Time
+	ResolvedAt            sql.NullTime
+	ReversalTransactionID sql.NullInt64
+}
+
+func (db dbDispute) ToDTO() dispute.DTO {
+	return dispute.DTO{
+		ID:                    db.ID,
+		TransactionID:         db.TransactionID,
+		Reason:                db.Reason,
+		Status:                db.Status,
+		HoldWalletID:          db.HoldWalletID.Int64,
+		HoldAmount:            db.HoldAmount,
+		OpenedAt:              db.OpenedAt,
+		ResolvedAt:            db.ResolvedAt.Time,
+		ReversalTransactionID: db.ReversalTransactionID.Int64,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDispute(row scanner) (dbDispute, error) {
+	var d dbDispute
+	err := row.Scan(&d.ID, &d.TransactionID, &d.Reason, &d.Status, &d.HoldWalletID, &d.HoldAmount, &d.OpenedAt, &d.ResolvedAt, &d.ReversalTransactionID)
+	return d, err
+}
+
+type disputeStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (dispute.Storage, error) {
+	return &disputeStorage{db: db, logger: logger}, nil
+}
+
+func (ds *disputeStorage) Create(ctx context.Context, dto *dispute.DTO) (dispute.DTO, error) {
+	result := *dto
+	tx, err := ds.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return result, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			ds.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	var holdWalletID sql.NullInt64
+	if dto.HoldWalletID != 0 {
+		holdWalletID = sql.NullInt64{Int64: dto.HoldWalletID, Valid: true}
+	}
+	row := tx.QueryRowContext(ctx, "INSERT INTO dispute (transaction_id, reason, status, hold_wallet_id, hold_amount, opened_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;",
+		dto.TransactionID, dto.Reason, dto.Status, holdWalletID, dto.HoldAmount, dto.OpenedAt)
+	if err = row.Scan(&result.ID); err != nil {
+		rollback()
+		return result, errors.Wrap(err, "error inserting dispute")
+	}
+	for i, evidence := range dto.Evidence {
+		row := tx.QueryRowContext(ctx, "INSERT INTO dispute_evidence (dispute_id, note, created_at) VALUES ($1, $2, $3) RETURNING id;", result.ID, evidence.Note, evidence.CreatedAt)
+		if err = row.Scan(&result.Evidence[i].ID); err != nil {
+			rollback()
+			return result, errors.Wrap(err, "error inserting dispute evidence")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return result, errors.Wrap(err, "error during commit")
+	}
+	return result, nil
+}
+
+func (ds *disputeStorage) GetByID(ctx context.Context, id int64) (dispute.DTO, error) {
+	row := ds.db.Conn.QueryRowContext(ctx, selectDispute+" WHERE id = $1;", id)
+	d, err := scanDispute(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dispute.DTO{}, nil
+		}
+		return dispute.DTO{}, err
+	}
+	result := d.ToDTO()
+
+	rows, err := ds.db.Conn.QueryContext(ctx, "SELECT id, note, created_at FROM dispute_evidence WHERE dispute_id = $1 ORDER BY id ASC;", id)
+	if err != nil {
+		return dispute.DTO{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var evidence dispute.EvidenceDTO
+		if err := rows.Scan(&evidence.ID, &evidence.Note, &evidence.CreatedAt); err != nil {
+			return dispute.DTO{}, err
+		}
+		result.Evidence = append(result.Evidence, evidence)
+	}
+	return result, rows.Err()
+}
+
+func (ds *disputeStorage) GetFiltered(ctx context.Context, filter *dispute.FilterDisputesDTO, limit int, offset int) ([]dispute.DTO, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter != nil {
+		if len(filter.TransactionIDs) > 0 {
+			addCondition("transaction_id = ANY($%d)", pq.Array(filter.TransactionIDs))
+		}
+		if len(filter.WalletIDs) > 0 {
+			addCondition("transaction_id IN (SELECT id FROM transaction WHERE sender_id = ANY($%[1]d) OR receiver_id = ANY($%[1]d))", pq.Array(filter.WalletIDs))
+		}
+		if len(filter.Statuses) > 0 {
+			statuses := make([]string, len(filter.Statuses))
+			for i, status := range filter.Statuses {
+				statuses[i] = string(status)
+			}
+			addCondition("status::text = ANY($%d)", pq.Array(statuses))
+		}
+		if len(filter.Reasons) > 0 {
+			reasons := make([]string, len(filter.Reasons))
+			for i, reason := range filter.Reasons {
+				reasons[i] = string(reason)
+			}
+			addCondition("reason::text = ANY($%d)", pq.Array(reasons))
+		}
+	}
+	query := selectDispute
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(" ORDER BY id ASC LIMIT $%d OFFSET $%d;", len(args)-1, len(args))
+
+	var list []dispute.DTO
+	rows, err := ds.db.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d.ToDTO())
+	}
+	return list, rows.Err()
+}
+
+func (ds *disputeStorage) HasActive(ctx context.Context, transactionID int64) (bool, error) {
+	var active bool
+	row := ds.db.Conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM dispute WHERE transaction_id = $1 AND status IN ('opened', 'under_review'));", transactionID)
+	err := row.Scan(&active)
+	return active, err
+}
+
+func (ds *disputeStorage) Update(ctx context.Context, dto *dispute.DTO) error {
+	tx, err := ds.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			ds.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	var (
+		resolvedAt            sql.NullTime
+		reversalTransactionID sql.NullInt64
+	)
+	if !dto.ResolvedAt.IsZero() {
+		resolvedAt = sql.NullTime{Time: dto.ResolvedAt, Valid: true}
+	}
+	// only an active dispute is updated, so that concurrent resolutions do not reverse the transaction twice;
+	// the status is changed first, so that the reversal is not blocked by the hold of this dispute
+	res, err := tx.ExecContext(ctx, "UPDATE dispute SET status=$1, resolved_at=$2 WHERE id=$3 AND status IN ('opened', 'under_review');",
+		dto.Status, resolvedAt, dto.ID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "error updating dispute")
+	}
+	if updated, err := res.RowsAffected(); err != nil || updated == 0 {
+		rollback()
+		if err != nil {
+			return errors.Wrap(err, "error updating dispute")
+		}
+		return dispute.ErrAlreadyResolved
+	}
+
+	if reversal := dto.ReversalToApply; reversal != nil {
+		// balances move relative to the current ones, those the reversal was computed from could be stale by now
+		debited, err := dbwallet.Debit(ctx, tx, reversal.Sender.ID, reversal.Amount)
+		if err != nil {
+			rollback()
+			return errors.Wrap(err, "error updating sender wallet")
+		}
+		if !debited {
+			rollback()
+			return dispute.ErrNotEnoughMoneyToRevert
+		}
+		if err = dbwallet.Credit(ctx, tx, reversal.Receiver.ID, reversal.Amount); err != nil {
+			rollback()
+			return errors.Wrap(err, "error updating receiver wallet")
+		}
+		row := tx.QueryRowContext(ctx, "INSERT INTO transaction (sender_id, receiver_id, amount, date, tran_type) VALUES ($1, $2, $3, $4, 'reversal') RETURNING id;",
+			reversal.Sender.ID, reversal.Receiver.ID, reversal.Amount, reversal.Timestamp)
+		if err = row.Scan(&dto.ReversalTransactionID); err != nil {
+			rollback()
+			return errors.Wrap(err, "error inserting reversal transaction")
+		}
+		if err = dbchain.Seal(ctx, tx); err != nil {
+			rollback()
+			return err
+		}
+		event, err := outbox.NewReversalCompleted(outbox.ReversalCompleted{
+			TransactionID: dto.ReversalTransactionID, DisputeID: dto.ID, DisputedTransactionID: dto.TransactionID,
+			SenderID: reversal.Sender.ID, ReceiverID: reversal.Receiver.ID, Amount: reversal.Amount, Date: reversal.Timestamp,
+		})
+		if err != nil {
+			rollback()
+			return err
+		}
+		if err = dboutbox.Write(ctx, tx, event); err != nil {
+			rollback()
+			return err
+		}
+		if err = dbrisk.Write(ctx, tx, reversal.Risk); err != nil {
+			rollback()
+			return err
+		}
+		reversalTransactionID = sql.NullInt64{Int64: dto.ReversalTransactionID, Valid: true}
+		if _, err = tx.ExecContext(ctx, "UPDATE dispute SET reversal_transaction_id=$1 WHERE id=$2;", reversalTransactionID, dto.ID); err != nil {
+			rollback()
+			return errors.Wrap(err, "error updating dispute")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error during commit")
+	}
+	dto.ReversalToApply = nil
+	return nil
+}
+
+func (ds *disputeStorage) AddEvidence(ctx context.Context, disputeID int64, evidence dispute.EvidenceDTO) (dispute.EvidenceDTO, error) {
+	row := ds.db.Conn.QueryRowContext(ctx, "INSERT INTO dispute_evidence (dispute_id, note, created_at) VALUES ($1, $2, $3) RETURNING id;", disputeID, evidence.Note, evidence.CreatedAt)
+	err := row.Scan(&evidence.ID)
+	return evidence, err
+}
+
+func (ds *disputeStorage) GetTransaction(ctx context.Context, id int64) (dispute.TransactionDTO, error) {
+	query := `SELECT t.id, t.amount, t.tran_type, s.id, s.balance, r.id, r.balance FROM transaction t
+		JOIN wallet s ON s.id = t.sender_id
+		JOIN wallet r ON r.id = t.receiver_id
+		WHERE t.id = $1;`
+	row := ds.db.Conn.QueryRowContext(ctx, query, id)
+	var tran dispute.TransactionDTO
+	switch err := row.Scan(&tran.ID, &tran.Amount, &tran.Type, &tran.Sender.ID, &tran.Sender.Balance, &tran.Receiver.ID, &tran.Receiver.Balance); err {
+	case sql.ErrNoRows:
+		return dispute.TransactionDTO{}, nil
+	default:
+		return tran, err
+	}
+}
@@ -0,0 +1,297 @@
+package escrow
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	dbchain "github.com/skwol/wallet/internal/adapters/db/chain"
+	dboutbox "github.com/skwol/wallet/internal/adapters/db/outbox"
+	dbrisk "github.com/skwol/wallet/internal/adapters/db/risk"
+	dbwallet "github.com/skwol/wallet/internal/adapters/db/wallet"
+	"github.com/skwol/wallet/internal/domain/escrow"
+	"github.com/skwol/wallet/internal/domain/outbox"
+)
+
+// holdingWalletName is a name of the reserved wallet that keeps money of all funded escrows, it is created by migrations.
+const holdingWalletName = "__escrow__"
+
+const selectEscrow = `SELECT id, buyer_id, seller_id, holding_id, amount, status, buyer_confirmed, seller_confirmed,
+	created_at, expires_at, settled_at, fund_transaction_id, settle_transaction_id FROM escrow`
+
+type dbEscrow struct {
+	ID                  int64
+	BuyerID             int64
+	SellerID            int64
+	HoldingID           int64
+	Amount              float64
+	Status              escrow.Status
+	BuyerConfirmed      bool
+	SellerConfirmed     bool
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+	SettledAt           sql.NullTime
+	FundTransactionID   int64
+	SettleTransactionID sql.NullInt64
+}
+
+func (db dbEscrow) ToDTO() escrow.DTO {
+	return escrow.DTO{
+		ID:                  db.ID,
+		Buyer:               escrow.WalletDTO{ID: db.BuyerID},
+		Seller:              escrow.WalletDTO{ID: db.SellerID},
+		Holding:             escrow.WalletDTO{ID: db.HoldingID},
+		Amount:              db.Amount,
+		Status:              db.Status,
+		BuyerConfirmed:      db.BuyerConfirmed,
+		SellerConfirmed:     db.SellerConfirmed,
+		CreatedAt:           db.CreatedAt,
+		ExpiresAt:           db.ExpiresAt,
+		SettledAt:           db.SettledAt.Time,
+		FundTransactionID:   db.FundTransactionID,
+		SettleTransactionID: db.SettleTransactionID.Int64,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEscrow(row scanner) (dbEscrow, error) {
+	var e dbEscrow
+	err := row.Scan(&e.ID, &e.BuyerID, &e.SellerID, &e.HoldingID, &e.Amount, &e.Status, &e.BuyerConfirmed, &e.SellerConfirmed,
+		&e.CreatedAt, &e.ExpiresAt, &e.SettledAt, &e.FundTransactionID, &e.SettleTransactionID)
+	return e, err
+}
+
+type dbWallet struct {
+	ID       int64
+	Balance  float64
+	Held     float64
+	Closed   bool
+	Frozen   bool
+	Reserved bool
+}
+
+func (db dbWallet) ToDTO() escrow.WalletDTO {
+	return escrow.WalletDTO{
+		ID:       db.ID,
+		Balance:  db.Balance,
+		Held:     db.Held,
+		Closed:   db.Closed,
+		Frozen:   db.Frozen,
+		Reserved: db.Reserved,
+	}
+}
+
+type escrowStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (escrow.Storage, error) {
+	return &escrowStorage{db: db, logger: logger}, nil
+}
+
+func (es *escrowStorage) Create(ctx context.Context, dto *escrow.DTO) (escrow.DTO, error) {
+	result := *dto
+	if dto.TransferToApply == nil {
+		return result, errors.New("missing transfer funding the escrow")
+	}
+	tx, err := es.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return result, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			es.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	if result.FundTransactionID, err = applyTransfer(ctx, tx, dto.TransferToApply); err != nil {
+		rollback()
+		return result, err
+	}
+	row := tx.QueryRowContext(ctx, `INSERT INTO escrow (buyer_id, seller_id, holding_id, amount, status, created_at, expires_at, fund_transaction_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;`,
+		dto.Buyer.ID, dto.Seller.ID, dto.Holding.ID, dto.Amount, dto.Status, dto.CreatedAt, dto.ExpiresAt, result.FundTransactionID)
+	if err = row.Scan(&result.ID); err != nil {
+		rollback()
+		return result, errors.Wrap(err, "error inserting escrow")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return result, errors.Wrap(err, "error during commit")
+	}
+	result.TransferToApply = nil
+	return result, nil
+}
+
+func (es *escrowStorage) GetByID(ctx context.Context, id int64) (escrow.DTO, error) {
+	row := es.db.Conn.QueryRowContext(ctx, selectEscrow+" WHERE id = $1;", id)
+	switch e, err := scanEscrow(row); err {
+	case sql.ErrNoRows:
+		return escrow.DTO{}, nil
+	default:
+		return e.ToDTO(), err
+	}
+}
+
+func (es *escrowStorage) GetAll(ctx context.Context, limit int, offset int) ([]escrow.DTO, error) {
+	return es.query(ctx, selectEscrow+" ORDER BY id ASC LIMIT $1 OFFSET $2;", limit, offset)
+}
+
+func (es *escrowStorage) GetExpired(ctx context.Context, at time.Time) ([]escrow.DTO, error) {
+	return es.query(ctx, selectEscrow+" WHERE status = $1 AND expires_at <= $2 ORDER BY id ASC;", escrow.StatusFunded, at)
+}
+
+func (es *escrowStorage) query(ctx context.Context, query string, args ...interface{}) ([]escrow.DTO, error) {
+	var list []escrow.DTO
+	rows, err := es.db.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e, err := scanEscrow(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e.ToDTO())
+	}
+	return list, rows.Err()
+}
+
+func (es *escrowStorage) Update(ctx context.Context, dto *escrow.DTO, prev escrow.DTO) error {
+	tx, err := es.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			es.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	if dto.TransferToApply != nil {
+		if dto.SettleTransactionID, err = applyTransfer(ctx, tx, dto.TransferToApply); err != nil {
+			rollback()
+			return err
+		}
+	}
+	var (
+		settledAt           sql.NullTime
+		settleTransactionID sql.NullInt64
+	)
+	if !dto.SettledAt.IsZero() {
+		settledAt = sql.NullTime{Time: dto.SettledAt, Valid: true}
+	}
+	if dto.SettleTransactionID != 0 {
+		settleTransactionID = sql.NullInt64{Int64: dto.SettleTransactionID, Valid: true}
+	}
+	// escrow is updated only from the state it was read in, so a refund can not race a release
+	// and concurrent confirmations are not lost.
+	res, err := tx.ExecContext(ctx, `UPDATE escrow SET status=$1, buyer_confirmed=$2, seller_confirmed=$3, settled_at=$4, settle_transaction_id=$5
+		WHERE id=$6 AND status=$7 AND buyer_confirmed=$8 AND seller_confirmed=$9;`,
+		dto.Status, dto.BuyerConfirmed, dto.SellerConfirmed, settledAt, settleTransactionID, dto.ID,
+		prev.Status, prev.BuyerConfirmed, prev.SellerConfirmed)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "error updating escrow")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "error getting number of updated escrows")
+	}
+	if affected == 0 {
+		rollback()
+		return es.conflict(ctx, dto.ID)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error during commit")
+	}
+	dto.TransferToApply = nil
+	return nil
+}
+
+// conflict tells why escrow was not updated from the state it was read in.
+func (es *escrowStorage) conflict(ctx context.Context, id int64) error {
+	var status escrow.Status
+	if err := es.db.Conn.QueryRowContext(ctx, "SELECT status FROM escrow WHERE id = $1;", id).Scan(&status); err != nil {
+		return errors.Wrap(err, "error getting escrow status")
+	}
+	if status == escrow.StatusReleased || status == escrow.StatusRefunded {
+		return escrow.ErrAlreadySettled
+	}
+	return escrow.ErrConcurrentUpdate
+}
+
+func (es *escrowStorage) GetWallet(ctx context.Context, id int64) (escrow.WalletDTO, error) {
+	query := `SELECT id, balance, (SELECT COALESCE(SUM(hold_amount), 0) FROM dispute WHERE hold_wallet_id = wallet.id AND status IN ('opened', 'under_review')),
+		status = 'closed', status = 'frozen', reserved FROM wallet WHERE id = $1;`
+	row := es.db.Conn.QueryRowContext(ctx, query, id)
+	var walletInDB dbWallet
+	switch err := row.Scan(&walletInDB.ID, &walletInDB.Balance, &walletInDB.Held, &walletInDB.Closed, &walletInDB.Frozen, &walletInDB.Reserved); err {
+	case sql.ErrNoRows:
+		return escrow.WalletDTO{}, nil
+	default:
+		return walletInDB.ToDTO(), err
+	}
+}
+
+// GetHoldingWallet returns the reserved wallet that keeps escrowed money, a customer wallet can not take its name.
+func (es *escrowStorage) GetHoldingWallet(ctx context.Context) (escrow.WalletDTO, error) {
+	row := es.db.Conn.QueryRowContext(ctx, `SELECT id, balance, reserved FROM wallet WHERE name = $1 AND reserved;`, holdingWalletName)
+	var walletInDB dbWallet
+	if err := row.Scan(&walletInDB.ID, &walletInDB.Balance, &walletInDB.Reserved); err != nil {
+		return escrow.WalletDTO{}, errors.Wrap(err, "error getting holding wallet")
+	}
+	return walletInDB.ToDTO(), nil
+}
+
+// applyTransfer moves amount between the wallets relative to their current balances, the holding
+// wallet is shared by all escrows so balances read before the transaction can not be written back.
+// Buyer can not fund the escrow with money held by its active disputes.
+func applyTransfer(ctx context.Context, tx *sql.Tx, transfer *escrow.TransferDTO) (int64, error) {
+	debited, err := dbwallet.Debit(ctx, tx, transfer.Sender.ID, transfer.Amount)
+	if err != nil {
+		return 0, errors.Wrap(err, "error updating sender wallet")
+	}
+	if !debited {
+		return 0, escrow.ErrNotEnoughMoney
+	}
+	if err := dbwallet.Credit(ctx, tx, transfer.Receiver.ID, transfer.Amount); err != nil {
+		return 0, errors.Wrap(err, "error updating receiver wallet")
+	}
+	var id int64
+	row := tx.QueryRowContext(ctx, "INSERT INTO transaction (sender_id, receiver_id, amount, date, tran_type) VALUES ($1, $2, $3, $4, 'transfer') RETURNING id;",
+		transfer.Sender.ID, transfer.Receiver.ID, transfer.Amount, transfer.Timestamp)
+	if err := row.Scan(&id); err != nil {
+		return 0, errors.Wrap(err, "error inserting transaction")
+	}
+	if err := dbchain.Seal(ctx, tx); err != nil {
+		return 0, err
+	}
+	event, err := outbox.NewTransferCompleted(outbox.TransferCompleted{
+		TransactionID: id, SenderID: transfer.Sender.ID, ReceiverID: transfer.Receiver.ID, Amount: transfer.Amount, Date: transfer.Timestamp,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := dboutbox.Write(ctx, tx, event); err != nil {
+		return 0, err
+	}
+	if err := dbrisk.Write(ctx, tx, transfer.Risk); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,159 @@
+package liability
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/merklesum"
+
+	"github.com/skwol/wallet/internal/domain/liability"
+)
+
+// nodesBatch is how many nodes are inserted at once.
+const nodesBatch = 1000
+
+const insertNodes = `INSERT INTO liability_node (tree_id, level, position, hash, sum, wallet_id, salt)
+	SELECT $1, level, position, hash, sum, NULLIF(wallet_id, 0), NULLIF(salt, ''::bytea)
+	FROM unnest($2::integer[], $3::bigint[], $4::bytea[], $5::bigint[], $6::bigint[], $7::bytea[]) AS node (level, position, hash, sum, wallet_id, salt);`
+
+type liabilityStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (liability.Storage, error) {
+	return &liabilityStorage{db: db, logger: logger}, nil
+}
+
+func (ls *liabilityStorage) GetBalances(ctx context.Context) ([]liability.BalanceDTO, error) {
+	rows, err := ls.db.Conn.QueryContext(ctx, "SELECT id, COALESCE(balance, 0) FROM wallet ORDER BY id ASC;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var balances []liability.BalanceDTO
+	for rows.Next() {
+		var balance liability.BalanceDTO
+		if err := rows.Scan(&balance.WalletID, &balance.Balance); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+	return balances, rows.Err()
+}
+
+func (ls *liabilityStorage) CreateTree(ctx context.Context, tree liability.TreeDTO, nodes []liability.NodeDTO, kept int) (liability.TreeDTO, error) {
+	tx, err := ls.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return tree, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			ls.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	row := tx.QueryRowContext(ctx, "INSERT INTO liability_tree (root, total, wallets, depth, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id;",
+		[]byte(tree.Root), int64(tree.Total), tree.Wallets, tree.Depth, tree.CreatedAt.UTC())
+	if err := row.Scan(&tree.ID); err != nil {
+		rollback()
+		return tree, errors.Wrap(err, "error inserting liability tree")
+	}
+	for start := 0; start < len(nodes); start += nodesBatch {
+		end := start + nodesBatch
+		if end > len(nodes) {
+			end = len(nodes)
+		}
+		batch := nodes[start:end]
+		var (
+			levels    = make([]int64, len(batch))
+			positions = make([]int64, len(batch))
+			hashes    = make([][]byte, len(batch))
+			sums      = make([]int64, len(batch))
+			wallets   = make([]int64, len(batch))
+			salts     = make([][]byte, len(batch))
+		)
+		for i, node := range batch {
+			levels[i], positions[i], hashes[i], sums[i] = int64(node.Level), node.Position, node.Node.Hash, int64(node.Node.Sum)
+			wallets[i], salts[i] = node.WalletID, node.Salt
+			if salts[i] == nil {
+				salts[i] = []byte{}
+			}
+		}
+		if _, err := tx.ExecContext(ctx, insertNodes, tree.ID, pq.Array(levels), pq.Array(positions), pq.ByteaArray(hashes),
+			pq.Array(sums), pq.Array(wallets), pq.ByteaArray(salts)); err != nil {
+			rollback()
+			return tree, errors.Wrap(err, "error inserting liability nodes")
+		}
+	}
+	// roots of older trees stay published, only their proofs are gone
+	if _, err := tx.ExecContext(ctx, `DELETE FROM liability_node WHERE tree_id <= (
+		SELECT id FROM liability_tree ORDER BY id DESC OFFSET $1 LIMIT 1);`, kept); err != nil {
+		rollback()
+		return tree, errors.Wrap(err, "error removing old liability nodes")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return tree, errors.Wrap(err, "error committing transction")
+	}
+	return tree, nil
+}
+
+func (ls *liabilityStorage) GetLatestTree(ctx context.Context) (liability.TreeDTO, error) {
+	var tree liability.TreeDTO
+	var root []byte
+	var total int64
+	row := ls.db.Conn.QueryRowContext(ctx, "SELECT id, root, total, wallets, depth, created_at FROM liability_tree ORDER BY id DESC LIMIT 1;")
+	switch err := row.Scan(&tree.ID, &root, &total, &tree.Wallets, &tree.Depth, &tree.CreatedAt); err {
+	case nil:
+		tree.Root, tree.Total = root, merklesum.Sum(total)
+		return tree, nil
+	case sql.ErrNoRows:
+		return liability.TreeDTO{}, nil
+	default:
+		return liability.TreeDTO{}, err
+	}
+}
+
+func (ls *liabilityStorage) GetLeaf(ctx context.Context, treeID, walletID int64) (liability.NodeDTO, error) {
+	var leaf liability.NodeDTO
+	var hash []byte
+	var sum int64
+	row := ls.db.Conn.QueryRowContext(ctx, "SELECT position, hash, sum, wallet_id, salt FROM liability_node WHERE tree_id = $1 AND wallet_id = $2;", treeID, walletID)
+	switch err := row.Scan(&leaf.Position, &hash, &sum, &leaf.WalletID, &leaf.Salt); err {
+	case nil:
+		leaf.Node = merklesum.Node{Hash: hash, Sum: merklesum.Sum(sum)}
+		return leaf, nil
+	case sql.ErrNoRows:
+		return liability.NodeDTO{}, nil
+	default:
+		return liability.NodeDTO{}, err
+	}
+}
+
+func (ls *liabilityStorage) GetSiblings(ctx context.Context, treeID int64, positions []int64) (map[int]merklesum.Node, error) {
+	query := `SELECT level, hash, sum FROM liability_node
+		JOIN unnest($2::bigint[]) WITH ORDINALITY AS sibling (position, ordinality) ON liability_node.position = sibling.position
+		WHERE tree_id = $1 AND level = sibling.ordinality - 1;`
+	rows, err := ls.db.Conn.QueryContext(ctx, query, treeID, pq.Array(positions))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	siblings := make(map[int]merklesum.Node, len(positions))
+	for rows.Next() {
+		var level int
+		var hash []byte
+		var sum int64
+		if err := rows.Scan(&level, &hash, &sum); err != nil {
+			return nil, err
+		}
+		siblings[level] = merklesum.Node{Hash: hash, Sum: merklesum.Sum(sum)}
+	}
+	return siblings, rows.Err()
+}
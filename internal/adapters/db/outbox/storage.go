@@ -0,0 +1,109 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/outbox"
+)
+
+// advisory locks of the outbox, writers hold theirs until commit, so that events are committed in id order
+const (
+	writeLock int64 = 0x6f7574626f78
+	relayLock int64 = 0x72656c6179
+)
+
+type outboxStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (outbox.Storage, error) {
+	return &outboxStorage{db: db, logger: logger}, nil
+}
+
+// Write adds events to the outbox within tx, it is called right before tx is committed and after the chain is sealed.
+func Write(ctx context.Context, tx *sql.Tx, events ...outbox.EventDTO) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1);", writeLock); err != nil {
+		return errors.Wrap(err, "error locking outbox")
+	}
+	for _, event := range events {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO outbox (type, aggregate_id, payload) VALUES ($1, $2, $3);",
+			event.Type, event.AggregateID, []byte(event.Payload)); err != nil {
+			return errors.Wrapf(err, "error inserting %s event", event.Type)
+		}
+	}
+	return nil
+}
+
+func (obs *outboxStorage) Relay(ctx context.Context, limit int, publish func(outbox.EventDTO) error) (int, error) {
+	tx, err := obs.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			obs.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1);", relayLock).Scan(&locked); err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "error locking outbox relay")
+	}
+	if !locked {
+		rollback()
+		return 0, nil
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id, type, aggregate_id, payload, created_at FROM outbox
+		WHERE published_at IS NULL ORDER BY id ASC LIMIT $1;`, limit)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "error getting outbox events")
+	}
+	var events []outbox.EventDTO
+	for rows.Next() {
+		var event outbox.EventDTO
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.Type, &event.AggregateID, &payload, &event.CreatedAt); err != nil {
+			rows.Close()
+			rollback()
+			return 0, errors.Wrap(err, "error getting outbox events")
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "error getting outbox events")
+	}
+
+	// events published before a failure are marked, the failed one is published first by the next relay
+	published := make([]int64, 0, len(events))
+	var publishErr error
+	for _, event := range events {
+		if publishErr = publish(event); publishErr != nil {
+			publishErr = errors.Wrapf(publishErr, "error publishing event %d", event.ID)
+			break
+		}
+		published = append(published, event.ID)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE outbox SET published_at = now() AT TIME ZONE 'utc' WHERE id = ANY($1);", pq.Array(published)); err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "error marking published events")
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "error committing transction")
+	}
+	return len(published), publishErr
+}
@@ -0,0 +1,37 @@
+package receipt
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/receipt"
+)
+
+type receiptStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (receipt.Storage, error) {
+	return &receiptStorage{db: db, logger: logger}, nil
+}
+
+func (rs *receiptStorage) GetTransaction(ctx context.Context, id int64) (receipt.TransactionDTO, error) {
+	query := `SELECT id, sender_id, receiver_id, amount::text, date, tran_type::text, parent_payment_id, hash FROM transaction WHERE id = $1;`
+	var dto receipt.TransactionDTO
+	var parent sql.NullInt64
+	switch err := rs.db.Conn.QueryRowContext(ctx, query, id).Scan(&dto.ID, &dto.SenderID, &dto.ReceiverID, &dto.Amount, &dto.Date, &dto.Type, &parent, &dto.Hash); err {
+	case nil:
+	case sql.ErrNoRows:
+		return receipt.TransactionDTO{}, nil
+	default:
+		return receipt.TransactionDTO{}, err
+	}
+	if parent.Valid {
+		dto.ParentPaymentID = &parent.Int64
+	}
+	return dto, nil
+}
@@ -0,0 +1,112 @@
+package reconciliation
+
+import (
+	"context"
+	"database/sql"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	dbchain "github.com/skwol/wallet/internal/adapters/db/chain"
+	dboutbox "github.com/skwol/wallet/internal/adapters/db/outbox"
+	"github.com/skwol/wallet/internal/domain/outbox"
+	"github.com/skwol/wallet/internal/domain/reconciliation"
+)
+
+// computedBalance is the sum of all movements of the wallet of the outer query, money leaving the wallet is negative.
+const computedBalance = `(SELECT COALESCE(SUM(CASE WHEN tran_type = 'deposit' THEN amount WHEN tran_type = 'withdraw' THEN -amount
+	WHEN receiver_id = wallet.id THEN amount ELSE -amount END), 0) FROM transaction WHERE sender_id = wallet.id OR receiver_id = wallet.id)`
+
+type reconciliationStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (reconciliation.Storage, error) {
+	return &reconciliationStorage{db: db, logger: logger}, nil
+}
+
+func (rs *reconciliationStorage) GetBalances(ctx context.Context, afterWalletID int64, limit int) ([]reconciliation.BalanceDTO, error) {
+	rows, err := rs.db.Conn.QueryContext(ctx, "SELECT id, COALESCE(balance, 0), "+computedBalance+` FROM wallet
+		WHERE id > $1 ORDER BY id ASC LIMIT $2;`, afterWalletID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []reconciliation.BalanceDTO
+	for rows.Next() {
+		var balance reconciliation.BalanceDTO
+		if err := rows.Scan(&balance.WalletID, &balance.Balance, &balance.Computed); err != nil {
+			return nil, err
+		}
+		list = append(list, balance)
+	}
+	return list, rows.Err()
+}
+
+func (rs *reconciliationStorage) CreateAdjustment(ctx context.Context, dto reconciliation.AdjustmentDTO) (reconciliation.AdjustmentDTO, error) {
+	tx, err := rs.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return dto, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			rs.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	// the wallet is locked, so that neither its balance nor its transactions change until the adjustment is written
+	var balance, computed float64
+	row := tx.QueryRowContext(ctx, "SELECT COALESCE(balance, 0), "+computedBalance+" FROM wallet WHERE id = $1 FOR UPDATE;", dto.WalletID)
+	if err := row.Scan(&balance, &computed); err != nil {
+		rollback()
+		return dto, errors.Wrap(err, "error getting wallet balance")
+	}
+	if !sameAmount(balance, dto.Balance) || !sameAmount(computed, dto.Computed) {
+		rollback()
+		return dto, reconciliation.ErrBalanceChanged
+	}
+
+	row = tx.QueryRowContext(ctx, "INSERT INTO transaction (sender_id, receiver_id, amount, date, tran_type) VALUES ($1, $1, $2, $3, $4) RETURNING id;",
+		dto.WalletID, dto.Amount, dto.CreatedAt, dto.Type)
+	if err := row.Scan(&dto.TransactionID); err != nil {
+		rollback()
+		return dto, errors.Wrap(err, "error inserting adjusting transaction")
+	}
+	if err := dbchain.Seal(ctx, tx); err != nil {
+		rollback()
+		return dto, err
+	}
+	row = tx.QueryRowContext(ctx, `INSERT INTO adjustment (wallet_id, transaction_id, balance, computed, reason, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;`,
+		dto.WalletID, dto.TransactionID, dto.Balance, dto.Computed, dto.Reason, dto.Actor, dto.CreatedAt)
+	if err := row.Scan(&dto.ID); err != nil {
+		rollback()
+		return dto, errors.Wrap(err, "error inserting adjustment")
+	}
+	event, err := outbox.NewAdjustmentMade(outbox.AdjustmentMade{
+		TransactionID: dto.TransactionID, AdjustmentID: dto.ID, WalletID: dto.WalletID, Type: string(dto.Type),
+		Amount: dto.Amount, Reason: dto.Reason, Date: dto.CreatedAt,
+	})
+	if err != nil {
+		rollback()
+		return dto, err
+	}
+	if err := dboutbox.Write(ctx, tx, event); err != nil {
+		rollback()
+		return dto, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dto, errors.Wrap(err, "error committing transction")
+	}
+	return dto, nil
+}
+
+// sameAmount compares amounts with the precision they are stored with.
+func sameAmount(a, b float64) bool {
+	return math.Round(a*1e4) == math.Round(b*1e4)
+}
@@ -0,0 +1,202 @@
+package replay
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/replay"
+)
+
+// replayChunk adds the chunk of the log to daily movements of wallets: the receiver gets the amount, the sender of
+// a transfer or a reversal loses it, deposits and withdrawals have the same sender and receiver.
+const replayChunk = `WITH chunk AS (
+	SELECT id, sender_id, receiver_id, amount, date, tran_type FROM transaction WHERE id > $1 AND id <= $2 ORDER BY id ASC LIMIT $3
+), movement AS (
+	SELECT receiver_id AS wallet_id, date::date AS day, CASE WHEN tran_type = 'withdraw' THEN -amount ELSE amount END AS amount FROM chunk
+	UNION ALL
+	SELECT sender_id, date::date, -amount FROM chunk WHERE tran_type NOT IN ('deposit', 'withdraw')
+), replayed AS (
+	INSERT INTO movement_rebuild (wallet_id, day, amount) SELECT wallet_id, day, SUM(amount) FROM movement GROUP BY wallet_id, day
+	ON CONFLICT (wallet_id, day) DO UPDATE SET amount = movement_rebuild.amount + EXCLUDED.amount
+)
+SELECT COALESCE(MAX(id), 0), COUNT(*) FROM chunk;`
+
+// queries of the projection, the snapshot is the balance at the end of its day
+const (
+	projectBalances = `INSERT INTO wallet_balance_rebuild (wallet_id, balance)
+		SELECT wallet.id, COALESCE(SUM(movement_rebuild.amount), 0) FROM wallet
+		LEFT JOIN movement_rebuild ON movement_rebuild.wallet_id = wallet.id GROUP BY wallet.id;`
+	projectSnapshots = `INSERT INTO balance_snapshot_rebuild (wallet_id, day, balance)
+		SELECT wallet_id, day, (SELECT COALESCE(SUM(amount), 0) FROM movement_rebuild
+			WHERE movement_rebuild.wallet_id = balance_snapshot.wallet_id AND movement_rebuild.day <= balance_snapshot.day)
+		FROM balance_snapshot;`
+)
+
+// queries of the diff, balances are compared with the precision they are stored with
+const (
+	changedBalances = `FROM wallet JOIN wallet_balance_rebuild ON wallet_balance_rebuild.wallet_id = wallet.id
+		WHERE COALESCE(wallet.balance, 0) <> round(wallet_balance_rebuild.balance, 4)`
+	changedSnapshots = `FROM balance_snapshot JOIN balance_snapshot_rebuild
+		ON balance_snapshot_rebuild.wallet_id = balance_snapshot.wallet_id AND balance_snapshot_rebuild.day = balance_snapshot.day
+		WHERE balance_snapshot.balance <> round(balance_snapshot_rebuild.balance, 4)`
+)
+
+type replayStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (replay.Storage, error) {
+	return &replayStorage{db: db, logger: logger}, nil
+}
+
+// execer runs statements within a transaction or outside of it.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (rs *replayStorage) Prepare(ctx context.Context) (replay.ReplayDTO, error) {
+	var dto replay.ReplayDTO
+	if _, err := rs.db.Conn.ExecContext(ctx, "TRUNCATE movement_rebuild, wallet_balance_rebuild, balance_snapshot_rebuild;"); err != nil {
+		return dto, errors.Wrap(err, "error truncating shadow tables")
+	}
+	// the count is only the progress estimate, it is taken from the same snapshot as the last id
+	row := rs.db.Conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(id), 0), COUNT(*) FROM transaction;")
+	if err := row.Scan(&dto.LastTransactionID, &dto.Total); err != nil {
+		return dto, errors.Wrap(err, "error getting last transaction")
+	}
+	return dto, nil
+}
+
+func (rs *replayStorage) ReplayChunk(ctx context.Context, afterID, lastID int64, limit int) (replay.ChunkDTO, error) {
+	return replayTransactions(ctx, rs.db.Conn, afterID, lastID, limit)
+}
+
+func replayTransactions(ctx context.Context, q execer, afterID, lastID int64, limit int) (replay.ChunkDTO, error) {
+	var dto replay.ChunkDTO
+	if err := q.QueryRowContext(ctx, replayChunk, afterID, lastID, limit).Scan(&dto.LastTransactionID, &dto.Transactions); err != nil {
+		return replay.ChunkDTO{}, err
+	}
+	return dto, nil
+}
+
+func (rs *replayStorage) Project(ctx context.Context) error {
+	tx, err := rs.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "error beginning transaction")
+	}
+	if err := project(ctx, tx); err != nil {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			rs.logger.Errorf("rollback transaction %s", err)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "error committing transction")
+	}
+	return nil
+}
+
+func project(ctx context.Context, q execer) error {
+	for _, query := range []string{"TRUNCATE wallet_balance_rebuild, balance_snapshot_rebuild;", projectBalances, projectSnapshots} {
+		if _, err := q.ExecContext(ctx, query); err != nil {
+			return errors.Wrap(err, "error projecting balances")
+		}
+	}
+	return nil
+}
+
+func (rs *replayStorage) Diff(ctx context.Context, limit int) (replay.DiffDTO, error) {
+	return diff(ctx, rs.db.Conn, limit)
+}
+
+func diff(ctx context.Context, q execer, limit int) (replay.DiffDTO, error) {
+	var dto replay.DiffDTO
+	if err := q.QueryRowContext(ctx, "SELECT COUNT(*) "+changedBalances+";").Scan(&dto.Wallets); err != nil {
+		return dto, errors.Wrap(err, "error counting changed balances")
+	}
+	if err := q.QueryRowContext(ctx, "SELECT COUNT(*) "+changedSnapshots+";").Scan(&dto.Snapshots); err != nil {
+		return dto, errors.Wrap(err, "error counting changed snapshots")
+	}
+	rows, err := q.QueryContext(ctx, "SELECT wallet.id, COALESCE(wallet.balance, 0), round(wallet_balance_rebuild.balance, 4) "+
+		changedBalances+" ORDER BY wallet.id ASC LIMIT $1;", limit)
+	if err != nil {
+		return dto, errors.Wrap(err, "error getting changed balances")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var change replay.BalanceChangeDTO
+		if err := rows.Scan(&change.WalletID, &change.Balance, &change.Rebuilt); err != nil {
+			return dto, err
+		}
+		dto.Changes = append(dto.Changes, change)
+	}
+	return dto, rows.Err()
+}
+
+func (rs *replayStorage) Swap(ctx context.Context, lastID int64, limit int) (replay.ChunkDTO, replay.DiffDTO, error) {
+	tx, err := rs.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return replay.ChunkDTO{}, replay.DiffDTO{}, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			rs.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	// wallets are locked first as transfers update them before they write their transactions
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE wallet, transaction IN SHARE ROW EXCLUSIVE MODE;"); err != nil {
+		rollback()
+		return replay.ChunkDTO{}, replay.DiffDTO{}, errors.Wrap(err, "error locking tables")
+	}
+	var tail replay.ChunkDTO
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM transaction;").Scan(&tail.LastTransactionID); err != nil {
+		rollback()
+		return replay.ChunkDTO{}, replay.DiffDTO{}, errors.Wrap(err, "error getting last transaction")
+	}
+	if tail.LastTransactionID > lastID {
+		chunk, err := replayTransactions(ctx, tx, lastID, tail.LastTransactionID, int(tail.LastTransactionID-lastID))
+		if err != nil {
+			rollback()
+			return replay.ChunkDTO{}, replay.DiffDTO{}, errors.Wrap(err, "error replaying transactions written during rebuild")
+		}
+		tail.Transactions = chunk.Transactions
+	}
+	// wallets created during the rebuild have no projected balance yet
+	if err := project(ctx, tx); err != nil {
+		rollback()
+		return replay.ChunkDTO{}, replay.DiffDTO{}, err
+	}
+	changes, err := diff(ctx, tx, limit)
+	if err != nil {
+		rollback()
+		return replay.ChunkDTO{}, replay.DiffDTO{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE wallet SET balance = round(wallet_balance_rebuild.balance, 4) FROM wallet_balance_rebuild
+		WHERE wallet_balance_rebuild.wallet_id = wallet.id AND COALESCE(wallet.balance, 0) <> round(wallet_balance_rebuild.balance, 4);`); err != nil {
+		rollback()
+		return replay.ChunkDTO{}, replay.DiffDTO{}, errors.Wrap(err, "error updating wallet balances")
+	}
+	// every snapshot has seen the whole log now, transactions written later with an earlier date are added to it again
+	if _, err := tx.ExecContext(ctx, `UPDATE balance_snapshot SET balance = round(balance_snapshot_rebuild.balance, 4), last_transaction_id = $1
+		FROM balance_snapshot_rebuild
+		WHERE balance_snapshot_rebuild.wallet_id = balance_snapshot.wallet_id AND balance_snapshot_rebuild.day = balance_snapshot.day;`,
+		tail.LastTransactionID); err != nil {
+		rollback()
+		return replay.ChunkDTO{}, replay.DiffDTO{}, errors.Wrap(err, "error updating balance snapshots")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return replay.ChunkDTO{}, replay.DiffDTO{}, errors.Wrap(err, "error committing transction")
+	}
+	return tail, changes, nil
+}
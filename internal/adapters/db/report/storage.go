@@ -0,0 +1,179 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/report"
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+const reportColumns = `id, filter, format, time_zone, status, attempt, row_count, total, file, error,
+	created_at, started_at, finished_at, expires_at`
+
+type dbReport struct {
+	ID         int64
+	Filter     []byte
+	Format     report.Format
+	TimeZone   string
+	Status     report.Status
+	Attempt    int
+	Rows       int64
+	Total      int64
+	File       sql.NullString
+	Error      sql.NullString
+	CreatedAt  time.Time
+	StartedAt  sql.NullTime
+	FinishedAt sql.NullTime
+	ExpiresAt  sql.NullTime
+}
+
+func (db dbReport) ToDTO() (report.DTO, error) {
+	dto := report.DTO{
+		ID:         db.ID,
+		Format:     db.Format,
+		TimeZone:   db.TimeZone,
+		Status:     db.Status,
+		Attempt:    db.Attempt,
+		Rows:       db.Rows,
+		Total:      db.Total,
+		File:       db.File.String,
+		Error:      db.Error.String,
+		CreatedAt:  db.CreatedAt,
+		StartedAt:  db.StartedAt.Time,
+		FinishedAt: db.FinishedAt.Time,
+		ExpiresAt:  db.ExpiresAt.Time,
+	}
+	var filter transaction.FilterTransactionsDTO
+	if err := json.Unmarshal(db.Filter, &filter); err != nil {
+		return dto, errors.Wrap(err, "error unmarshaling report filter")
+	}
+	dto.Filter = filter
+	return dto, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReport(row scanner) (report.DTO, error) {
+	var r dbReport
+	err := row.Scan(&r.ID, &r.Filter, &r.Format, &r.TimeZone, &r.Status, &r.Attempt, &r.Rows, &r.Total, &r.File, &r.Error,
+		&r.CreatedAt, &r.StartedAt, &r.FinishedAt, &r.ExpiresAt)
+	if err != nil {
+		return report.DTO{}, err
+	}
+	return r.ToDTO()
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
+
+type reportStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (report.Storage, error) {
+	return &reportStorage{db: db, logger: logger}, nil
+}
+
+func (rs *reportStorage) Create(ctx context.Context, dto *report.DTO) (report.DTO, error) {
+	result := *dto
+	filter, err := json.Marshal(dto.Filter)
+	if err != nil {
+		return result, errors.Wrap(err, "error marshaling report filter")
+	}
+	row := rs.db.Conn.QueryRowContext(ctx, `INSERT INTO report_job (filter, format, time_zone, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5) RETURNING id;`, filter, dto.Format, dto.TimeZone, dto.Status, dto.CreatedAt)
+	if err := row.Scan(&result.ID); err != nil {
+		return result, errors.Wrap(err, "error inserting report job")
+	}
+	return result, nil
+}
+
+func (rs *reportStorage) GetByID(ctx context.Context, id int64) (report.DTO, error) {
+	row := rs.db.Conn.QueryRowContext(ctx, "SELECT "+reportColumns+" FROM report_job WHERE id = $1;", id)
+	switch dto, err := scanReport(row); err {
+	case sql.ErrNoRows:
+		return report.DTO{}, nil
+	default:
+		return dto, err
+	}
+}
+
+// Claim locks the job with SKIP LOCKED, so workers of all instances take different jobs.
+func (rs *reportStorage) Claim(ctx context.Context, now time.Time, stale time.Time) (report.DTO, error) {
+	row := rs.db.Conn.QueryRowContext(ctx, `UPDATE report_job SET status = $1, attempt = attempt + 1, row_count = 0, total = 0,
+		started_at = $2, updated_at = $2
+		WHERE id = (SELECT id FROM report_job WHERE status = $3 OR status = $1 AND updated_at < $4
+			ORDER BY id ASC LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING `+reportColumns+`;`, report.StatusRunning, now, report.StatusQueued, stale)
+	switch dto, err := scanReport(row); err {
+	case sql.ErrNoRows:
+		return report.DTO{}, nil
+	default:
+		return dto, err
+	}
+}
+
+func (rs *reportStorage) UpdateProgress(ctx context.Context, dto *report.DTO, now time.Time) error {
+	result, err := rs.db.Conn.ExecContext(ctx, `UPDATE report_job SET row_count = $1, total = $2, updated_at = $3
+		WHERE id = $4 AND attempt = $5 AND status = $6;`, dto.Rows, dto.Total, now, dto.ID, dto.Attempt, report.StatusRunning)
+	if err != nil {
+		return errors.Wrap(err, "error updating report progress")
+	}
+	return checkAttempt(result)
+}
+
+func (rs *reportStorage) Update(ctx context.Context, dto *report.DTO) error {
+	result, err := rs.db.Conn.ExecContext(ctx, `UPDATE report_job SET status = $1, row_count = $2, total = $3, file = $4, error = $5,
+		finished_at = $6, expires_at = $7 WHERE id = $8 AND attempt = $9;`,
+		dto.Status, dto.Rows, dto.Total, nullString(dto.File), nullString(dto.Error), nullTime(dto.FinishedAt), nullTime(dto.ExpiresAt),
+		dto.ID, dto.Attempt)
+	if err != nil {
+		return errors.Wrap(err, "error updating report job")
+	}
+	return checkAttempt(result)
+}
+
+func checkAttempt(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return report.ErrJobLost
+	}
+	return nil
+}
+
+func (rs *reportStorage) GetExpired(ctx context.Context, at time.Time) ([]report.DTO, error) {
+	rows, err := rs.db.Conn.QueryContext(ctx, "SELECT "+reportColumns+` FROM report_job
+		WHERE status IN ($1, $2) AND expires_at <= $3 ORDER BY id ASC;`, report.StatusDone, report.StatusFailed, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []report.DTO
+	for rows.Next() {
+		dto, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, dto)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,177 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/risk"
+)
+
+const selectDecision = `SELECT id, kind, wallet_id, receiver_ids, amount, decision, score, hits, created_at FROM risk_decision`
+
+type dbDecision struct {
+	ID          int64
+	Kind        risk.Kind
+	WalletID    int64
+	ReceiverIDs pq.Int64Array
+	Amount      float64
+	Decision    risk.Decision
+	Score       float64
+	Hits        []byte
+	CreatedAt   time.Time
+}
+
+func (db dbDecision) ToDTO() (risk.DecisionDTO, error) {
+	dto := risk.DecisionDTO{
+		ID:        db.ID,
+		Kind:      db.Kind,
+		WalletID:  db.WalletID,
+		Receivers: db.ReceiverIDs,
+		Amount:    db.Amount,
+		Decision:  db.Decision,
+		Score:     db.Score,
+		CreatedAt: db.CreatedAt,
+	}
+	if err := json.Unmarshal(db.Hits, &dto.Hits); err != nil {
+		return dto, errors.Wrap(err, "error unmarshaling risk rule hits")
+	}
+	return dto, nil
+}
+
+// tranType is a type of the transactions made by the movements of the kind.
+func tranType(kind risk.Kind) string {
+	if kind == risk.KindSplitPayment || kind == risk.KindEscrowRelease {
+		return string(risk.KindTransfer)
+	}
+	return string(kind)
+}
+
+// movements is the condition on the transactions of the wallet $1 made by the movements of the kind with tran type $2.
+func movements(kind risk.Kind) string {
+	if kind == risk.KindEscrowRelease {
+		// released money comes to the seller from the reserved holding wallet
+		return "receiver_id = $1 AND tran_type = $2 AND sender_id IN (SELECT id FROM wallet WHERE reserved)"
+	}
+	return "sender_id = $1 AND tran_type = $2"
+}
+
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Write adds decisions of the movements within tx, so that they are kept only when the money moves.
+// Decisions are nil for movements evaluated without rules.
+func Write(ctx context.Context, tx *sql.Tx, decisions ...*risk.DecisionDTO) error {
+	for _, decision := range decisions {
+		if decision == nil {
+			continue
+		}
+		if _, err := insert(ctx, tx, decision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insert(ctx context.Context, q querier, dto *risk.DecisionDTO) (int64, error) {
+	var id int64
+	hits, err := json.Marshal(dto.Hits)
+	if err != nil {
+		return id, errors.Wrap(err, "error marshaling risk rule hits")
+	}
+	receivers := dto.Receivers
+	if receivers == nil {
+		receivers = []int64{}
+	}
+	row := q.QueryRowContext(ctx, `INSERT INTO risk_decision (kind, wallet_id, receiver_ids, amount, decision, score, hits, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;`,
+		dto.Kind, dto.WalletID, pq.Array(receivers), dto.Amount, dto.Decision, dto.Score, hits, dto.CreatedAt)
+	if err = row.Scan(&id); err != nil {
+		return id, errors.Wrap(err, "error inserting risk decision")
+	}
+	return id, nil
+}
+
+type riskStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (risk.Storage, error) {
+	return &riskStorage{db: db, logger: logger}, nil
+}
+
+func (rs *riskStorage) CountMovements(ctx context.Context, walletID int64, kind risk.Kind, since time.Time) (int, error) {
+	var count int
+	row := rs.db.Conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM transaction WHERE "+movements(kind)+" AND date >= $3;", walletID, tranType(kind), since)
+	err := row.Scan(&count)
+	return count, err
+}
+
+func (rs *riskStorage) AverageAmount(ctx context.Context, walletID int64, kind risk.Kind) (float64, int, error) {
+	var (
+		average float64
+		count   int
+	)
+	row := rs.db.Conn.QueryRowContext(ctx, "SELECT COALESCE(AVG(amount), 0), COUNT(*) FROM transaction WHERE "+movements(kind)+";", walletID, tranType(kind))
+	err := row.Scan(&average, &count)
+	return average, count, err
+}
+
+func (rs *riskStorage) FirstActivity(ctx context.Context, walletID int64) (time.Time, error) {
+	var first sql.NullTime
+	row := rs.db.Conn.QueryRowContext(ctx, "SELECT MIN(date) FROM transaction WHERE sender_id = $1 OR receiver_id = $1;", walletID)
+	err := row.Scan(&first)
+	return first.Time, err
+}
+
+func (rs *riskStorage) NewReceivers(ctx context.Context, walletID int64, receivers []int64, since time.Time) (int, error) {
+	query := `SELECT COUNT(DISTINCT receiver_id) FROM (
+			SELECT receiver_id FROM transaction WHERE sender_id = $1 AND tran_type = 'transfer' AND date >= $2
+			UNION SELECT unnest($3::bigint[])
+		) recent
+		WHERE receiver_id NOT IN (SELECT receiver_id FROM transaction WHERE sender_id = $1 AND tran_type = 'transfer' AND date < $2);`
+	var count int
+	row := rs.db.Conn.QueryRowContext(ctx, query, walletID, since, pq.Array(receivers))
+	err := row.Scan(&count)
+	return count, err
+}
+
+func (rs *riskStorage) Create(ctx context.Context, dto *risk.DecisionDTO) (risk.DecisionDTO, error) {
+	result := *dto
+	id, err := insert(ctx, rs.db.Conn, dto)
+	if err != nil {
+		return result, err
+	}
+	result.ID = id
+	return result, nil
+}
+
+func (rs *riskStorage) GetByDecision(ctx context.Context, decision risk.Decision, limit int, offset int) ([]risk.DecisionDTO, error) {
+	var list []risk.DecisionDTO
+	rows, err := rs.db.Conn.QueryContext(ctx, selectDecision+" WHERE decision = $1 ORDER BY id DESC LIMIT $2 OFFSET $3;", decision, limit, offset)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d dbDecision
+		if err := rows.Scan(&d.ID, &d.Kind, &d.WalletID, &d.ReceiverIDs, &d.Amount, &d.Decision, &d.Score, &d.Hits, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		dto, err := d.ToDTO()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, dto)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,244 @@
+package screening
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/client/pgdb"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/screening"
+)
+
+const selectMatch = `SELECT id, wallet_id, wallet_name, entry_name, entry_reference, normalized, score, status, created_at, reviewed_at, reviewer FROM screening_match`
+
+type dbMatch struct {
+	ID             int64
+	WalletID       int64
+	WalletName     string
+	EntryName      string
+	EntryReference sql.NullString
+	Normalized     string
+	Score          float64
+	Status         screening.Status
+	CreatedAt      time.Time
+	ReviewedAt     sql.NullTime
+	Reviewer       sql.NullString
+}
+
+func (db dbMatch) ToDTO() screening.MatchDTO {
+	return screening.MatchDTO{
+		ID:             db.ID,
+		WalletID:       db.WalletID,
+		WalletName:     db.WalletName,
+		EntryName:      db.EntryName,
+		EntryReference: db.EntryReference.String,
+		Normalized:     db.Normalized,
+		Score:          db.Score,
+		Status:         db.Status,
+		CreatedAt:      db.CreatedAt,
+		ReviewedAt:     db.ReviewedAt.Time,
+		Reviewer:       db.Reviewer.String,
+	}
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMatch(row scanner) (dbMatch, error) {
+	var m dbMatch
+	err := row.Scan(&m.ID, &m.WalletID, &m.WalletName, &m.EntryName, &m.EntryReference, &m.Normalized, &m.Score, &m.Status, &m.CreatedAt, &m.ReviewedAt, &m.Reviewer)
+	return m, err
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+type screeningStorage struct {
+	db     *pgdb.PGDB
+	logger logging.Logger
+}
+
+func NewStorage(db *pgdb.PGDB, logger logging.Logger) (screening.Storage, error) {
+	return &screeningStorage{db: db, logger: logger}, nil
+}
+
+func (ss *screeningStorage) GetEntries(ctx context.Context) ([]screening.EntryDTO, error) {
+	var list []screening.EntryDTO
+	rows, err := ss.db.Conn.QueryContext(ctx, "SELECT id, name, normalized, COALESCE(reference, '') FROM watchlist_entry ORDER BY id ASC;")
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry screening.EntryDTO
+		if err := rows.Scan(&entry.ID, &entry.Name, &entry.Normalized, &entry.Reference); err != nil {
+			return nil, err
+		}
+		list = append(list, entry)
+	}
+	return list, rows.Err()
+}
+
+func (ss *screeningStorage) ReplaceEntries(ctx context.Context, entries []screening.EntryDTO) error {
+	tx, err := ss.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			ss.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM watchlist_entry;"); err != nil {
+		rollback()
+		return errors.Wrap(err, "error deleting watchlist")
+	}
+	for _, entry := range entries {
+		if _, err = tx.ExecContext(ctx, "INSERT INTO watchlist_entry (name, normalized, reference) VALUES ($1, $2, $3);",
+			entry.Name, entry.Normalized, nullString(entry.Reference)); err != nil {
+			rollback()
+			return errors.Wrap(err, "error inserting watchlist entry")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error during commit")
+	}
+	return nil
+}
+
+func (ss *screeningStorage) Flag(ctx context.Context, walletID int64, matches []screening.MatchDTO) ([]screening.MatchDTO, error) {
+	tx, err := ss.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			ss.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	var flagged []screening.MatchDTO
+	for _, match := range matches {
+		row := tx.QueryRowContext(ctx, `INSERT INTO screening_match (wallet_id, wallet_name, entry_name, entry_reference, normalized, score, status, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (wallet_id, normalized) DO NOTHING RETURNING id;`,
+			walletID, match.WalletName, match.EntryName, nullString(match.EntryReference), match.Normalized, match.Score, match.Status, match.CreatedAt)
+		switch err = row.Scan(&match.ID); err {
+		case nil:
+			flagged = append(flagged, match)
+		case sql.ErrNoRows:
+		default:
+			rollback()
+			return nil, errors.Wrap(err, "error inserting screening match")
+		}
+	}
+	if len(flagged) > 0 {
+		if _, err = tx.ExecContext(ctx, "UPDATE wallet SET status = 'frozen' WHERE id = $1 AND status = 'active';", walletID); err != nil {
+			rollback()
+			return nil, errors.Wrap(err, "error freezing wallet")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "error during commit")
+	}
+	return flagged, nil
+}
+
+func (ss *screeningStorage) Clear(ctx context.Context, walletID int64) error {
+	if _, err := ss.db.Conn.ExecContext(ctx, `UPDATE wallet SET status = 'active' WHERE id = $1 AND status = 'frozen'
+		AND NOT EXISTS (SELECT 1 FROM screening_match WHERE wallet_id = $1 AND status IN ('pending', 'confirmed'));`, walletID); err != nil {
+		return errors.Wrap(err, "error unfreezing wallet")
+	}
+	return nil
+}
+
+func (ss *screeningStorage) GetMatch(ctx context.Context, id int64) (screening.MatchDTO, error) {
+	row := ss.db.Conn.QueryRowContext(ctx, selectMatch+" WHERE id = $1;", id)
+	switch m, err := scanMatch(row); err {
+	case sql.ErrNoRows:
+		return screening.MatchDTO{}, nil
+	default:
+		return m.ToDTO(), err
+	}
+}
+
+func (ss *screeningStorage) GetMatches(ctx context.Context, status screening.Status, limit int, offset int) ([]screening.MatchDTO, error) {
+	var list []screening.MatchDTO
+	rows, err := ss.db.Conn.QueryContext(ctx, selectMatch+" WHERE status = $1 ORDER BY id ASC LIMIT $2 OFFSET $3;", status, limit, offset)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		m, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m.ToDTO())
+	}
+	return list, rows.Err()
+}
+
+func (ss *screeningStorage) Review(ctx context.Context, dto *screening.MatchDTO) error {
+	tx, err := ss.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			ss.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE screening_match SET status=$1, reviewed_at=$2, reviewer=$3 WHERE id=$4 AND status='pending';",
+		dto.Status, dto.ReviewedAt, dto.Reviewer, dto.ID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "error updating screening match")
+	}
+	if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+		rollback()
+		if err != nil {
+			return errors.Wrap(err, "error updating screening match")
+		}
+		return screening.ErrAlreadyReviewed
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE wallet SET status = 'active' WHERE id = $1 AND status = 'frozen'
+		AND NOT EXISTS (SELECT 1 FROM screening_match WHERE wallet_id = $1 AND status IN ('pending', 'confirmed'));`, dto.WalletID); err != nil {
+		rollback()
+		return errors.Wrap(err, "error unfreezing wallet")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "error during commit")
+	}
+	return nil
+}
+
+func (ss *screeningStorage) GetWallets(ctx context.Context) ([]screening.WalletDTO, error) {
+	var list []screening.WalletDTO
+	rows, err := ss.db.Conn.QueryContext(ctx, "SELECT id, name FROM wallet WHERE status <> 'closed' ORDER BY id ASC;")
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var wallet screening.WalletDTO
+		if err := rows.Scan(&wallet.ID, &wallet.Name); err != nil {
+			return nil, err
+		}
+		list = append(list, wallet)
+	}
+	return list, rows.Err()
+}
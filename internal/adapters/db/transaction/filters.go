@@ -3,156 +3,228 @@ package transaction
 import (
 	"fmt"
 	"strings"
-	"time"
 
-	"github.com/skwol/wallet/internal/domain/transaction"
-)
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
 
-type transactionFilter struct {
-	senderID        int64Filter
-	receiverID      int64Filter
-	amount          *floatRangeFilter
-	timestamp       *dateRangeFilter
-	transactionType stringFilter
-}
+	"github.com/skwol/wallet/pkg/pagination"
 
-func newTransactionFilter(dto *transaction.FilterTransactionsDTO) transactionFilter {
-	var filter transactionFilter
-	if dto == nil {
-		return filter
-	}
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
 
-	filter.senderID = newInt64Filter(dto.SenderIDs...)
-	filter.receiverID = newInt64Filter(dto.ReceiverIDs...)
-	filter.amount = newFloatRangeFilter(dto.Amount.From, dto.Amount.To)
-	filter.timestamp = newDateRangeFilter(dto.Timestamp.From, dto.Timestamp.To)
-	filter.transactionType = newStringFilter(dto.Types...)
+// queryArgs collects values bound to the query, every value gets its own placeholder
+// so that nothing coming from the filter is ever written into the query text.
+type queryArgs []interface{}
 
-	return filter
+func (a *queryArgs) bind(value interface{}) string {
+	*a = append(*a, value)
+	return fmt.Sprintf("$%d", len(*a))
 }
 
-func (s transactionFilter) Empty() bool {
-	return s.senderID == nil && s.receiverID == nil && s.amount == nil && s.timestamp == nil && s.transactionType == nil
+var idColumns = map[transaction.Field]string{
+	transaction.FieldSender:   "sender_id",
+	transaction.FieldReceiver: "receiver_id",
 }
 
-func (s transactionFilter) BuildQuery(limit, offset int) string {
-	var filters []string
-	if !s.senderID.Empty() {
-		filters = append(filters, s.senderID.Build("sender_id"))
-	}
-	if !s.receiverID.Empty() {
-		filters = append(filters, s.receiverID.Build("receiver_id"))
-	}
-	if !s.amount.Empty() {
-		filters = append(filters, s.amount.Build("amount"))
-	}
-	if !s.timestamp.Empty() {
-		filters = append(filters, s.timestamp.Build("date"))
-	}
-	if !s.transactionType.Empty() {
-		filters = append(filters, s.transactionType.Build("tran_type"))
-	}
-	var filter string
-	if len(filters) > 0 {
-		filter = fmt.Sprintf("WHERE %s ", strings.Join(filters, " AND "))
-	}
-	return fmt.Sprintf("SELECT id, sender_id, receiver_id, amount, date, tran_type FROM transaction %sORDER BY id ASC LIMIT %d OFFSET %d;", filter, limit, offset)
+var comparisons = map[transaction.Operator]string{
+	transaction.OpGt:  ">",
+	transaction.OpGte: ">=",
+	transaction.OpLt:  "<",
+	transaction.OpLte: "<=",
 }
 
-type stringFilter []string
+const transactionColumns = "id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id"
 
-func newStringFilter(values ...string) stringFilter {
-	if len(values) == 0 {
-		return nil
-	}
-	return values
+var sortColumns = map[transaction.SortField]string{
+	transaction.SortID:     "id",
+	transaction.SortAmount: "amount",
+	transaction.SortDate:   "date",
 }
 
-func (f stringFilter) Empty() bool {
-	return len(f) == 0
-}
-
-func (f stringFilter) Build(fieldName string) string {
-	vals := make([]string, len(f))
-	for i, v := range f {
-		vals[i] = fmt.Sprintf("'%s'", v)
-	}
-	return fmt.Sprintf("%s IN (%s)", fieldName, strings.Join(vals, ", "))
+type transactionFilter struct {
+	where *transaction.Expression
+	sort  []transaction.Sort
 }
 
-type int64Filter []int64
-
-func newInt64Filter(values ...int64) int64Filter {
-	if len(values) == 0 {
-		return nil
+func newTransactionFilter(dto *transaction.FilterTransactionsDTO) transactionFilter {
+	if dto == nil {
+		return transactionFilter{}
 	}
-	return values
+	return transactionFilter{where: dto.Expression(), sort: dto.Sort}
 }
 
-func (f int64Filter) Empty() bool {
-	return len(f) == 0
+func (s transactionFilter) Empty() bool {
+	return s.where == nil
 }
 
-func (f int64Filter) Build(fieldName string) string {
-	values := make([]string, len(f))
-	for i, v := range f {
-		values[i] = fmt.Sprintf("%d", v)
+// BuildQuery returns the query with placeholders and the values to bind to them, only column names
+// and operators from the fixed lists above are written into the query text.
+func (s transactionFilter) BuildQuery(limit, offset int) (string, []interface{}, error) {
+	var args queryArgs
+	query, err := s.buildSelect(&args)
+	if err != nil {
+		return "", nil, err
 	}
-	return fmt.Sprintf("%s IN (%s)", fieldName, strings.Join(values, ", "))
+	query = fmt.Sprintf("%s LIMIT %s OFFSET %s;", query, args.bind(limit), args.bind(offset))
+	return query, args, nil
 }
 
-type floatRangeFilter struct {
-	From float64
-	To   float64
-}
-
-func newFloatRangeFilter(from, to float64) *floatRangeFilter {
-	if from == 0 && to == 0 {
-		return nil
+// BuildCursorQuery returns the query to declare a cursor with, without the semicolon.
+// Rows are limited only when limit is greater then 0.
+func (s transactionFilter) BuildCursorQuery(limit, offset int) (string, []interface{}, error) {
+	var args queryArgs
+	query, err := s.buildSelect(&args)
+	if err != nil {
+		return "", nil, err
 	}
-	return &floatRangeFilter{
-		From: from,
-		To:   to,
+	if limit > 0 {
+		query = fmt.Sprintf("%s LIMIT %s OFFSET %s", query, args.bind(limit), args.bind(offset))
 	}
+	return query, args, nil
 }
 
-func (f *floatRangeFilter) Empty() bool {
-	return f == nil || f.From == 0 && f.To == 0
-}
-
-func (f floatRangeFilter) Build(fieldName string) string {
-	if f.From > 0 && f.To == 0 {
-		return fmt.Sprintf("%s > %f", fieldName, f.From)
-	} else if f.From == 0 && f.To > 0 {
-		return fmt.Sprintf("%s < %f", fieldName, f.To)
+// BuildPageQuery returns the query of the keyset page, the sort of the filter is not used as pages
+// go in id order. Page before the cursor is read backwards and put in ascending order again.
+func (s transactionFilter) BuildPageQuery(page pagination.Page) (string, []interface{}, error) {
+	var args queryArgs
+	filter, err := s.buildWhere(&args)
+	if err != nil {
+		return "", nil, err
 	}
-	return fmt.Sprintf("%s BETWEEN %f AND %f", fieldName, f.From, f.To)
-}
-
-type dateRangeFilter struct {
-	From time.Time
-	To   time.Time
-}
-
-func newDateRangeFilter(from, to time.Time) *dateRangeFilter {
-	if from.IsZero() && to.IsZero() {
-		return nil
-	}
-	return &dateRangeFilter{
-		From: from,
-		To:   to,
-	}
-}
-
-func (f *dateRangeFilter) Empty() bool {
-	return f == nil || f.From.IsZero() && f.To.IsZero()
-}
-
-func (f dateRangeFilter) Build(fieldName string) string {
-	if !f.From.IsZero() && f.To.IsZero() {
-		return fmt.Sprintf("%s > '%s'", fieldName, f.From.Format("2006-01-02 15:04:05"))
-	} else if f.From.IsZero() && !f.To.IsZero() {
-		return fmt.Sprintf("%s < '%s'", fieldName, f.To.Format("2006-01-02 15:04:05"))
+	keyset := "WHERE "
+	if filter != "" {
+		keyset = filter + "AND "
 	}
-	return fmt.Sprintf("%s BETWEEN '%s' AND '%s'", fieldName, f.From.Format("2006-01-02 15:04:05"), f.To.Format("2006-01-02 15:04:05"))
+	if page.Backward() {
+		keyset += "id < " + args.bind(page.Before)
+		return fmt.Sprintf("SELECT * FROM (SELECT %s FROM transaction %s ORDER BY id DESC LIMIT %s) page ORDER BY id ASC;",
+			transactionColumns, keyset, args.bind(page.Fetch())), args, nil
+	}
+	keyset += "id > " + args.bind(page.After)
+	return fmt.Sprintf("SELECT %s FROM transaction %s ORDER BY id ASC LIMIT %s;", transactionColumns, keyset, args.bind(page.Fetch())), args, nil
+}
+
+// BuildCountQuery returns the query counting all filtered transactions, sort is not needed for it.
+func (s transactionFilter) BuildCountQuery() (string, []interface{}, error) {
+	var args queryArgs
+	filter, err := s.buildWhere(&args)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace("SELECT COUNT(*) FROM transaction "+filter) + ";", args, nil
+}
+
+func (s transactionFilter) buildWhere(args *queryArgs) (string, error) {
+	if s.Empty() {
+		return "", nil
+	}
+	where, err := buildExpression(*s.where, args)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("WHERE %s ", where), nil
+}
+
+func (s transactionFilter) buildSelect(args *queryArgs) (string, error) {
+	filter, err := s.buildWhere(args)
+	if err != nil {
+		return "", err
+	}
+	orderBy, err := s.orderBy()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SELECT %s FROM transaction %sORDER BY %s", transactionColumns, filter, orderBy), nil
+}
+
+// orderBy always ends with id so that pages are stable for equal amounts and dates.
+func (s transactionFilter) orderBy() (string, error) {
+	var columns []string
+	var byID bool
+	for _, sort := range s.sort {
+		column, ok := sortColumns[sort.Field]
+		if !ok {
+			return "", errors.Wrapf(transaction.ErrUnknownSortField, "%q", sort.Field)
+		}
+		direction := "ASC"
+		if sort.Desc {
+			direction = "DESC"
+		}
+		columns = append(columns, fmt.Sprintf("%s %s", column, direction))
+		byID = byID || sort.Field == transaction.SortID
+	}
+	if !byID {
+		columns = append(columns, "id ASC")
+	}
+	return strings.Join(columns, ", "), nil
+}
+
+func buildExpression(e transaction.Expression, args *queryArgs) (string, error) {
+	switch {
+	case e.Condition != nil:
+		return buildCondition(*e.Condition, args)
+	case e.Not != nil:
+		inner, err := buildExpression(*e.Not, args)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("NOT %s", inner), nil
+	case len(e.And) > 0:
+		return buildGroup(e.And, " AND ", args)
+	case len(e.Or) > 0:
+		return buildGroup(e.Or, " OR ", args)
+	}
+	return "", transaction.ErrInvalidExpression
+}
+
+func buildGroup(children []transaction.Expression, operator string, args *queryArgs) (string, error) {
+	parts := make([]string, 0, len(children))
+	for _, child := range children {
+		part, err := buildExpression(child, args)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, operator)), nil
+}
+
+func buildCondition(c transaction.Condition, args *queryArgs) (string, error) {
+	var condition string
+	switch c.Field {
+	case transaction.FieldID:
+		if operator, ok := comparisons[c.Op]; ok {
+			return fmt.Sprintf("id %s %s", operator, args.bind(c.ID)), nil
+		}
+		condition = fmt.Sprintf("id = ANY(%s)", args.bind(pq.Int64Array(c.IDs)))
+	case transaction.FieldSender, transaction.FieldReceiver:
+		condition = fmt.Sprintf("%s = ANY(%s)", idColumns[c.Field], args.bind(pq.Int64Array(c.IDs)))
+	case transaction.FieldWallet:
+		ids := args.bind(pq.Int64Array(c.IDs))
+		condition = fmt.Sprintf("(sender_id = ANY(%s) OR receiver_id = ANY(%s))", ids, ids)
+	case transaction.FieldType:
+		types := make([]string, 0, len(c.Types))
+		for _, t := range c.Types {
+			types = append(types, string(t))
+		}
+		condition = fmt.Sprintf("tran_type = ANY(%s::transaction_type[])", args.bind(pq.Array(types)))
+	case transaction.FieldAmount, transaction.FieldDate:
+		operator, ok := comparisons[c.Op]
+		if !ok {
+			return "", errors.Wrapf(transaction.ErrUnsupportedOperator, "%s %s", c.Field, c.Op)
+		}
+		if c.Field == transaction.FieldAmount {
+			return fmt.Sprintf("amount %s %s", operator, args.bind(c.Amount)), nil
+		}
+		return fmt.Sprintf("date %s %s", operator, args.bind(c.Date)), nil
+	default:
+		return "", errors.Wrapf(transaction.ErrUnknownField, "%q", c.Field)
+	}
+	switch c.Op {
+	case transaction.OpIn:
+		return condition, nil
+	case transaction.OpNotIn:
+		return fmt.Sprintf("NOT %s", condition), nil
+	}
+	return "", errors.Wrapf(transaction.ErrUnsupportedOperator, "%s %s", c.Field, c.Op)
 }
@@ -0,0 +1,240 @@
+package transaction
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skwol/wallet/pkg/pagination"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+var shapeDate = time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+// shapeOf replaces every value of the filter with a harmless one which takes the same branches
+// when the query is built, both filters must produce the same query text.
+func shapeOf(dto transaction.FilterTransactionsDTO) transaction.FilterTransactionsDTO {
+	sign := func(f float64) float64 {
+		switch {
+		case f == 0:
+			return 0
+		case f > 0:
+			return 1
+		}
+		return -1
+	}
+	date := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		return shapeDate
+	}
+	shape := transaction.FilterTransactionsDTO{
+		SenderIDs:   make([]int64, len(dto.SenderIDs)),
+		ReceiverIDs: make([]int64, len(dto.ReceiverIDs)),
+		Types:       make([]string, len(dto.Types)),
+		Amount:      transaction.FloatRangeFilter{From: sign(dto.Amount.From), To: sign(dto.Amount.To)},
+		Timestamp:   transaction.DateRangeFilter{From: date(dto.Timestamp.From), To: date(dto.Timestamp.To)},
+		Sort:        dto.Sort,
+	}
+	if dto.Where != nil {
+		where := shapeOfExpression(*dto.Where)
+		shape.Where = &where
+	}
+	return shape
+}
+
+func shapeOfExpression(e transaction.Expression) transaction.Expression {
+	var shape transaction.Expression
+	for _, child := range e.And {
+		shape.And = append(shape.And, shapeOfExpression(child))
+	}
+	for _, child := range e.Or {
+		shape.Or = append(shape.Or, shapeOfExpression(child))
+	}
+	if e.Not != nil {
+		not := shapeOfExpression(*e.Not)
+		shape.Not = &not
+	}
+	if e.Condition != nil {
+		shape.Condition = &transaction.Condition{
+			Field: e.Condition.Field,
+			Op:    e.Condition.Op,
+			IDs:   make([]int64, len(e.Condition.IDs)),
+			Types: make([]transaction.TranType, len(e.Condition.Types)),
+			Date:  shapeDate,
+		}
+	}
+	return shape
+}
+
+func FuzzTransactionFilter_BuildQuery(f *testing.F) {
+	f.Add(int64(1), int64(2), "deposit", 10.5, 0.0, int64(0), int64(1633860000), 10, 0)
+	f.Add(int64(0), int64(0), "deposit') OR 1=1; --", 0.0, 0.0, int64(0), int64(0), 10, 0)
+	f.Add(int64(-1), int64(0), "'; DROP TABLE transaction; --", -1.0, 5.0, int64(1633860000), int64(0), -1, -5)
+	f.Add(int64(0), int64(7), "$1", 1e308, -1e308, int64(-1), int64(1), 0, 0)
+	f.Fuzz(func(t *testing.T, sender, receiver int64, tranType string, amountFrom, amountTo float64, from, to int64, limit, offset int) {
+		date := func(unix int64) time.Time {
+			if unix == 0 {
+				return time.Time{}
+			}
+			return time.Unix(unix, 0).UTC()
+		}
+		dto := transaction.FilterTransactionsDTO{
+			Amount:    transaction.FloatRangeFilter{From: amountFrom, To: amountTo},
+			Timestamp: transaction.DateRangeFilter{From: date(from), To: date(to)},
+		}
+		if sender != 0 {
+			dto.SenderIDs = []int64{sender}
+		}
+		if receiver != 0 {
+			dto.ReceiverIDs = []int64{receiver, sender}
+			dto.Where = &transaction.Expression{Or: []transaction.Expression{
+				{Condition: &transaction.Condition{Field: transaction.FieldWallet, Op: transaction.OpIn, IDs: []int64{receiver}}},
+				{Not: &transaction.Expression{Condition: &transaction.Condition{Field: transaction.FieldType, Op: transaction.OpNotIn, Types: []transaction.TranType{transaction.TranType(tranType)}}}},
+				{Condition: &transaction.Condition{Field: transaction.FieldAmount, Op: transaction.OpLte, Amount: amountTo}},
+				{Condition: &transaction.Condition{Field: transaction.FieldDate, Op: transaction.OpGt, Date: date(from)}},
+				{Condition: &transaction.Condition{Field: transaction.FieldID, Op: transaction.OpGte, ID: sender}},
+				{Condition: &transaction.Condition{Field: transaction.FieldID, Op: transaction.OpNotIn, IDs: []int64{receiver}}},
+			}}
+			dto.Sort = []transaction.Sort{{Field: transaction.SortAmount, Desc: true}}
+		}
+		if tranType != "" {
+			dto.Types = []string{tranType}
+		}
+
+		query, args, err := newTransactionFilter(&dto).BuildQuery(limit, offset)
+		if err != nil {
+			t.Fatalf("BuildQuery() unexpected error = %v", err)
+		}
+		shape := shapeOf(dto)
+		want, wantArgs, err := newTransactionFilter(&shape).BuildQuery(1, 1)
+		if err != nil {
+			t.Fatalf("BuildQuery() unexpected error = %v", err)
+		}
+		if query != want {
+			t.Fatalf("BuildQuery() query = %q, want %q", query, want)
+		}
+		if len(args) != len(wantArgs) {
+			t.Fatalf("BuildQuery() args = %d, want %d", len(args), len(wantArgs))
+		}
+		for i := len(args); i > 0; i-- {
+			if placeholder := "$" + strconv.Itoa(i); !strings.Contains(query, placeholder) {
+				t.Fatalf("BuildQuery() missing placeholder %s in %q", placeholder, query)
+			}
+		}
+		if args[len(args)-2] != limit || args[len(args)-1] != offset {
+			t.Errorf("BuildQuery() limit and offset args = %v, want %d %d", args[len(args)-2:], limit, offset)
+		}
+	})
+}
+
+func TestTransactionFilter_BuildQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		dto     *transaction.FilterTransactionsDTO
+		want    string
+		args    int
+		wantErr string
+	}{
+		{
+			name: "test no filter",
+			dto:  nil,
+			want: "SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction ORDER BY id ASC LIMIT $1 OFFSET $2;",
+			args: 2,
+		},
+		{
+			name: "test either side or excluded types sorted by amount",
+			dto: &transaction.FilterTransactionsDTO{
+				Where: &transaction.Expression{Or: []transaction.Expression{
+					{Condition: &transaction.Condition{Field: transaction.FieldWallet, Op: transaction.OpIn, IDs: []int64{1}}},
+					{And: []transaction.Expression{
+						{Condition: &transaction.Condition{Field: transaction.FieldType, Op: transaction.OpNotIn, Types: []transaction.TranType{transaction.TranTypeDeposit}}},
+						{Not: &transaction.Expression{Condition: &transaction.Condition{Field: transaction.FieldAmount, Op: transaction.OpGte, Amount: 0}}},
+					}},
+				}},
+				Sort: []transaction.Sort{{Field: transaction.SortAmount, Desc: true}, {Field: transaction.SortDate}},
+			},
+			want: "SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction " +
+				"WHERE (((sender_id = ANY($1) OR receiver_id = ANY($1)) OR (NOT tran_type = ANY($2::transaction_type[]) AND NOT amount >= $3))) " +
+				"ORDER BY amount DESC, date ASC, id ASC LIMIT $4 OFFSET $5;",
+			args: 5,
+		},
+		{
+			name: "test sort by id has no tie breaker",
+			dto:  &transaction.FilterTransactionsDTO{SenderIDs: []int64{1, 2}, Sort: []transaction.Sort{{Field: transaction.SortID, Desc: true}}},
+			want: "SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction WHERE (sender_id = ANY($1)) ORDER BY id DESC LIMIT $2 OFFSET $3;",
+			args: 3,
+		},
+		{
+			name: "test unsupported operator",
+			dto: &transaction.FilterTransactionsDTO{Where: &transaction.Expression{
+				Condition: &transaction.Condition{Field: transaction.FieldSender, Op: transaction.OpGt, IDs: []int64{1}},
+			}},
+			wantErr: "sender_id gt: operator is not supported for the filter field",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := newTransactionFilter(tt.dto).BuildQuery(10, 0)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("BuildQuery() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildQuery() unexpected error = %v", err)
+			}
+			if got != tt.want || len(args) != tt.args {
+				t.Errorf("BuildQuery() = %q %d args, want %q %d args", got, len(args), tt.want, tt.args)
+			}
+		})
+	}
+}
+
+func TestTransactionFilter_BuildPageQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		dto  *transaction.FilterTransactionsDTO
+		page pagination.Page
+		want string
+		args int
+	}{
+		{
+			name: "test first page without filter",
+			page: pagination.Page{Limit: 10},
+			want: "SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction WHERE id > $1 ORDER BY id ASC LIMIT $2;",
+			args: 2,
+		},
+		{
+			name: "test next page of filtered transactions",
+			dto:  &transaction.FilterTransactionsDTO{SenderIDs: []int64{1, 2}},
+			page: pagination.Page{After: 5, Limit: 10},
+			want: "SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction " +
+				"WHERE (sender_id = ANY($1)) AND id > $2 ORDER BY id ASC LIMIT $3;",
+			args: 3,
+		},
+		{
+			name: "test previous page of filtered transactions",
+			dto:  &transaction.FilterTransactionsDTO{SenderIDs: []int64{1, 2}},
+			page: pagination.Page{Before: 5, Limit: 10},
+			want: "SELECT * FROM (SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction " +
+				"WHERE (sender_id = ANY($1)) AND id < $2 ORDER BY id DESC LIMIT $3) page ORDER BY id ASC;",
+			args: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := newTransactionFilter(tt.dto).BuildPageQuery(tt.page)
+			if err != nil {
+				t.Fatalf("BuildPageQuery() unexpected error = %v", err)
+			}
+			if got != tt.want || len(args) != tt.args {
+				t.Errorf("BuildPageQuery() = %q %d args, want %q %d args", got, len(args), tt.want, tt.args)
+			}
+		})
+	}
+}
@@ -3,31 +3,37 @@ package transaction
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/skwol/wallet/pkg/client/pgdb"
 	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/pagination"
 
 	"github.com/skwol/wallet/internal/domain/transaction"
 )
 
 type dbTransaction struct {
-	ID         int64
-	SenderID   int64
-	ReceiverID int64
-	Amount     float64
-	Timestamp  time.Time
-	Type       transaction.TranType
+	ID              int64
+	SenderID        int64
+	ReceiverID      int64
+	Amount          float64
+	Timestamp       time.Time
+	Type            transaction.TranType
+	ParentPaymentID sql.NullInt64
 }
 
 func (db dbTransaction) ToDTO() transaction.DTO {
 	return transaction.DTO{
-		ID:         db.ID,
-		SenderID:   db.SenderID,
-		ReceiverID: db.ReceiverID,
-		Amount:     db.Amount,
-		Timestamp:  db.Timestamp,
-		Type:       db.Type,
+		ID:              db.ID,
+		SenderID:        db.SenderID,
+		ReceiverID:      db.ReceiverID,
+		Amount:          db.Amount,
+		Timestamp:       db.Timestamp,
+		Type:            db.Type,
+		ParentPaymentID: db.ParentPaymentID.Int64,
 	}
 }
 
@@ -41,26 +47,46 @@ func NewStorage(db *pgdb.PGDB, logger logging.Logger) (transaction.Storage, erro
 }
 
 func (as *transactionStorage) GetByID(ctx context.Context, id int64) (transaction.DTO, error) {
-	row := as.db.Conn.QueryRowContext(ctx, "SELECT id, sender_id, receiver_id, amount, date, tran_type FROM transaction WHERE id = $1;", id)
+	row := as.db.Conn.QueryRowContext(ctx, "SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction WHERE id = $1;", id)
 	var tran dbTransaction
-	switch err := row.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type); err {
+	switch err := row.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type, &tran.ParentPaymentID); err {
+	case nil:
 	case sql.ErrNoRows:
 		return transaction.DTO{}, nil
 	default:
-		return tran.ToDTO(), err
+		return transaction.DTO{}, err
+	}
+	result := tran.ToDTO()
+
+	rows, err := as.db.Conn.QueryContext(ctx, "SELECT id, reason, status, hold_amount, opened_at, resolved_at FROM dispute WHERE transaction_id = $1 ORDER BY id ASC;", id)
+	if err != nil {
+		return transaction.DTO{}, err
 	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			dispute    transaction.DisputeDTO
+			resolvedAt sql.NullTime
+		)
+		if err := rows.Scan(&dispute.ID, &dispute.Reason, &dispute.Status, &dispute.HoldAmount, &dispute.OpenedAt, &resolvedAt); err != nil {
+			return transaction.DTO{}, err
+		}
+		dispute.ResolvedAt = resolvedAt.Time
+		result.Disputes = append(result.Disputes, dispute)
+	}
+	return result, rows.Err()
 }
 
 func (as *transactionStorage) GetAll(ctx context.Context, limit int, offset int) ([]transaction.DTO, error) {
 	var list []transaction.DTO
 
-	rows, err := as.db.Conn.QueryContext(ctx, "SELECT id, sender_id, receiver_id, amount, date, tran_type FROM transaction ORDER BY ID ASC LIMIT $1 OFFSET $2", limit, offset)
+	rows, err := as.db.Conn.QueryContext(ctx, "SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction ORDER BY ID ASC LIMIT $1 OFFSET $2", limit, offset)
 	if err != nil {
 		return list, err
 	}
 	var tran dbTransaction
 	for rows.Next() {
-		if err := rows.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type); err != nil {
+		if err := rows.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type, &tran.ParentPaymentID); err != nil {
 			return nil, err
 		}
 		list = append(list, tran.ToDTO())
@@ -68,15 +94,51 @@ func (as *transactionStorage) GetAll(ctx context.Context, limit int, offset int)
 	return list, nil
 }
 
+func (as *transactionStorage) GetPage(ctx context.Context, page pagination.Page) ([]transaction.DTO, error) {
+	query := "SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction WHERE id > $1 ORDER BY id ASC LIMIT $2;"
+	cursor := page.After
+	if page.Backward() {
+		// page before the cursor is the closest records, they are read backwards and put in order again
+		query = `SELECT * FROM (SELECT id, sender_id, receiver_id, amount, date, tran_type, parent_payment_id FROM transaction
+			WHERE id < $1 ORDER BY id DESC LIMIT $2) page ORDER BY id ASC;`
+		cursor = page.Before
+	}
+	rows, err := as.db.Conn.QueryContext(ctx, query, cursor, page.Fetch())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []transaction.DTO
+	for rows.Next() {
+		var tran dbTransaction
+		if err := rows.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type, &tran.ParentPaymentID); err != nil {
+			return nil, err
+		}
+		list = append(list, tran.ToDTO())
+	}
+	return list, rows.Err()
+}
+
+func (as *transactionStorage) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := as.db.Conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM transaction;").Scan(&count)
+	return count, err
+}
+
 func (as *transactionStorage) GetFiltered(ctx context.Context, filter *transaction.FilterTransactionsDTO, limit, offset int) ([]transaction.DTO, error) {
 	var list []transaction.DTO
-	rows, err := as.db.Conn.QueryContext(ctx, newTransactionFilter(filter).BuildQuery(limit, offset))
+	query, args, err := newTransactionFilter(filter).BuildQuery(limit, offset)
 	if err != nil {
 		return list, err
 	}
+	rows, err := as.db.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
 	for rows.Next() {
 		var transaction dbTransaction
-		err := rows.Scan(&transaction.ID, &transaction.SenderID, &transaction.ReceiverID, &transaction.Amount, &transaction.Timestamp, &transaction.Type)
+		err := rows.Scan(&transaction.ID, &transaction.SenderID, &transaction.ReceiverID, &transaction.Amount, &transaction.Timestamp, &transaction.Type, &transaction.ParentPaymentID)
 		if err != nil {
 			return list, err
 		}
@@ -84,3 +146,92 @@ func (as *transactionStorage) GetFiltered(ctx context.Context, filter *transacti
 	}
 	return list, nil
 }
+
+func (as *transactionStorage) GetFilteredPage(ctx context.Context, filter *transaction.FilterTransactionsDTO, page pagination.Page) ([]transaction.DTO, error) {
+	query, args, err := newTransactionFilter(filter).BuildPageQuery(page)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := as.db.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []transaction.DTO
+	for rows.Next() {
+		var tran dbTransaction
+		if err := rows.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type, &tran.ParentPaymentID); err != nil {
+			return nil, err
+		}
+		list = append(list, tran.ToDTO())
+	}
+	return list, rows.Err()
+}
+
+func (as *transactionStorage) CountFiltered(ctx context.Context, filter *transaction.FilterTransactionsDTO) (int64, error) {
+	query, args, err := newTransactionFilter(filter).BuildCountQuery()
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = as.db.Conn.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// streamBatch is the number of rows fetched from the cursor at once.
+const streamBatch = 1000
+
+// StreamFiltered reads filtered transactions with a server side cursor, so that only one batch
+// is held in memory however many rows match.
+func (as *transactionStorage) StreamFiltered(ctx context.Context, filter *transaction.FilterTransactionsDTO, limit, offset int, fn func(transaction.DTO) error) error {
+	query, args, err := newTransactionFilter(filter).BuildCursorQuery(limit, offset)
+	if err != nil {
+		return err
+	}
+	tx, err := as.db.Conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			as.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+	defer rollback()
+
+	if _, err := tx.ExecContext(ctx, "DECLARE transaction_report NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return errors.Wrap(err, "error declaring cursor")
+	}
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM transaction_report;", streamBatch)
+	for {
+		fetched, err := fetchBatch(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if fetched < streamBatch {
+			break
+		}
+	}
+	return tx.Commit()
+}
+
+func fetchBatch(ctx context.Context, tx *sql.Tx, fetch string, fn func(transaction.DTO) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, errors.Wrap(err, "error fetching from cursor")
+	}
+	defer rows.Close()
+	var fetched int
+	for rows.Next() {
+		var tran dbTransaction
+		if err := rows.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type, &tran.ParentPaymentID); err != nil {
+			return fetched, err
+		}
+		fetched++
+		if err := fn(tran.ToDTO()); err != nil {
+			return fetched, err
+		}
+	}
+	return fetched, rows.Err()
+}
@@ -9,18 +9,31 @@ import (
 	"github.com/skwol/wallet/pkg/client/pgdb"
 	"github.com/skwol/wallet/pkg/logging"
 
+	dbchain "github.com/skwol/wallet/internal/adapters/db/chain"
+	dboutbox "github.com/skwol/wallet/internal/adapters/db/outbox"
+	dbrisk "github.com/skwol/wallet/internal/adapters/db/risk"
+	dbwallet "github.com/skwol/wallet/internal/adapters/db/wallet"
+	"github.com/skwol/wallet/internal/domain/outbox"
 	"github.com/skwol/wallet/internal/domain/transfer"
 )
 
 type dbWallet struct {
-	ID      int64
-	Balance float64
+	ID       int64
+	Balance  float64
+	Held     float64
+	Closed   bool
+	Frozen   bool
+	Reserved bool
 }
 
 func (db dbWallet) ToDTO() transfer.WalletDTO {
 	return transfer.WalletDTO{
-		ID:      db.ID,
-		Balance: db.Balance,
+		ID:       db.ID,
+		Balance:  db.Balance,
+		Held:     db.Held,
+		Closed:   db.Closed,
+		Frozen:   db.Frozen,
+		Reserved: db.Reserved,
 	}
 }
 
@@ -47,11 +60,17 @@ func (ts transferStorage) Create(ctx context.Context, dto *transfer.CreateTransf
 		rollback()
 		return result, errors.Wrap(err, "error beginning transaction")
 	}
-	if _, err = tx.ExecContext(ctx, "UPDATE wallet SET balance=$1 WHERE id=$2;", dto.Sender.Balance, dto.Sender.ID); err != nil {
+	// balances move relative to the current ones, balances read before the transaction could be stale by now
+	debited, err := dbwallet.Debit(ctx, tx, dto.Sender.ID, dto.Amount)
+	if err != nil {
 		rollback()
 		return result, errors.Wrap(err, "error updating sender wallet")
 	}
-	if _, err = tx.ExecContext(ctx, "UPDATE wallet SET balance=$1 WHERE id=$2;", dto.Receiver.Balance, dto.Receiver.ID); err != nil {
+	if !debited {
+		rollback()
+		return result, transfer.ErrNotEnoughMoney
+	}
+	if err = dbwallet.Credit(ctx, tx, dto.Receiver.ID, dto.Amount); err != nil {
 		rollback()
 		return result, errors.Wrap(err, "error updating receiver wallet")
 	}
@@ -61,6 +80,96 @@ func (ts transferStorage) Create(ctx context.Context, dto *transfer.CreateTransf
 		rollback()
 		return result, err
 	}
+	if err = dbchain.Seal(ctx, tx); err != nil {
+		rollback()
+		return result, err
+	}
+	event, err := outbox.NewTransferCompleted(outbox.TransferCompleted{
+		TransactionID: result.ID, SenderID: dto.Sender.ID, ReceiverID: dto.Receiver.ID, Amount: dto.Amount, Date: dto.Timestamp,
+	})
+	if err != nil {
+		rollback()
+		return result, err
+	}
+	if err = dboutbox.Write(ctx, tx, event); err != nil {
+		rollback()
+		return result, err
+	}
+	if err = dbrisk.Write(ctx, tx, dto.Risk); err != nil {
+		rollback()
+		return result, err
+	}
+	if err = tx.Commit(); err != nil {
+		return result, errors.Wrap(err, "error during commit")
+	}
+	return result, nil
+}
+
+// CreateSplitPayment posts all legs of the payment in one db transaction, each leg references the payment.
+func (ts transferStorage) CreateSplitPayment(ctx context.Context, dto *transfer.PaymentDTO) (transfer.PaymentDTO, error) {
+	result := *dto
+	result.Legs = make([]transfer.DTO, len(dto.Legs))
+	copy(result.Legs, dto.Legs)
+	tx, err := ts.db.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return result, errors.Wrap(err, "error beginning transaction")
+	}
+	rollback := func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			ts.logger.Errorf("rollback transaction %s", err)
+		}
+	}
+
+	row := tx.QueryRowContext(ctx, "INSERT INTO payment (sender_id, amount, date) VALUES ($1, $2, $3) RETURNING id;", dto.Sender.ID, dto.Amount, dto.Timestamp)
+	if err = row.Scan(&result.ID); err != nil {
+		rollback()
+		return result, errors.Wrap(err, "error inserting payment")
+	}
+	debited, err := dbwallet.Debit(ctx, tx, dto.Sender.ID, dto.Amount)
+	if err != nil {
+		rollback()
+		return result, errors.Wrap(err, "error updating sender wallet")
+	}
+	if !debited {
+		rollback()
+		return result, transfer.ErrNotEnoughMoney
+	}
+	for i, leg := range dto.Legs {
+		if err = dbwallet.Credit(ctx, tx, leg.Receiver.ID, leg.Amount); err != nil {
+			rollback()
+			return result, errors.Wrap(err, "error updating receiver wallet")
+		}
+		row := tx.QueryRowContext(ctx, "INSERT INTO transaction (sender_id, receiver_id, amount, date, tran_type, parent_payment_id) VALUES ($1, $2, $3, $4, 'transfer', $5) RETURNING id;",
+			leg.Sender.ID, leg.Receiver.ID, leg.Amount, leg.Timestamp, result.ID)
+		if err = row.Scan(&result.Legs[i].ID); err != nil {
+			rollback()
+			return result, errors.Wrap(err, "error inserting transaction")
+		}
+	}
+	if err = dbchain.Seal(ctx, tx); err != nil {
+		rollback()
+		return result, err
+	}
+	events := make([]outbox.EventDTO, 0, len(result.Legs))
+	for _, leg := range result.Legs {
+		event, err := outbox.NewTransferCompleted(outbox.TransferCompleted{
+			TransactionID: leg.ID, SenderID: leg.Sender.ID, ReceiverID: leg.Receiver.ID, Amount: leg.Amount, Date: leg.Timestamp, ParentPaymentID: result.ID,
+		})
+		if err != nil {
+			rollback()
+			return result, err
+		}
+		events = append(events, event)
+	}
+	if err = dboutbox.Write(ctx, tx, events...); err != nil {
+		rollback()
+		return result, err
+	}
+	if err = dbrisk.Write(ctx, tx, dto.Risk); err != nil {
+		rollback()
+		return result, err
+	}
 	if err = tx.Commit(); err != nil {
 		return result, errors.Wrap(err, "error during commit")
 	}
@@ -68,10 +177,11 @@ func (ts transferStorage) Create(ctx context.Context, dto *transfer.CreateTransf
 }
 
 func (ts transferStorage) GetWallet(ctx context.Context, id int64) (transfer.WalletDTO, error) {
-	query := `SELECT id, balance FROM wallet WHERE id = $1;`
+	query := `SELECT id, balance, (SELECT COALESCE(SUM(hold_amount), 0) FROM dispute WHERE hold_wallet_id = wallet.id AND status IN ('opened', 'under_review')),
+		status = 'closed', status = 'frozen', reserved FROM wallet WHERE id = $1;`
 	row := ts.db.Conn.QueryRow(query, id)
 	var walletInDB dbWallet
-	switch err := row.Scan(&walletInDB.ID, &walletInDB.Balance); err {
+	switch err := row.Scan(&walletInDB.ID, &walletInDB.Balance, &walletInDB.Held, &walletInDB.Closed, &walletInDB.Frozen, &walletInDB.Reserved); err {
 	case sql.ErrNoRows:
 		return transfer.WalletDTO{}, nil
 	default:
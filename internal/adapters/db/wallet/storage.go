@@ -3,47 +3,63 @@ package wallet
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 
 	"github.com/skwol/wallet/pkg/client/pgdb"
 	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/pagination"
 
+	dbchain "github.com/skwol/wallet/internal/adapters/db/chain"
+	dboutbox "github.com/skwol/wallet/internal/adapters/db/outbox"
+	dbrisk "github.com/skwol/wallet/internal/adapters/db/risk"
+	"github.com/skwol/wallet/internal/domain/outbox"
+	"github.com/skwol/wallet/internal/domain/risk"
 	"github.com/skwol/wallet/internal/domain/wallet"
 )
 
 type dbWallet struct {
-	ID      int64
-	Name    string
-	Balance float64
+	ID       int64
+	Name     string
+	Balance  float64
+	Held     float64
+	Status   wallet.Status
+	Reserved bool
 }
 
 func (db dbWallet) ToDTO() wallet.DTO {
 	return wallet.DTO{
-		ID:      db.ID,
-		Name:    db.Name,
-		Balance: db.Balance,
+		ID:       db.ID,
+		Name:     db.Name,
+		Balance:  db.Balance,
+		Held:     db.Held,
+		Status:   db.Status,
+		Reserved: db.Reserved,
 	}
 }
 
 type dbTransaction struct {
-	ID         int64
-	SenderID   int64
-	ReceiverID int64
-	Amount     float64
-	Timestamp  time.Time
-	Type       wallet.TranType
+	ID              int64
+	SenderID        int64
+	ReceiverID      int64
+	Amount          float64
+	Timestamp       time.Time
+	Type            wallet.TranType
+	ParentPaymentID int64
 }
 
 func (db dbTransaction) ToDTO() wallet.TransactionDTO {
 	return wallet.TransactionDTO{
-		ID:         db.ID,
-		SenderID:   db.SenderID,
-		ReceiverID: db.ReceiverID,
-		Amount:     db.Amount,
-		Timestamp:  db.Timestamp,
-		Type:       db.Type,
+		ID:              db.ID,
+		SenderID:        db.SenderID,
+		ReceiverID:      db.ReceiverID,
+		Amount:          db.Amount,
+		Timestamp:       db.Timestamp,
+		Type:            db.Type,
+		ParentPaymentID: db.ParentPaymentID,
 	}
 }
 
@@ -62,7 +78,8 @@ func (as *walletStorage) Create(ctx context.Context, dto wallet.DTO) (wallet.DTO
 		return dto, err
 	}
 
-	row := tx.QueryRowContext(ctx, "INSERT INTO wallet (name, balance) VALUES ($1, $2) RETURNING id;", dto.Name, dto.Balance)
+	row := tx.QueryRowContext(ctx, "INSERT INTO wallet (name, balance, status) VALUES ($1, $2, COALESCE(NULLIF($3, '')::wallet_status, 'active')) RETURNING id;",
+		dto.Name, dto.Balance, dto.Status)
 	rollback := func() {
 		err := tx.Rollback()
 		if err != nil && !errors.Is(err, sql.ErrTxDone) {
@@ -74,12 +91,19 @@ func (as *walletStorage) Create(ctx context.Context, dto wallet.DTO) (wallet.DTO
 		return dto, err
 	}
 
-	for _, tran := range dto.TransactionsToApply {
-		_, err = tx.ExecContext(ctx, "INSERT INTO transaction (sender_id, receiver_id, amount, date, tran_type) VALUES ($1, $1, $2, $3, $4);", dto.ID, tran.Amount, tran.Timestamp, tran.Type)
-		if err != nil {
-			rollback()
-			return dto, errors.Wrap(err, "error inserting transaction")
-		}
+	created, err := outbox.NewWalletCreated(outbox.WalletCreated{WalletID: dto.ID, Name: dto.Name, Balance: dto.Balance})
+	if err != nil {
+		rollback()
+		return dto, err
+	}
+	events, err := applyTransactions(ctx, tx, dto.ID, dto.TransactionsToApply, dto.RiskDecisions)
+	if err != nil {
+		rollback()
+		return dto, err
+	}
+	if err := dboutbox.Write(ctx, tx, append([]outbox.EventDTO{created}, events...)...); err != nil {
+		rollback()
+		return dto, err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -90,10 +114,11 @@ func (as *walletStorage) Create(ctx context.Context, dto wallet.DTO) (wallet.DTO
 }
 
 func (as *walletStorage) GetByID(ctx context.Context, id int64) (wallet.DTO, error) {
-	query := `SELECT id, name, balance FROM wallet WHERE id = $1;`
+	query := `SELECT id, name, balance, (SELECT COALESCE(SUM(hold_amount), 0) FROM dispute WHERE hold_wallet_id = wallet.id AND status IN ('opened', 'under_review')), status, reserved
+		FROM wallet WHERE id = $1;`
 	row := as.db.Conn.QueryRowContext(ctx, query, id)
 	var walletInDB dbWallet
-	switch err := row.Scan(&walletInDB.ID, &walletInDB.Name, &walletInDB.Balance); err {
+	switch err := row.Scan(&walletInDB.ID, &walletInDB.Name, &walletInDB.Balance, &walletInDB.Held, &walletInDB.Status, &walletInDB.Reserved); err {
 	case sql.ErrNoRows:
 		return wallet.DTO{}, nil
 	default:
@@ -106,17 +131,18 @@ func (as *walletStorage) GetByIDWithTransactions(ctx context.Context, id int64,
 	if err != nil {
 		return wallet.DTO{}, errors.Wrap(err, "error beginning transaction")
 	}
-	query := `SELECT id, name, balance FROM wallet WHERE id = $1;`
+	query := `SELECT id, name, balance, status FROM wallet WHERE id = $1;`
 	row := as.db.Conn.QueryRowContext(ctx, query, id)
 	var walletInDB dbWallet
-	if err := row.Scan(&walletInDB.ID, &walletInDB.Name, &walletInDB.Balance); err != nil {
+	if err := row.Scan(&walletInDB.ID, &walletInDB.Name, &walletInDB.Balance, &walletInDB.Status); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return wallet.DTO{}, nil
 		}
 		return wallet.DTO{}, err
 	}
 
-	query = "SELECT id, sender_id, receiver_id, amount, date, tran_type FROM transaction WHERE sender_id = $1 OR receiver_id = $1 ORDER BY ID ASC LIMIT $2 OFFSET $3"
+	query = `SELECT id, sender_id, receiver_id, amount, date, tran_type, COALESCE(parent_payment_id, 0) FROM transaction
+		WHERE sender_id = $1 OR receiver_id = $1 ORDER BY ID ASC LIMIT $2 OFFSET $3`
 	rows, err := as.db.Conn.Query(query, walletInDB.ID, limit, offset)
 	if err != nil {
 		return wallet.DTO{}, err
@@ -126,7 +152,7 @@ func (as *walletStorage) GetByIDWithTransactions(ctx context.Context, id int64,
 		tran dbTransaction
 	)
 	for rows.Next() {
-		if err := rows.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type); err != nil {
+		if err := rows.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type, &tran.ParentPaymentID); err != nil {
 			return wallet.DTO{}, err
 		}
 		list = append(list, tran.ToDTO())
@@ -154,13 +180,13 @@ func (as *walletStorage) GetByName(ctx context.Context, name string) (wallet.DTO
 
 func (as *walletStorage) GetAll(ctx context.Context, limit int, offset int) ([]wallet.DTO, error) {
 	var list []wallet.DTO
-	rows, err := as.db.Conn.Query("SELECT id, name, balance FROM wallet ORDER BY ID ASC LIMIT $1 OFFSET $2", limit, offset)
+	rows, err := as.db.Conn.Query("SELECT id, name, balance, status FROM wallet ORDER BY ID ASC LIMIT $1 OFFSET $2", limit, offset)
 	if err != nil {
 		return list, err
 	}
 	var wallet dbWallet
 	for rows.Next() {
-		if err := rows.Scan(&wallet.ID, &wallet.Name, &wallet.Balance); err != nil {
+		if err := rows.Scan(&wallet.ID, &wallet.Name, &wallet.Balance, &wallet.Status); err != nil {
 			return nil, err
 		}
 		list = append(list, wallet.ToDTO())
@@ -168,7 +194,282 @@ func (as *walletStorage) GetAll(ctx context.Context, limit int, offset int) ([]w
 	return list, nil
 }
 
-func (as *walletStorage) Update(ctx context.Context, walletDTO wallet.DTO) error {
+const walletColumns = `id, name, balance, (SELECT COALESCE(SUM(hold_amount), 0) FROM dispute WHERE hold_wallet_id = wallet.id AND status IN ('opened', 'under_review')), status`
+
+func (as *walletStorage) GetPage(ctx context.Context, page pagination.Page) ([]wallet.DTO, error) {
+	query := fmt.Sprintf("SELECT %s FROM wallet WHERE id > $1 ORDER BY id ASC LIMIT $2;", walletColumns)
+	cursor := page.After
+	if page.Backward() {
+		// page before the cursor is the closest records, they are read backwards and put in order again
+		query = fmt.Sprintf("SELECT * FROM (SELECT %s FROM wallet WHERE id < $1 ORDER BY id DESC LIMIT $2) page ORDER BY id ASC;", walletColumns)
+		cursor = page.Before
+	}
+	rows, err := as.db.Conn.QueryContext(ctx, query, cursor, page.Fetch())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []wallet.DTO
+	for rows.Next() {
+		var w dbWallet
+		if err := rows.Scan(&w.ID, &w.Name, &w.Balance, &w.Held, &w.Status); err != nil {
+			return nil, err
+		}
+		list = append(list, w.ToDTO())
+	}
+	return list, rows.Err()
+}
+
+func (as *walletStorage) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := as.db.Conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM wallet;").Scan(&count)
+	return count, err
+}
+
+func (as *walletStorage) GetTransactionsPage(ctx context.Context, walletID int64, page pagination.Page) ([]wallet.TransactionDTO, error) {
+	query := `SELECT id, sender_id, receiver_id, amount, date, tran_type, COALESCE(parent_payment_id, 0) FROM transaction
+		WHERE (sender_id = $1 OR receiver_id = $1) AND id > $2 ORDER BY id ASC LIMIT $3;`
+	cursor := page.After
+	if page.Backward() {
+		query = `SELECT * FROM (SELECT id, sender_id, receiver_id, amount, date, tran_type, COALESCE(parent_payment_id, 0) FROM transaction
+			WHERE (sender_id = $1 OR receiver_id = $1) AND id < $2 ORDER BY id DESC LIMIT $3) page ORDER BY id ASC;`
+		cursor = page.Before
+	}
+	rows, err := as.db.Conn.QueryContext(ctx, query, walletID, cursor, page.Fetch())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []wallet.TransactionDTO
+	for rows.Next() {
+		var tran dbTransaction
+		if err := rows.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type, &tran.ParentPaymentID); err != nil {
+			return nil, err
+		}
+		list = append(list, tran.ToDTO())
+	}
+	return list, rows.Err()
+}
+
+func (as *walletStorage) CountTransactions(ctx context.Context, walletID int64) (int64, error) {
+	var count int64
+	err := as.db.Conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM transaction WHERE sender_id = $1 OR receiver_id = $1;", walletID).Scan(&count)
+	return count, err
+}
+
+// signedAmount is the movement of a transaction for the wallet in $1, it is negative when money leaves the wallet.
+const signedAmount = `CASE WHEN tran_type = 'deposit' THEN amount WHEN tran_type = 'withdraw' THEN -amount
+	WHEN receiver_id = $1 THEN amount ELSE -amount END`
+
+func (as *walletStorage) GetStatement(ctx context.Context, walletID int64, from, to time.Time) (float64, []wallet.TransactionDTO, error) {
+	tx, err := as.db.Conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "error beginning transaction")
+	}
+	// read only transaction has nothing to commit, it only keeps the snapshot of both queries
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			as.logger.Errorf("rollback transaction %s", err)
+		}
+	}()
+
+	var openingBalance float64
+	row := tx.QueryRowContext(ctx, "SELECT COALESCE(SUM("+signedAmount+`), 0) FROM transaction
+		WHERE (sender_id = $1 OR receiver_id = $1) AND date < $2;`, walletID, from)
+	if err := row.Scan(&openingBalance); err != nil {
+		return 0, nil, errors.Wrap(err, "error getting opening balance")
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, sender_id, receiver_id, amount, date, tran_type, COALESCE(parent_payment_id, 0) FROM transaction
+		WHERE (sender_id = $1 OR receiver_id = $1) AND date >= $2 AND date < $3 ORDER BY date ASC, id ASC;`, walletID, from, to)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "error getting statement transactions")
+	}
+	defer rows.Close()
+	var list []wallet.TransactionDTO
+	for rows.Next() {
+		var tran dbTransaction
+		if err := rows.Scan(&tran.ID, &tran.SenderID, &tran.ReceiverID, &tran.Amount, &tran.Timestamp, &tran.Type, &tran.ParentPaymentID); err != nil {
+			return 0, nil, err
+		}
+		list = append(list, tran.ToDTO())
+	}
+	return openingBalance, list, rows.Err()
+}
+
+func (as *walletStorage) GetBalanceBefore(ctx context.Context, walletID, transactionID int64) (float64, error) {
+	var balance float64
+	row := as.db.Conn.QueryRowContext(ctx, "SELECT COALESCE(SUM("+signedAmount+`), 0) FROM transaction
+		WHERE (sender_id = $1 OR receiver_id = $1) AND id < $2;`, walletID, transactionID)
+	if err := row.Scan(&balance); err != nil {
+		return 0, errors.Wrap(err, "error getting balance before transaction")
+	}
+	return balance, nil
+}
+
+// querier runs queries within a transaction or outside of it.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// balanceAt adds the transactions after the snapshot to its balance. Transactions which were written after the snapshot
+// with a date within its day have greater id than any transaction the snapshot has seen, so they are added as well.
+func balanceAt(ctx context.Context, q querier, walletID int64, at time.Time) (float64, error) {
+	var (
+		snapshotEnd     time.Time
+		balance         float64
+		lastTransaction int64
+	)
+	row := q.QueryRowContext(ctx, `SELECT (day + 1)::timestamp, balance, last_transaction_id FROM balance_snapshot
+		WHERE wallet_id = $1 AND (day + 1)::timestamp <= $2 ORDER BY day DESC LIMIT 1;`, walletID, at.UTC())
+	switch err := row.Scan(&snapshotEnd, &balance, &lastTransaction); {
+	case errors.Is(err, sql.ErrNoRows):
+		// without a snapshot every transaction before at is summed up
+		snapshotEnd = at.UTC()
+	case err != nil:
+		return 0, errors.Wrap(err, "error getting balance snapshot")
+	}
+
+	var movement float64
+	row = q.QueryRowContext(ctx, "SELECT COALESCE(SUM("+signedAmount+`), 0) FROM transaction
+		WHERE (sender_id = $1 OR receiver_id = $1) AND ((date >= $2 AND date < $3) OR (date < $2 AND id > $4));`,
+		walletID, snapshotEnd, at.UTC(), lastTransaction)
+	if err := row.Scan(&movement); err != nil {
+		return 0, errors.Wrap(err, "error getting balance movement")
+	}
+	return balance + movement, nil
+}
+
+func (as *walletStorage) GetBalanceAt(ctx context.Context, walletID int64, at time.Time) (float64, error) {
+	tx, err := as.db.Conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, errors.Wrap(err, "error beginning transaction")
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			as.logger.Errorf("rollback transaction %s", err)
+		}
+	}()
+	return balanceAt(ctx, tx, walletID, at)
+}
+
+func (as *walletStorage) GetBalanceHistory(ctx context.Context, walletID int64, starts []time.Time, to time.Time) (float64, []float64, error) {
+	if len(starts) == 0 {
+		return 0, nil, nil
+	}
+	tx, err := as.db.Conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "error beginning transaction")
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			as.logger.Errorf("rollback transaction %s", err)
+		}
+	}()
+
+	openingBalance, err := balanceAt(ctx, tx, walletID, starts[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	// width_bucket numbers the interval of a date by the starts, the first interval is 1
+	thresholds := make([]string, len(starts))
+	for i, start := range starts {
+		thresholds[i] = start.UTC().Format("2006-01-02 15:04:05.999999")
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT width_bucket(date, $2::timestamp[]), SUM("+signedAmount+`) FROM transaction
+		WHERE (sender_id = $1 OR receiver_id = $1) AND date >= $3 AND date < $4 GROUP BY 1;`,
+		walletID, pq.Array(thresholds), starts[0].UTC(), to.UTC())
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "error getting balance movements")
+	}
+	defer rows.Close()
+	changes := make([]float64, len(starts))
+	for rows.Next() {
+		var (
+			bucket int
+			change float64
+		)
+		if err := rows.Scan(&bucket, &change); err != nil {
+			return 0, nil, err
+		}
+		if bucket < 1 || bucket > len(starts) {
+			return 0, nil, errors.Errorf("balance movement out of history intervals %d", bucket)
+		}
+		changes[bucket-1] = change
+	}
+	return openingBalance, changes, rows.Err()
+}
+
+// heldAmount is the amount held on the wallet $2 by its active disputes.
+const heldAmount = `(SELECT COALESCE(SUM(hold_amount), 0) FROM dispute WHERE hold_wallet_id = $2 AND status IN ('opened', 'under_review'))`
+
+// Debit takes amount off the wallet within tx relative to its current balance, so that concurrent movements
+// of the wallet are not lost. It changes nothing and returns false when the wallet would be left with less
+// than the amount held by its active disputes.
+func Debit(ctx context.Context, tx *sql.Tx, walletID int64, amount float64) (bool, error) {
+	result, err := tx.ExecContext(ctx, "UPDATE wallet SET balance = balance - $1 WHERE id = $2 AND balance - $1 >= "+heldAmount+";", amount, walletID)
+	if err != nil {
+		return false, errors.Wrapf(err, "error debiting wallet %d", walletID)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrapf(err, "error debiting wallet %d", walletID)
+	}
+	return updated == 1, nil
+}
+
+// Credit adds amount to the wallet within tx relative to its current balance.
+func Credit(ctx context.Context, tx *sql.Tx, walletID int64, amount float64) error {
+	result, err := tx.ExecContext(ctx, "UPDATE wallet SET balance = balance + $1 WHERE id = $2;", amount, walletID)
+	if err != nil {
+		return errors.Wrapf(err, "error crediting wallet %d", walletID)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrapf(err, "error crediting wallet %d", walletID)
+	}
+	if updated == 0 {
+		return errors.Errorf("missing wallet %d", walletID)
+	}
+	return nil
+}
+
+// applyTransactions writes deposits and withdrawals of the wallet with the risk decisions on them and chains
+// them, it returns their events. Decisions on the deposit of the new wallet get its id here.
+func applyTransactions(ctx context.Context, tx *sql.Tx, walletID int64, transactions []wallet.TransactionDTO, decisions []*risk.DecisionDTO) ([]outbox.EventDTO, error) {
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+	events := make([]outbox.EventDTO, 0, len(transactions))
+	for _, tran := range transactions {
+		var id int64
+		row := tx.QueryRowContext(ctx, "INSERT INTO transaction (sender_id, receiver_id, amount, date, tran_type) VALUES ($1, $1, $2, $3, $4) RETURNING id;",
+			walletID, tran.Amount, tran.Timestamp, tran.Type)
+		if err := row.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "error inserting transaction")
+		}
+		event, err := outbox.NewDepositMade(outbox.DepositMade{TransactionID: id, WalletID: walletID, Amount: tran.Amount, Date: tran.Timestamp},
+			tran.Type == wallet.TranTypeWithdraw)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := dbchain.Seal(ctx, tx); err != nil {
+		return nil, err
+	}
+	for _, decision := range decisions {
+		if decision != nil {
+			decision.WalletID = walletID
+		}
+	}
+	if err := dbrisk.Write(ctx, tx, decisions...); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (as *walletStorage) Update(ctx context.Context, walletDTO wallet.DTO, prev wallet.DTO) error {
 	tx, err := as.db.Conn.BeginTx(ctx, nil)
 	if err != nil {
 		return errors.Wrap(err, "error beginning transaction")
@@ -180,18 +481,38 @@ func (as *walletStorage) Update(ctx context.Context, walletDTO wallet.DTO) error
 			as.logger.Errorf("rollback transaction %s", err)
 		}
 	}
-	_, err = tx.ExecContext(ctx, "UPDATE wallet SET name=$1, balance=$2 WHERE id=$3;", walletDTO.Name, walletDTO.Balance, walletDTO.ID)
+	// balance moves by the difference only from the balance it was read with, a wallet frozen by screening
+	// after it was read is left as it is, and the balance does not go below the amount held by disputes
+	result, err := tx.ExecContext(ctx, `UPDATE wallet SET name=$1, balance = balance + $3, status=COALESCE(NULLIF($4, '')::wallet_status, status)
+		WHERE id=$2 AND status <> 'frozen' AND balance = $5 AND balance + $3 >= `+heldAmount+";",
+		walletDTO.Name, walletDTO.ID, walletDTO.Balance-prev.Balance, walletDTO.Status, prev.Balance)
 	if err != nil {
 		rollback()
 		return errors.Wrap(err, "error updating wallet")
 	}
-
-	for _, tran := range walletDTO.TransactionsToApply {
-		_, err = tx.ExecContext(ctx, "INSERT INTO transaction (sender_id, receiver_id, amount, date, tran_type) VALUES ($1, $1, $2, $3, $4);", walletDTO.ID, tran.Amount, tran.Timestamp, tran.Type)
+	if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+		rollback()
 		if err != nil {
-			rollback()
-			return errors.Wrap(err, "error inserting transaction")
+			return errors.Wrap(err, "error updating wallet")
 		}
+		return as.conflict(ctx, walletDTO.ID, prev.Balance)
+	}
+
+	updated, err := outbox.NewWalletUpdated(outbox.WalletUpdated{
+		WalletID: walletDTO.ID, Name: walletDTO.Name, Balance: walletDTO.Balance, Status: string(walletDTO.Status),
+	})
+	if err != nil {
+		rollback()
+		return err
+	}
+	events, err := applyTransactions(ctx, tx, walletDTO.ID, walletDTO.TransactionsToApply, walletDTO.RiskDecisions)
+	if err != nil {
+		rollback()
+		return err
+	}
+	if err := dboutbox.Write(ctx, tx, append([]outbox.EventDTO{updated}, events...)...); err != nil {
+		rollback()
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -200,3 +521,23 @@ func (as *walletStorage) Update(ctx context.Context, walletDTO wallet.DTO) error
 
 	return nil
 }
+
+// conflict tells why the wallet was not updated from the balance it was read with.
+func (as *walletStorage) conflict(ctx context.Context, id int64, balance float64) error {
+	var (
+		status  wallet.Status
+		changed bool
+	)
+	row := as.db.Conn.QueryRowContext(ctx, "SELECT status, balance <> $2 FROM wallet WHERE id = $1;", id, balance)
+	if err := row.Scan(&status, &changed); err != nil {
+		return errors.Wrap(err, "error getting wallet status")
+	}
+	switch {
+	case status == wallet.StatusFrozen:
+		return wallet.ErrWalletFrozen
+	case changed:
+		return wallet.ErrConcurrentUpdate
+	default:
+		return wallet.ErrBalanceBelowHeld
+	}
+}
@@ -0,0 +1,42 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerapproval "github.com/skwol/wallet/internal/adapters/api/approval"
+	dbapproval "github.com/skwol/wallet/internal/adapters/db/approval"
+	domainapproval "github.com/skwol/wallet/internal/domain/approval"
+)
+
+type ApprovalComposite struct {
+	Storage domainapproval.Storage
+	Service domainapproval.Service
+	Handler adapters.Handler
+}
+
+func NewApprovalComposite(db *PgDBComposite, logger logging.Logger, clk clock.Clock, rules domainapproval.Rules) (*ApprovalComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	storage, err := dbapproval.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating approval storage")
+	}
+	service, err := domainapproval.NewService(storage, logger, clk, rules)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating approval service")
+	}
+	handler, err := handlerapproval.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating approval handler")
+	}
+	return &ApprovalComposite{
+		Storage: storage,
+		Service: service,
+		Handler: handler,
+	}, nil
+}
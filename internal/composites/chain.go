@@ -0,0 +1,34 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	dbchain "github.com/skwol/wallet/internal/adapters/db/chain"
+	domainchain "github.com/skwol/wallet/internal/domain/chain"
+)
+
+type ChainComposite struct {
+	Storage domainchain.Storage
+	Service domainchain.Service
+}
+
+func NewChainComposite(db *PgDBComposite, logger logging.Logger, clk clock.Clock) (*ChainComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	storage, err := dbchain.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating chain storage")
+	}
+	service, err := domainchain.NewService(storage, logger, clk)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating chain service")
+	}
+	return &ChainComposite{
+		Storage: storage,
+		Service: service,
+	}, nil
+}
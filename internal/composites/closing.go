@@ -0,0 +1,34 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	dbclosing "github.com/skwol/wallet/internal/adapters/db/closing"
+	domainclosing "github.com/skwol/wallet/internal/domain/closing"
+)
+
+type ClosingComposite struct {
+	Storage domainclosing.Storage
+	Service domainclosing.Service
+}
+
+func NewClosingComposite(db *PgDBComposite, logger logging.Logger, clk clock.Clock) (*ClosingComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	storage, err := dbclosing.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating closing storage")
+	}
+	service, err := domainclosing.NewService(storage, logger, clk)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating closing service")
+	}
+	return &ClosingComposite{
+		Storage: storage,
+		Service: service,
+	}, nil
+}
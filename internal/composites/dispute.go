@@ -0,0 +1,45 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerdispute "github.com/skwol/wallet/internal/adapters/api/dispute"
+	dbdispute "github.com/skwol/wallet/internal/adapters/db/dispute"
+	domaindispute "github.com/skwol/wallet/internal/domain/dispute"
+)
+
+type DisputeComposite struct {
+	Storage domaindispute.Storage
+	Service domaindispute.Service
+	Handler adapters.Handler
+}
+
+func NewDisputeComposite(db *PgDBComposite, risk *RiskComposite, logger logging.Logger, clk clock.Clock) (*DisputeComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	if risk == nil {
+		return nil, errors.New("missing risk composite")
+	}
+	storage, err := dbdispute.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating dispute storage")
+	}
+	service, err := domaindispute.NewService(storage, risk.Service, logger, clk)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating dispute service")
+	}
+	handler, err := handlerdispute.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating dispute handler")
+	}
+	return &DisputeComposite{
+		Storage: storage,
+		Service: service,
+		Handler: handler,
+	}, nil
+}
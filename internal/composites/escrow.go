@@ -0,0 +1,50 @@
+package composites
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerescrow "github.com/skwol/wallet/internal/adapters/api/escrow"
+	dbescrow "github.com/skwol/wallet/internal/adapters/db/escrow"
+	domainescrow "github.com/skwol/wallet/internal/domain/escrow"
+)
+
+type EscrowComposite struct {
+	Storage domainescrow.Storage
+	Service domainescrow.Service
+	Handler adapters.Handler
+}
+
+func NewEscrowComposite(db *PgDBComposite, approvals *ApprovalComposite, risk *RiskComposite, logger logging.Logger, clk clock.Clock, timeout time.Duration) (*EscrowComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	if approvals == nil {
+		return nil, errors.New("missing approval composite")
+	}
+	if risk == nil {
+		return nil, errors.New("missing risk composite")
+	}
+	storage, err := dbescrow.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating escrow storage")
+	}
+	service, err := domainescrow.NewService(storage, approvals.Service, risk.Service, logger, clk, timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating escrow service")
+	}
+	handler, err := handlerescrow.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating escrow handler")
+	}
+	return &EscrowComposite{
+		Storage: storage,
+		Service: service,
+		Handler: handler,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerexport "github.com/skwol/wallet/internal/adapters/api/export"
+	domainexport "github.com/skwol/wallet/internal/domain/export"
+)
+
+type ExportComposite struct {
+	Service domainexport.Service
+	Handler adapters.Handler
+}
+
+func NewExportComposite(transactions *TransactionComposite, wallets *WalletComposite, logger logging.Logger, clk clock.Clock, currency string) (*ExportComposite, error) {
+	if transactions == nil {
+		return nil, errors.New("missing transaction composite")
+	}
+	if wallets == nil {
+		return nil, errors.New("missing wallet composite")
+	}
+	service, err := domainexport.NewService(transactions.Service, wallets.Service, logger, clk, currency)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating export service")
+	}
+	handler, err := handlerexport.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating export handler")
+	}
+	return &ExportComposite{
+		Service: service,
+		Handler: handler,
+	}, nil
+}
@@ -0,0 +1,35 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerjournal "github.com/skwol/wallet/internal/adapters/api/journal"
+	domainjournal "github.com/skwol/wallet/internal/domain/journal"
+)
+
+type JournalComposite struct {
+	Service domainjournal.Service
+	Handler adapters.Handler
+}
+
+func NewJournalComposite(wallets *WalletComposite, logger logging.Logger, clk clock.Clock, currency string) (*JournalComposite, error) {
+	if wallets == nil {
+		return nil, errors.New("missing wallet composite")
+	}
+	service, err := domainjournal.NewService(wallets.Service, logger, clk, currency)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating journal service")
+	}
+	handler, err := handlerjournal.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating journal handler")
+	}
+	return &JournalComposite{
+		Service: service,
+		Handler: handler,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerliability "github.com/skwol/wallet/internal/adapters/api/liability"
+	dbliability "github.com/skwol/wallet/internal/adapters/db/liability"
+	domainliability "github.com/skwol/wallet/internal/domain/liability"
+)
+
+type LiabilityComposite struct {
+	Storage domainliability.Storage
+	Service domainliability.Service
+	Handler adapters.Handler
+}
+
+func NewLiabilityComposite(db *PgDBComposite, logger logging.Logger, clk clock.Clock) (*LiabilityComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	storage, err := dbliability.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating liability storage")
+	}
+	service, err := domainliability.NewService(storage, logger, clk)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating liability service")
+	}
+	handler, err := handlerliability.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating liability handler")
+	}
+	return &LiabilityComposite{
+		Storage: storage,
+		Service: service,
+		Handler: handler,
+	}, nil
+}
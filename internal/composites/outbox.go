@@ -0,0 +1,35 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	dboutbox "github.com/skwol/wallet/internal/adapters/db/outbox"
+	domainoutbox "github.com/skwol/wallet/internal/domain/outbox"
+)
+
+type OutboxComposite struct {
+	Storage   domainoutbox.Storage
+	Service   domainoutbox.Service
+	Publisher domainoutbox.Publisher
+}
+
+func NewOutboxComposite(db *PgDBComposite, publisher domainoutbox.Publisher, logger logging.Logger) (*OutboxComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	storage, err := dboutbox.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating outbox storage")
+	}
+	service, err := domainoutbox.NewService(storage, publisher, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating outbox service")
+	}
+	return &OutboxComposite{
+		Storage:   storage,
+		Service:   service,
+		Publisher: publisher,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerreceipt "github.com/skwol/wallet/internal/adapters/api/receipt"
+	dbreceipt "github.com/skwol/wallet/internal/adapters/db/receipt"
+	domainreceipt "github.com/skwol/wallet/internal/domain/receipt"
+)
+
+type ReceiptComposite struct {
+	Storage domainreceipt.Storage
+	Service domainreceipt.Service
+	Handler adapters.Handler
+}
+
+func NewReceiptComposite(db *PgDBComposite, signer domainreceipt.Signer, logger logging.Logger) (*ReceiptComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	storage, err := dbreceipt.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating receipt storage")
+	}
+	service, err := domainreceipt.NewService(storage, signer, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating receipt service")
+	}
+	handler, err := handlerreceipt.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating receipt handler")
+	}
+	return &ReceiptComposite{
+		Storage: storage,
+		Service: service,
+		Handler: handler,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerreconciliation "github.com/skwol/wallet/internal/adapters/api/reconciliation"
+	dbreconciliation "github.com/skwol/wallet/internal/adapters/db/reconciliation"
+	domainreconciliation "github.com/skwol/wallet/internal/domain/reconciliation"
+)
+
+type ReconciliationComposite struct {
+	Storage domainreconciliation.Storage
+	Service domainreconciliation.Service
+	Handler adapters.Handler
+}
+
+func NewReconciliationComposite(db *PgDBComposite, logger logging.Logger, clk clock.Clock) (*ReconciliationComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	storage, err := dbreconciliation.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating reconciliation storage")
+	}
+	service, err := domainreconciliation.NewService(storage, logger, clk)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating reconciliation service")
+	}
+	handler, err := handlerreconciliation.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating reconciliation handler")
+	}
+	return &ReconciliationComposite{
+		Storage: storage,
+		Service: service,
+		Handler: handler,
+	}, nil
+}
@@ -0,0 +1,47 @@
+package composites
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerreport "github.com/skwol/wallet/internal/adapters/api/report"
+	dbreport "github.com/skwol/wallet/internal/adapters/db/report"
+	domainreport "github.com/skwol/wallet/internal/domain/report"
+)
+
+type ReportComposite struct {
+	Storage domainreport.Storage
+	Service domainreport.Service
+	Handler adapters.Handler
+}
+
+func NewReportComposite(db *PgDBComposite, transactions *TransactionComposite, logger logging.Logger, clk clock.Clock, dir string, retention time.Duration) (*ReportComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	if transactions == nil {
+		return nil, errors.New("missing transaction composite")
+	}
+	storage, err := dbreport.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating report storage")
+	}
+	service, err := domainreport.NewService(storage, transactions.Service, logger, clk, dir, retention)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating report service")
+	}
+	handler, err := handlerreport.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating report handler")
+	}
+	return &ReportComposite{
+		Storage: storage,
+		Service: service,
+		Handler: handler,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerrisk "github.com/skwol/wallet/internal/adapters/api/risk"
+	dbrisk "github.com/skwol/wallet/internal/adapters/db/risk"
+	domainrisk "github.com/skwol/wallet/internal/domain/risk"
+)
+
+type RiskComposite struct {
+	Storage domainrisk.Storage
+	Service domainrisk.Service
+	Handler adapters.Handler
+}
+
+func NewRiskComposite(db *PgDBComposite, logger logging.Logger, clk clock.Clock, engine *domainrisk.Engine) (*RiskComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	storage, err := dbrisk.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating risk storage")
+	}
+	service, err := domainrisk.NewService(storage, logger, clk, engine)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating risk service")
+	}
+	handler, err := handlerrisk.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating risk handler")
+	}
+	return &RiskComposite{
+		Storage: storage,
+		Service: service,
+		Handler: handler,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package composites
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	adapters "github.com/skwol/wallet/internal/adapters/api"
+	handlerscreening "github.com/skwol/wallet/internal/adapters/api/screening"
+	dbscreening "github.com/skwol/wallet/internal/adapters/db/screening"
+	domainscreening "github.com/skwol/wallet/internal/domain/screening"
+)
+
+type ScreeningComposite struct {
+	Storage domainscreening.Storage
+	Service domainscreening.Service
+	Handler adapters.Handler
+}
+
+func NewScreeningComposite(db *PgDBComposite, logger logging.Logger, clk clock.Clock, threshold float64) (*ScreeningComposite, error) {
+	if db == nil {
+		return nil, errors.New("missing db composite")
+	}
+	storage, err := dbscreening.NewStorage(db.client, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating screening storage")
+	}
+	service, err := domainscreening.NewService(storage, logger, clk, threshold)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating screening service")
+	}
+	handler, err := handlerscreening.NewHandler(service, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating screening handler")
+	}
+	return &ScreeningComposite{
+		Storage: storage,
+		Service: service,
+		Handler: handler,
+	}, nil
+}
@@ -18,15 +18,21 @@ type TransferComposite struct {
 	Handler adapters.Handler
 }
 
-func NewTransferComposite(db *PgDBComposite, logger logging.Logger, clk clock.Clock) (*TransferComposite, error) {
+func NewTransferComposite(db *PgDBComposite, approvals *ApprovalComposite, risk *RiskComposite, logger logging.Logger, clk clock.Clock) (*TransferComposite, error) {
 	if db == nil {
 		return nil, errors.New("missing db composite")
 	}
+	if approvals == nil {
+		return nil, errors.New("missing approval composite")
+	}
+	if risk == nil {
+		return nil, errors.New("missing risk composite")
+	}
 	storage, err := dbtransfer.NewStorage(db.client, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating transaction storage")
 	}
-	service, err := domaintransfer.NewService(storage, logger, clk)
+	service, err := domaintransfer.NewService(storage, approvals.Service, risk.Service, logger, clk)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating transaction service")
 	}
@@ -18,19 +18,28 @@ type WalletComposite struct {
 	Handler adapters.Handler
 }
 
-func NewWalletComposite(db *PgDBComposite, logger logging.Logger) (*WalletComposite, error) {
+func NewWalletComposite(db *PgDBComposite, approvals *ApprovalComposite, risk *RiskComposite, screening *ScreeningComposite, logger logging.Logger, currency string) (*WalletComposite, error) {
 	if db == nil {
 		return nil, errors.New("missing db composite")
 	}
+	if approvals == nil {
+		return nil, errors.New("missing approval composite")
+	}
+	if risk == nil {
+		return nil, errors.New("missing risk composite")
+	}
+	if screening == nil {
+		return nil, errors.New("missing screening composite")
+	}
 	storage, err := dbwallet.NewStorage(db.client, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating wallet storage")
 	}
-	service, err := domainwallet.NewService(storage, logger, clock.Real{})
+	service, err := domainwallet.NewService(storage, approvals.Service, risk.Service, screening.Service, logger, clock.Real{})
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating wallet service")
 	}
-	handler, err := handlerwallet.NewHandler(service, logger)
+	handler, err := handlerwallet.NewHandler(service, logger, currency)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating wallet handler")
 	}
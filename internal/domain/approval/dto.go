@@ -0,0 +1,63 @@
+package approval
+
+import "time"
+
+type DTO struct {
+	ID        int64
+	Operation Operation
+	Amount    float64
+	Payload   []byte
+	Maker     string
+	Checker   string
+	Status    Status
+	Reason    string
+	CreatedAt time.Time
+	DecidedAt time.Time
+	Audit     []AuditDTO
+}
+
+func (d DTO) toModel() *Request {
+	return &Request{
+		ID:        d.ID,
+		Operation: d.Operation,
+		Amount:    d.Amount,
+		Payload:   d.Payload,
+		Maker:     d.Maker,
+		Checker:   d.Checker,
+		Status:    d.Status,
+		Reason:    d.Reason,
+		CreatedAt: d.CreatedAt,
+		DecidedAt: d.DecidedAt,
+	}
+}
+
+// SubmitDTO is an operation captured by the maker, Payload is everything needed to execute it later.
+type SubmitDTO struct {
+	Operation Operation
+	Amount    float64
+	Payload   []byte
+	Maker     string
+}
+
+func (d SubmitDTO) validate() error {
+	if !d.Operation.valid() {
+		return ErrUnknownOperation
+	}
+	if d.Maker == "" {
+		return ErrMissingActor
+	}
+	if len(d.Payload) == 0 {
+		return ErrMissingPayload
+	}
+	return nil
+}
+
+// AuditDTO is a record of who did what with the approval request.
+type AuditDTO struct {
+	ID         int64
+	Actor      string
+	Action     Action
+	ApprovalID int64
+	Details    string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,202 @@
+package approval
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	OperationTransfer      Operation = "transfer"
+	OperationSplitPayment  Operation = "split_payment"
+	OperationWalletUpdate  Operation = "wallet_update"
+	OperationWalletClose   Operation = "wallet_close"
+	OperationEscrowFund    Operation = "escrow_fund"
+	OperationEscrowRelease Operation = "escrow_release"
+)
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+	StatusFailed   Status = "failed"
+)
+
+const (
+	ActionSubmit  Action = "submit"
+	ActionApprove Action = "approve"
+	ActionReject  Action = "reject"
+	ActionDenied  Action = "denied"
+)
+
+var (
+	ErrUnknownOperation = errors.New("unknown operation")
+	ErrMissingActor     = errors.New("missing actor")
+	ErrMissingPayload   = errors.New("missing operation payload")
+	ErrMissingReason    = errors.New("reject reason can not be empty")
+	ErrSelfApproval     = errors.New("operation can not be approved or rejected by its maker")
+	ErrAlreadyDecided   = errors.New("approval request is already decided")
+	ErrNegativeAmount   = errors.New("approval threshold can not be less then 0")
+)
+
+// PendingError is returned by the gated operation that was put into the approval queue instead of being executed.
+type PendingError struct {
+	Request DTO
+}
+
+func (e *PendingError) Error() string {
+	return fmt.Sprintf("%s operation is waiting for approval request %d", e.Request.Operation, e.Request.ID)
+}
+
+// AsPending tells whether err comes from the operation that was put into the approval queue.
+func AsPending(err error) (DTO, bool) {
+	var pending *PendingError
+	if errors.As(err, &pending) {
+		return pending.Request, true
+	}
+	return DTO{}, false
+}
+
+type Operation string
+
+func (o Operation) valid() bool {
+	switch o {
+	case OperationTransfer, OperationSplitPayment, OperationWalletUpdate, OperationWalletClose,
+		OperationEscrowFund, OperationEscrowRelease:
+		return true
+	}
+	return false
+}
+
+type Status string
+
+type Action string
+
+// Rules keeps amount threshold per operation, operation with amount at or above the threshold
+// needs approval, operation missing in rules is executed right away.
+type Rules map[Operation]float64
+
+// DefaultRules send every balance adjustment and closure to approval, transfers and escrows from 1000.
+var DefaultRules = Rules{
+	OperationTransfer:      1000,
+	OperationSplitPayment:  1000,
+	OperationWalletUpdate:  0,
+	OperationWalletClose:   0,
+	OperationEscrowFund:    1000,
+	OperationEscrowRelease: 1000,
+}
+
+// ParseRules reads rules from json object like {"transfer": 1000, "wallet_close": 0}.
+func ParseRules(data []byte) (Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, errors.Wrap(err, "error parsing approval rules")
+	}
+	for operation, threshold := range rules {
+		if !operation.valid() {
+			return nil, errors.Wrapf(ErrUnknownOperation, "approval rule %q", operation)
+		}
+		if threshold < 0 {
+			return nil, errors.Wrapf(ErrNegativeAmount, "approval rule %q", operation)
+		}
+	}
+	return rules, nil
+}
+
+// Required tells whether the operation needs approval, wallet update that does not adjust
+// the balance only renames the wallet and never needs it.
+func (r Rules) Required(operation Operation, amount float64) bool {
+	if operation == OperationWalletUpdate && amount <= 0 {
+		return false
+	}
+	threshold, ok := r[operation]
+	return ok && amount >= threshold
+}
+
+// Request is an operation waiting for the second person to approve it.
+type Request struct {
+	ID        int64
+	Operation Operation
+	Amount    float64
+	Payload   []byte
+	Maker     string
+	Checker   string
+	Status    Status
+	Reason    string
+	CreatedAt time.Time
+	DecidedAt time.Time
+}
+
+func newRequest(dto *SubmitDTO, timestamp time.Time) (*Request, error) {
+	if err := dto.validate(); err != nil {
+		return nil, err
+	}
+	return &Request{
+		Operation: dto.Operation,
+		Amount:    dto.Amount,
+		Payload:   dto.Payload,
+		Maker:     dto.Maker,
+		Status:    StatusPending,
+		CreatedAt: timestamp,
+	}, nil
+}
+
+func (r *Request) decide(checker string) error {
+	if checker == "" {
+		return ErrMissingActor
+	}
+	if r.Status != StatusPending {
+		return ErrAlreadyDecided
+	}
+	if checker == r.Maker {
+		return ErrSelfApproval
+	}
+	return nil
+}
+
+func (r *Request) Approve(checker string, timestamp time.Time) error {
+	if err := r.decide(checker); err != nil {
+		return err
+	}
+	r.Checker = checker
+	r.Status = StatusApproved
+	r.DecidedAt = timestamp
+	return nil
+}
+
+func (r *Request) Reject(checker, reason string, timestamp time.Time) error {
+	if err := r.decide(checker); err != nil {
+		return err
+	}
+	if reason == "" {
+		return ErrMissingReason
+	}
+	r.Checker = checker
+	r.Status = StatusRejected
+	r.Reason = reason
+	r.DecidedAt = timestamp
+	return nil
+}
+
+// Fail marks approved request which operation could not be executed.
+func (r *Request) Fail(err error) {
+	r.Status = StatusFailed
+	r.Reason = err.Error()
+}
+
+func (r *Request) toDTO() *DTO {
+	return &DTO{
+		ID:        r.ID,
+		Operation: r.Operation,
+		Amount:    r.Amount,
+		Payload:   r.Payload,
+		Maker:     r.Maker,
+		Checker:   r.Checker,
+		Status:    r.Status,
+		Reason:    r.Reason,
+		CreatedAt: r.CreatedAt,
+		DecidedAt: r.DecidedAt,
+	}
+}
@@ -0,0 +1,218 @@
+package approval
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Rules
+		wantErr bool
+	}{
+		{
+			name: "test ok",
+			data: `{"transfer": 500, "wallet_close": 0}`,
+			want: Rules{OperationTransfer: 500, OperationWalletClose: 0},
+		},
+		{
+			name:    "test unknown operation",
+			data:    `{"withdraw": 500}`,
+			wantErr: true,
+		},
+		{
+			name:    "test negative threshold",
+			data:    `{"transfer": -1}`,
+			wantErr: true,
+		},
+		{
+			name:    "test invalid json",
+			data:    `transfer=500`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRules([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRules_Required(t *testing.T) {
+	rules := Rules{OperationTransfer: 1000, OperationWalletClose: 0, OperationWalletUpdate: 0, OperationEscrowRelease: 1000}
+	tests := []struct {
+		name      string
+		operation Operation
+		amount    float64
+		want      bool
+	}{
+		{name: "test below threshold", operation: OperationTransfer, amount: 999.9999, want: false},
+		{name: "test at threshold", operation: OperationTransfer, amount: 1000, want: true},
+		{name: "test zero threshold", operation: OperationWalletClose, amount: 0, want: true},
+		{name: "test operation without rule", operation: OperationSplitPayment, amount: 1000000, want: false},
+		{name: "test wallet update without adjustment", operation: OperationWalletUpdate, amount: 0, want: false},
+		{name: "test wallet update with adjustment", operation: OperationWalletUpdate, amount: 0.0001, want: true},
+		{name: "test escrow release", operation: OperationEscrowRelease, amount: 1000, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.Required(tt.operation, tt.amount); got != tt.want {
+				t.Errorf("Rules.Required() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_Approve(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name    string
+		request *Request
+		checker string
+		want    *Request
+		wantErr error
+	}{
+		{
+			name:    "test missing checker",
+			request: &Request{ID: 1, Maker: "alice", Status: StatusPending},
+			checker: "",
+			wantErr: errors.New("missing actor"),
+		},
+		{
+			name:    "test maker approves own operation",
+			request: &Request{ID: 1, Maker: "alice", Status: StatusPending},
+			checker: "alice",
+			wantErr: errors.New("operation can not be approved or rejected by its maker"),
+		},
+		{
+			name:    "test already rejected",
+			request: &Request{ID: 1, Maker: "alice", Status: StatusRejected},
+			checker: "bob",
+			wantErr: errors.New("approval request is already decided"),
+		},
+		{
+			name:    "test ok",
+			request: &Request{ID: 1, Maker: "alice", Status: StatusPending},
+			checker: "bob",
+			want:    &Request{ID: 1, Maker: "alice", Checker: "bob", Status: StatusApproved, DecidedAt: clk.Now()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Approve(tt.checker, clk.Now())
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("Request.Approve() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Request.Approve() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.request, tt.want) {
+				t.Errorf("Request.Approve() = %v, want %v", tt.request, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequest_Reject(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name    string
+		request *Request
+		checker string
+		reason  string
+		want    *Request
+		wantErr error
+	}{
+		{
+			name:    "test missing reason",
+			request: &Request{ID: 1, Maker: "alice", Status: StatusPending},
+			checker: "bob",
+			wantErr: errors.New("reject reason can not be empty"),
+		},
+		{
+			name:    "test maker rejects own operation",
+			request: &Request{ID: 1, Maker: "alice", Status: StatusPending},
+			checker: "alice",
+			reason:  "changed my mind",
+			wantErr: errors.New("operation can not be approved or rejected by its maker"),
+		},
+		{
+			name:    "test ok",
+			request: &Request{ID: 1, Maker: "alice", Status: StatusPending},
+			checker: "bob",
+			reason:  "amount looks wrong",
+			want:    &Request{ID: 1, Maker: "alice", Checker: "bob", Status: StatusRejected, Reason: "amount looks wrong", DecidedAt: clk.Now()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Reject(tt.checker, tt.reason, clk.Now())
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("Request.Reject() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Request.Reject() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.request, tt.want) {
+				t.Errorf("Request.Reject() = %v, want %v", tt.request, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsPending(t *testing.T) {
+	request := DTO{ID: 1, Operation: OperationTransfer, Maker: "alice", Status: StatusPending}
+	tests := []struct {
+		name   string
+		err    error
+		want   DTO
+		wantOk bool
+	}{
+		{
+			name:   "test pending",
+			err:    &PendingError{Request: request},
+			want:   request,
+			wantOk: true,
+		},
+		{
+			name:   "test wrapped pending",
+			err:    errors.Wrap(&PendingError{Request: request}, "error updating escrow model"),
+			want:   request,
+			wantOk: true,
+		},
+		{
+			name: "test other error",
+			err:  ErrMissingActor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := AsPending(tt.err)
+			if ok != tt.wantOk {
+				t.Fatalf("AsPending() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AsPending() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
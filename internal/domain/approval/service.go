@@ -0,0 +1,172 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/actor"
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+// Executor runs the operation captured in the payload once it is approved.
+type Executor func(ctx context.Context, payload []byte) error
+
+// Service lets the second person approve operations of the first one. Maker and checker are always
+// the actor of the context, so that the caller can not choose who it is.
+type Service interface {
+	// Gate puts the operation into the approval queue when rules require it and returns *PendingError,
+	// nil is returned when the operation should be executed right away. The approved operation passes
+	// the gate when its executor runs it again.
+	Gate(ctx context.Context, operation Operation, amount float64, payload interface{}) error
+	GetByID(context.Context, int64) (DTO, error)
+	GetByStatus(ctx context.Context, status Status, limit int, offset int) ([]DTO, error)
+	Approve(ctx context.Context, id int64) (DTO, error)
+	Reject(ctx context.Context, id int64, reason string) (DTO, error)
+	RegisterExecutor(Operation, Executor)
+}
+
+type service struct {
+	storage   Storage
+	logger    logging.Logger
+	clk       clock.Clock
+	rules     Rules
+	executors map[Operation]Executor
+}
+
+// approvedKey marks the context of the executor with the operation it was approved for.
+type approvedKey struct{}
+
+func NewService(storage Storage, logger logging.Logger, clk clock.Clock, rules Rules) (Service, error) {
+	return &service{storage: storage, logger: logger, clk: clk, rules: rules, executors: make(map[Operation]Executor)}, nil
+}
+
+// RegisterExecutor is expected to be called on startup before requests are served.
+func (s *service) RegisterExecutor(operation Operation, executor Executor) {
+	s.executors[operation] = executor
+}
+
+func (s *service) Gate(ctx context.Context, operation Operation, amount float64, payload interface{}) error {
+	if approved, ok := ctx.Value(approvedKey{}).(Operation); ok && approved == operation {
+		return nil
+	}
+	if !s.rules.Required(operation, amount) {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		s.logger.Errorf("error marshaling operation payload: %s", err.Error())
+		return errors.Wrap(err, "error marshaling operation payload")
+	}
+	dto := SubmitDTO{Operation: operation, Amount: amount, Payload: data, Maker: actor.FromContext(ctx)}
+	requestModel, err := newRequest(&dto, s.clk.Now())
+	if err != nil {
+		s.logger.Errorf("error creating approval request model: %s", err.Error())
+		return errors.Wrap(err, "error creating approval request model")
+	}
+	audit := AuditDTO{Actor: dto.Maker, Action: ActionSubmit, CreatedAt: requestModel.CreatedAt}
+	result, err := s.storage.Create(ctx, requestModel.toDTO(), audit)
+	if err != nil {
+		s.logger.Errorf("error creating approval request in db: %s", err.Error())
+		return errors.Wrap(err, "error creating approval request in db")
+	}
+	if result.ID == 0 {
+		s.logger.Errorf("empty approval request returned from db")
+		return errors.New("empty approval request returned from db")
+	}
+	return &PendingError{Request: result}
+}
+
+func (s *service) GetByID(ctx context.Context, id int64) (DTO, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *service) GetByStatus(ctx context.Context, status Status, limit int, offset int) ([]DTO, error) {
+	return s.storage.GetByStatus(ctx, status, limit, offset)
+}
+
+func (s *service) Approve(ctx context.Context, id int64) (DTO, error) {
+	checker := actor.FromContext(ctx)
+	requestModel, err := s.get(ctx, id)
+	if err != nil {
+		return DTO{}, err
+	}
+	executor, ok := s.executors[requestModel.Operation]
+	if !ok {
+		s.logger.Errorf("missing executor for %s operation", requestModel.Operation)
+		return DTO{}, errors.Errorf("missing executor for %s operation", requestModel.Operation)
+	}
+	now := s.clk.Now()
+	if err := requestModel.Approve(checker, now); err != nil {
+		s.deny(ctx, id, checker, err)
+		return DTO{}, errors.Wrap(err, "error approving request")
+	}
+	if err := s.decide(ctx, requestModel, AuditDTO{Actor: checker, Action: ActionApprove, ApprovalID: id, CreatedAt: now}); err != nil {
+		return DTO{}, err
+	}
+
+	if err := executor(context.WithValue(ctx, approvedKey{}, requestModel.Operation), requestModel.Payload); err != nil {
+		s.logger.Errorf("error executing approved %s operation: %s", requestModel.Operation, err.Error())
+		requestModel.Fail(err)
+		if err := s.storage.Update(ctx, requestModel.toDTO()); err != nil {
+			s.logger.Errorf("error updating approval request in db: %s", err.Error())
+		}
+		return *requestModel.toDTO(), errors.Wrap(err, "error executing approved operation")
+	}
+	return *requestModel.toDTO(), nil
+}
+
+func (s *service) Reject(ctx context.Context, id int64, reason string) (DTO, error) {
+	checker := actor.FromContext(ctx)
+	requestModel, err := s.get(ctx, id)
+	if err != nil {
+		return DTO{}, err
+	}
+	now := s.clk.Now()
+	if err := requestModel.Reject(checker, reason, now); err != nil {
+		s.deny(ctx, id, checker, err)
+		return DTO{}, errors.Wrap(err, "error rejecting request")
+	}
+	if err := s.decide(ctx, requestModel, AuditDTO{Actor: checker, Action: ActionReject, ApprovalID: id, Details: reason, CreatedAt: now}); err != nil {
+		return DTO{}, err
+	}
+	return *requestModel.toDTO(), nil
+}
+
+func (s *service) get(ctx context.Context, id int64) (*Request, error) {
+	requestInDB, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Errorf("error getting approval request from db: %s", err.Error())
+		return nil, errors.Wrap(err, "error getting approval request from db")
+	}
+	if requestInDB.ID == 0 {
+		s.logger.Errorf("missing approval request in db")
+		return nil, errors.New("missing approval request in db")
+	}
+	return requestInDB.toModel(), nil
+}
+
+func (s *service) decide(ctx context.Context, requestModel *Request, audit AuditDTO) error {
+	decided, err := s.storage.Decide(ctx, requestModel.toDTO(), audit)
+	if err != nil {
+		s.logger.Errorf("error updating approval request in db: %s", err.Error())
+		return errors.Wrap(err, "error updating approval request in db")
+	}
+	if !decided {
+		return ErrAlreadyDecided
+	}
+	return nil
+}
+
+// deny keeps track of refused attempts to decide the request, e.g. maker trying to approve own operation.
+func (s *service) deny(ctx context.Context, id int64, actor string, reason error) {
+	if actor == "" {
+		return
+	}
+	audit := AuditDTO{Actor: actor, Action: ActionDenied, ApprovalID: id, Details: reason.Error(), CreatedAt: s.clk.Now()}
+	if err := s.storage.AddAudit(ctx, audit); err != nil {
+		s.logger.Errorf("error adding audit record: %s", err.Error())
+	}
+}
@@ -0,0 +1,13 @@
+package approval
+
+import "context"
+
+type Storage interface {
+	Create(context.Context, *DTO, AuditDTO) (DTO, error)
+	GetByID(context.Context, int64) (DTO, error)
+	GetByStatus(ctx context.Context, status Status, limit int, offset int) ([]DTO, error)
+	// Decide stores decision of the pending request, it returns false when request was already decided.
+	Decide(context.Context, *DTO, AuditDTO) (bool, error)
+	Update(context.Context, *DTO) error
+	AddAudit(context.Context, AuditDTO) error
+}
@@ -0,0 +1,48 @@
+package chain
+
+import "time"
+
+// LinkDTO is the chained transaction, Amount is kept the way the database prints it so that it hashes the same way.
+type LinkDTO struct {
+	Seq             int64
+	TransactionID   int64
+	SenderID        int64
+	ReceiverID      int64
+	Amount          string
+	Date            time.Time
+	Type            string
+	ParentPaymentID *int64
+	Hash            []byte
+}
+
+func (d LinkDTO) toModel() Link {
+	return Link(d)
+}
+
+// HeadDTO is the last link of the chain, Seq is 0 and Hash is GenesisHash until the first transaction is chained.
+type HeadDTO struct {
+	Seq  int64
+	Hash []byte
+}
+
+// AnchorDTO is the head of the chain recorded at CreatedAt for external notarisation.
+type AnchorDTO struct {
+	Seq           int64
+	TransactionID int64
+	Hash          []byte
+	CreatedAt     time.Time
+}
+
+// VerifyDTO is the walk over the chain, Broken is the first link which does not verify.
+type VerifyDTO struct {
+	Links   int64
+	Anchors int64
+	Head    HeadDTO
+	Broken  *BreakDTO
+}
+
+type BreakDTO struct {
+	Seq           int64
+	TransactionID int64
+	Reason        string
+}
@@ -0,0 +1,99 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// linksPage is how many links are verified at once.
+const linksPage = 1000
+
+// dateLayout keeps the microseconds the database stores dates with.
+const dateLayout = "2006-01-02T15:04:05.000000Z"
+
+// GenesisHash is the hash the first link is chained to.
+var GenesisHash = make([]byte, sha256.Size)
+
+var ErrChainBroken = errors.New("transaction chain is broken")
+
+// Link is the transaction in the chain.
+type Link struct {
+	Seq             int64
+	TransactionID   int64
+	SenderID        int64
+	ReceiverID      int64
+	Amount          string
+	Date            time.Time
+	Type            string
+	ParentPaymentID *int64
+	Hash            []byte
+}
+
+// canonical joins the fields of the link which can not change once the transaction is written.
+func (l Link) canonical() []byte {
+	parent := ""
+	if l.ParentPaymentID != nil {
+		parent = strconv.FormatInt(*l.ParentPaymentID, 10)
+	}
+	return []byte(fmt.Sprintf("%d|%d|%d|%d|%s|%s|%s|%s", l.Seq, l.TransactionID, l.SenderID, l.ReceiverID, l.Amount,
+		l.Date.UTC().Format(dateLayout), l.Type, parent))
+}
+
+// NextHash is the hash of the link chained to the previous hash.
+func NextHash(prev []byte, dto LinkDTO) []byte {
+	h := sha256.New()
+	h.Write(prev)
+	h.Write(dto.toModel().canonical())
+	return h.Sum(nil)
+}
+
+// verifier walks the chain in order, anchors are the hashes recorded for links.
+type verifier struct {
+	seq     int64
+	hash    []byte
+	anchors map[int64][]byte
+}
+
+func newVerifier(anchors []AnchorDTO) *verifier {
+	v := &verifier{hash: GenesisHash, anchors: make(map[int64][]byte, len(anchors))}
+	for _, anchor := range anchors {
+		v.anchors[anchor.Seq] = anchor.Hash
+	}
+	return v
+}
+
+// next checks that the link follows the previous one, it returns where the chain breaks otherwise.
+func (v *verifier) next(dto LinkDTO) *BreakDTO {
+	if dto.Seq != v.seq+1 {
+		return &BreakDTO{Seq: v.seq + 1, Reason: fmt.Sprintf("link %d is missing, the next one is %d", v.seq+1, dto.Seq)}
+	}
+	if !bytes.Equal(NextHash(v.hash, dto), dto.Hash) {
+		return &BreakDTO{Seq: dto.Seq, TransactionID: dto.TransactionID, Reason: "transaction does not match its hash"}
+	}
+	if anchor, ok := v.anchors[dto.Seq]; ok && !bytes.Equal(anchor, dto.Hash) {
+		return &BreakDTO{Seq: dto.Seq, TransactionID: dto.TransactionID, Reason: "hash does not match its anchor"}
+	}
+	v.seq, v.hash = dto.Seq, dto.Hash
+	return nil
+}
+
+// end checks that the chain ends at its head and no anchor is past it.
+func (v *verifier) end(head HeadDTO) *BreakDTO {
+	if v.seq != head.Seq {
+		return &BreakDTO{Seq: v.seq + 1, Reason: fmt.Sprintf("link %d is missing, the head is %d", v.seq+1, head.Seq)}
+	}
+	if !bytes.Equal(v.hash, head.Hash) {
+		return &BreakDTO{Seq: head.Seq, Reason: "last link does not match the head"}
+	}
+	for seq := range v.anchors {
+		if seq > head.Seq {
+			return &BreakDTO{Seq: seq, Reason: "anchored link is past the head"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package chain
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// newLinks chains n deposits the way the storage does.
+func newLinks(n int) []LinkDTO {
+	links := make([]LinkDTO, n)
+	prev := GenesisHash
+	for i := range links {
+		links[i] = LinkDTO{
+			Seq:           int64(i + 1),
+			TransactionID: int64(i + 10),
+			SenderID:      1,
+			ReceiverID:    1,
+			Amount:        "100.0000",
+			Date:          time.Date(2022, 5, 1, 10, i, 0, 0, time.UTC),
+			Type:          "deposit",
+		}
+		links[i].Hash = NextHash(prev, links[i])
+		prev = links[i].Hash
+	}
+	return links
+}
+
+func TestNextHash(t *testing.T) {
+	link := newLinks(1)[0]
+	parent := int64(7)
+	tests := []struct {
+		name   string
+		prev   []byte
+		change func(*LinkDTO)
+		same   bool
+	}{
+		{name: "same link", prev: GenesisHash, change: func(*LinkDTO) {}, same: true},
+		{name: "same date in other zone", prev: GenesisHash, change: func(l *LinkDTO) { l.Date = l.Date.In(time.FixedZone("", 3600)) }, same: true},
+		{name: "hash is not hashed", prev: GenesisHash, change: func(l *LinkDTO) { l.Hash = nil }, same: true},
+		{name: "other previous hash", prev: bytes.Repeat([]byte{1}, 32), change: func(*LinkDTO) {}},
+		{name: "other amount", prev: GenesisHash, change: func(l *LinkDTO) { l.Amount = "100.0001" }},
+		{name: "other receiver", prev: GenesisHash, change: func(l *LinkDTO) { l.ReceiverID = 2 }},
+		{name: "other date", prev: GenesisHash, change: func(l *LinkDTO) { l.Date = l.Date.Add(time.Microsecond) }},
+		{name: "other type", prev: GenesisHash, change: func(l *LinkDTO) { l.Type = "withdraw" }},
+		{name: "parent payment", prev: GenesisHash, change: func(l *LinkDTO) { l.ParentPaymentID = &parent }},
+		{name: "other seq", prev: GenesisHash, change: func(l *LinkDTO) { l.Seq = 2 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := link
+			tt.change(&changed)
+			if got := bytes.Equal(NextHash(tt.prev, changed), link.Hash); got != tt.same {
+				t.Errorf("NextHash() equal = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func([]LinkDTO) []LinkDTO
+		anchors func([]LinkDTO) []AnchorDTO
+		head    func([]LinkDTO) HeadDTO
+		broken  int64
+	}{
+		{
+			name:   "intact",
+			change: func(links []LinkDTO) []LinkDTO { return links },
+		},
+		{
+			name:    "intact with anchors",
+			change:  func(links []LinkDTO) []LinkDTO { return links },
+			anchors: func(links []LinkDTO) []AnchorDTO { return []AnchorDTO{{Seq: 2, Hash: links[1].Hash}} },
+		},
+		{
+			name: "edited amount",
+			change: func(links []LinkDTO) []LinkDTO {
+				links[1].Amount = "1000.0000"
+				return links
+			},
+			broken: 2,
+		},
+		{
+			name: "edited and rehashed",
+			change: func(links []LinkDTO) []LinkDTO {
+				links[1].Amount = "1000.0000"
+				links[1].Hash = NextHash(links[0].Hash, links[1])
+				return links
+			},
+			broken: 3,
+		},
+		{
+			name:   "deleted",
+			change: func(links []LinkDTO) []LinkDTO { return append(links[:1], links[2:]...) },
+			broken: 2,
+		},
+		{
+			name:   "deleted last",
+			change: func(links []LinkDTO) []LinkDTO { return links[:2] },
+			broken: 3,
+		},
+		{
+			name:    "rewritten after anchor",
+			change:  func(links []LinkDTO) []LinkDTO { return links },
+			anchors: func(links []LinkDTO) []AnchorDTO { return []AnchorDTO{{Seq: 2, Hash: links[0].Hash}} },
+			broken:  2,
+		},
+		{
+			name:   "head does not match",
+			change: func(links []LinkDTO) []LinkDTO { return links },
+			head:   func(links []LinkDTO) HeadDTO { return HeadDTO{Seq: 3, Hash: links[1].Hash} },
+			broken: 3,
+		},
+		{
+			name:    "anchor past head",
+			change:  func(links []LinkDTO) []LinkDTO { return links },
+			anchors: func(links []LinkDTO) []AnchorDTO { return []AnchorDTO{{Seq: 4, Hash: links[2].Hash}} },
+			broken:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := newLinks(3)
+			head := HeadDTO{Seq: 3, Hash: original[2].Hash}
+			if tt.head != nil {
+				head = tt.head(original)
+			}
+			var anchors []AnchorDTO
+			if tt.anchors != nil {
+				anchors = tt.anchors(original)
+			}
+			links := tt.change(newLinks(3))
+
+			v := newVerifier(anchors)
+			var broken *BreakDTO
+			for _, link := range links {
+				if broken = v.next(link); broken != nil {
+					break
+				}
+			}
+			if broken == nil {
+				broken = v.end(head)
+			}
+			switch {
+			case tt.broken == 0 && broken != nil:
+				t.Errorf("chain broken at %d: %s", broken.Seq, broken.Reason)
+			case tt.broken != 0 && broken == nil:
+				t.Errorf("chain verified, want broken at %d", tt.broken)
+			case tt.broken != 0 && broken.Seq != tt.broken:
+				t.Errorf("chain broken at %d, want %d", broken.Seq, tt.broken)
+			}
+		})
+	}
+}
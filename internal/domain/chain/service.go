@@ -0,0 +1,87 @@
+package chain
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+type Service interface {
+	// Verify walks the chain from the first link to the head and reports the first link which does not verify,
+	// transactions not chained yet are not part of it.
+	Verify(context.Context) (VerifyDTO, error)
+	// Anchor records the head of the chain, it is the job which makes anchors for notarisation.
+	Anchor(context.Context) error
+	GetAnchors(ctx context.Context, from, to time.Time) ([]AnchorDTO, error)
+}
+
+type service struct {
+	storage Storage
+	logger  logging.Logger
+	clk     clock.Clock
+}
+
+func NewService(storage Storage, logger logging.Logger, clk clock.Clock) (Service, error) {
+	return &service{storage: storage, logger: logger, clk: clk}, nil
+}
+
+func (s *service) Verify(ctx context.Context) (VerifyDTO, error) {
+	var result VerifyDTO
+	var err error
+	// links after the head are chained while the walk goes, they are left to the next one
+	if result.Head, err = s.storage.GetHead(ctx); err != nil {
+		s.logger.Errorf("error getting chain head from db: %s", err.Error())
+		return VerifyDTO{}, errors.Wrap(err, "error getting chain head from db")
+	}
+	anchors, err := s.storage.GetAnchors(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		s.logger.Errorf("error getting chain anchors from db: %s", err.Error())
+		return VerifyDTO{}, errors.Wrap(err, "error getting chain anchors from db")
+	}
+	result.Anchors = int64(len(anchors))
+
+	v := newVerifier(anchors)
+	for v.seq < result.Head.Seq {
+		links, err := s.storage.GetLinks(ctx, v.seq, result.Head.Seq, linksPage)
+		if err != nil {
+			s.logger.Errorf("error getting chain links from db: %s", err.Error())
+			return VerifyDTO{}, errors.Wrap(err, "error getting chain links from db")
+		}
+		if len(links) == 0 {
+			break
+		}
+		for _, link := range links {
+			if result.Broken = v.next(link); result.Broken != nil {
+				return result, nil
+			}
+			result.Links++
+		}
+	}
+	result.Broken = v.end(result.Head)
+	return result, nil
+}
+
+func (s *service) Anchor(ctx context.Context) error {
+	anchor, ok, err := s.storage.CreateAnchor(ctx, s.clk.Now())
+	if err != nil {
+		s.logger.Errorf("error anchoring chain: %s", err.Error())
+		return errors.Wrap(err, "error anchoring chain")
+	}
+	if ok {
+		s.logger.Infof("chain anchored at link %d", anchor.Seq)
+	}
+	return nil
+}
+
+func (s *service) GetAnchors(ctx context.Context, from, to time.Time) ([]AnchorDTO, error) {
+	anchors, err := s.storage.GetAnchors(ctx, from, to)
+	if err != nil {
+		s.logger.Errorf("error getting chain anchors from db: %s", err.Error())
+		return nil, errors.Wrap(err, "error getting chain anchors from db")
+	}
+	return anchors, nil
+}
@@ -0,0 +1,17 @@
+package chain
+
+import (
+	"context"
+	"time"
+)
+
+type Storage interface {
+	// GetHead returns the last link of the chain.
+	GetHead(ctx context.Context) (HeadDTO, error)
+	// GetLinks returns up to limit links with seq greater than afterSeq and up to lastSeq in seq order.
+	GetLinks(ctx context.Context, afterSeq, lastSeq int64, limit int) ([]LinkDTO, error)
+	// CreateAnchor records the head of the chain unless it is anchored already, ok is false then.
+	CreateAnchor(ctx context.Context, createdAt time.Time) (anchor AnchorDTO, ok bool, err error)
+	// GetAnchors returns anchors created within the period in seq order, zero times leave it open.
+	GetAnchors(ctx context.Context, from, to time.Time) ([]AnchorDTO, error)
+}
@@ -0,0 +1,32 @@
+package closing
+
+import "time"
+
+// CloseDTO is the closed day, Discrepancies are the wallets which kept it open.
+type CloseDTO struct {
+	Day           time.Time
+	Wallets       int64
+	ClosedAt      time.Time
+	Discrepancies []DiscrepancyDTO
+}
+
+// SnapshotDTO is the balance of the wallet at the end of Day. Balance is taken from the wallet without movements
+// after the day and Expected is the previous snapshot with movements since it, LastTransactionID is the greatest
+// transaction id seen by the snapshot.
+type SnapshotDTO struct {
+	WalletID          int64
+	Day               time.Time
+	Balance           float64
+	Expected          float64
+	LastTransactionID int64
+}
+
+func (d SnapshotDTO) toModel() Snapshot {
+	return Snapshot(d)
+}
+
+type DiscrepancyDTO struct {
+	WalletID int64
+	Balance  float64
+	Expected float64
+}
@@ -0,0 +1,91 @@
+package closing
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SnapshotDelay is how long after the end of a day it is closed,
+// transactions of the day which are still being written by then are not lost by its snapshots.
+const SnapshotDelay = 10 * time.Minute
+
+// snapshotsPage is how many wallets are snapshotted at once.
+const snapshotsPage = 500
+
+var (
+	ErrDayNotEnded     = errors.New("day has not ended yet")
+	ErrPreviousDayOpen = errors.New("previous day is not closed")
+	ErrPeriodClosed    = errors.New("day is before the first closed day")
+	ErrDiscrepancy     = errors.New("wallet balance does not match its transactions")
+	errDayClosed       = errors.New("day is already closed")
+)
+
+// Snapshot is the balance of the wallet at the end of the UTC day.
+type Snapshot struct {
+	WalletID          int64
+	Day               time.Time
+	Balance           float64
+	Expected          float64
+	LastTransactionID int64
+}
+
+func (s Snapshot) toDTO() SnapshotDTO {
+	return SnapshotDTO(s)
+}
+
+// verified is true when the balance of the wallet is what its transactions add up to.
+func (s Snapshot) verified() bool {
+	return roundAmount(s.Balance) == roundAmount(s.Expected)
+}
+
+// verifySnapshots splits snapshots into verified ones and discrepancies of the rest.
+func verifySnapshots(snapshots []Snapshot) ([]Snapshot, []DiscrepancyDTO) {
+	var (
+		verified      []Snapshot
+		discrepancies []DiscrepancyDTO
+	)
+	for _, s := range snapshots {
+		if !s.verified() {
+			discrepancies = append(discrepancies, DiscrepancyDTO{WalletID: s.WalletID, Balance: roundAmount(s.Balance), Expected: roundAmount(s.Expected)})
+			continue
+		}
+		s.Balance = roundAmount(s.Balance)
+		s.Expected = s.Balance
+		verified = append(verified, s)
+	}
+	return verified, discrepancies
+}
+
+// LastEndedDay is the last UTC day which has ended at least SnapshotDelay before now.
+func LastEndedDay(now time.Time) time.Time {
+	return utcDay(now.Add(-SnapshotDelay)).AddDate(0, 0, -1)
+}
+
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// checkDay allows to close days in order, lastClosed is zero when no day is closed yet.
+func checkDay(day, lastClosed, now time.Time) error {
+	if day.After(LastEndedDay(now)) {
+		return ErrDayNotEnded
+	}
+	if lastClosed.IsZero() {
+		return nil
+	}
+	if !day.After(lastClosed) {
+		return errDayClosed
+	}
+	if day.After(lastClosed.AddDate(0, 0, 1)) {
+		return ErrPreviousDayOpen
+	}
+	return nil
+}
+
+// roundAmount drops the float error below the precision amounts are stored with.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*1e4) / 1e4
+}
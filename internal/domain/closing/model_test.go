@@ -0,0 +1,131 @@
+package closing
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+)
+
+func Test_checkDay(t *testing.T) {
+	clk := clock.NewFake(time.Date(2022, 5, 10, 10, 0, 0, 0, time.UTC))
+	day := time.Date(2022, 5, 5, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		day        time.Time
+		lastClosed time.Time
+		now        time.Time
+		wantErr    error
+	}{
+		{
+			name: "test first close",
+			day:  day,
+			now:  clk.Now(),
+		},
+		{
+			name:       "test next day",
+			day:        day,
+			lastClosed: day.AddDate(0, 0, -1),
+			now:        clk.Now(),
+		},
+		{
+			name:       "test closed day",
+			day:        day,
+			lastClosed: day,
+			now:        clk.Now(),
+			wantErr:    errDayClosed,
+		},
+		{
+			name:       "test day before closed one",
+			day:        day.AddDate(0, 0, -3),
+			lastClosed: day,
+			now:        clk.Now(),
+			wantErr:    errDayClosed,
+		},
+		{
+			name:       "test day after open one",
+			day:        day,
+			lastClosed: day.AddDate(0, 0, -2),
+			now:        clk.Now(),
+			wantErr:    ErrPreviousDayOpen,
+		},
+		{
+			name:    "test today",
+			day:     day,
+			now:     day.Add(12 * time.Hour),
+			wantErr: ErrDayNotEnded,
+		},
+		{
+			name:    "test right after the day",
+			day:     day,
+			now:     day.AddDate(0, 0, 1).Add(SnapshotDelay / 2),
+			wantErr: ErrDayNotEnded,
+		},
+		{
+			name: "test after snapshot delay",
+			day:  day,
+			now:  day.AddDate(0, 0, 1).Add(SnapshotDelay),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkDay(tt.day, tt.lastClosed, tt.now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkDay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_verifySnapshots(t *testing.T) {
+	day := time.Date(2022, 5, 5, 0, 0, 0, 0, time.UTC)
+	matching := Snapshot{WalletID: 1, Day: day, Balance: 10.30000001, Expected: 10.3, LastTransactionID: 7}
+	zero := Snapshot{WalletID: 2, Day: day, LastTransactionID: 7}
+	drifted := Snapshot{WalletID: 3, Day: day, Balance: 15, Expected: 14.9, LastTransactionID: 7}
+	verified, discrepancies := verifySnapshots([]Snapshot{matching, zero, drifted})
+
+	wantVerified := []Snapshot{
+		{WalletID: 1, Day: day, Balance: 10.3, Expected: 10.3, LastTransactionID: 7},
+		zero,
+	}
+	if !reflect.DeepEqual(verified, wantVerified) {
+		t.Errorf("verifySnapshots() verified = %v, want %v", verified, wantVerified)
+	}
+	wantDiscrepancies := []DiscrepancyDTO{{WalletID: 3, Balance: 15, Expected: 14.9}}
+	if !reflect.DeepEqual(discrepancies, wantDiscrepancies) {
+		t.Errorf("verifySnapshots() discrepancies = %v, want %v", discrepancies, wantDiscrepancies)
+	}
+}
+
+func TestLastEndedDay(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "test day before",
+			now:  time.Date(2022, 5, 2, 10, 0, 0, 0, time.UTC),
+			want: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "test right after midnight",
+			now:  time.Date(2022, 5, 2, 0, 5, 0, 0, time.UTC),
+			want: time.Date(2022, 4, 30, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "test other time zone",
+			now:  time.Date(2022, 5, 2, 1, 0, 0, 0, time.FixedZone("EEST", 3*60*60)),
+			want: time.Date(2022, 4, 30, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LastEndedDay(tt.now); !got.Equal(tt.want) {
+				t.Errorf("LastEndedDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package closing
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+type Service interface {
+	// CloseDay snapshots the balance of every wallet at the end of the UTC day, verifies it against the previous snapshot
+	// and the transactions since and marks the day closed, transactions are not accepted into closed days.
+	// The day which is closed already is returned as it is. With discrepancies the day stays open, verified
+	// snapshots are kept and the next run snapshots only the rest.
+	CloseDay(ctx context.Context, day time.Time) (CloseDTO, error)
+	// CloseDays closes every day after the last closed one which has ended, it starts with the last ended day
+	// when no day is closed yet.
+	CloseDays(ctx context.Context) error
+}
+
+type service struct {
+	storage Storage
+	logger  logging.Logger
+	clk     clock.Clock
+}
+
+func NewService(storage Storage, logger logging.Logger, clk clock.Clock) (Service, error) {
+	return &service{storage: storage, logger: logger, clk: clk}, nil
+}
+
+func (s *service) CloseDay(ctx context.Context, day time.Time) (CloseDTO, error) {
+	day = utcDay(day)
+	now := s.clk.Now()
+	last, err := s.storage.GetLastClose(ctx)
+	if err != nil {
+		s.logger.Errorf("error getting last closed day from db: %s", err.Error())
+		return CloseDTO{}, errors.Wrap(err, "error getting last closed day from db")
+	}
+	switch err := checkDay(day, last.Day, now); {
+	case errors.Is(err, errDayClosed):
+		closed, err := s.storage.GetClose(ctx, day)
+		if err != nil {
+			return CloseDTO{}, errors.Wrap(err, "error getting closed day from db")
+		}
+		if closed.Day.IsZero() {
+			return CloseDTO{}, ErrPeriodClosed
+		}
+		return closed, nil
+	case err != nil:
+		return CloseDTO{}, err
+	}
+
+	var (
+		after         int64
+		discrepancies []DiscrepancyDTO
+	)
+	for {
+		snapshots, err := s.storage.ComputeSnapshots(ctx, day, after, snapshotsPage)
+		if err != nil {
+			s.logger.Errorf("error computing balance snapshots: %s", err.Error())
+			return CloseDTO{}, errors.Wrap(err, "error computing balance snapshots")
+		}
+		if len(snapshots) == 0 {
+			break
+		}
+		models := make([]Snapshot, len(snapshots))
+		for i, snapshot := range snapshots {
+			models[i] = snapshot.toModel()
+		}
+		verified, pageDiscrepancies := verifySnapshots(models)
+		discrepancies = append(discrepancies, pageDiscrepancies...)
+		dtos := make([]SnapshotDTO, len(verified))
+		for i, snapshot := range verified {
+			dtos[i] = snapshot.toDTO()
+		}
+		if err := s.storage.CreateSnapshots(ctx, dtos, now); err != nil {
+			s.logger.Errorf("error creating balance snapshots: %s", err.Error())
+			return CloseDTO{}, errors.Wrap(err, "error creating balance snapshots")
+		}
+		after = snapshots[len(snapshots)-1].WalletID
+	}
+	if len(discrepancies) > 0 {
+		for _, d := range discrepancies {
+			s.logger.Errorf("wallet %d balance %v does not match its transactions %v on %s", d.WalletID, d.Balance, d.Expected, day.Format("2006-01-02"))
+		}
+		return CloseDTO{Day: day, Discrepancies: discrepancies}, errors.Wrapf(ErrDiscrepancy, "%d wallets on %s", len(discrepancies), day.Format("2006-01-02"))
+	}
+
+	if err := s.storage.Create(ctx, day, now); err != nil {
+		s.logger.Errorf("error closing day in db: %s", err.Error())
+		return CloseDTO{}, errors.Wrap(err, "error closing day in db")
+	}
+	closed, err := s.storage.GetClose(ctx, day)
+	if err != nil {
+		return CloseDTO{}, errors.Wrap(err, "error getting closed day from db")
+	}
+	s.logger.Infof("closed %s with %d wallets", day.Format("2006-01-02"), closed.Wallets)
+	return closed, nil
+}
+
+func (s *service) CloseDays(ctx context.Context) error {
+	last, err := s.storage.GetLastClose(ctx)
+	if err != nil {
+		s.logger.Errorf("error getting last closed day from db: %s", err.Error())
+		return errors.Wrap(err, "error getting last closed day from db")
+	}
+	lastEnded := LastEndedDay(s.clk.Now())
+	day := lastEnded
+	if !last.Day.IsZero() {
+		day = last.Day.AddDate(0, 0, 1)
+	}
+	for ; !day.After(lastEnded); day = day.AddDate(0, 0, 1) {
+		if _, err := s.CloseDay(ctx, day); err != nil {
+			return errors.Wrapf(err, "error closing %s", day.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package closing
+
+import (
+	"context"
+	"time"
+)
+
+type Storage interface {
+	// GetLastClose returns the latest closed day, Day is zero when no day is closed yet.
+	GetLastClose(context.Context) (CloseDTO, error)
+	// GetClose returns the close of the day, Day is zero when it is not closed.
+	GetClose(ctx context.Context, day time.Time) (CloseDTO, error)
+	// ComputeSnapshots returns up to limit snapshots of the day for wallets with id greater than afterWalletID
+	// in id order, wallets which already have the snapshot of the day are skipped.
+	ComputeSnapshots(ctx context.Context, day time.Time, afterWalletID int64, limit int) ([]SnapshotDTO, error)
+	// CreateSnapshots keeps the snapshots, the ones already kept are left as they are.
+	CreateSnapshots(ctx context.Context, snapshots []SnapshotDTO, createdAt time.Time) error
+	// Create marks the day closed with the number of its snapshots, the closed day is left as it is.
+	Create(ctx context.Context, day time.Time, closedAt time.Time) error
+}
@@ -0,0 +1,116 @@
+package dispute
+
+import (
+	"time"
+
+	"github.com/skwol/wallet/internal/domain/risk"
+)
+
+type DTO struct {
+	ID                    int64
+	TransactionID         int64
+	Reason                Reason
+	Status                Status
+	HoldWalletID          int64
+	HoldAmount            float64
+	OpenedAt              time.Time
+	ResolvedAt            time.Time
+	ReversalTransactionID int64
+	Evidence              []EvidenceDTO
+	ReversalToApply       *ReversalDTO
+}
+
+func (d DTO) toModel() *Dispute {
+	evidence := make([]Evidence, len(d.Evidence))
+	for i, e := range d.Evidence {
+		evidence[i] = Evidence(e)
+	}
+	return &Dispute{
+		ID:                    d.ID,
+		TransactionID:         d.TransactionID,
+		Reason:                d.Reason,
+		Status:                d.Status,
+		HoldWalletID:          d.HoldWalletID,
+		HoldAmount:            d.HoldAmount,
+		OpenedAt:              d.OpenedAt,
+		ResolvedAt:            d.ResolvedAt,
+		ReversalTransactionID: d.ReversalTransactionID,
+		Evidence:              evidence,
+	}
+}
+
+type OpenDisputeDTO struct {
+	TransactionID int64
+	Reason        Reason
+	Note          string
+	Hold          bool
+}
+
+func (d OpenDisputeDTO) validate() error {
+	if d.TransactionID == 0 {
+		return ErrMissingTransaction
+	}
+	if !d.Reason.valid() {
+		return ErrUnknownReason
+	}
+	return nil
+}
+
+type EvidenceDTO struct {
+	ID        int64
+	Note      string
+	CreatedAt time.Time
+}
+
+type FilterDisputesDTO struct {
+	TransactionIDs []int64
+	WalletIDs      []int64
+	Statuses       []Status
+	Reasons        []Reason
+}
+
+func (d FilterDisputesDTO) validate() error {
+	for _, status := range d.Statuses {
+		if !status.valid() {
+			return ErrUnknownStatus
+		}
+	}
+	for _, reason := range d.Reasons {
+		if !reason.valid() {
+			return ErrUnknownReason
+		}
+	}
+	return nil
+}
+
+type TransactionDTO struct {
+	ID       int64
+	Sender   WalletDTO
+	Receiver WalletDTO
+	Amount   float64
+	Type     string
+}
+
+func (d TransactionDTO) toModel() Transaction {
+	return Transaction{
+		ID:       d.ID,
+		Sender:   Wallet(d.Sender),
+		Receiver: Wallet(d.Receiver),
+		Amount:   d.Amount,
+		Type:     d.Type,
+	}
+}
+
+type WalletDTO struct {
+	ID      int64
+	Balance float64
+}
+
+type ReversalDTO struct {
+	Sender    WalletDTO
+	Receiver  WalletDTO
+	Amount    float64
+	Timestamp time.Time
+	// Risk is the decision on the reversal, written together with it
+	Risk *risk.DecisionDTO
+}
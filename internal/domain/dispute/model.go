@@ -0,0 +1,204 @@
+package dispute
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Dispute outcome is seen from the receiver (merchant) side: won keeps the money
+// with the receiver, lost reverses the transaction back to the sender.
+const (
+	StatusOpened      Status = "opened"
+	StatusUnderReview Status = "under_review"
+	StatusWon         Status = "won"
+	StatusLost        Status = "lost"
+)
+
+const (
+	ReasonFraud          Reason = "fraud"
+	ReasonNotReceived    Reason = "not_received"
+	ReasonNotAsDescribed Reason = "not_as_described"
+	ReasonDuplicate      Reason = "duplicate"
+	ReasonCancelled      Reason = "cancelled"
+	ReasonOther          Reason = "other"
+)
+
+const tranTypeTransfer = "transfer"
+
+var (
+	ErrMissingTransaction     = errors.New("missing transaction")
+	ErrUnknownReason          = errors.New("unknown dispute reason")
+	ErrUnknownStatus          = errors.New("unknown dispute status")
+	ErrNotDisputable          = errors.New("only transfers can be disputed")
+	ErrAlreadyDisputed        = errors.New("transaction already has an active dispute")
+	ErrAlreadyResolved        = errors.New("dispute is already resolved")
+	ErrAlreadyUnderReview     = errors.New("dispute is already under review")
+	ErrNotAnOutcome           = errors.New("dispute can only be resolved as won or lost")
+	ErrMissingNote            = errors.New("evidence note can not be empty")
+	ErrNotEnoughMoneyToRevert = errors.New("receiver does not have enough 'money' to revert the transaction")
+)
+
+type Status string
+
+func (s Status) valid() bool {
+	switch s {
+	case StatusOpened, StatusUnderReview, StatusWon, StatusLost:
+		return true
+	}
+	return false
+}
+
+type Reason string
+
+func (r Reason) valid() bool {
+	switch r {
+	case ReasonFraud, ReasonNotReceived, ReasonNotAsDescribed, ReasonDuplicate, ReasonCancelled, ReasonOther:
+		return true
+	}
+	return false
+}
+
+type Dispute struct {
+	ID                    int64
+	TransactionID         int64
+	Reason                Reason
+	Status                Status
+	HoldWalletID          int64
+	HoldAmount            float64
+	OpenedAt              time.Time
+	ResolvedAt            time.Time
+	ReversalTransactionID int64
+	Evidence              []Evidence
+	ReversalToApply       *Reversal
+}
+
+type Evidence struct {
+	ID        int64
+	Note      string
+	CreatedAt time.Time
+}
+
+type Transaction struct {
+	ID       int64
+	Sender   Wallet
+	Receiver Wallet
+	Amount   float64
+	Type     string
+}
+
+type Wallet struct {
+	ID      int64
+	Balance float64
+}
+
+// Reversal moves disputed amount from the receiver back to the sender.
+type Reversal struct {
+	Sender    Wallet
+	Receiver  Wallet
+	Amount    float64
+	Timestamp time.Time
+}
+
+func openDispute(dto *OpenDisputeDTO, tran Transaction, timestamp time.Time) (*Dispute, error) {
+	if err := dto.validate(); err != nil {
+		return nil, err
+	}
+	if tran.Type != tranTypeTransfer {
+		return nil, ErrNotDisputable
+	}
+	d := &Dispute{
+		TransactionID: tran.ID,
+		Reason:        dto.Reason,
+		Status:        StatusOpened,
+		OpenedAt:      timestamp,
+	}
+	if dto.Hold {
+		d.HoldWalletID = tran.Receiver.ID
+		d.HoldAmount = tran.Amount
+	}
+	if dto.Note != "" {
+		d.Evidence = append(d.Evidence, Evidence{Note: dto.Note, CreatedAt: timestamp})
+	}
+	return d, nil
+}
+
+func (d *Dispute) resolved() bool {
+	return d.Status == StatusWon || d.Status == StatusLost
+}
+
+func (d *Dispute) Review() error {
+	if d.resolved() {
+		return ErrAlreadyResolved
+	}
+	if d.Status == StatusUnderReview {
+		return ErrAlreadyUnderReview
+	}
+	d.Status = StatusUnderReview
+	return nil
+}
+
+func (d *Dispute) AddEvidence(note string, timestamp time.Time) (Evidence, error) {
+	if d.resolved() {
+		return Evidence{}, ErrAlreadyResolved
+	}
+	if note == "" {
+		return Evidence{}, ErrMissingNote
+	}
+	evidence := Evidence{Note: note, CreatedAt: timestamp}
+	d.Evidence = append(d.Evidence, evidence)
+	return evidence, nil
+}
+
+// Resolve closes the dispute releasing the hold, lost dispute reverts the transaction.
+func (d *Dispute) Resolve(outcome Status, tran Transaction, timestamp time.Time) error {
+	if d.resolved() {
+		return ErrAlreadyResolved
+	}
+	switch outcome {
+	case StatusWon:
+	case StatusLost:
+		if tran.Receiver.Balance-tran.Amount < 0 {
+			return ErrNotEnoughMoneyToRevert
+		}
+		d.ReversalToApply = &Reversal{
+			Sender:    Wallet{ID: tran.Receiver.ID, Balance: tran.Receiver.Balance - tran.Amount},
+			Receiver:  Wallet{ID: tran.Sender.ID, Balance: tran.Sender.Balance + tran.Amount},
+			Amount:    tran.Amount,
+			Timestamp: timestamp,
+		}
+	default:
+		return ErrNotAnOutcome
+	}
+	d.Status = outcome
+	d.ResolvedAt = timestamp
+	return nil
+}
+
+func (d *Dispute) toDTO() *DTO {
+	evidence := make([]EvidenceDTO, len(d.Evidence))
+	for i, e := range d.Evidence {
+		evidence[i] = EvidenceDTO(e)
+	}
+	dto := &DTO{
+		ID:                    d.ID,
+		TransactionID:         d.TransactionID,
+		Reason:                d.Reason,
+		Status:                d.Status,
+		HoldWalletID:          d.HoldWalletID,
+		HoldAmount:            d.HoldAmount,
+		OpenedAt:              d.OpenedAt,
+		ResolvedAt:            d.ResolvedAt,
+		ReversalTransactionID: d.ReversalTransactionID,
+		Evidence:              evidence,
+	}
+	if d.ReversalToApply != nil {
+		dto.ReversalToApply = &ReversalDTO{
+			Sender:    WalletDTO(d.ReversalToApply.Sender),
+			Receiver:  WalletDTO(d.ReversalToApply.Receiver),
+			Amount:    d.ReversalToApply.Amount,
+			Timestamp: d.ReversalToApply.Timestamp,
+		}
+	}
+	return dto
+}
@@ -0,0 +1,135 @@
+package dispute
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+)
+
+func Test_openDispute(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	transfer := Transaction{ID: 7, Sender: Wallet{ID: 1}, Receiver: Wallet{ID: 2, Balance: 300}, Amount: 100, Type: "transfer"}
+	type args struct {
+		dto  *OpenDisputeDTO
+		tran Transaction
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Dispute
+		wantErr error
+	}{
+		{
+			name:    "test unknown reason",
+			args:    args{dto: &OpenDisputeDTO{TransactionID: 7, Reason: "bored"}, tran: transfer},
+			want:    nil,
+			wantErr: errors.New("unknown dispute reason"),
+		},
+		{
+			name:    "test deposit is not disputable",
+			args:    args{dto: &OpenDisputeDTO{TransactionID: 7, Reason: ReasonFraud}, tran: Transaction{ID: 7, Type: "deposit"}},
+			want:    nil,
+			wantErr: errors.New("only transfers can be disputed"),
+		},
+		{
+			name: "test ok without hold",
+			args: args{dto: &OpenDisputeDTO{TransactionID: 7, Reason: ReasonNotReceived, Note: "parcel never arrived"}, tran: transfer},
+			want: &Dispute{
+				TransactionID: 7, Reason: ReasonNotReceived, Status: StatusOpened, OpenedAt: clk.Now(),
+				Evidence: []Evidence{{Note: "parcel never arrived", CreatedAt: clk.Now()}},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "test ok with hold on receiver",
+			args: args{dto: &OpenDisputeDTO{TransactionID: 7, Reason: ReasonFraud, Hold: true}, tran: transfer},
+			want: &Dispute{
+				TransactionID: 7, Reason: ReasonFraud, Status: StatusOpened, OpenedAt: clk.Now(), HoldWalletID: 2, HoldAmount: 100,
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := openDispute(tt.args.dto, tt.args.tran, clk.Now())
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("openDispute() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("openDispute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispute_Resolve(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	transfer := Transaction{ID: 7, Sender: Wallet{ID: 1, Balance: 10}, Receiver: Wallet{ID: 2, Balance: 300}, Amount: 100, Type: "transfer"}
+	type args struct {
+		outcome Status
+		tran    Transaction
+	}
+	tests := []struct {
+		name    string
+		dispute *Dispute
+		args    args
+		want    *Dispute
+		wantErr error
+	}{
+		{
+			name:    "test not an outcome",
+			dispute: &Dispute{ID: 1, Status: StatusOpened},
+			args:    args{outcome: StatusUnderReview, tran: transfer},
+			wantErr: errors.New("dispute can only be resolved as won or lost"),
+		},
+		{
+			name:    "test already resolved",
+			dispute: &Dispute{ID: 1, Status: StatusWon},
+			args:    args{outcome: StatusLost, tran: transfer},
+			wantErr: errors.New("dispute is already resolved"),
+		},
+		{
+			name:    "test won keeps money with receiver",
+			dispute: &Dispute{ID: 1, Status: StatusUnderReview},
+			args:    args{outcome: StatusWon, tran: transfer},
+			want:    &Dispute{ID: 1, Status: StatusWon, ResolvedAt: clk.Now()},
+		},
+		{
+			name:    "test lost reverts transaction",
+			dispute: &Dispute{ID: 1, Status: StatusOpened},
+			args:    args{outcome: StatusLost, tran: transfer},
+			want: &Dispute{ID: 1, Status: StatusLost, ResolvedAt: clk.Now(), ReversalToApply: &Reversal{
+				Sender: Wallet{ID: 2, Balance: 200}, Receiver: Wallet{ID: 1, Balance: 110}, Amount: 100, Timestamp: clk.Now(),
+			}},
+		},
+		{
+			name:    "test lost when receiver spent the money",
+			dispute: &Dispute{ID: 1, Status: StatusOpened},
+			args:    args{outcome: StatusLost, tran: Transaction{ID: 7, Sender: Wallet{ID: 1}, Receiver: Wallet{ID: 2, Balance: 50}, Amount: 100}},
+			wantErr: errors.New("receiver does not have enough 'money' to revert the transaction"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dispute.Resolve(tt.args.outcome, tt.args.tran, clk.Now())
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("Dispute.Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Dispute.Resolve() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.dispute, tt.want) {
+				t.Errorf("Dispute.Resolve() = %v, want %v", tt.dispute, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package dispute
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/risk"
+)
+
+type Service interface {
+	Open(context.Context, *OpenDisputeDTO) (DTO, error)
+	GetByID(context.Context, int64) (DTO, error)
+	GetFiltered(ctx context.Context, filter *FilterDisputesDTO, limit int, offset int) ([]DTO, error)
+	Review(context.Context, int64) (DTO, error)
+	AddEvidence(context.Context, int64, string) (DTO, error)
+	Resolve(context.Context, int64, Status) (DTO, error)
+}
+
+type service struct {
+	storage Storage
+	risk    risk.Service
+	logger  logging.Logger
+	clk     clock.Clock
+}
+
+func NewService(storage Storage, riskService risk.Service, logger logging.Logger, clk clock.Clock) (Service, error) {
+	return &service{storage: storage, risk: riskService, logger: logger, clk: clk}, nil
+}
+
+func (s *service) Open(ctx context.Context, dto *OpenDisputeDTO) (DTO, error) {
+	if err := dto.validate(); err != nil {
+		s.logger.Errorf("error validating dispute: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error validating dispute")
+	}
+	tran, err := s.getTransaction(ctx, dto.TransactionID)
+	if err != nil {
+		return DTO{}, err
+	}
+	active, err := s.storage.HasActive(ctx, dto.TransactionID)
+	if err != nil {
+		s.logger.Errorf("error checking active disputes in db: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error checking active disputes in db")
+	}
+	if active {
+		s.logger.Errorf("transaction %d already has an active dispute", dto.TransactionID)
+		return DTO{}, ErrAlreadyDisputed
+	}
+
+	disputeModel, err := openDispute(dto, tran.toModel(), s.clk.Now())
+	if err != nil {
+		s.logger.Errorf("error creating dispute model: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error creating dispute model")
+	}
+	result, err := s.storage.Create(ctx, disputeModel.toDTO())
+	if err != nil {
+		s.logger.Errorf("error creating dispute in db: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error creating dispute in db")
+	}
+	if result.ID == 0 {
+		s.logger.Errorf("empty dispute returned from db")
+		return DTO{}, errors.New("empty dispute returned from db")
+	}
+	return result, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id int64) (DTO, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *service) GetFiltered(ctx context.Context, filter *FilterDisputesDTO, limit int, offset int) ([]DTO, error) {
+	if err := filter.validate(); err != nil {
+		return nil, errors.Wrap(err, "error validating filter")
+	}
+	return s.storage.GetFiltered(ctx, filter, limit, offset)
+}
+
+func (s *service) Review(ctx context.Context, id int64) (DTO, error) {
+	disputeModel, err := s.get(ctx, id)
+	if err != nil {
+		return DTO{}, err
+	}
+	if err := disputeModel.Review(); err != nil {
+		s.logger.Errorf("error updating dispute model: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error updating dispute model")
+	}
+	return s.update(ctx, disputeModel)
+}
+
+func (s *service) AddEvidence(ctx context.Context, id int64, note string) (DTO, error) {
+	disputeModel, err := s.get(ctx, id)
+	if err != nil {
+		return DTO{}, err
+	}
+	evidence, err := disputeModel.AddEvidence(note, s.clk.Now())
+	if err != nil {
+		s.logger.Errorf("error adding evidence to dispute model: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error adding evidence to dispute model")
+	}
+	if _, err := s.storage.AddEvidence(ctx, id, EvidenceDTO(evidence)); err != nil {
+		s.logger.Errorf("error adding evidence in db: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error adding evidence in db")
+	}
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *service) Resolve(ctx context.Context, id int64, outcome Status) (DTO, error) {
+	disputeModel, err := s.get(ctx, id)
+	if err != nil {
+		return DTO{}, err
+	}
+	tran, err := s.getTransaction(ctx, disputeModel.TransactionID)
+	if err != nil {
+		return DTO{}, err
+	}
+	if err := disputeModel.Resolve(outcome, tran.toModel(), s.clk.Now()); err != nil {
+		s.logger.Errorf("error resolving dispute model: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error resolving dispute model")
+	}
+	result := disputeModel.toDTO()
+	if reversal := result.ReversalToApply; reversal != nil {
+		movement := &risk.MovementDTO{
+			Kind:      risk.KindReversal,
+			WalletID:  reversal.Sender.ID,
+			Receivers: []int64{reversal.Receiver.ID},
+			Amount:    reversal.Amount,
+		}
+		if reversal.Risk, err = s.risk.Evaluate(ctx, movement); err != nil {
+			s.logger.Errorf("error evaluating reversal risk: %s", err.Error())
+			return DTO{}, errors.Wrap(err, "error evaluating reversal risk")
+		}
+	}
+	return s.store(ctx, result)
+}
+
+func (s *service) get(ctx context.Context, id int64) (*Dispute, error) {
+	disputeInDB, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Errorf("error getting dispute from db: %s", err.Error())
+		return nil, errors.Wrap(err, "error getting dispute from db")
+	}
+	if disputeInDB.ID == 0 {
+		s.logger.Errorf("missing dispute in db")
+		return nil, errors.New("missing dispute in db")
+	}
+	return disputeInDB.toModel(), nil
+}
+
+func (s *service) update(ctx context.Context, disputeModel *Dispute) (DTO, error) {
+	return s.store(ctx, disputeModel.toDTO())
+}
+
+func (s *service) store(ctx context.Context, result *DTO) (DTO, error) {
+	if err := s.storage.Update(ctx, result); err != nil {
+		s.logger.Errorf("error updating dispute in db: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error updating dispute in db")
+	}
+	return *result, nil
+}
+
+func (s *service) getTransaction(ctx context.Context, id int64) (TransactionDTO, error) {
+	tran, err := s.storage.GetTransaction(ctx, id)
+	if err != nil {
+		s.logger.Errorf("error getting transaction from db: %s", err.Error())
+		return TransactionDTO{}, errors.Wrap(err, "error getting transaction from db")
+	}
+	if tran.ID == 0 {
+		s.logger.Errorf("missing transaction in db")
+		return TransactionDTO{}, errors.New("missing transaction in db")
+	}
+	return tran, nil
+}
@@ -0,0 +1,13 @@
+package dispute
+
+import "context"
+
+type Storage interface {
+	Create(context.Context, *DTO) (DTO, error)
+	GetByID(context.Context, int64) (DTO, error)
+	GetFiltered(context.Context, *FilterDisputesDTO, int, int) ([]DTO, error)
+	HasActive(context.Context, int64) (bool, error)
+	Update(context.Context, *DTO) error
+	AddEvidence(context.Context, int64, EvidenceDTO) (EvidenceDTO, error)
+	GetTransaction(context.Context, int64) (TransactionDTO, error)
+}
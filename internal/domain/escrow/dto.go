@@ -0,0 +1,92 @@
+package escrow
+
+import (
+	"time"
+
+	"github.com/skwol/wallet/internal/domain/risk"
+)
+
+type DTO struct {
+	ID                  int64
+	Buyer               WalletDTO
+	Seller              WalletDTO
+	Holding             WalletDTO
+	Amount              float64
+	Status              Status
+	BuyerConfirmed      bool
+	SellerConfirmed     bool
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+	SettledAt           time.Time
+	FundTransactionID   int64
+	SettleTransactionID int64
+	TransferToApply     *TransferDTO
+}
+
+func (d DTO) toModel() *Escrow {
+	return &Escrow{
+		ID:                  d.ID,
+		Buyer:               d.Buyer.toModel(),
+		Seller:              d.Seller.toModel(),
+		Holding:             d.Holding.toModel(),
+		Amount:              d.Amount,
+		Status:              d.Status,
+		BuyerConfirmed:      d.BuyerConfirmed,
+		SellerConfirmed:     d.SellerConfirmed,
+		CreatedAt:           d.CreatedAt,
+		ExpiresAt:           d.ExpiresAt,
+		SettledAt:           d.SettledAt,
+		FundTransactionID:   d.FundTransactionID,
+		SettleTransactionID: d.SettleTransactionID,
+	}
+}
+
+// ConfirmDTO is the confirmation that releases the escrow, it is captured for approval.
+type ConfirmDTO struct {
+	ID    int64
+	Party Party
+}
+
+type CreateEscrowDTO struct {
+	BuyerID  int64
+	SellerID int64
+	Amount   float64
+}
+
+func (d CreateEscrowDTO) validate() error {
+	if d.BuyerID == 0 {
+		return ErrMissingBuyer
+	}
+	if d.SellerID == 0 {
+		return ErrMissingSeller
+	}
+	if d.BuyerID == d.SellerID {
+		return ErrSameBuyerAndSeller
+	}
+	if d.Amount <= 0 {
+		return ErrNonPositiveAmount
+	}
+	return nil
+}
+
+type WalletDTO struct {
+	ID       int64
+	Balance  float64
+	Held     float64
+	Closed   bool
+	Frozen   bool
+	Reserved bool
+}
+
+func (d WalletDTO) toModel() Wallet {
+	return Wallet(d)
+}
+
+type TransferDTO struct {
+	Sender    WalletDTO
+	Receiver  WalletDTO
+	Amount    float64
+	Timestamp time.Time
+	// Risk is the decision on the transfer, written together with it
+	Risk *risk.DecisionDTO
+}
@@ -0,0 +1,225 @@
+package escrow
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	StatusFunded   Status = "funded"
+	StatusReleased Status = "released"
+	StatusRefunded Status = "refunded"
+	StatusDisputed Status = "disputed"
+)
+
+const (
+	PartyBuyer  Party = "buyer"
+	PartySeller Party = "seller"
+)
+
+var (
+	ErrMissingBuyer       = errors.New("missing buyer")
+	ErrMissingSeller      = errors.New("missing seller")
+	ErrSameBuyerAndSeller = errors.New("buyer and seller is the same wallet")
+	ErrNonPositiveAmount  = errors.New("amount should be greater then 0")
+	ErrNotEnoughMoney     = errors.New("buyer does not have enough 'money' for escrow")
+	ErrUnknownParty       = errors.New("party should be either buyer or seller")
+	ErrAlreadyConfirmed   = errors.New("party has already confirmed the escrow")
+	ErrAlreadySettled     = errors.New("escrow is already settled")
+	ErrAlreadyDisputed    = errors.New("escrow is already disputed")
+	ErrConcurrentUpdate   = errors.New("escrow was changed by another request")
+	ErrClosedWallet       = errors.New("escrow can not be performed with closed wallet")
+	ErrFrozenWallet       = errors.New("escrow can not be performed with wallet frozen pending screening review")
+	ErrReservedWallet     = errors.New("escrow can not be performed with wallet reserved by the service")
+)
+
+type Status string
+
+type Party string
+
+// Escrow holds buyer's money on a holding wallet until the deal is settled.
+type Escrow struct {
+	ID                  int64
+	Buyer               Wallet
+	Seller              Wallet
+	Holding             Wallet
+	Amount              float64
+	Status              Status
+	BuyerConfirmed      bool
+	SellerConfirmed     bool
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+	SettledAt           time.Time
+	FundTransactionID   int64
+	SettleTransactionID int64
+	TransferToApply     *Transfer
+}
+
+// Wallet keeps Held amount that is reserved by active disputes and can not be spent.
+// Closed, Frozen and Reserved wallets can neither fund nor receive escrowed money,
+// the holding wallet is the only reserved wallet escrow moves money through.
+type Wallet struct {
+	ID       int64
+	Balance  float64
+	Held     float64
+	Closed   bool
+	Frozen   bool
+	Reserved bool
+}
+
+func (w Wallet) validate() error {
+	if w.Closed {
+		return ErrClosedWallet
+	}
+	if w.Frozen {
+		return ErrFrozenWallet
+	}
+	if w.Reserved {
+		return ErrReservedWallet
+	}
+	return nil
+}
+
+func (w Wallet) toDTO() WalletDTO {
+	return WalletDTO(w)
+}
+
+// Transfer is a money movement that has to be posted together with the escrow state change.
+type Transfer struct {
+	Sender    Wallet
+	Receiver  Wallet
+	Amount    float64
+	Timestamp time.Time
+}
+
+func (t Transfer) toDTO() TransferDTO {
+	return TransferDTO{
+		Sender:    t.Sender.toDTO(),
+		Receiver:  t.Receiver.toDTO(),
+		Amount:    t.Amount,
+		Timestamp: t.Timestamp,
+	}
+}
+
+func newEscrow(dto *CreateEscrowDTO, buyer, seller, holding Wallet, timestamp time.Time, timeout time.Duration) (*Escrow, error) {
+	if err := dto.validate(); err != nil {
+		return nil, err
+	}
+	if err := buyer.validate(); err != nil {
+		return nil, err
+	}
+	if err := seller.validate(); err != nil {
+		return nil, err
+	}
+	if buyer.Balance-buyer.Held-dto.Amount < 0 {
+		return nil, ErrNotEnoughMoney
+	}
+	e := &Escrow{
+		Buyer:     buyer,
+		Seller:    seller,
+		Holding:   holding,
+		Amount:    dto.Amount,
+		Status:    StatusFunded,
+		CreatedAt: timestamp,
+		ExpiresAt: timestamp.Add(timeout),
+	}
+	e.Buyer.Balance -= e.Amount
+	e.Holding.Balance += e.Amount
+	e.TransferToApply = &Transfer{Sender: e.Buyer, Receiver: e.Holding, Amount: e.Amount, Timestamp: timestamp}
+	return e, nil
+}
+
+func (e *Escrow) settled() bool {
+	return e.Status == StatusReleased || e.Status == StatusRefunded
+}
+
+// Confirm registers confirmation of one of the parties, money is released to the seller
+// as soon as both of them have confirmed.
+func (e *Escrow) Confirm(party Party, timestamp time.Time) error {
+	if e.settled() {
+		return ErrAlreadySettled
+	}
+	switch party {
+	case PartyBuyer:
+		if e.BuyerConfirmed {
+			return ErrAlreadyConfirmed
+		}
+		e.BuyerConfirmed = true
+	case PartySeller:
+		if e.SellerConfirmed {
+			return ErrAlreadyConfirmed
+		}
+		e.SellerConfirmed = true
+	default:
+		return ErrUnknownParty
+	}
+	if e.BuyerConfirmed && e.SellerConfirmed {
+		if err := e.Seller.validate(); err != nil {
+			return err
+		}
+		e.Holding.Balance -= e.Amount
+		e.Seller.Balance += e.Amount
+		e.TransferToApply = &Transfer{Sender: e.Holding, Receiver: e.Seller, Amount: e.Amount, Timestamp: timestamp}
+		e.Status = StatusReleased
+		e.SettledAt = timestamp
+	}
+	return nil
+}
+
+// Dispute stops the escrow from expiring, disputed escrow is either refunded or released
+// when both parties confirm it after all.
+func (e *Escrow) Dispute() error {
+	if e.settled() {
+		return ErrAlreadySettled
+	}
+	if e.Status == StatusDisputed {
+		return ErrAlreadyDisputed
+	}
+	e.Status = StatusDisputed
+	return nil
+}
+
+// Refund returns held money back to the buyer.
+func (e *Escrow) Refund(timestamp time.Time) error {
+	if e.settled() {
+		return ErrAlreadySettled
+	}
+	if err := e.Buyer.validate(); err != nil {
+		return err
+	}
+	e.Holding.Balance -= e.Amount
+	e.Buyer.Balance += e.Amount
+	e.TransferToApply = &Transfer{Sender: e.Holding, Receiver: e.Buyer, Amount: e.Amount, Timestamp: timestamp}
+	e.Status = StatusRefunded
+	e.SettledAt = timestamp
+	return nil
+}
+
+// Expired reports whether funded escrow outlived its timeout, disputed escrow never expires.
+func (e *Escrow) Expired(timestamp time.Time) bool {
+	return e.Status == StatusFunded && !timestamp.Before(e.ExpiresAt)
+}
+
+func (e *Escrow) toDTO() *DTO {
+	dto := &DTO{
+		ID:                  e.ID,
+		Buyer:               e.Buyer.toDTO(),
+		Seller:              e.Seller.toDTO(),
+		Holding:             e.Holding.toDTO(),
+		Amount:              e.Amount,
+		Status:              e.Status,
+		BuyerConfirmed:      e.BuyerConfirmed,
+		SellerConfirmed:     e.SellerConfirmed,
+		CreatedAt:           e.CreatedAt,
+		ExpiresAt:           e.ExpiresAt,
+		SettledAt:           e.SettledAt,
+		FundTransactionID:   e.FundTransactionID,
+		SettleTransactionID: e.SettleTransactionID,
+	}
+	if e.TransferToApply != nil {
+		transfer := e.TransferToApply.toDTO()
+		dto.TransferToApply = &transfer
+	}
+	return dto
+}
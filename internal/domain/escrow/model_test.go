@@ -0,0 +1,228 @@
+package escrow
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+)
+
+func Test_newEscrow(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	type args struct {
+		dto    *CreateEscrowDTO
+		buyer  Wallet
+		seller Wallet
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Escrow
+		wantErr error
+	}{
+		{
+			name:    "test missing buyer",
+			args:    args{dto: &CreateEscrowDTO{SellerID: 2, Amount: 10}},
+			want:    nil,
+			wantErr: errors.New("missing buyer"),
+		},
+		{
+			name:    "test same buyer and seller",
+			args:    args{dto: &CreateEscrowDTO{BuyerID: 1, SellerID: 1, Amount: 10}},
+			want:    nil,
+			wantErr: errors.New("buyer and seller is the same wallet"),
+		},
+		{
+			name:    "test closed buyer",
+			args:    args{dto: &CreateEscrowDTO{BuyerID: 1, SellerID: 2, Amount: 10}, buyer: Wallet{ID: 1, Balance: 15, Closed: true}},
+			want:    nil,
+			wantErr: errors.New("escrow can not be performed with closed wallet"),
+		},
+		{
+			name:    "test frozen seller",
+			args:    args{dto: &CreateEscrowDTO{BuyerID: 1, SellerID: 2, Amount: 10}, buyer: Wallet{ID: 1, Balance: 15}, seller: Wallet{ID: 2, Frozen: true}},
+			want:    nil,
+			wantErr: errors.New("escrow can not be performed with wallet frozen pending screening review"),
+		},
+		{
+			name:    "test reserved seller",
+			args:    args{dto: &CreateEscrowDTO{BuyerID: 1, SellerID: 2, Amount: 10}, buyer: Wallet{ID: 1, Balance: 15}, seller: Wallet{ID: 2, Reserved: true}},
+			want:    nil,
+			wantErr: errors.New("escrow can not be performed with wallet reserved by the service"),
+		},
+		{
+			name:    "test buyer does not have enough money",
+			args:    args{dto: &CreateEscrowDTO{BuyerID: 1, SellerID: 2, Amount: 10}, buyer: Wallet{ID: 1, Balance: 5}},
+			want:    nil,
+			wantErr: errors.New("buyer does not have enough 'money' for escrow"),
+		},
+		{
+			name: "test ok",
+			args: args{dto: &CreateEscrowDTO{BuyerID: 1, SellerID: 2, Amount: 10}, buyer: Wallet{ID: 1, Balance: 15}, seller: Wallet{ID: 2}},
+			want: &Escrow{
+				Buyer:     Wallet{ID: 1, Balance: 5},
+				Seller:    Wallet{ID: 2},
+				Holding:   Wallet{ID: 3, Balance: 10},
+				Amount:    10,
+				Status:    StatusFunded,
+				CreatedAt: clk.Now(),
+				ExpiresAt: clk.Now().Add(time.Hour),
+				TransferToApply: &Transfer{
+					Sender: Wallet{ID: 1, Balance: 5}, Receiver: Wallet{ID: 3, Balance: 10}, Amount: 10, Timestamp: clk.Now(),
+				},
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newEscrow(tt.args.dto, tt.args.buyer, tt.args.seller, Wallet{ID: 3}, clk.Now(), time.Hour)
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("newEscrow() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newEscrow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscrow_Confirm(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	funded := func() *Escrow {
+		return &Escrow{
+			ID:      1,
+			Buyer:   Wallet{ID: 1, Balance: 5},
+			Seller:  Wallet{ID: 2},
+			Holding: Wallet{ID: 3, Balance: 10},
+			Amount:  10,
+			Status:  StatusFunded,
+		}
+	}
+	tests := []struct {
+		name    string
+		escrow  *Escrow
+		parties []Party
+		want    *Escrow
+		wantErr error
+	}{
+		{
+			name:    "test unknown party",
+			escrow:  funded(),
+			parties: []Party{"courier"},
+			wantErr: errors.New("party should be either buyer or seller"),
+		},
+		{
+			name:    "test confirm twice",
+			escrow:  funded(),
+			parties: []Party{PartyBuyer, PartyBuyer},
+			wantErr: errors.New("party has already confirmed the escrow"),
+		},
+		{
+			name:    "test one party confirmed",
+			escrow:  funded(),
+			parties: []Party{PartySeller},
+			want: &Escrow{
+				ID: 1, Buyer: Wallet{ID: 1, Balance: 5}, Seller: Wallet{ID: 2}, Holding: Wallet{ID: 3, Balance: 10},
+				Amount: 10, Status: StatusFunded, SellerConfirmed: true,
+			},
+		},
+		{
+			name:    "test both parties confirmed",
+			escrow:  funded(),
+			parties: []Party{PartyBuyer, PartySeller},
+			want: &Escrow{
+				ID: 1, Buyer: Wallet{ID: 1, Balance: 5}, Seller: Wallet{ID: 2, Balance: 10}, Holding: Wallet{ID: 3},
+				Amount: 10, Status: StatusReleased, BuyerConfirmed: true, SellerConfirmed: true, SettledAt: clk.Now(),
+				TransferToApply: &Transfer{Sender: Wallet{ID: 3}, Receiver: Wallet{ID: 2, Balance: 10}, Amount: 10, Timestamp: clk.Now()},
+			},
+		},
+		{
+			name: "test both parties confirmed with closed seller",
+			escrow: &Escrow{
+				ID: 1, Buyer: Wallet{ID: 1, Balance: 5}, Seller: Wallet{ID: 2, Closed: true}, Holding: Wallet{ID: 3, Balance: 10},
+				Amount: 10, Status: StatusFunded,
+			},
+			parties: []Party{PartyBuyer, PartySeller},
+			wantErr: errors.New("escrow can not be performed with closed wallet"),
+		},
+		{
+			name:    "test settled escrow",
+			escrow:  &Escrow{ID: 1, Amount: 10, Status: StatusRefunded},
+			parties: []Party{PartyBuyer},
+			wantErr: errors.New("escrow is already settled"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			for _, party := range tt.parties {
+				if err = tt.escrow.Confirm(party, clk.Now()); err != nil {
+					break
+				}
+			}
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("Escrow.Confirm() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Escrow.Confirm() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.escrow, tt.want) {
+				t.Errorf("Escrow.Confirm() = %v, want %v", tt.escrow, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscrow_Refund(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	disputed := &Escrow{
+		ID: 1, Buyer: Wallet{ID: 1, Balance: 5}, Seller: Wallet{ID: 2}, Holding: Wallet{ID: 3, Balance: 10},
+		Amount: 10, Status: StatusDisputed,
+	}
+	if err := disputed.Refund(clk.Now()); err != nil {
+		t.Fatalf("Escrow.Refund() unexpected error = %v", err)
+	}
+	want := &Escrow{
+		ID: 1, Buyer: Wallet{ID: 1, Balance: 15}, Seller: Wallet{ID: 2}, Holding: Wallet{ID: 3},
+		Amount: 10, Status: StatusRefunded, SettledAt: clk.Now(),
+		TransferToApply: &Transfer{Sender: Wallet{ID: 3}, Receiver: Wallet{ID: 1, Balance: 15}, Amount: 10, Timestamp: clk.Now()},
+	}
+	if !reflect.DeepEqual(disputed, want) {
+		t.Errorf("Escrow.Refund() = %v, want %v", disputed, want)
+	}
+	if err := disputed.Refund(clk.Now()); err == nil || err.Error() != "escrow is already settled" {
+		t.Errorf("Escrow.Refund() error = %v, wantErr %v", err, ErrAlreadySettled)
+	}
+}
+
+func TestEscrow_Expired(t *testing.T) {
+	expiresAt := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		status    Status
+		timestamp time.Time
+		want      bool
+	}{
+		{name: "test funded before timeout", status: StatusFunded, timestamp: expiresAt.Add(-time.Second), want: false},
+		{name: "test funded at timeout", status: StatusFunded, timestamp: expiresAt, want: true},
+		{name: "test disputed after timeout", status: StatusDisputed, timestamp: expiresAt.Add(time.Hour), want: false},
+		{name: "test released after timeout", status: StatusReleased, timestamp: expiresAt.Add(time.Hour), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Escrow{Status: tt.status, ExpiresAt: expiresAt}
+			if got := e.Expired(tt.timestamp); got != tt.want {
+				t.Errorf("Escrow.Expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package escrow
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/approval"
+	"github.com/skwol/wallet/internal/domain/risk"
+)
+
+// DefaultTimeout is used when escrow is neither confirmed nor disputed.
+const DefaultTimeout = 14 * 24 * time.Hour
+
+type Service interface {
+	Create(context.Context, *CreateEscrowDTO) (DTO, error)
+	GetByID(context.Context, int64) (DTO, error)
+	GetAll(ctx context.Context, limit int, offset int) ([]DTO, error)
+	Confirm(context.Context, int64, Party) (DTO, error)
+	Dispute(context.Context, int64) (DTO, error)
+	Refund(context.Context, int64) (DTO, error)
+	RefundExpired(context.Context) error
+}
+
+type service struct {
+	storage  Storage
+	approval approval.Service
+	risk     risk.Service
+	logger   logging.Logger
+	clk      clock.Clock
+	timeout  time.Duration
+}
+
+// NewService registers executors of escrow operations that wait for approval.
+func NewService(storage Storage, approvalService approval.Service, riskService risk.Service, logger logging.Logger, clk clock.Clock, timeout time.Duration) (Service, error) {
+	if timeout <= 0 {
+		return nil, errors.New("escrow timeout should be greater then 0")
+	}
+	s := &service{storage: storage, approval: approvalService, risk: riskService, logger: logger, clk: clk, timeout: timeout}
+	approvalService.RegisterExecutor(approval.OperationEscrowFund, s.executeFund)
+	approvalService.RegisterExecutor(approval.OperationEscrowRelease, s.executeRelease)
+	return s, nil
+}
+
+func (s *service) Create(ctx context.Context, dto *CreateEscrowDTO) (DTO, error) {
+	if err := dto.validate(); err != nil {
+		s.logger.Errorf("error validating escrow: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error validating escrow")
+	}
+	buyer, err := s.getWallet(ctx, dto.BuyerID, "buyer")
+	if err != nil {
+		return DTO{}, err
+	}
+	seller, err := s.getWallet(ctx, dto.SellerID, "seller")
+	if err != nil {
+		return DTO{}, err
+	}
+	holding, err := s.storage.GetHoldingWallet(ctx)
+	if err != nil {
+		s.logger.Errorf("error getting holding wallet from db: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error getting holding wallet from db")
+	}
+
+	escrowModel, err := newEscrow(dto, buyer.toModel(), seller.toModel(), holding.toModel(), s.clk.Now(), s.timeout)
+	if err != nil {
+		s.logger.Errorf("error creating escrow model: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error creating escrow model")
+	}
+	if err := s.approval.Gate(ctx, approval.OperationEscrowFund, escrowModel.Amount, dto); err != nil {
+		return DTO{}, err
+	}
+	// funding is a transfer of the buyer to the holding wallet
+	movement := &risk.MovementDTO{Kind: risk.KindTransfer, WalletID: buyer.ID, Receivers: []int64{holding.ID}, Amount: escrowModel.Amount}
+	decision, err := s.evaluate(ctx, movement)
+	if err != nil {
+		return DTO{}, err
+	}
+	escrowDTO := escrowModel.toDTO()
+	escrowDTO.TransferToApply.Risk = decision
+	result, err := s.storage.Create(ctx, escrowDTO)
+	if err != nil {
+		s.logger.Errorf("error creating escrow in db: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error creating escrow in db")
+	}
+	if result.ID == 0 {
+		s.logger.Errorf("empty escrow returned from db")
+		return DTO{}, errors.New("empty escrow returned from db")
+	}
+	return result, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id int64) (DTO, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *service) GetAll(ctx context.Context, limit int, offset int) ([]DTO, error) {
+	return s.storage.GetAll(ctx, limit, offset)
+}
+
+func (s *service) Confirm(ctx context.Context, id int64, party Party) (DTO, error) {
+	return s.apply(ctx, id, func(e *Escrow) (*risk.DecisionDTO, error) {
+		if err := e.Confirm(party, s.clk.Now()); err != nil {
+			return nil, err
+		}
+		// only the confirmation of the last party moves money to the seller
+		if e.TransferToApply == nil {
+			return nil, nil
+		}
+		if err := s.approval.Gate(ctx, approval.OperationEscrowRelease, e.Amount, ConfirmDTO{ID: id, Party: party}); err != nil {
+			return nil, err
+		}
+		// released money leaves the holding wallet shared by all escrows, so it is evaluated for the seller
+		return s.evaluate(ctx, &risk.MovementDTO{Kind: risk.KindEscrowRelease, WalletID: e.Seller.ID, Amount: e.Amount})
+	})
+}
+
+func (s *service) Dispute(ctx context.Context, id int64) (DTO, error) {
+	return s.apply(ctx, id, func(e *Escrow) (*risk.DecisionDTO, error) {
+		return nil, e.Dispute()
+	})
+}
+
+// Refund returns money to the buyer it came from, so it is not evaluated by risk rules.
+func (s *service) Refund(ctx context.Context, id int64) (DTO, error) {
+	return s.apply(ctx, id, func(e *Escrow) (*risk.DecisionDTO, error) {
+		return nil, e.Refund(s.clk.Now())
+	})
+}
+
+// RefundExpired returns money of every funded escrow that outlived its timeout back to the buyer.
+func (s *service) RefundExpired(ctx context.Context) error {
+	now := s.clk.Now()
+	expired, err := s.storage.GetExpired(ctx, now)
+	if err != nil {
+		s.logger.Errorf("error getting expired escrows from db: %s", err.Error())
+		return errors.Wrap(err, "error getting expired escrows from db")
+	}
+	var failed int
+	for _, dto := range expired {
+		if _, err := s.apply(ctx, dto.ID, func(e *Escrow) (*risk.DecisionDTO, error) {
+			if !e.Expired(now) {
+				return nil, nil
+			}
+			return nil, e.Refund(now)
+		}); err != nil && errors.Cause(err) != ErrAlreadySettled {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d expired escrows were not refunded", failed, len(expired))
+	}
+	return nil
+}
+
+// apply runs action on the escrow and stores it, action returns the risk decision on the transfer it makes.
+func (s *service) apply(ctx context.Context, id int64, action func(*Escrow) (*risk.DecisionDTO, error)) (DTO, error) {
+	escrowInDB, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Errorf("error getting escrow from db: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error getting escrow from db")
+	}
+	if escrowInDB.ID == 0 {
+		s.logger.Errorf("missing escrow in db")
+		return DTO{}, errors.New("missing escrow in db")
+	}
+	if escrowInDB.Buyer, err = s.getWallet(ctx, escrowInDB.Buyer.ID, "buyer"); err != nil {
+		return DTO{}, err
+	}
+	if escrowInDB.Seller, err = s.getWallet(ctx, escrowInDB.Seller.ID, "seller"); err != nil {
+		return DTO{}, err
+	}
+	if escrowInDB.Holding, err = s.getWallet(ctx, escrowInDB.Holding.ID, "holding"); err != nil {
+		return DTO{}, err
+	}
+
+	escrowModel := escrowInDB.toModel()
+	decision, err := action(escrowModel)
+	if err != nil {
+		s.logger.Errorf("error updating escrow model: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error updating escrow model")
+	}
+	result := escrowModel.toDTO()
+	if result.TransferToApply != nil {
+		result.TransferToApply.Risk = decision
+	}
+	if err := s.storage.Update(ctx, result, escrowInDB); err != nil {
+		s.logger.Errorf("error updating escrow in db: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error updating escrow in db")
+	}
+	return *result, nil
+}
+
+func (s *service) evaluate(ctx context.Context, movement *risk.MovementDTO) (*risk.DecisionDTO, error) {
+	decision, err := s.risk.Evaluate(ctx, movement)
+	if err != nil {
+		s.logger.Errorf("error evaluating %s risk: %s", movement.Kind, err.Error())
+		return nil, errors.Wrapf(err, "error evaluating %s risk", movement.Kind)
+	}
+	return decision, nil
+}
+
+func (s *service) getWallet(ctx context.Context, id int64, role string) (WalletDTO, error) {
+	wallet, err := s.storage.GetWallet(ctx, id)
+	if err != nil {
+		s.logger.Errorf("error getting %s wallet from db: %s", role, err.Error())
+		return WalletDTO{}, errors.Wrapf(err, "error getting %s wallet from db", role)
+	}
+	if wallet.ID == 0 {
+		s.logger.Errorf("missing %s wallet in db", role)
+		return WalletDTO{}, errors.Errorf("missing %s wallet in db", role)
+	}
+	return wallet, nil
+}
+
+func (s *service) executeFund(ctx context.Context, payload []byte) error {
+	var dto CreateEscrowDTO
+	if err := json.Unmarshal(payload, &dto); err != nil {
+		return errors.Wrap(err, "error unmarshaling escrow")
+	}
+	_, err := s.Create(ctx, &dto)
+	return err
+}
+
+func (s *service) executeRelease(ctx context.Context, payload []byte) error {
+	var dto ConfirmDTO
+	if err := json.Unmarshal(payload, &dto); err != nil {
+		return errors.Wrap(err, "error unmarshaling escrow confirmation")
+	}
+	_, err := s.Confirm(ctx, dto.ID, dto.Party)
+	return err
+}
@@ -0,0 +1,18 @@
+package escrow
+
+import (
+	"context"
+	"time"
+)
+
+type Storage interface {
+	Create(context.Context, *DTO) (DTO, error)
+	GetByID(context.Context, int64) (DTO, error)
+	GetAll(context.Context, int, int) ([]DTO, error)
+	GetExpired(context.Context, time.Time) ([]DTO, error)
+	// Update stores dto only if the escrow is still in the prev state, it fails with ErrAlreadySettled
+	// or ErrConcurrentUpdate otherwise.
+	Update(ctx context.Context, dto *DTO, prev DTO) error
+	GetWallet(context.Context, int64) (WalletDTO, error)
+	GetHoldingWallet(context.Context) (WalletDTO, error)
+}
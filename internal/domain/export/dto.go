@@ -0,0 +1,12 @@
+package export
+
+import "time"
+
+// ExportDTO asks for the transactions of the wallet in [From, To), dates are written in Location.
+type ExportDTO struct {
+	WalletID int64
+	Format   Format
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+}
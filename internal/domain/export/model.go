@@ -0,0 +1,126 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+const (
+	FormatOFX Format = "ofx"
+	FormatQIF Format = "qif"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown export format")
+	ErrInvalidPeriod = errors.New("export period should end after it starts")
+)
+
+type Format string
+
+// Formats lists the supported formats with their media types.
+var Formats = map[Format]string{
+	FormatOFX: "application/x-ofx",
+	FormatQIF: "application/qif",
+}
+
+func (f Format) Valid() bool {
+	_, ok := Formats[f]
+	return ok
+}
+
+// ContentType is the media type the export is served with.
+func (f Format) ContentType() string {
+	if contentType, ok := Formats[f]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// Account is the wallet the transactions are exported of, Balance is the ledger balance at Created.
+type Account struct {
+	WalletID int64
+	Name     string
+	Currency string
+	Balance  float64
+	From     time.Time
+	To       time.Time
+	Created  time.Time
+}
+
+// Entry is the transaction as the wallet sees it, Amount is negative when money leaves the wallet.
+// Payee is the name of the counterpart wallet, deposits and withdrawals have none.
+type Entry struct {
+	TransactionID   int64
+	Type            transaction.TranType
+	Amount          float64
+	Timestamp       time.Time
+	CounterpartyID  int64
+	ParentPaymentID int64
+	Payee           string
+}
+
+func newEntry(dto transaction.DTO, walletID int64) Entry {
+	entry := Entry{
+		TransactionID:   dto.ID,
+		Type:            dto.Type,
+		Amount:          dto.Amount,
+		Timestamp:       dto.Timestamp,
+		ParentPaymentID: dto.ParentPaymentID,
+	}
+	switch {
+	case dto.Type == transaction.TranTypeDeposit:
+		entry.Payee = "Deposit"
+	case dto.Type == transaction.TranTypeWithdraw:
+		entry.Amount, entry.Payee = -dto.Amount, "Withdrawal"
+	case dto.ReceiverID == walletID:
+		entry.CounterpartyID = dto.SenderID
+	default:
+		entry.Amount, entry.CounterpartyID = -dto.Amount, dto.ReceiverID
+	}
+	return entry
+}
+
+// setPayee takes the name of the counterpart wallet, wallets without a name are called by their id.
+func (e *Entry) setPayee(names map[int64]string) {
+	if e.CounterpartyID == 0 {
+		return
+	}
+	if name := names[e.CounterpartyID]; name != "" {
+		e.Payee = name
+		return
+	}
+	e.Payee = fmt.Sprintf("Wallet #%d", e.CounterpartyID)
+}
+
+// Memo describes the transaction for the notes of personal finance tools.
+func (e Entry) Memo() string {
+	var memo string
+	switch {
+	case e.CounterpartyID == 0:
+		memo = string(e.Type)
+	case e.Amount < 0:
+		memo = fmt.Sprintf("%s to wallet %d", e.Type, e.CounterpartyID)
+	default:
+		memo = fmt.Sprintf("%s from wallet %d", e.Type, e.CounterpartyID)
+	}
+	if e.ParentPaymentID != 0 {
+		memo += fmt.Sprintf(", payment %d", e.ParentPaymentID)
+	}
+	return memo
+}
+
+// periodFilter selects the transactions of the wallet in [from, to) in the order they were made.
+func periodFilter(walletID int64, from, to time.Time) *transaction.FilterTransactionsDTO {
+	return &transaction.FilterTransactionsDTO{
+		Where: &transaction.Expression{And: []transaction.Expression{
+			{Condition: &transaction.Condition{Field: transaction.FieldWallet, Op: transaction.OpIn, IDs: []int64{walletID}}},
+			{Condition: &transaction.Condition{Field: transaction.FieldDate, Op: transaction.OpGte, Date: from}},
+			{Condition: &transaction.Condition{Field: transaction.FieldDate, Op: transaction.OpLt, Date: to}},
+		}},
+		Sort: []transaction.Sort{{Field: transaction.SortDate}, {Field: transaction.SortID}},
+	}
+}
@@ -0,0 +1,57 @@
+package export
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+func Test_newEntry(t *testing.T) {
+	timestamp := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	names := map[int64]string{2: "Savings"}
+	tests := []struct {
+		name     string
+		dto      transaction.DTO
+		want     Entry
+		wantMemo string
+	}{
+		{
+			name:     "test deposit",
+			dto:      transaction.DTO{ID: 1, SenderID: 1, ReceiverID: 1, Amount: 10, Timestamp: timestamp, Type: transaction.TranTypeDeposit},
+			want:     Entry{TransactionID: 1, Type: transaction.TranTypeDeposit, Amount: 10, Timestamp: timestamp, Payee: "Deposit"},
+			wantMemo: "deposit",
+		},
+		{
+			name:     "test withdraw",
+			dto:      transaction.DTO{ID: 2, SenderID: 1, ReceiverID: 1, Amount: 10, Timestamp: timestamp, Type: transaction.TranTypeWithdraw},
+			want:     Entry{TransactionID: 2, Type: transaction.TranTypeWithdraw, Amount: -10, Timestamp: timestamp, Payee: "Withdrawal"},
+			wantMemo: "withdraw",
+		},
+		{
+			name:     "test outgoing transfer of split payment",
+			dto:      transaction.DTO{ID: 3, SenderID: 1, ReceiverID: 2, Amount: 2.5, Timestamp: timestamp, Type: transaction.TranTypeTransfer, ParentPaymentID: 7},
+			want:     Entry{TransactionID: 3, Type: transaction.TranTypeTransfer, Amount: -2.5, Timestamp: timestamp, CounterpartyID: 2, ParentPaymentID: 7, Payee: "Savings"},
+			wantMemo: "transfer to wallet 2, payment 7",
+		},
+		{
+			name:     "test incoming reversal from wallet without a name",
+			dto:      transaction.DTO{ID: 4, SenderID: 3, ReceiverID: 1, Amount: 2.5, Timestamp: timestamp, Type: transaction.TranTypeReversal},
+			want:     Entry{TransactionID: 4, Type: transaction.TranTypeReversal, Amount: 2.5, Timestamp: timestamp, CounterpartyID: 3, Payee: "Wallet #3"},
+			wantMemo: "reversal from wallet 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newEntry(tt.dto, 1)
+			got.setPayee(names)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newEntry() = %v, want %v", got, tt.want)
+			}
+			if memo := got.Memo(); memo != tt.wantMemo {
+				t.Errorf("Memo() = %q, want %q", memo, tt.wantMemo)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/bankstatement"
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+	"github.com/skwol/wallet/internal/domain/wallet"
+)
+
+type Service interface {
+	// Export writes the transactions of the wallet in the period, the returned wallet has ID 0 when there is no such wallet.
+	Export(ctx context.Context, w io.Writer, dto *ExportDTO) (wallet.DTO, error)
+}
+
+type service struct {
+	transactions transaction.Service
+	wallets      wallet.Service
+	logger       logging.Logger
+	clk          clock.Clock
+	currency     string
+}
+
+func NewService(transactions transaction.Service, wallets wallet.Service, logger logging.Logger, clk clock.Clock, currency string) (Service, error) {
+	if err := bankstatement.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+	return &service{transactions: transactions, wallets: wallets, logger: logger, clk: clk, currency: currency}, nil
+}
+
+func (s *service) Export(ctx context.Context, w io.Writer, dto *ExportDTO) (wallet.DTO, error) {
+	if !dto.Format.Valid() {
+		return wallet.DTO{}, errors.Wrapf(ErrUnknownFormat, "%q", dto.Format)
+	}
+	if !dto.From.Before(dto.To) {
+		return wallet.DTO{}, ErrInvalidPeriod
+	}
+	account, err := s.wallets.GetByID(ctx, dto.WalletID)
+	if err != nil || account.ID == 0 {
+		return wallet.DTO{}, err
+	}
+
+	// entries are read before the names of counterpart wallets, so that no query runs while the cursor is open
+	var entries []Entry
+	err = s.transactions.StreamFiltered(ctx, periodFilter(account.ID, dto.From, dto.To), 0, 0, func(tran transaction.DTO) error {
+		entries = append(entries, newEntry(tran, account.ID))
+		return nil
+	})
+	if err != nil {
+		s.logger.Errorf("error reading transactions to export: %s", err.Error())
+		return wallet.DTO{}, errors.Wrap(err, "error reading transactions to export")
+	}
+	names := make(map[int64]string)
+	for i := range entries {
+		id := entries[i].CounterpartyID
+		if _, ok := names[id]; id != 0 && !ok {
+			counterparty, err := s.wallets.GetByID(ctx, id)
+			if err != nil {
+				s.logger.Errorf("error getting counterpart wallet: %s", err.Error())
+				return wallet.DTO{}, errors.Wrap(err, "error getting counterpart wallet")
+			}
+			names[id] = counterparty.Name
+		}
+		entries[i].setPayee(names)
+	}
+
+	loc := dto.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	writer, err := NewWriter(dto.Format, w, Account{
+		WalletID: account.ID,
+		Name:     account.Name,
+		Currency: s.currency,
+		Balance:  account.Balance,
+		From:     dto.From,
+		To:       dto.To,
+		Created:  s.clk.Now(),
+	}, loc)
+	if err != nil {
+		return wallet.DTO{}, err
+	}
+	for _, entry := range entries {
+		if err := writer.Write(entry); err != nil {
+			return wallet.DTO{}, errors.Wrap(err, "error writing export")
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return wallet.DTO{}, errors.Wrap(err, "error writing export")
+	}
+	return account, nil
+}
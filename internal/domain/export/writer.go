@@ -0,0 +1,182 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+const (
+	ofxDateLayout = "20060102150405.000"
+	// ofxBankID is the routing number of the account, wallets have none
+	ofxBankID        = "WALLET"
+	ofxNameLength    = 32
+	ofxMemoLength    = 255
+	qifDateLayout    = "01/02/2006"
+	qifAccountHeader = "!Type:Bank"
+)
+
+// Writer writes the entries of the account in one of the formats.
+type Writer interface {
+	Write(Entry) error
+	// Close completes the export, it does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns the writer of the format, dates are written in loc where the format has no time zone.
+func NewWriter(format Format, w io.Writer, account Account, loc *time.Location) (Writer, error) {
+	switch format {
+	case FormatOFX:
+		return newOFXWriter(w, account)
+	case FormatQIF:
+		return newQIFWriter(w, loc)
+	}
+	return nil, errors.Wrapf(ErrUnknownFormat, "%q", format)
+}
+
+// ofxWriter writes OFX 2.2 bank statement download, FITID is the transaction id, so that tools importing
+// overlapping periods recognize the transactions they already have.
+type ofxWriter struct {
+	out     *bufio.Writer
+	account Account
+}
+
+func newOFXWriter(w io.Writer, account Account) (*ofxWriter, error) {
+	writer := &ofxWriter{out: bufio.NewWriter(w), account: account}
+	writer.raw(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	writer.raw(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	writer.raw("<OFX>\n<SIGNONMSGSRSV1>\n<SONRS>\n")
+	writer.status()
+	writer.element("DTSERVER", ofxDate(account.Created))
+	writer.element("LANGUAGE", "ENG")
+	writer.raw("</SONRS>\n</SIGNONMSGSRSV1>\n<BANKMSGSRSV1>\n<STMTTRNRS>\n")
+	writer.element("TRNUID", "0")
+	writer.status()
+	writer.raw("<STMTRS>\n")
+	writer.element("CURDEF", account.Currency)
+	writer.raw("<BANKACCTFROM>\n")
+	writer.element("BANKID", ofxBankID)
+	writer.element("ACCTID", strconv.FormatInt(account.WalletID, 10))
+	writer.element("ACCTTYPE", "CHECKING")
+	writer.raw("</BANKACCTFROM>\n<BANKTRANLIST>\n")
+	writer.element("DTSTART", ofxDate(account.From))
+	writer.element("DTEND", ofxDate(account.To))
+	return writer, writer.out.Flush()
+}
+
+func (w *ofxWriter) Write(entry Entry) error {
+	w.raw("<STMTTRN>\n")
+	w.element("TRNTYPE", ofxTransactionType(entry))
+	w.element("DTPOSTED", ofxDate(entry.Timestamp))
+	w.element("TRNAMT", formatAmount(entry.Amount))
+	w.element("FITID", strconv.FormatInt(entry.TransactionID, 10))
+	if entry.Payee != "" {
+		w.element("NAME", maxText(entry.Payee, ofxNameLength))
+	}
+	w.element("MEMO", maxText(entry.Memo(), ofxMemoLength))
+	w.raw("</STMTTRN>\n")
+	return w.out.Flush()
+}
+
+func (w *ofxWriter) Close() error {
+	w.raw("</BANKTRANLIST>\n<LEDGERBAL>\n")
+	w.element("BALAMT", formatAmount(w.account.Balance))
+	w.element("DTASOF", ofxDate(w.account.Created))
+	w.raw("</LEDGERBAL>\n</STMTRS>\n</STMTTRNRS>\n</BANKMSGSRSV1>\n</OFX>\n")
+	return w.out.Flush()
+}
+
+func (w *ofxWriter) status() {
+	w.raw("<STATUS>\n")
+	w.element("CODE", "0")
+	w.element("SEVERITY", "INFO")
+	w.raw("</STATUS>\n")
+}
+
+func (w *ofxWriter) element(name, value string) {
+	w.raw("<" + name + ">")
+	// bufio.Writer keeps the first error and returns it on Flush
+	_ = xml.EscapeText(w.out, []byte(value))
+	w.raw("</" + name + ">\n")
+}
+
+func (w *ofxWriter) raw(text string) {
+	w.out.WriteString(text)
+}
+
+// ofxDate writes the time in UTC with the time zone OFX readers expect, e.g. 20220501103000.000[0:GMT].
+func ofxDate(t time.Time) string {
+	return t.UTC().Format(ofxDateLayout) + "[0:GMT]"
+}
+
+func ofxTransactionType(e Entry) string {
+	switch {
+	case e.Type == transaction.TranTypeDeposit:
+		return "DEP"
+	case e.Type == transaction.TranTypeWithdraw:
+		return "CASH"
+	case e.Type == transaction.TranTypeTransfer:
+		return "XFER"
+	case e.Amount < 0:
+		return "DEBIT"
+	default:
+		return "CREDIT"
+	}
+}
+
+// qifWriter writes QIF bank account records, QIF has no transaction ids, so the id is the check number.
+type qifWriter struct {
+	out *bufio.Writer
+	loc *time.Location
+}
+
+func newQIFWriter(w io.Writer, loc *time.Location) (*qifWriter, error) {
+	writer := &qifWriter{out: bufio.NewWriter(w), loc: loc}
+	writer.out.WriteString(qifAccountHeader + "\n")
+	return writer, writer.out.Flush()
+}
+
+func (w *qifWriter) Write(entry Entry) error {
+	w.field('D', entry.Timestamp.In(w.loc).Format(qifDateLayout))
+	w.field('T', formatAmount(entry.Amount))
+	w.field('N', strconv.FormatInt(entry.TransactionID, 10))
+	if entry.Payee != "" {
+		w.field('P', entry.Payee)
+	}
+	w.field('M', entry.Memo())
+	w.out.WriteString("^\n")
+	return w.out.Flush()
+}
+
+func (w *qifWriter) Close() error {
+	return w.out.Flush()
+}
+
+// field writes a QIF line, line breaks in the value would start new fields.
+func (w *qifWriter) field(code byte, value string) {
+	w.out.WriteByte(code)
+	w.out.WriteString(strings.NewReplacer("\r", " ", "\n", " ").Replace(value))
+	w.out.WriteByte('\n')
+}
+
+// formatAmount writes the amount with a dot and without trailing zeros, amounts are kept with 4 decimals.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(math.Round(amount*1e4)/1e4, 'f', -1, 64)
+}
+
+// maxText cuts text to the max length in characters.
+func maxText(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length])
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+func TestNewWriter(t *testing.T) {
+	riga, err := time.LoadLocation("Europe/Riga")
+	if err != nil {
+		t.Skipf("missing time zone data: %s", err.Error())
+	}
+	from := time.Date(2022, 5, 1, 0, 0, 0, 0, riga)
+	account := Account{WalletID: 1, Name: "Main", Currency: "EUR", Balance: 7.5, From: from, To: from.AddDate(0, 1, 0), Created: from.AddDate(0, 2, 0)}
+	entries := []Entry{
+		{TransactionID: 1, Type: transaction.TranTypeDeposit, Amount: 10, Timestamp: time.Date(2022, 5, 1, 22, 30, 0, 0, time.UTC), Payee: "Deposit"},
+		{TransactionID: 2, Type: transaction.TranTypeTransfer, Amount: -2.5, Timestamp: time.Date(2022, 5, 2, 10, 0, 0, 0, time.UTC), CounterpartyID: 2, Payee: "Tom & Jerry <family> savings account"},
+	}
+	tests := []struct {
+		name    string
+		format  Format
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "test ofx",
+			format: FormatOFX,
+			want: `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1>
+<SONRS>
+<STATUS>
+<CODE>0</CODE>
+<SEVERITY>INFO</SEVERITY>
+</STATUS>
+<DTSERVER>20220630210000.000[0:GMT]</DTSERVER>
+<LANGUAGE>ENG</LANGUAGE>
+</SONRS>
+</SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>0</TRNUID>
+<STATUS>
+<CODE>0</CODE>
+<SEVERITY>INFO</SEVERITY>
+</STATUS>
+<STMTRS>
+<CURDEF>EUR</CURDEF>
+<BANKACCTFROM>
+<BANKID>WALLET</BANKID>
+<ACCTID>1</ACCTID>
+<ACCTTYPE>CHECKING</ACCTTYPE>
+</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20220430210000.000[0:GMT]</DTSTART>
+<DTEND>20220531210000.000[0:GMT]</DTEND>
+<STMTTRN>
+<TRNTYPE>DEP</TRNTYPE>
+<DTPOSTED>20220501223000.000[0:GMT]</DTPOSTED>
+<TRNAMT>10</TRNAMT>
+<FITID>1</FITID>
+<NAME>Deposit</NAME>
+<MEMO>deposit</MEMO>
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>XFER</TRNTYPE>
+<DTPOSTED>20220502100000.000[0:GMT]</DTPOSTED>
+<TRNAMT>-2.5</TRNAMT>
+<FITID>2</FITID>
+<NAME>Tom &amp; Jerry &lt;family&gt; savings acc</NAME>
+<MEMO>transfer to wallet 2</MEMO>
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL>
+<BALAMT>7.5</BALAMT>
+<DTASOF>20220630210000.000[0:GMT]</DTASOF>
+</LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`,
+		},
+		{
+			name:   "test qif",
+			format: FormatQIF,
+			want: "!Type:Bank\n" +
+				"D05/02/2022\nT10\nN1\nPDeposit\nMdeposit\n^\n" +
+				"D05/02/2022\nT-2.5\nN2\nPTom & Jerry <family> savings account\nMtransfer to wallet 2\n^\n",
+		},
+		{
+			name:    "test unknown format",
+			format:  "csv",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			writer, err := NewWriter(tt.format, &out, account, riga)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewWriter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for _, entry := range entries {
+				if err := writer.Write(entry); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("export =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+			if tt.format == FormatOFX {
+				checkWellFormed(t, out.Bytes())
+			}
+		})
+	}
+}
+
+func checkWellFormed(t *testing.T, document []byte) {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("document is not well formed: %s", err.Error())
+		}
+	}
+}
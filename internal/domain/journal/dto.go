@@ -0,0 +1,13 @@
+package journal
+
+import "time"
+
+// ExportDTO asks for the journal of the wallets for [From, To), all wallets when WalletIDs is empty.
+// Dates are written in Location.
+type ExportDTO struct {
+	WalletIDs []int64
+	Format    Format
+	From      time.Time
+	To        time.Time
+	Location  *time.Location
+}
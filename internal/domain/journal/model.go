@@ -0,0 +1,197 @@
+package journal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/wallet"
+)
+
+const (
+	FormatLedger    Format = "ledger"
+	FormatHledger   Format = "hledger"
+	FormatBeancount Format = "beancount"
+)
+
+const (
+	// AccountExternal is the other side of deposits and withdrawals, money coming from or going out of the system.
+	AccountExternal = "Equity:External"
+	// AccountOpening is the other side of balances the wallets have at the start of the period.
+	AccountOpening      = "Equity:Opening-Balances"
+	walletAccountPrefix = "Assets:Wallets:"
+)
+
+var (
+	ErrUnknownFormat  = errors.New("unknown journal format")
+	ErrInvalidPeriod  = errors.New("journal period should end after it starts")
+	ErrWalletNotFound = errors.New("wallet not found")
+)
+
+type Format string
+
+// Formats lists the supported formats with the extensions of their files.
+var Formats = map[Format]string{
+	FormatLedger:    "ledger",
+	FormatHledger:   "journal",
+	FormatBeancount: "beancount",
+}
+
+func (f Format) Valid() bool {
+	_, ok := Formats[f]
+	return ok
+}
+
+// Extension is the extension of the journal file the tool expects.
+func (f Format) Extension() string {
+	if extension, ok := Formats[f]; ok {
+		return extension
+	}
+	return "txt"
+}
+
+// WalletAccount is the account name of the wallet, it is valid in all formats.
+func WalletAccount(id int64) string {
+	return fmt.Sprintf("%s%d", walletAccountPrefix, id)
+}
+
+// Journal is the double entry journal of the wallets for [From, To). Every wallet is asserted to have
+// its Closing balance at the end of the period.
+type Journal struct {
+	From     time.Time
+	To       time.Time
+	Currency string
+	Created  time.Time
+	Wallets  []Account
+	Entries  []Entry
+}
+
+type Account struct {
+	WalletID int64
+	Name     string
+	Opening  float64
+	Closing  float64
+}
+
+// Entry moves Amount from the From account to the To account.
+type Entry struct {
+	TransactionID   int64
+	Type            wallet.TranType
+	Timestamp       time.Time
+	From            string
+	To              string
+	Amount          float64
+	ParentPaymentID int64
+}
+
+// Description is the payee of the entry.
+func (e Entry) Description() string {
+	switch e.Type {
+	case wallet.TranTypeDeposit:
+		return "Deposit"
+	case wallet.TranTypeWithdraw:
+		return "Withdrawal"
+	case wallet.TranTypeReversal:
+		return "Reversal"
+	default:
+		return "Transfer"
+	}
+}
+
+// Note is the narration of the entry.
+func (e Entry) Note() string {
+	note := fmt.Sprintf("%s %d", e.Type, e.TransactionID)
+	if e.ParentPaymentID != 0 {
+		note += fmt.Sprintf(", payment %d", e.ParentPaymentID)
+	}
+	return note
+}
+
+// newJournal joins the statements of the wallets, a transfer between two of them is in both statements
+// and is written once. The closing balance is the balance of the wallet when the period is not over yet,
+// so that the tools check the stored balance against the transactions, otherwise it is computed.
+func newJournal(statements []wallet.StatementDTO, from, to time.Time, currency string, timestamp time.Time) (*Journal, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+	journal := &Journal{From: from, To: to, Currency: currency, Created: timestamp, Wallets: make([]Account, 0, len(statements))}
+	written := make(map[int64]bool)
+	for _, statement := range statements {
+		account := Account{
+			WalletID: statement.Wallet.ID,
+			Name:     statement.Wallet.Name,
+			Opening:  statement.OpeningBalance,
+			Closing:  statement.ClosingBalance,
+		}
+		if !to.Before(timestamp) {
+			account.Closing = statement.Wallet.Balance
+		}
+		journal.Wallets = append(journal.Wallets, account)
+
+		for _, line := range statement.Lines {
+			if written[line.Transaction.ID] {
+				continue
+			}
+			written[line.Transaction.ID] = true
+			journal.Entries = append(journal.Entries, newEntry(line.Transaction))
+		}
+	}
+	sort.SliceStable(journal.Entries, func(i, j int) bool {
+		a, b := journal.Entries[i], journal.Entries[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return a.TransactionID < b.TransactionID
+	})
+	return journal, nil
+}
+
+func newEntry(tran wallet.TransactionDTO) Entry {
+	entry := Entry{
+		TransactionID:   tran.ID,
+		Type:            tran.Type,
+		Timestamp:       tran.Timestamp,
+		From:            WalletAccount(tran.SenderID),
+		To:              WalletAccount(tran.ReceiverID),
+		Amount:          tran.Amount,
+		ParentPaymentID: tran.ParentPaymentID,
+	}
+	switch tran.Type {
+	case wallet.TranTypeDeposit:
+		entry.From = AccountExternal
+	case wallet.TranTypeWithdraw:
+		entry.To = AccountExternal
+	}
+	return entry
+}
+
+// accounts lists the accounts used by the journal in the order they are declared.
+func (j *Journal) accounts() []string {
+	seen := make(map[string]bool)
+	var accounts []string
+	add := func(account string) {
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	for _, account := range j.Wallets {
+		add(WalletAccount(account.WalletID))
+	}
+	var others []string
+	for _, entry := range j.Entries {
+		for _, account := range []string{entry.From, entry.To} {
+			if !seen[account] && account != AccountExternal {
+				seen[account] = true
+				others = append(others, account)
+			}
+		}
+	}
+	sort.Strings(others)
+	accounts = append(accounts, others...)
+	add(AccountExternal)
+	add(AccountOpening)
+	return accounts
+}
@@ -0,0 +1,79 @@
+package journal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+
+	"github.com/skwol/wallet/internal/domain/wallet"
+)
+
+func Test_newJournal(t *testing.T) {
+	clk := clock.NewFake(time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC))
+	from := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	deposit := wallet.TransactionDTO{ID: 1, SenderID: 1, ReceiverID: 1, Amount: 10, Timestamp: from.Add(time.Hour), Type: wallet.TranTypeDeposit}
+	transfer := wallet.TransactionDTO{ID: 2, SenderID: 1, ReceiverID: 2, Amount: 4, Timestamp: from.Add(2 * time.Hour), Type: wallet.TranTypeTransfer, ParentPaymentID: 7}
+	withdraw := wallet.TransactionDTO{ID: 3, SenderID: 2, ReceiverID: 2, Amount: 1, Timestamp: from.Add(time.Hour), Type: wallet.TranTypeWithdraw}
+	statements := []wallet.StatementDTO{
+		{
+			Wallet:         wallet.DTO{ID: 1, Name: "Main", Balance: 20},
+			OpeningBalance: 5,
+			ClosingBalance: 11,
+			Lines:          []wallet.StatementLineDTO{{Transaction: deposit}, {Transaction: transfer}},
+		},
+		{
+			Wallet:         wallet.DTO{ID: 2, Name: "Savings", Balance: 3},
+			ClosingBalance: 3,
+			Lines:          []wallet.StatementLineDTO{{Transaction: withdraw}, {Transaction: transfer}},
+		},
+	}
+	entries := []Entry{
+		{TransactionID: 1, Type: wallet.TranTypeDeposit, Timestamp: deposit.Timestamp, From: AccountExternal, To: "Assets:Wallets:1", Amount: 10},
+		{TransactionID: 3, Type: wallet.TranTypeWithdraw, Timestamp: withdraw.Timestamp, From: "Assets:Wallets:2", To: AccountExternal, Amount: 1},
+		{TransactionID: 2, Type: wallet.TranTypeTransfer, Timestamp: transfer.Timestamp, From: "Assets:Wallets:1", To: "Assets:Wallets:2", Amount: 4, ParentPaymentID: 7},
+	}
+	tests := []struct {
+		name    string
+		to      time.Time
+		want    *Journal
+		wantErr error
+	}{
+		{
+			name:    "test invalid period",
+			to:      from,
+			wantErr: ErrInvalidPeriod,
+		},
+		{
+			name: "test closed period asserts computed balances",
+			to:   to,
+			want: &Journal{From: from, To: to, Currency: "EUR", Created: clk.Now(), Entries: entries, Wallets: []Account{
+				{WalletID: 1, Name: "Main", Opening: 5, Closing: 11},
+				{WalletID: 2, Name: "Savings", Closing: 3},
+			}},
+		},
+		{
+			name: "test open period asserts wallet balances",
+			to:   clk.Now(),
+			want: &Journal{From: from, To: clk.Now(), Currency: "EUR", Created: clk.Now(), Entries: entries, Wallets: []Account{
+				{WalletID: 1, Name: "Main", Opening: 5, Closing: 20},
+				{WalletID: 2, Name: "Savings", Closing: 3},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newJournal(statements, from, tt.to, "EUR", clk.Now())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("newJournal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newJournal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package journal
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/bankstatement"
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/pagination"
+
+	"github.com/skwol/wallet/internal/domain/wallet"
+)
+
+type Service interface {
+	// Export writes the journal of the wallets, ErrWalletNotFound is returned for a missing wallet.
+	Export(ctx context.Context, w io.Writer, dto *ExportDTO) error
+}
+
+type service struct {
+	wallets  wallet.Service
+	logger   logging.Logger
+	clk      clock.Clock
+	currency string
+}
+
+func NewService(wallets wallet.Service, logger logging.Logger, clk clock.Clock, currency string) (Service, error) {
+	if err := bankstatement.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+	return &service{wallets: wallets, logger: logger, clk: clk, currency: currency}, nil
+}
+
+func (s *service) Export(ctx context.Context, w io.Writer, dto *ExportDTO) error {
+	if !dto.Format.Valid() {
+		return errors.Wrapf(ErrUnknownFormat, "%q", dto.Format)
+	}
+	if !dto.From.Before(dto.To) {
+		return ErrInvalidPeriod
+	}
+	ids := dto.WalletIDs
+	if len(ids) == 0 {
+		var err error
+		if ids, err = s.allWalletIDs(ctx); err != nil {
+			s.logger.Errorf("error listing wallets: %s", err.Error())
+			return errors.Wrap(err, "error listing wallets")
+		}
+	}
+
+	statements := make([]wallet.StatementDTO, 0, len(ids))
+	for _, id := range ids {
+		statement, err := s.wallets.GetStatement(ctx, id, dto.From, dto.To)
+		if err != nil {
+			s.logger.Errorf("error getting wallet statement: %s", err.Error())
+			return errors.Wrap(err, "error getting wallet statement")
+		}
+		if statement.Wallet.ID == 0 {
+			return errors.Wrapf(ErrWalletNotFound, "%d", id)
+		}
+		statements = append(statements, statement)
+	}
+	journal, err := newJournal(statements, dto.From, dto.To, s.currency, s.clk.Now())
+	if err != nil {
+		return errors.Wrap(err, "error creating journal model")
+	}
+
+	loc := dto.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if err := Write(w, dto.Format, journal, loc); err != nil {
+		return errors.Wrap(err, "error writing journal")
+	}
+	return nil
+}
+
+func (s *service) allWalletIDs(ctx context.Context) ([]int64, error) {
+	var ids []int64
+	page := pagination.Page{Limit: pagination.MaxLimit}
+	for {
+		result, err := s.wallets.GetPage(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range result.Wallets {
+			ids = append(ids, w.ID)
+		}
+		if result.Next == "" || len(result.Wallets) == 0 {
+			return ids, nil
+		}
+		page.After = result.Wallets[len(result.Wallets)-1].ID
+	}
+}
@@ -0,0 +1,155 @@
+package journal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const dateLayout = "2006-01-02"
+
+// Write writes the journal in the syntax of the format, dates are in loc.
+// Ledger and hledger assert closing balances with a posting of zero on the last day of the period,
+// beancount asserts them with balance directives, which check the balance at the start of the next day.
+func Write(w io.Writer, format Format, j *Journal, loc *time.Location) error {
+	out := bufio.NewWriter(w)
+	switch format {
+	case FormatLedger, FormatHledger:
+		writeLedger(out, format, j, loc)
+	case FormatBeancount:
+		writeBeancount(out, j, loc)
+	default:
+		return errors.Wrapf(ErrUnknownFormat, "%q", format)
+	}
+	return out.Flush()
+}
+
+func writeLedger(out *bufio.Writer, format Format, j *Journal, loc *time.Location) {
+	fmt.Fprintf(out, "; %s\n\n", j.title(loc))
+	fmt.Fprintf(out, "commodity %s\n\n", j.Currency)
+	names := j.names()
+	for _, account := range j.accounts() {
+		name := names[account]
+		switch {
+		case name == "":
+			fmt.Fprintf(out, "account %s\n", account)
+		case format == FormatLedger:
+			fmt.Fprintf(out, "account %s\n    note %s\n", account, singleLine(name))
+		default:
+			fmt.Fprintf(out, "account %s  ; %s\n", account, singleLine(name))
+		}
+	}
+
+	from := j.From.In(loc).Format(dateLayout)
+	if postings := j.openingPostings(); len(postings) > 0 {
+		fmt.Fprintf(out, "\n%s * Opening balances\n", from)
+		for _, p := range postings {
+			fmt.Fprintf(out, "    %s  %s\n", p.account, j.amount(p.amount))
+		}
+	}
+	for _, e := range j.Entries {
+		fmt.Fprintf(out, "\n%s * (%d) %s\n", e.Timestamp.In(loc).Format(dateLayout), e.TransactionID, e.Description())
+		fmt.Fprintf(out, "    ; %s\n", e.Note())
+		fmt.Fprintf(out, "    %s  %s\n", e.To, j.amount(e.Amount))
+		fmt.Fprintf(out, "    %s  %s\n", e.From, j.amount(-e.Amount))
+	}
+	if len(j.Wallets) > 0 {
+		fmt.Fprintf(out, "\n%s * Closing balances\n", j.lastDay(loc).Format(dateLayout))
+		for _, account := range j.Wallets {
+			fmt.Fprintf(out, "    %s  %s = %s\n", WalletAccount(account.WalletID), j.amount(0), j.amount(account.Closing))
+		}
+	}
+}
+
+func writeBeancount(out *bufio.Writer, j *Journal, loc *time.Location) {
+	fmt.Fprintf(out, "; %s\n\n", j.title(loc))
+	fmt.Fprintf(out, "option \"operating_currency\" %s\n\n", quote(j.Currency))
+	from := j.From.In(loc).Format(dateLayout)
+	names := j.names()
+	for _, account := range j.accounts() {
+		fmt.Fprintf(out, "%s open %s %s\n", from, account, j.Currency)
+		if name := names[account]; name != "" {
+			fmt.Fprintf(out, "  name: %s\n", quote(name))
+		}
+	}
+
+	if postings := j.openingPostings(); len(postings) > 0 {
+		fmt.Fprintf(out, "\n%s * \"Opening balances\"\n", from)
+		for _, p := range postings {
+			fmt.Fprintf(out, "  %s  %s\n", p.account, j.amount(p.amount))
+		}
+	}
+	for _, e := range j.Entries {
+		fmt.Fprintf(out, "\n%s * %s %s\n", e.Timestamp.In(loc).Format(dateLayout), quote(e.Description()), quote(e.Note()))
+		fmt.Fprintf(out, "  transaction-id: %s\n", quote(strconv.FormatInt(e.TransactionID, 10)))
+		fmt.Fprintf(out, "  %s  %s\n", e.To, j.amount(e.Amount))
+		fmt.Fprintf(out, "  %s  %s\n", e.From, j.amount(-e.Amount))
+	}
+	if len(j.Wallets) > 0 {
+		out.WriteString("\n")
+		next := j.lastDay(loc).AddDate(0, 0, 1).Format(dateLayout)
+		for _, account := range j.Wallets {
+			fmt.Fprintf(out, "%s balance %s  %s\n", next, WalletAccount(account.WalletID), j.amount(account.Closing))
+		}
+	}
+}
+
+type posting struct {
+	account string
+	amount  float64
+}
+
+// openingPostings brings the wallets to their opening balances, there are none when all of them start empty.
+func (j *Journal) openingPostings() []posting {
+	var postings []posting
+	var total float64
+	for _, account := range j.Wallets {
+		if account.Opening != 0 {
+			postings = append(postings, posting{account: WalletAccount(account.WalletID), amount: account.Opening})
+			total += account.Opening
+		}
+	}
+	if len(postings) == 0 {
+		return nil
+	}
+	return append(postings, posting{account: AccountOpening, amount: -total})
+}
+
+func (j *Journal) names() map[string]string {
+	names := make(map[string]string, len(j.Wallets))
+	for _, account := range j.Wallets {
+		names[WalletAccount(account.WalletID)] = account.Name
+	}
+	return names
+}
+
+func (j *Journal) title(loc *time.Location) string {
+	return fmt.Sprintf("Wallet journal from %s to %s, created %s",
+		j.From.In(loc).Format(dateLayout), j.lastDay(loc).Format(dateLayout), j.Created.In(loc).Format(time.RFC3339))
+}
+
+// lastDay is the date of the last moment of the period, To is not included.
+func (j *Journal) lastDay(loc *time.Location) time.Time {
+	return j.To.Add(-time.Nanosecond).In(loc)
+}
+
+// amount writes the amount with the currency after it, amounts are kept with 4 decimals.
+func (j *Journal) amount(value float64) string {
+	// adding 0 turns negative zero into zero
+	return strconv.FormatFloat(math.Round(value*1e4)/1e4+0, 'f', -1, 64) + " " + j.Currency
+}
+
+func singleLine(text string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(text)
+}
+
+// quote writes the beancount string, which has no escapes but for quotes and backslashes.
+func quote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
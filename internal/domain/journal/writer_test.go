@@ -0,0 +1,147 @@
+package journal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/wallet"
+)
+
+func TestWrite(t *testing.T) {
+	from := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	journal := &Journal{
+		From:     from,
+		To:       from.AddDate(0, 1, 0),
+		Currency: "EUR",
+		Created:  from.AddDate(0, 2, 0),
+		Wallets:  []Account{{WalletID: 1, Name: `Jörg "Main"`, Opening: 5, Closing: 10.125}, {WalletID: 2}},
+		Entries: []Entry{
+			{TransactionID: 1, Type: wallet.TranTypeDeposit, Timestamp: from.Add(time.Hour), From: AccountExternal, To: "Assets:Wallets:1", Amount: 10.125},
+			{TransactionID: 2, Type: wallet.TranTypeTransfer, Timestamp: from.Add(26 * time.Hour), From: "Assets:Wallets:1", To: "Assets:Wallets:3", Amount: 5, ParentPaymentID: 7},
+		},
+	}
+	tests := []struct {
+		name    string
+		format  Format
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "test ledger",
+			format: FormatLedger,
+			want: `; Wallet journal from 2022-05-01 to 2022-05-31, created 2022-07-01T00:00:00Z
+
+commodity EUR
+
+account Assets:Wallets:1
+    note Jörg "Main"
+account Assets:Wallets:2
+account Assets:Wallets:3
+account Equity:External
+account Equity:Opening-Balances
+
+2022-05-01 * Opening balances
+    Assets:Wallets:1  5 EUR
+    Equity:Opening-Balances  -5 EUR
+
+2022-05-01 * (1) Deposit
+    ; deposit 1
+    Assets:Wallets:1  10.125 EUR
+    Equity:External  -10.125 EUR
+
+2022-05-02 * (2) Transfer
+    ; transfer 2, payment 7
+    Assets:Wallets:3  5 EUR
+    Assets:Wallets:1  -5 EUR
+
+2022-05-31 * Closing balances
+    Assets:Wallets:1  0 EUR = 10.125 EUR
+    Assets:Wallets:2  0 EUR = 0 EUR
+`,
+		},
+		{
+			name:   "test hledger",
+			format: FormatHledger,
+			want: `; Wallet journal from 2022-05-01 to 2022-05-31, created 2022-07-01T00:00:00Z
+
+commodity EUR
+
+account Assets:Wallets:1  ; Jörg "Main"
+account Assets:Wallets:2
+account Assets:Wallets:3
+account Equity:External
+account Equity:Opening-Balances
+
+2022-05-01 * Opening balances
+    Assets:Wallets:1  5 EUR
+    Equity:Opening-Balances  -5 EUR
+
+2022-05-01 * (1) Deposit
+    ; deposit 1
+    Assets:Wallets:1  10.125 EUR
+    Equity:External  -10.125 EUR
+
+2022-05-02 * (2) Transfer
+    ; transfer 2, payment 7
+    Assets:Wallets:3  5 EUR
+    Assets:Wallets:1  -5 EUR
+
+2022-05-31 * Closing balances
+    Assets:Wallets:1  0 EUR = 10.125 EUR
+    Assets:Wallets:2  0 EUR = 0 EUR
+`,
+		},
+		{
+			name:   "test beancount",
+			format: FormatBeancount,
+			want: `; Wallet journal from 2022-05-01 to 2022-05-31, created 2022-07-01T00:00:00Z
+
+option "operating_currency" "EUR"
+
+2022-05-01 open Assets:Wallets:1 EUR
+  name: "Jörg \"Main\""
+2022-05-01 open Assets:Wallets:2 EUR
+2022-05-01 open Assets:Wallets:3 EUR
+2022-05-01 open Equity:External EUR
+2022-05-01 open Equity:Opening-Balances EUR
+
+2022-05-01 * "Opening balances"
+  Assets:Wallets:1  5 EUR
+  Equity:Opening-Balances  -5 EUR
+
+2022-05-01 * "Deposit" "deposit 1"
+  transaction-id: "1"
+  Assets:Wallets:1  10.125 EUR
+  Equity:External  -10.125 EUR
+
+2022-05-02 * "Transfer" "transfer 2, payment 7"
+  transaction-id: "2"
+  Assets:Wallets:3  5 EUR
+  Assets:Wallets:1  -5 EUR
+
+2022-06-01 balance Assets:Wallets:1  10.125 EUR
+2022-06-01 balance Assets:Wallets:2  0 EUR
+`,
+		},
+		{
+			name:    "test unknown format",
+			format:  "gnucash",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Write(&out, tt.format, journal, time.UTC)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && out.String() != tt.want {
+				t.Errorf("Write() =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
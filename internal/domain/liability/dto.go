@@ -0,0 +1,37 @@
+package liability
+
+import (
+	"time"
+
+	"github.com/skwol/wallet/pkg/merklesum"
+)
+
+type BalanceDTO struct {
+	WalletID int64
+	Balance  float64
+}
+
+// TreeDTO is the published tree, Depth is the number of levels above the leaves.
+type TreeDTO struct {
+	ID        int64
+	Root      merklesum.Hash
+	Total     merklesum.Sum
+	Wallets   int64
+	Depth     int
+	CreatedAt time.Time
+}
+
+// NodeDTO is the node of the tree, leaves have the wallet and the salt of their hash.
+type NodeDTO struct {
+	Level    int
+	Position int64
+	Node     merklesum.Node
+	WalletID int64
+	Salt     []byte
+}
+
+// ProofDTO is the inclusion proof of the wallet in the tree.
+type ProofDTO struct {
+	Tree  TreeDTO
+	Proof merklesum.Proof
+}
@@ -0,0 +1,83 @@
+package liability
+
+import (
+	"crypto/rand"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/merklesum"
+)
+
+const (
+	// TreesKept is how many recent trees keep their nodes for proofs, older ones only keep the published root.
+	TreesKept = 24
+	saltSize  = 32
+)
+
+var (
+	ErrNoTree = errors.New("no liability tree is built yet")
+	// ErrWalletNotInTree is returned for wallets created after the tree was built.
+	ErrWalletNotInTree = errors.New("wallet is not in the liability tree")
+)
+
+// newTree builds the tree over balances, leaves are in the order of balances.
+func newTree(balances []BalanceDTO, salt func() ([]byte, error)) (TreeDTO, []NodeDTO, error) {
+	leaves := make([]merklesum.Node, 0, len(balances))
+	salts := make([][]byte, 0, len(balances))
+	for _, balance := range balances {
+		s, err := salt()
+		if err != nil {
+			return TreeDTO{}, nil, errors.Wrap(err, "error generating salt")
+		}
+		leaves = append(leaves, merklesum.Leaf(balance.WalletID, merklesum.NewSum(balance.Balance), s))
+		salts = append(salts, s)
+	}
+	levels, err := merklesum.Build(leaves)
+	if err != nil {
+		return TreeDTO{}, nil, err
+	}
+
+	var nodes []NodeDTO
+	for level, levelNodes := range levels {
+		for position, node := range levelNodes {
+			dto := NodeDTO{Level: level, Position: int64(position), Node: node}
+			if level == 0 && len(balances) > 0 {
+				dto.WalletID, dto.Salt = balances[position].WalletID, salts[position]
+			}
+			nodes = append(nodes, dto)
+		}
+	}
+	root := levels[len(levels)-1][0]
+	return TreeDTO{Root: root.Hash, Total: root.Sum, Wallets: int64(len(balances)), Depth: len(levels) - 1}, nodes, nil
+}
+
+func randomSalt() ([]byte, error) {
+	salt := make([]byte, saltSize)
+	_, err := rand.Read(salt)
+	return salt, err
+}
+
+// siblingPositions are positions of siblings of the leaf at position on every level below the root.
+func siblingPositions(position int64, depth int) []int64 {
+	positions := make([]int64, 0, depth)
+	for level := 0; level < depth; level++ {
+		positions = append(positions, merklesum.SiblingPosition(position))
+		position /= 2
+	}
+	return positions
+}
+
+// newProof puts siblings of the leaf in order, siblings past the end of their level are padding.
+func newProof(leaf NodeDTO, depth int, siblings map[int]merklesum.Node) merklesum.Proof {
+	proof := merklesum.Proof{ID: leaf.WalletID, Sum: leaf.Node.Sum, Salt: leaf.Salt, Path: make([]merklesum.Step, 0, depth)}
+	position := leaf.Position
+	for level := 0; level < depth; level++ {
+		sibling, ok := siblings[level]
+		if !ok {
+			sibling = merklesum.Empty
+		}
+		proof.Path = append(proof.Path, merklesum.Step{Node: sibling, Left: position%2 == 1})
+		position /= 2
+	}
+	return proof
+}
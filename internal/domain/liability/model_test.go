@@ -0,0 +1,79 @@
+package liability
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/skwol/wallet/pkg/merklesum"
+)
+
+func fixedSalt() ([]byte, error) {
+	return bytes.Repeat([]byte{7}, saltSize), nil
+}
+
+func TestNewTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances []BalanceDTO
+		total    merklesum.Sum
+		depth    int
+	}{
+		{name: "no wallets", total: 0, depth: 0},
+		{name: "one wallet", balances: []BalanceDTO{{WalletID: 1, Balance: 10.5}}, total: 105000, depth: 0},
+		{
+			name:     "odd wallets",
+			balances: []BalanceDTO{{WalletID: 1, Balance: 10.5}, {WalletID: 2, Balance: 0}, {WalletID: 5, Balance: 0.0001}},
+			total:    105001,
+			depth:    2,
+		},
+		{
+			name: "more wallets",
+			balances: []BalanceDTO{{WalletID: 1, Balance: 1}, {WalletID: 2, Balance: 2}, {WalletID: 3, Balance: 3}, {WalletID: 4, Balance: 4},
+				{WalletID: 6, Balance: 6}, {WalletID: 7, Balance: 7}},
+			total: 230000,
+			depth: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, nodes, err := newTree(tt.balances, fixedSalt)
+			if err != nil {
+				t.Fatalf("newTree() unexpected error = %v", err)
+			}
+			if tree.Total != tt.total || tree.Depth != tt.depth || tree.Wallets != int64(len(tt.balances)) {
+				t.Errorf("newTree() = total %s, depth %d, wallets %d, want total %s, depth %d, wallets %d",
+					tree.Total, tree.Depth, tree.Wallets, tt.total, tt.depth, len(tt.balances))
+			}
+
+			// proofs are put together from stored nodes the way the service does
+			byPosition := make(map[[2]int64]merklesum.Node, len(nodes))
+			leaves := make(map[int64]NodeDTO)
+			for _, node := range nodes {
+				byPosition[[2]int64{int64(node.Level), node.Position}] = node.Node
+				if node.WalletID != 0 {
+					leaves[node.WalletID] = node
+				}
+			}
+			if len(leaves) != len(tt.balances) {
+				t.Fatalf("newTree() has %d leaves with wallets, want %d", len(leaves), len(tt.balances))
+			}
+			for _, balance := range tt.balances {
+				leaf := leaves[balance.WalletID]
+				siblings := make(map[int]merklesum.Node)
+				for level, position := range siblingPositions(leaf.Position, tree.Depth) {
+					if node, ok := byPosition[[2]int64{int64(level), position}]; ok {
+						siblings[level] = node
+					}
+				}
+				root, err := merklesum.Verify(newProof(leaf, tree.Depth, siblings), tree.Root)
+				if err != nil {
+					t.Errorf("Verify() of wallet %d unexpected error = %v", balance.WalletID, err)
+					continue
+				}
+				if root.Sum != tree.Total {
+					t.Errorf("Verify() of wallet %d total = %s, want %s", balance.WalletID, root.Sum, tree.Total)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package liability
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+type Service interface {
+	// Build builds the Merkle sum tree over balances of all wallets and publishes its root and total,
+	// it is the job which keeps the published tree recent.
+	Build(context.Context) error
+	// GetLatest returns the last published tree.
+	GetLatest(context.Context) (TreeDTO, error)
+	// GetProof returns the proof that the balance of the wallet is included in the total of the last published tree.
+	GetProof(ctx context.Context, walletID int64) (ProofDTO, error)
+}
+
+type service struct {
+	storage Storage
+	logger  logging.Logger
+	clk     clock.Clock
+}
+
+func NewService(storage Storage, logger logging.Logger, clk clock.Clock) (Service, error) {
+	return &service{storage: storage, logger: logger, clk: clk}, nil
+}
+
+func (s *service) Build(ctx context.Context) error {
+	balances, err := s.storage.GetBalances(ctx)
+	if err != nil {
+		s.logger.Errorf("error getting wallet balances from db: %s", err.Error())
+		return errors.Wrap(err, "error getting wallet balances from db")
+	}
+	tree, nodes, err := newTree(balances, randomSalt)
+	if err != nil {
+		s.logger.Errorf("error building liability tree: %s", err.Error())
+		return errors.Wrap(err, "error building liability tree")
+	}
+	tree.CreatedAt = s.clk.Now()
+	if tree, err = s.storage.CreateTree(ctx, tree, nodes, TreesKept); err != nil {
+		s.logger.Errorf("error saving liability tree: %s", err.Error())
+		return errors.Wrap(err, "error saving liability tree")
+	}
+	s.logger.Infof("liability tree %d published over %d wallets, total %s, root %s", tree.ID, tree.Wallets, tree.Total, tree.Root)
+	return nil
+}
+
+func (s *service) GetLatest(ctx context.Context) (TreeDTO, error) {
+	tree, err := s.storage.GetLatestTree(ctx)
+	if err != nil {
+		s.logger.Errorf("error getting liability tree from db: %s", err.Error())
+		return TreeDTO{}, errors.Wrap(err, "error getting liability tree from db")
+	}
+	if tree.ID == 0 {
+		return TreeDTO{}, ErrNoTree
+	}
+	return tree, nil
+}
+
+func (s *service) GetProof(ctx context.Context, walletID int64) (ProofDTO, error) {
+	tree, err := s.GetLatest(ctx)
+	if err != nil {
+		return ProofDTO{}, err
+	}
+	leaf, err := s.storage.GetLeaf(ctx, tree.ID, walletID)
+	if err != nil {
+		s.logger.Errorf("error getting liability leaf from db: %s", err.Error())
+		return ProofDTO{}, errors.Wrap(err, "error getting liability leaf from db")
+	}
+	if leaf.WalletID == 0 {
+		return ProofDTO{}, ErrWalletNotInTree
+	}
+	siblings, err := s.storage.GetSiblings(ctx, tree.ID, siblingPositions(leaf.Position, tree.Depth))
+	if err != nil {
+		s.logger.Errorf("error getting liability nodes from db: %s", err.Error())
+		return ProofDTO{}, errors.Wrap(err, "error getting liability nodes from db")
+	}
+	return ProofDTO{Tree: tree, Proof: newProof(leaf, tree.Depth, siblings)}, nil
+}
@@ -0,0 +1,20 @@
+package liability
+
+import (
+	"context"
+
+	"github.com/skwol/wallet/pkg/merklesum"
+)
+
+type Storage interface {
+	// GetBalances returns balances of all wallets in id order as of one moment.
+	GetBalances(ctx context.Context) ([]BalanceDTO, error)
+	// CreateTree writes the tree with its nodes and removes nodes of trees older than the last kept ones.
+	CreateTree(ctx context.Context, tree TreeDTO, nodes []NodeDTO, kept int) (TreeDTO, error)
+	// GetLatestTree returns the tree with zero id when there is none.
+	GetLatestTree(ctx context.Context) (TreeDTO, error)
+	// GetLeaf returns the leaf of the wallet in the tree, with zero wallet id when there is none.
+	GetLeaf(ctx context.Context, treeID, walletID int64) (NodeDTO, error)
+	// GetSiblings returns nodes at positions on their levels, positions[level] is the one of the level, missing nodes are left out.
+	GetSiblings(ctx context.Context, treeID int64, positions []int64) (map[int]merklesum.Node, error)
+}
@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventDTO is the event as it is kept in the outbox and published, AggregateID is the wallet or the transaction it is about.
+type EventDTO struct {
+	ID          int64           `json:"id"`
+	Type        EventType       `json:"type"`
+	AggregateID int64           `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type WalletCreated struct {
+	WalletID int64   `json:"wallet_id"`
+	Name     string  `json:"name"`
+	Balance  float64 `json:"balance"`
+}
+
+// WalletUpdated has the status only when it is changed.
+type WalletUpdated struct {
+	WalletID int64   `json:"wallet_id"`
+	Name     string  `json:"name"`
+	Balance  float64 `json:"balance"`
+	Status   string  `json:"status,omitempty"`
+}
+
+// DepositMade is DepositMade or WithdrawalMade event, depending on the type of the transaction.
+type DepositMade struct {
+	TransactionID int64     `json:"transaction_id"`
+	WalletID      int64     `json:"wallet_id"`
+	Amount        float64   `json:"amount"`
+	Date          time.Time `json:"date"`
+}
+
+type TransferCompleted struct {
+	TransactionID   int64     `json:"transaction_id"`
+	SenderID        int64     `json:"sender_id"`
+	ReceiverID      int64     `json:"receiver_id"`
+	Amount          float64   `json:"amount"`
+	Date            time.Time `json:"date"`
+	ParentPaymentID int64     `json:"parent_payment_id,omitempty"`
+}
+
+// ReversalCompleted is the money of the disputed transaction returned by the resolved dispute.
+type ReversalCompleted struct {
+	TransactionID         int64     `json:"transaction_id"`
+	DisputeID             int64     `json:"dispute_id"`
+	DisputedTransactionID int64     `json:"disputed_transaction_id"`
+	SenderID              int64     `json:"sender_id"`
+	ReceiverID            int64     `json:"receiver_id"`
+	Amount                float64   `json:"amount"`
+	Date                  time.Time `json:"date"`
+}
+
+// AdjustmentMade is the deposit or withdrawal posted by reconciliation, Type is either of them.
+type AdjustmentMade struct {
+	TransactionID int64     `json:"transaction_id"`
+	AdjustmentID  int64     `json:"adjustment_id"`
+	WalletID      int64     `json:"wallet_id"`
+	Type          string    `json:"type"`
+	Amount        float64   `json:"amount"`
+	Reason        string    `json:"reason"`
+	Date          time.Time `json:"date"`
+}
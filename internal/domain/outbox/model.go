@@ -0,0 +1,64 @@
+package outbox
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	EventWalletCreated     EventType = "WalletCreated"
+	EventWalletUpdated     EventType = "WalletUpdated"
+	EventDepositMade       EventType = "DepositMade"
+	EventWithdrawalMade    EventType = "WithdrawalMade"
+	EventTransferCompleted EventType = "TransferCompleted"
+	EventReversalCompleted EventType = "ReversalCompleted"
+	EventAdjustmentMade    EventType = "AdjustmentMade"
+)
+
+// eventsPage is how many events are published at once.
+const eventsPage = 100
+
+var (
+	ErrUnknownPublisher = errors.New("unknown outbox publisher")
+	ErrMissingPublisher = errors.New("outbox publisher is not set")
+	ErrNoSubscribers    = errors.New("outbox bus has no subscribers")
+)
+
+type EventType string
+
+func newEvent(eventType EventType, aggregateID int64, payload interface{}) (EventDTO, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return EventDTO{}, errors.Wrapf(err, "error marshaling %s event", eventType)
+	}
+	return EventDTO{Type: eventType, AggregateID: aggregateID, Payload: data}, nil
+}
+
+func NewWalletCreated(payload WalletCreated) (EventDTO, error) {
+	return newEvent(EventWalletCreated, payload.WalletID, payload)
+}
+
+func NewWalletUpdated(payload WalletUpdated) (EventDTO, error) {
+	return newEvent(EventWalletUpdated, payload.WalletID, payload)
+}
+
+// NewDepositMade is the event of the deposit, or of the withdrawal when withdraw is set.
+func NewDepositMade(payload DepositMade, withdraw bool) (EventDTO, error) {
+	if withdraw {
+		return newEvent(EventWithdrawalMade, payload.WalletID, payload)
+	}
+	return newEvent(EventDepositMade, payload.WalletID, payload)
+}
+
+func NewTransferCompleted(payload TransferCompleted) (EventDTO, error) {
+	return newEvent(EventTransferCompleted, payload.TransactionID, payload)
+}
+
+func NewReversalCompleted(payload ReversalCompleted) (EventDTO, error) {
+	return newEvent(EventReversalCompleted, payload.TransactionID, payload)
+}
+
+func NewAdjustmentMade(payload AdjustmentMade) (EventDTO, error) {
+	return newEvent(EventAdjustmentMade, payload.WalletID, payload)
+}
@@ -0,0 +1,184 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestNewEvents(t *testing.T) {
+	date := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	newEvent := func(event EventDTO, err error) func() (EventDTO, error) {
+		return func() (EventDTO, error) { return event, err }
+	}
+	tests := []struct {
+		name      string
+		new       func() (EventDTO, error)
+		eventType EventType
+		aggregate int64
+		payload   string
+	}{
+		{
+			name:      "wallet created",
+			new:       newEvent(NewWalletCreated(WalletCreated{WalletID: 1, Name: "alice", Balance: 10})),
+			eventType: EventWalletCreated,
+			aggregate: 1,
+			payload:   `{"wallet_id":1,"name":"alice","balance":10}`,
+		},
+		{
+			name:      "wallet updated",
+			new:       newEvent(NewWalletUpdated(WalletUpdated{WalletID: 1, Name: "alice", Balance: 10})),
+			eventType: EventWalletUpdated,
+			aggregate: 1,
+			payload:   `{"wallet_id":1,"name":"alice","balance":10}`,
+		},
+		{
+			name:      "wallet closed",
+			new:       newEvent(NewWalletUpdated(WalletUpdated{WalletID: 1, Name: "alice", Balance: 0, Status: "closed"})),
+			eventType: EventWalletUpdated,
+			aggregate: 1,
+			payload:   `{"wallet_id":1,"name":"alice","balance":0,"status":"closed"}`,
+		},
+		{
+			name:      "deposit",
+			new:       newEvent(NewDepositMade(DepositMade{TransactionID: 5, WalletID: 1, Amount: 2.5, Date: date}, false)),
+			eventType: EventDepositMade,
+			aggregate: 1,
+			payload:   `{"transaction_id":5,"wallet_id":1,"amount":2.5,"date":"2022-05-01T10:00:00Z"}`,
+		},
+		{
+			name:      "withdrawal",
+			new:       newEvent(NewDepositMade(DepositMade{TransactionID: 6, WalletID: 1, Amount: 2.5, Date: date}, true)),
+			eventType: EventWithdrawalMade,
+			aggregate: 1,
+			payload:   `{"transaction_id":6,"wallet_id":1,"amount":2.5,"date":"2022-05-01T10:00:00Z"}`,
+		},
+		{
+			name:      "transfer",
+			new:       newEvent(NewTransferCompleted(TransferCompleted{TransactionID: 7, SenderID: 1, ReceiverID: 2, Amount: 1, Date: date})),
+			eventType: EventTransferCompleted,
+			aggregate: 7,
+			payload:   `{"transaction_id":7,"sender_id":1,"receiver_id":2,"amount":1,"date":"2022-05-01T10:00:00Z"}`,
+		},
+		{
+			name:      "payment leg",
+			new:       newEvent(NewTransferCompleted(TransferCompleted{TransactionID: 8, SenderID: 1, ReceiverID: 3, Amount: 1, Date: date, ParentPaymentID: 2})),
+			eventType: EventTransferCompleted,
+			aggregate: 8,
+			payload:   `{"transaction_id":8,"sender_id":1,"receiver_id":3,"amount":1,"date":"2022-05-01T10:00:00Z","parent_payment_id":2}`,
+		},
+		{
+			name:      "reversal",
+			new:       newEvent(NewReversalCompleted(ReversalCompleted{TransactionID: 9, DisputeID: 4, DisputedTransactionID: 7, SenderID: 2, ReceiverID: 1, Amount: 1, Date: date})),
+			eventType: EventReversalCompleted,
+			aggregate: 9,
+			payload:   `{"transaction_id":9,"dispute_id":4,"disputed_transaction_id":7,"sender_id":2,"receiver_id":1,"amount":1,"date":"2022-05-01T10:00:00Z"}`,
+		},
+		{
+			name:      "adjustment",
+			new:       newEvent(NewAdjustmentMade(AdjustmentMade{TransactionID: 10, AdjustmentID: 3, WalletID: 1, Type: "deposit", Amount: 0.5, Reason: "bank fee", Date: date})),
+			eventType: EventAdjustmentMade,
+			aggregate: 1,
+			payload:   `{"transaction_id":10,"adjustment_id":3,"wallet_id":1,"type":"deposit","amount":0.5,"reason":"bank fee","date":"2022-05-01T10:00:00Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := tt.new()
+			if err != nil {
+				t.Fatalf("unexpected error = %v", err)
+			}
+			if event.Type != tt.eventType || event.AggregateID != tt.aggregate || string(event.Payload) != tt.payload {
+				t.Errorf("event = %s %d %s, want %s %d %s", event.Type, event.AggregateID, event.Payload, tt.eventType, tt.aggregate, tt.payload)
+			}
+		})
+	}
+}
+
+func TestBus(t *testing.T) {
+	errHandler := errors.New("handler failed")
+	tests := []struct {
+		name          string
+		noSubscribers bool
+		fail          bool
+		want          []string
+		wantErr       error
+	}{
+		{name: "subscribers in order", want: []string{"first 1", "second 1", "first 2", "second 2"}},
+		{name: "failed subscriber", fail: true, want: []string{"first 1"}, wantErr: errHandler},
+		{name: "no subscribers", noSubscribers: true, wantErr: ErrNoSubscribers},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			bus := NewBus()
+			if !tt.noSubscribers {
+				bus.Subscribe(func(_ context.Context, event EventDTO) error {
+					got = append(got, fmt.Sprintf("first %d", event.ID))
+					return nil
+				})
+				bus.Subscribe(func(_ context.Context, event EventDTO) error {
+					if tt.fail {
+						return errHandler
+					}
+					got = append(got, fmt.Sprintf("second %d", event.ID))
+					return nil
+				})
+			}
+			var err error
+			for id := int64(1); id <= 2 && err == nil; id++ {
+				err = bus.Publish(context.Background(), EventDTO{ID: id})
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Publish() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("handled %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+	for id := int64(1); id <= 2; id++ {
+		event := EventDTO{ID: id, Type: EventDepositMade, AggregateID: 1, Payload: json.RawMessage(`{"amount":1}`), CreatedAt: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)}
+		if err := publisher.Publish(context.Background(), event); err != nil {
+			t.Fatalf("Publish() unexpected error = %v", err)
+		}
+	}
+	want := `{"id":1,"type":"DepositMade","aggregate_id":1,"payload":{"amount":1},"created_at":"2022-05-01T00:00:00Z"}
+{"id":2,"type":"DepositMade","aggregate_id":1,"payload":{"amount":1},"created_at":"2022-05-01T00:00:00Z"}
+`
+	if buf.String() != want {
+		t.Errorf("written %s, want %s", buf.String(), want)
+	}
+}
+
+func TestNewPublisher(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting string
+		wantErr error
+	}{
+		{name: "missing", setting: "", wantErr: ErrMissingPublisher},
+		{name: "bus", setting: "bus"},
+		{name: "stdout", setting: "stdout"},
+		{name: "file", setting: "file:" + t.TempDir() + "/events.jsonl"},
+		{name: "unknown", setting: "kafka", wantErr: ErrUnknownPublisher},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPublisher(tt.setting); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewPublisher() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
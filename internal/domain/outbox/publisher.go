@@ -0,0 +1,88 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Publisher delivers events downstream, events are published one at a time in the order they were written.
+// An event is published again when the relay fails to record it was published, so consumers drop ids they have seen.
+type Publisher interface {
+	Publish(context.Context, EventDTO) error
+}
+
+// Bus is the in-process publisher, it calls subscribers of the event in the order they subscribed.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []func(context.Context, EventDTO) error
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe adds fn to subscribers, an error of fn fails the publishing and the event is published again.
+func (b *Bus) Subscribe(fn func(context.Context, EventDTO) error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, fn)
+}
+
+// Publish fails while nobody is subscribed, so that events wait in the outbox instead of being dropped.
+func (b *Bus) Publish(ctx context.Context, event EventDTO) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.subscribers) == 0 {
+		return ErrNoSubscribers
+	}
+	for _, fn := range b.subscribers {
+		if err := fn(ctx, event); err != nil {
+			return errors.Wrapf(err, "error handling event %d", event.ID)
+		}
+	}
+	return nil
+}
+
+// writerPublisher writes events as json lines, it is meant for local development.
+type writerPublisher struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewWriterPublisher(w io.Writer) Publisher {
+	return &writerPublisher{encoder: json.NewEncoder(w)}
+}
+
+func (p *writerPublisher) Publish(_ context.Context, event EventDTO) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.encoder.Encode(event)
+}
+
+// NewPublisher returns the publisher of the setting: "bus" for the in-process bus, "stdout" for json lines
+// on the standard output and "file:<path>" for json lines appended to the file. There is no default,
+// events are marked published once they are handed over, so they must not go where nobody reads them.
+func NewPublisher(setting string) (Publisher, error) {
+	switch {
+	case setting == "":
+		return nil, ErrMissingPublisher
+	case setting == "bus":
+		return NewBus(), nil
+	case setting == "stdout":
+		return NewWriterPublisher(os.Stdout), nil
+	case strings.HasPrefix(setting, "file:"):
+		f, err := os.OpenFile(strings.TrimPrefix(setting, "file:"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, errors.Wrap(err, "error opening outbox file")
+		}
+		return NewWriterPublisher(f), nil
+	default:
+		return nil, errors.Wrapf(ErrUnknownPublisher, "%q", setting)
+	}
+}
@@ -0,0 +1,46 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+type Service interface {
+	// Relay publishes events of the outbox in order until none is left, it is the job which keeps the stream going.
+	Relay(context.Context) error
+}
+
+type service struct {
+	storage   Storage
+	publisher Publisher
+	logger    logging.Logger
+}
+
+func NewService(storage Storage, publisher Publisher, logger logging.Logger) (Service, error) {
+	if publisher == nil {
+		return nil, errors.New("missing outbox publisher")
+	}
+	return &service{storage: storage, publisher: publisher, logger: logger}, nil
+}
+
+func (s *service) Relay(ctx context.Context) error {
+	for {
+		published, err := s.storage.Relay(ctx, eventsPage, func(event EventDTO) error {
+			return s.publisher.Publish(ctx, event)
+		})
+		if errors.Is(err, ErrNoSubscribers) {
+			// events wait in the outbox until something subscribes to the bus
+			return nil
+		}
+		if err != nil {
+			s.logger.Errorf("error relaying outbox events: %s", err.Error())
+			return errors.Wrap(err, "error relaying outbox events")
+		}
+		if published < eventsPage {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package outbox
+
+import "context"
+
+type Storage interface {
+	// Relay calls publish for up to limit unpublished events in id order and marks the published ones,
+	// it stops at the first error. Only one relay runs at a time, the others return right away.
+	Relay(ctx context.Context, limit int, publish func(EventDTO) error) (int, error)
+}
@@ -0,0 +1,16 @@
+package receipt
+
+import "time"
+
+// TransactionDTO is the transaction as it is stored, Amount is kept the way the database prints it.
+type TransactionDTO struct {
+	ID              int64
+	SenderID        int64
+	ReceiverID      int64
+	Amount          string
+	Date            time.Time
+	Type            string
+	ParentPaymentID *int64
+	// Hash is nil until the transaction is chained
+	Hash []byte
+}
@@ -0,0 +1,46 @@
+package receipt
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/receipt"
+)
+
+const TranTypeTransfer = "transfer"
+
+// dateLayout keeps the microseconds the database stores dates with.
+const dateLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	// ErrNotTransfer is returned for deposits, withdrawals and reversals, only transfers are paid to merchants.
+	ErrNotTransfer = errors.New("receipts are only issued for transfers")
+)
+
+// Signer signs receipts and publishes keys they are signed with, receipt.Keyring is the one of the service.
+type Signer interface {
+	Sign(receipt.Receipt) (receipt.Signed, error)
+	KeySet() receipt.KeySet
+}
+
+func newReceipt(dto TransactionDTO) (receipt.Receipt, error) {
+	if dto.ID == 0 {
+		return receipt.Receipt{}, ErrTransactionNotFound
+	}
+	if dto.Type != TranTypeTransfer {
+		return receipt.Receipt{}, ErrNotTransfer
+	}
+	return receipt.Receipt{
+		Amount:          dto.Amount,
+		Date:            dto.Date.In(time.UTC).Format(dateLayout),
+		Hash:            hex.EncodeToString(dto.Hash),
+		ParentPaymentID: dto.ParentPaymentID,
+		ReceiverID:      dto.ReceiverID,
+		SenderID:        dto.SenderID,
+		TransactionID:   dto.ID,
+		Type:            dto.Type,
+	}, nil
+}
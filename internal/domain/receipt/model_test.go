@@ -0,0 +1,51 @@
+package receipt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/receipt"
+)
+
+func TestNewReceipt(t *testing.T) {
+	parent := int64(7)
+	date := time.Date(2022, 5, 1, 10, 0, 0, 123456000, time.UTC)
+	tests := []struct {
+		name    string
+		dto     TransactionDTO
+		want    receipt.Receipt
+		wantErr error
+	}{
+		{
+			name: "transfer",
+			dto:  TransactionDTO{ID: 42, SenderID: 1, ReceiverID: 2, Amount: "12.5000", Date: date, Type: TranTypeTransfer, Hash: []byte{0, 255}},
+			want: receipt.Receipt{Amount: "12.5000", Date: "2022-05-01T10:00:00.123456Z", Hash: "00ff", ReceiverID: 2, SenderID: 1, TransactionID: 42, Type: "transfer"},
+		},
+		{
+			name: "payment leg in other zone",
+			dto: TransactionDTO{ID: 42, SenderID: 1, ReceiverID: 2, Amount: "12.5000", Date: date.In(time.FixedZone("", 7200)), Type: TranTypeTransfer,
+				ParentPaymentID: &parent},
+			want: receipt.Receipt{Amount: "12.5000", Date: "2022-05-01T10:00:00.123456Z", ParentPaymentID: &parent, ReceiverID: 2, SenderID: 1, TransactionID: 42, Type: "transfer"},
+		},
+		{name: "missing", dto: TransactionDTO{}, wantErr: ErrTransactionNotFound},
+		{name: "deposit", dto: TransactionDTO{ID: 1, SenderID: 1, ReceiverID: 1, Amount: "1.0000", Date: date, Type: "deposit"}, wantErr: ErrNotTransfer},
+		{name: "reversal", dto: TransactionDTO{ID: 1, SenderID: 2, ReceiverID: 1, Amount: "1.0000", Date: date, Type: "reversal"}, wantErr: ErrNotTransfer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newReceipt(tt.dto)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("newReceipt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newReceipt() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
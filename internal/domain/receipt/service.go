@@ -0,0 +1,52 @@
+package receipt
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/receipt"
+)
+
+type Service interface {
+	// GetReceipt signs the receipt of the transfer, the same transfer gets the same receipt until the key is rotated.
+	GetReceipt(ctx context.Context, transactionID int64) (receipt.Signed, error)
+	// GetKeys returns public keys of receipts, the active one first.
+	GetKeys() receipt.KeySet
+}
+
+type service struct {
+	storage Storage
+	signer  Signer
+	logger  logging.Logger
+}
+
+func NewService(storage Storage, signer Signer, logger logging.Logger) (Service, error) {
+	if signer == nil {
+		return nil, errors.New("missing receipt signer")
+	}
+	return &service{storage: storage, signer: signer, logger: logger}, nil
+}
+
+func (s *service) GetReceipt(ctx context.Context, transactionID int64) (receipt.Signed, error) {
+	transaction, err := s.storage.GetTransaction(ctx, transactionID)
+	if err != nil {
+		s.logger.Errorf("error getting transaction from db: %s", err.Error())
+		return receipt.Signed{}, errors.Wrap(err, "error getting transaction from db")
+	}
+	r, err := newReceipt(transaction)
+	if err != nil {
+		return receipt.Signed{}, err
+	}
+	signed, err := s.signer.Sign(r)
+	if err != nil {
+		s.logger.Errorf("error signing receipt: %s", err.Error())
+		return receipt.Signed{}, errors.Wrap(err, "error signing receipt")
+	}
+	return signed, nil
+}
+
+func (s *service) GetKeys() receipt.KeySet {
+	return s.signer.KeySet()
+}
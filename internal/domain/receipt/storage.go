@@ -0,0 +1,8 @@
+package receipt
+
+import "context"
+
+type Storage interface {
+	// GetTransaction returns the transaction with zero id when there is none.
+	GetTransaction(ctx context.Context, id int64) (TransactionDTO, error)
+}
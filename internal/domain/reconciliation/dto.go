@@ -0,0 +1,60 @@
+package reconciliation
+
+import "time"
+
+// BalanceDTO is the balance kept by the wallet and the one computed from all its transactions.
+type BalanceDTO struct {
+	WalletID int64
+	Balance  float64
+	Computed float64
+}
+
+// ReportDTO lists wallets whose balance does not match their transactions, Adjustments are only made by the fix.
+type ReportDTO struct {
+	CreatedAt     time.Time
+	Wallets       int64
+	Discrepancies []DiscrepancyDTO
+	Adjustments   []AdjustmentDTO
+}
+
+type DiscrepancyDTO struct {
+	WalletID int64
+	Balance  float64
+	Computed float64
+	// Difference is what the transactions of the wallet miss, negative when they add up to more than the balance
+	Difference float64
+}
+
+func (d DiscrepancyDTO) toModel() Discrepancy {
+	return Discrepancy(d)
+}
+
+// FixDTO is the audit reason of adjustments and the person who made them.
+type FixDTO struct {
+	Reason string
+	Actor  string
+}
+
+func (d FixDTO) validate() error {
+	if d.Reason == "" {
+		return ErrMissingReason
+	}
+	if d.Actor == "" {
+		return ErrMissingActor
+	}
+	return nil
+}
+
+// AdjustmentDTO is the deposit or withdrawal which made transactions of the wallet add up to its balance.
+type AdjustmentDTO struct {
+	ID            int64
+	WalletID      int64
+	TransactionID int64
+	Type          TranType
+	Amount        float64
+	Balance       float64
+	Computed      float64
+	Reason        string
+	Actor         string
+	CreatedAt     time.Time
+}
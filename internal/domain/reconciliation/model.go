@@ -0,0 +1,105 @@
+package reconciliation
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	TranTypeDeposit  TranType = "deposit"
+	TranTypeWithdraw TranType = "withdraw"
+)
+
+// balancesPage is how many wallets are reconciled at once.
+const balancesPage = 500
+
+var (
+	ErrMissingReason = errors.New("adjustment needs an audit reason")
+	ErrMissingActor  = errors.New("missing actor")
+	// ErrBalanceChanged is returned when the wallet has changed since the discrepancy was found, it is reconciled again then.
+	ErrBalanceChanged = errors.New("wallet balance has changed since reconciliation")
+)
+
+type TranType string
+
+// Discrepancy is the wallet whose balance does not match its transactions.
+type Discrepancy struct {
+	WalletID   int64
+	Balance    float64
+	Computed   float64
+	Difference float64
+}
+
+func (d Discrepancy) toDTO() DiscrepancyDTO {
+	return DiscrepancyDTO(d)
+}
+
+// newDiscrepancy compares balances with the precision amounts are stored with, ok is false when they match.
+func newDiscrepancy(balance BalanceDTO) (Discrepancy, bool) {
+	difference := roundAmount(balance.Balance - balance.Computed)
+	if difference == 0 {
+		return Discrepancy{}, false
+	}
+	return Discrepancy{
+		WalletID:   balance.WalletID,
+		Balance:    roundAmount(balance.Balance),
+		Computed:   roundAmount(balance.Computed),
+		Difference: difference,
+	}, true
+}
+
+// Adjustment is the transaction which makes transactions of the wallet add up to its balance, the balance stays as it is.
+type Adjustment struct {
+	WalletID  int64
+	Type      TranType
+	Amount    float64
+	Balance   float64
+	Computed  float64
+	Reason    string
+	Actor     string
+	CreatedAt time.Time
+}
+
+func newAdjustment(d Discrepancy, fix FixDTO, timestamp time.Time) (*Adjustment, error) {
+	if err := fix.validate(); err != nil {
+		return nil, err
+	}
+	if d.Difference == 0 {
+		return nil, errors.New("wallet without discrepancy needs no adjustment")
+	}
+	adjustment := &Adjustment{
+		WalletID:  d.WalletID,
+		Type:      TranTypeDeposit,
+		Amount:    d.Difference,
+		Balance:   d.Balance,
+		Computed:  d.Computed,
+		Reason:    fix.Reason,
+		Actor:     fix.Actor,
+		CreatedAt: timestamp,
+	}
+	if d.Difference < 0 {
+		adjustment.Type = TranTypeWithdraw
+		adjustment.Amount = -d.Difference
+	}
+	return adjustment, nil
+}
+
+func (a Adjustment) toDTO() AdjustmentDTO {
+	return AdjustmentDTO{
+		WalletID:  a.WalletID,
+		Type:      a.Type,
+		Amount:    a.Amount,
+		Balance:   a.Balance,
+		Computed:  a.Computed,
+		Reason:    a.Reason,
+		Actor:     a.Actor,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+// roundAmount drops the float error below the precision amounts are stored with.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*1e4) / 1e4
+}
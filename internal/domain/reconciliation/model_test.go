@@ -0,0 +1,100 @@
+package reconciliation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+)
+
+func Test_newDiscrepancy(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance BalanceDTO
+		want    Discrepancy
+		wantOK  bool
+	}{
+		{
+			name:    "test matching balance",
+			balance: BalanceDTO{WalletID: 1, Balance: 10.3, Computed: 10.30000001},
+		},
+		{
+			name:    "test transactions miss money",
+			balance: BalanceDTO{WalletID: 2, Balance: 15, Computed: 14.9},
+			want:    Discrepancy{WalletID: 2, Balance: 15, Computed: 14.9, Difference: 0.1},
+			wantOK:  true,
+		},
+		{
+			name:    "test transactions add up to more",
+			balance: BalanceDTO{WalletID: 3, Balance: 0, Computed: 2.5},
+			want:    Discrepancy{WalletID: 3, Balance: 0, Computed: 2.5, Difference: -2.5},
+			wantOK:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newDiscrepancy(tt.balance)
+			if ok != tt.wantOK {
+				t.Errorf("newDiscrepancy() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newDiscrepancy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newAdjustment(t *testing.T) {
+	clk := clock.NewFake(time.Date(2022, 5, 10, 10, 0, 0, 0, time.UTC))
+	fix := FixDTO{Reason: "balances written by migration", Actor: "alice"}
+	tests := []struct {
+		name        string
+		discrepancy Discrepancy
+		fix         FixDTO
+		want        *Adjustment
+		wantErr     error
+	}{
+		{
+			name:        "test missing reason",
+			discrepancy: Discrepancy{WalletID: 2, Balance: 15, Computed: 14.9, Difference: 0.1},
+			fix:         FixDTO{Actor: "alice"},
+			wantErr:     ErrMissingReason,
+		},
+		{
+			name:        "test missing actor",
+			discrepancy: Discrepancy{WalletID: 2, Balance: 15, Computed: 14.9, Difference: 0.1},
+			fix:         FixDTO{Reason: fix.Reason},
+			wantErr:     ErrMissingActor,
+		},
+		{
+			name:        "test deposit of missing money",
+			discrepancy: Discrepancy{WalletID: 2, Balance: 15, Computed: 14.9, Difference: 0.1},
+			fix:         fix,
+			want: &Adjustment{
+				WalletID: 2, Type: TranTypeDeposit, Amount: 0.1, Balance: 15, Computed: 14.9, Reason: fix.Reason, Actor: "alice", CreatedAt: clk.Now(),
+			},
+		},
+		{
+			name:        "test withdrawal of extra money",
+			discrepancy: Discrepancy{WalletID: 3, Balance: 0, Computed: 2.5, Difference: -2.5},
+			fix:         fix,
+			want: &Adjustment{
+				WalletID: 3, Type: TranTypeWithdraw, Amount: 2.5, Balance: 0, Computed: 2.5, Reason: fix.Reason, Actor: "alice", CreatedAt: clk.Now(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newAdjustment(tt.discrepancy, tt.fix, clk.Now())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("newAdjustment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newAdjustment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package reconciliation
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+type Service interface {
+	// Reconcile compares the balance of every wallet with what its transactions add up to.
+	Reconcile(context.Context) (ReportDTO, error)
+	// Fix reconciles wallets and posts an adjusting transaction for every discrepancy,
+	// the report keeps discrepancies that were found and adjustments that were made.
+	Fix(context.Context, FixDTO) (ReportDTO, error)
+}
+
+type service struct {
+	storage Storage
+	logger  logging.Logger
+	clk     clock.Clock
+}
+
+func NewService(storage Storage, logger logging.Logger, clk clock.Clock) (Service, error) {
+	return &service{storage: storage, logger: logger, clk: clk}, nil
+}
+
+func (s *service) Reconcile(ctx context.Context) (ReportDTO, error) {
+	report := ReportDTO{CreatedAt: s.clk.Now(), Discrepancies: []DiscrepancyDTO{}}
+	var after int64
+	for {
+		balances, err := s.storage.GetBalances(ctx, after, balancesPage)
+		if err != nil {
+			s.logger.Errorf("error getting wallet balances from db: %s", err.Error())
+			return ReportDTO{}, errors.Wrap(err, "error getting wallet balances from db")
+		}
+		if len(balances) == 0 {
+			return report, nil
+		}
+		for _, balance := range balances {
+			if discrepancy, ok := newDiscrepancy(balance); ok {
+				report.Discrepancies = append(report.Discrepancies, discrepancy.toDTO())
+			}
+		}
+		report.Wallets += int64(len(balances))
+		after = balances[len(balances)-1].WalletID
+	}
+}
+
+func (s *service) Fix(ctx context.Context, dto FixDTO) (ReportDTO, error) {
+	if err := dto.validate(); err != nil {
+		return ReportDTO{}, err
+	}
+	report, err := s.Reconcile(ctx)
+	if err != nil {
+		return ReportDTO{}, err
+	}
+	report.Adjustments = make([]AdjustmentDTO, 0, len(report.Discrepancies))
+	for _, discrepancy := range report.Discrepancies {
+		adjustment, err := newAdjustment(discrepancy.toModel(), dto, s.clk.Now())
+		if err != nil {
+			return report, errors.Wrap(err, "error creating adjustment model")
+		}
+		created, err := s.storage.CreateAdjustment(ctx, adjustment.toDTO())
+		if err != nil {
+			s.logger.Errorf("error adjusting wallet %d: %s", discrepancy.WalletID, err.Error())
+			return report, errors.Wrapf(err, "error adjusting wallet %d", discrepancy.WalletID)
+		}
+		s.logger.Infof("wallet %d adjusted by %s of %v by %s: %s", created.WalletID, created.Type, created.Amount, created.Actor, created.Reason)
+		report.Adjustments = append(report.Adjustments, created)
+	}
+	return report, nil
+}
@@ -0,0 +1,12 @@
+package reconciliation
+
+import "context"
+
+type Storage interface {
+	// GetBalances returns up to limit wallets with id greater than afterWalletID in id order,
+	// with their balance computed from all their transactions.
+	GetBalances(ctx context.Context, afterWalletID int64, limit int) ([]BalanceDTO, error)
+	// CreateAdjustment writes the adjusting transaction and its audit record. It returns ErrBalanceChanged
+	// when the wallet balance or its transactions no longer add up to the adjusted difference.
+	CreateAdjustment(context.Context, AdjustmentDTO) (AdjustmentDTO, error)
+}
@@ -0,0 +1,59 @@
+package replay
+
+import "time"
+
+// RebuildDTO sets how many transactions are replayed at once, DryRun stops at the diff without swapping it in.
+type RebuildDTO struct {
+	ChunkSize int
+	DryRun    bool
+}
+
+func (d *RebuildDTO) validate() error {
+	if d.ChunkSize == 0 {
+		d.ChunkSize = DefaultChunkSize
+	}
+	if d.ChunkSize < 0 || d.ChunkSize > MaxChunkSize {
+		return ErrInvalidChunkSize
+	}
+	return nil
+}
+
+// ReplayDTO is the log to replay, transactions up to LastTransactionID, Total of them.
+type ReplayDTO struct {
+	LastTransactionID int64
+	Total             int64
+}
+
+// ChunkDTO is the part of the log which was replayed, LastTransactionID is the greatest id of it.
+type ChunkDTO struct {
+	LastTransactionID int64
+	Transactions      int64
+}
+
+// DiffDTO is what the rebuild changes, Changes are the first wallets whose balance changes.
+type DiffDTO struct {
+	Wallets   int64
+	Snapshots int64
+	Changes   []BalanceChangeDTO
+}
+
+type BalanceChangeDTO struct {
+	WalletID int64
+	Balance  float64
+	Rebuilt  float64
+}
+
+type ProgressDTO struct {
+	Stage Stage
+	Done  int64
+	Total int64
+}
+
+// ResultDTO is the rebuild, Diff is the one which was swapped in unless the rebuild was dry.
+type ResultDTO struct {
+	Transactions int64
+	Diff         DiffDTO
+	Swapped      bool
+	StartedAt    time.Time
+	FinishedAt   time.Time
+}
@@ -0,0 +1,42 @@
+package replay
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	StageReplay  Stage = "replay"
+	StageProject Stage = "project"
+	StageSwap    Stage = "swap"
+)
+
+const (
+	DefaultChunkSize = 100000
+	MaxChunkSize     = 1000000
+	// maxChanges is how many changed balances the diff lists, it counts all of them
+	maxChanges = 100
+)
+
+var ErrInvalidChunkSize = errors.Errorf("chunk size should be from 1 to %d", MaxChunkSize)
+
+type Stage string
+
+// Percent is how much of the stage is done, stages without total are either started or done.
+func (p ProgressDTO) Percent() float64 {
+	if p.Total <= 0 {
+		if p.Done > 0 {
+			return 100
+		}
+		return 0
+	}
+	return float64(p.Done) * 100 / float64(p.Total)
+}
+
+func (p ProgressDTO) String() string {
+	if p.Total <= 0 {
+		return string(p.Stage)
+	}
+	return fmt.Sprintf("%s %d/%d (%.1f%%)", p.Stage, p.Done, p.Total, p.Percent())
+}
@@ -0,0 +1,58 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRebuildDTOValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunkSize int
+		want      int
+		err       error
+	}{
+		{name: "default", chunkSize: 0, want: DefaultChunkSize},
+		{name: "given", chunkSize: 500, want: 500},
+		{name: "max", chunkSize: MaxChunkSize, want: MaxChunkSize},
+		{name: "negative", chunkSize: -1, err: ErrInvalidChunkSize},
+		{name: "too large", chunkSize: MaxChunkSize + 1, err: ErrInvalidChunkSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dto := RebuildDTO{ChunkSize: tt.chunkSize}
+			err := dto.validate()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("validate() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && dto.ChunkSize != tt.want {
+				t.Errorf("ChunkSize = %d, want %d", dto.ChunkSize, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressString(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress ProgressDTO
+		percent  float64
+		want     string
+	}{
+		{name: "replay", progress: ProgressDTO{Stage: StageReplay, Done: 250, Total: 1000}, percent: 25, want: "replay 250/1000 (25.0%)"},
+		{name: "replayed", progress: ProgressDTO{Stage: StageReplay, Done: 1000, Total: 1000}, percent: 100, want: "replay 1000/1000 (100.0%)"},
+		{name: "stage started", progress: ProgressDTO{Stage: StageProject}, percent: 0, want: "project"},
+		{name: "stage done", progress: ProgressDTO{Stage: StageSwap, Done: 1}, percent: 100, want: "swap"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.progress.Percent(); got != tt.percent {
+				t.Errorf("Percent() = %v, want %v", got, tt.percent)
+			}
+			if got := tt.progress.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package replay
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+type Service interface {
+	// Rebuild replays the transaction log in id order into shadow tables, compares them with wallet balances
+	// and daily snapshots and swaps the changes in, progress is called after every chunk and stage.
+	// Balances which can not be kept, such as a negative one, fail the swap and leave everything as it was.
+	Rebuild(ctx context.Context, dto RebuildDTO, progress func(ProgressDTO)) (ResultDTO, error)
+}
+
+type service struct {
+	storage Storage
+	logger  logging.Logger
+	clk     clock.Clock
+}
+
+func NewService(storage Storage, logger logging.Logger, clk clock.Clock) (Service, error) {
+	return &service{storage: storage, logger: logger, clk: clk}, nil
+}
+
+func (s *service) Rebuild(ctx context.Context, dto RebuildDTO, progress func(ProgressDTO)) (ResultDTO, error) {
+	if err := dto.validate(); err != nil {
+		return ResultDTO{}, err
+	}
+	if progress == nil {
+		progress = func(ProgressDTO) {}
+	}
+	result := ResultDTO{StartedAt: s.clk.Now()}
+	replay, err := s.storage.Prepare(ctx)
+	if err != nil {
+		s.logger.Errorf("error preparing shadow tables: %s", err.Error())
+		return ResultDTO{}, errors.Wrap(err, "error preparing shadow tables")
+	}
+
+	for after := int64(0); after < replay.LastTransactionID; {
+		chunk, err := s.storage.ReplayChunk(ctx, after, replay.LastTransactionID, dto.ChunkSize)
+		if err != nil {
+			s.logger.Errorf("error replaying transactions after %d: %s", after, err.Error())
+			return ResultDTO{}, errors.Wrapf(err, "error replaying transactions after %d", after)
+		}
+		if chunk.Transactions == 0 {
+			break
+		}
+		result.Transactions += chunk.Transactions
+		after = chunk.LastTransactionID
+		progress(ProgressDTO{Stage: StageReplay, Done: result.Transactions, Total: replay.Total})
+	}
+
+	progress(ProgressDTO{Stage: StageProject})
+	if err := s.storage.Project(ctx); err != nil {
+		s.logger.Errorf("error projecting balances: %s", err.Error())
+		return ResultDTO{}, errors.Wrap(err, "error projecting balances")
+	}
+	if result.Diff, err = s.storage.Diff(ctx, maxChanges); err != nil {
+		s.logger.Errorf("error comparing rebuilt balances: %s", err.Error())
+		return ResultDTO{}, errors.Wrap(err, "error comparing rebuilt balances")
+	}
+	if dto.DryRun {
+		result.FinishedAt = s.clk.Now()
+		return result, nil
+	}
+
+	progress(ProgressDTO{Stage: StageSwap})
+	tail, diff, err := s.storage.Swap(ctx, replay.LastTransactionID, maxChanges)
+	if err != nil {
+		s.logger.Errorf("error swapping rebuilt balances: %s", err.Error())
+		return ResultDTO{}, errors.Wrap(err, "error swapping rebuilt balances")
+	}
+	result.Transactions += tail.Transactions
+	result.Diff = diff
+	result.Swapped = true
+	result.FinishedAt = s.clk.Now()
+	s.logger.Infof("rebuild changed %d wallet balances and %d snapshots", result.Diff.Wallets, result.Diff.Snapshots)
+	return result, nil
+}
@@ -0,0 +1,19 @@
+package replay
+
+import "context"
+
+type Storage interface {
+	// Prepare empties shadow tables and returns the log up to the last transaction to replay.
+	Prepare(context.Context) (ReplayDTO, error)
+	// ReplayChunk adds up to limit transactions with id greater than afterID and up to lastID in id order
+	// to daily movements of their wallets in the shadow table.
+	ReplayChunk(ctx context.Context, afterID, lastID int64, limit int) (ChunkDTO, error)
+	// Project computes wallet balances and balances of existing daily snapshots into shadow tables from the movements.
+	Project(context.Context) error
+	// Diff compares shadow tables with wallet balances and daily snapshots, it lists up to limit changed balances.
+	Diff(ctx context.Context, limit int) (DiffDTO, error)
+	// Swap replays transactions written after lastID, projects them and writes the changed balances and snapshots
+	// within one database transaction, writes of transactions and wallets wait for it. It returns the replayed tail
+	// of the log and the diff which was written.
+	Swap(ctx context.Context, lastID int64, limit int) (ChunkDTO, DiffDTO, error)
+}
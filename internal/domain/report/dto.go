@@ -0,0 +1,75 @@
+package report
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+type DTO struct {
+	ID         int64
+	Filter     transaction.FilterTransactionsDTO
+	Format     Format
+	TimeZone   string
+	Status     Status
+	Attempt    int
+	Rows       int64
+	Total      int64
+	File       string
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	ExpiresAt  time.Time
+}
+
+func (d DTO) toModel() *Job {
+	return &Job{
+		ID:         d.ID,
+		Filter:     d.Filter,
+		Format:     d.Format,
+		TimeZone:   d.TimeZone,
+		Status:     d.Status,
+		Attempt:    d.Attempt,
+		Rows:       d.Rows,
+		Total:      d.Total,
+		File:       d.File,
+		Error:      d.Error,
+		CreatedAt:  d.CreatedAt,
+		StartedAt:  d.StartedAt,
+		FinishedAt: d.FinishedAt,
+		ExpiresAt:  d.ExpiresAt,
+	}
+}
+
+// Progress is the share of rows already written, from 0 to 1.
+func (d DTO) Progress() float64 {
+	switch {
+	case d.Status == StatusDone:
+		return 1
+	case d.Total == 0:
+		return 0
+	case d.Rows >= d.Total:
+		// rows added after the count are written too, the job is not done until the file is closed
+		return 0.99
+	}
+	return float64(d.Rows) / float64(d.Total)
+}
+
+type CreateReportDTO struct {
+	Filter   transaction.FilterTransactionsDTO
+	Format   Format
+	TimeZone string
+}
+
+func (d CreateReportDTO) validate() error {
+	if !d.Format.Valid() {
+		return errors.Wrapf(ErrUnknownFormat, "%q", d.Format)
+	}
+	if _, err := time.LoadLocation(d.TimeZone); err != nil {
+		return errors.Wrapf(ErrUnknownTimeZone, "%q", d.TimeZone)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+	// StatusExpired is a done or failed job whose file was removed after the retention period.
+	StatusExpired Status = "expired"
+)
+
+const (
+	FormatCSV   Format = "csv"
+	FormatXLSX  Format = "xlsx"
+	FormatJSONL Format = "jsonl"
+	// FormatParquet is for analytics, it is buffered by row groups and it is compressed by itself.
+	FormatParquet Format = "parquet"
+)
+
+var (
+	ErrUnknownFormat   = errors.New("unknown report format")
+	ErrTooManyRows     = errors.New("too many rows for the report format")
+	ErrUnknownTimeZone = errors.New("unknown time zone")
+	ErrNotDone         = errors.New("report is not done yet")
+	ErrReportFailed    = errors.New("report has failed")
+	ErrReportExpired   = errors.New("report has expired")
+	// ErrJobLost is returned to the worker whose job was taken over by another one.
+	ErrJobLost = errors.New("report job was taken over by another worker")
+)
+
+type Status string
+
+type Format string
+
+// Formats lists the supported formats with their media types.
+var Formats = map[Format]string{
+	FormatCSV:     "text/csv",
+	FormatXLSX:    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatJSONL:   "application/x-ndjson",
+	FormatParquet: "application/vnd.apache.parquet",
+}
+
+func (f Format) Valid() bool {
+	_, ok := Formats[f]
+	return ok
+}
+
+// ContentType is the media type the report file is served with.
+func (f Format) ContentType() string {
+	if contentType, ok := Formats[f]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// Job is a report exported in the background, Rows is the progress and Total is the number
+// of rows the filter matched when the job started.
+type Job struct {
+	ID         int64
+	Filter     transaction.FilterTransactionsDTO
+	Format     Format
+	TimeZone   string
+	Status     Status
+	Attempt    int
+	Rows       int64
+	Total      int64
+	File       string
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	ExpiresAt  time.Time
+}
+
+func newJob(dto *CreateReportDTO, timestamp time.Time) (*Job, error) {
+	if err := dto.validate(); err != nil {
+		return nil, err
+	}
+	timeZone := dto.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	return &Job{
+		Filter:    dto.Filter,
+		Format:    dto.Format,
+		TimeZone:  timeZone,
+		Status:    StatusQueued,
+		CreatedAt: timestamp,
+	}, nil
+}
+
+// Location is the time zone timestamps of the report are written in.
+func (j *Job) Location() (*time.Location, error) {
+	loc, err := time.LoadLocation(j.TimeZone)
+	if err != nil {
+		return nil, errors.Wrapf(ErrUnknownTimeZone, "%q", j.TimeZone)
+	}
+	return loc, nil
+}
+
+// FileName is unique for every attempt, so a worker that lost its job never writes into the file of the new one.
+func (j *Job) FileName() string {
+	return fmt.Sprintf("report-%d-%d.%s", j.ID, j.Attempt, j.Format)
+}
+
+// Finish keeps the file of the job until the retention period is over.
+func (j *Job) Finish(timestamp time.Time, retention time.Duration) {
+	j.Status = StatusDone
+	j.File = j.FileName()
+	j.FinishedAt = timestamp
+	j.ExpiresAt = timestamp.Add(retention)
+}
+
+// Fail keeps the error of the job for as long as a done job would be kept.
+func (j *Job) Fail(cause error, timestamp time.Time, retention time.Duration) {
+	j.Status = StatusFailed
+	j.File = ""
+	j.Error = cause.Error()
+	j.FinishedAt = timestamp
+	j.ExpiresAt = timestamp.Add(retention)
+}
+
+// Expire drops the file of a finished job, jobs which are still queued or running never expire.
+func (j *Job) Expire(timestamp time.Time) bool {
+	if j.Status != StatusDone && j.Status != StatusFailed || timestamp.Before(j.ExpiresAt) {
+		return false
+	}
+	j.Status = StatusExpired
+	j.File = ""
+	return true
+}
+
+// Ready tells whether the file of the job can be downloaded.
+func (j *Job) Ready() error {
+	switch j.Status {
+	case StatusDone:
+		return nil
+	case StatusFailed:
+		return errors.Wrap(ErrReportFailed, j.Error)
+	case StatusExpired:
+		return ErrReportExpired
+	}
+	return ErrNotDone
+}
+
+func (j *Job) toDTO() *DTO {
+	return &DTO{
+		ID:         j.ID,
+		Filter:     j.Filter,
+		Format:     j.Format,
+		TimeZone:   j.TimeZone,
+		Status:     j.Status,
+		Attempt:    j.Attempt,
+		Rows:       j.Rows,
+		Total:      j.Total,
+		File:       j.File,
+		Error:      j.Error,
+		CreatedAt:  j.CreatedAt,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+		ExpiresAt:  j.ExpiresAt,
+	}
+}
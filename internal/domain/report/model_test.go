@@ -0,0 +1,146 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+func Test_newJob(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	filter := transaction.FilterTransactionsDTO{SenderIDs: []int64{1}}
+	tests := []struct {
+		name    string
+		dto     *CreateReportDTO
+		want    *Job
+		wantErr error
+	}{
+		{
+			name:    "test unknown format",
+			dto:     &CreateReportDTO{Format: "pdf"},
+			wantErr: ErrUnknownFormat,
+		},
+		{
+			name:    "test unknown time zone",
+			dto:     &CreateReportDTO{Format: FormatCSV, TimeZone: "Mars/Olympus"},
+			wantErr: ErrUnknownTimeZone,
+		},
+		{
+			name: "test utc by default",
+			dto:  &CreateReportDTO{Filter: filter, Format: FormatCSV},
+			want: &Job{Filter: filter, Format: FormatCSV, TimeZone: "UTC", Status: StatusQueued, CreatedAt: clk.Now()},
+		},
+		{
+			name: "test ok",
+			dto:  &CreateReportDTO{Filter: filter, Format: FormatCSV, TimeZone: "Europe/Riga"},
+			want: &Job{Filter: filter, Format: FormatCSV, TimeZone: "Europe/Riga", Status: StatusQueued, CreatedAt: clk.Now()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newJob(tt.dto, clk.Now())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("newJob() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newJob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJob_Expire(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name       string
+		job        *Job
+		want       bool
+		wantStatus Status
+	}{
+		{
+			name:       "test running job never expires",
+			job:        &Job{Status: StatusRunning},
+			want:       false,
+			wantStatus: StatusRunning,
+		},
+		{
+			name:       "test done job within retention",
+			job:        &Job{Status: StatusDone, File: "report-1-1.csv", ExpiresAt: clk.Now().Add(time.Second)},
+			want:       false,
+			wantStatus: StatusDone,
+		},
+		{
+			name:       "test done job after retention",
+			job:        &Job{Status: StatusDone, File: "report-1-1.csv", ExpiresAt: clk.Now()},
+			want:       true,
+			wantStatus: StatusExpired,
+		},
+		{
+			name:       "test failed job after retention",
+			job:        &Job{Status: StatusFailed, ExpiresAt: clk.Now().Add(-time.Hour)},
+			want:       true,
+			wantStatus: StatusExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.job.Expire(clk.Now()); got != tt.want {
+				t.Errorf("Expire() = %v, want %v", got, tt.want)
+			}
+			if tt.job.Status != tt.wantStatus {
+				t.Errorf("Expire() status = %v, want %v", tt.job.Status, tt.wantStatus)
+			}
+			if tt.want && tt.job.File != "" {
+				t.Errorf("Expire() kept file %q", tt.job.File)
+			}
+		})
+	}
+}
+
+func TestJob_Ready(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  Status
+		wantErr error
+	}{
+		{name: "test queued", status: StatusQueued, wantErr: ErrNotDone},
+		{name: "test running", status: StatusRunning, wantErr: ErrNotDone},
+		{name: "test failed", status: StatusFailed, wantErr: ErrReportFailed},
+		{name: "test expired", status: StatusExpired, wantErr: ErrReportExpired},
+		{name: "test done", status: StatusDone, wantErr: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &Job{Status: tt.status, Error: "db is gone"}
+			if err := job.Ready(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Ready() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDTO_Progress(t *testing.T) {
+	tests := []struct {
+		name string
+		dto  DTO
+		want float64
+	}{
+		{name: "test queued", dto: DTO{Status: StatusQueued}, want: 0},
+		{name: "test running", dto: DTO{Status: StatusRunning, Rows: 250, Total: 1000}, want: 0.25},
+		{name: "test more rows than counted", dto: DTO{Status: StatusRunning, Rows: 1001, Total: 1000}, want: 0.99},
+		{name: "test done without rows", dto: DTO{Status: StatusDone}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dto.Progress(); got != tt.want {
+				t.Errorf("Progress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"io"
+
+	"github.com/skwol/wallet/pkg/parquet"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+// amountScale is the number of decimals amounts are stored with.
+const amountScale = 4
+
+// ParquetColumns is the schema of transactions in parquet files, timestamps are instants in UTC
+// and the parent payment is null for transactions which are not legs of a split payment.
+var ParquetColumns = []parquet.Column{
+	{Name: "id", Kind: parquet.KindInt64},
+	{Name: "sender_id", Kind: parquet.KindInt64},
+	{Name: "receiver_id", Kind: parquet.KindInt64},
+	{Name: "amount", Kind: parquet.KindDecimal, Scale: amountScale},
+	{Name: "timestamp", Kind: parquet.KindTimestamp},
+	{Name: "type", Kind: parquet.KindEnum},
+	{Name: "parent_payment_id", Kind: parquet.KindInt64, Optional: true},
+}
+
+// parquetWriter keeps a row group in memory, the time zone does not apply as timestamps are instants.
+type parquetWriter struct {
+	file *parquet.Writer
+}
+
+func newParquetWriter(w io.Writer) (*parquetWriter, error) {
+	file, err := parquet.NewWriter(w, ParquetColumns)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetWriter{file: file}, nil
+}
+
+func (w *parquetWriter) Write(dto transaction.DTO) error {
+	return w.file.Write(ParquetRow(dto)...)
+}
+
+// Flush does nothing, rows are written a row group at a time, so that the groups are not cut short.
+func (w *parquetWriter) Flush() error {
+	return nil
+}
+
+func (w *parquetWriter) Close() error {
+	return w.file.Close()
+}
+
+// ParquetRow is the transaction as the values of ParquetColumns.
+func ParquetRow(dto transaction.DTO) []interface{} {
+	var parentPaymentID interface{}
+	if dto.ParentPaymentID != 0 {
+		parentPaymentID = dto.ParentPaymentID
+	}
+	return []interface{}{dto.ID, dto.SenderID, dto.ReceiverID, dto.Amount, dto.Timestamp, string(dto.Type), parentPaymentID}
+}
@@ -0,0 +1,284 @@
+package report
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+const (
+	// DefaultWorkers is the number of reports exported at the same time by one instance.
+	DefaultWorkers = 2
+	// DefaultRetention is how long report files are kept after they are done.
+	DefaultRetention = 24 * time.Hour
+)
+
+const (
+	// pollInterval is how often idle workers look for jobs queued by other instances.
+	pollInterval = 5 * time.Second
+	// staleAfter is how long a running job may go without progress before another worker takes it over.
+	staleAfter = 2 * time.Minute
+	// progressRows is how often the progress of a running job is saved.
+	progressRows = 1000
+	partSuffix   = ".part"
+)
+
+type Service interface {
+	Create(context.Context, *CreateReportDTO) (DTO, error)
+	GetByID(context.Context, int64) (DTO, error)
+	// Open returns the file of a done report, the caller has to close it.
+	Open(context.Context, int64) (DTO, *os.File, error)
+	// Run starts the workers exporting queued reports, they stop when ctx is done.
+	Run(ctx context.Context, workers int)
+	// RemoveExpired removes files of reports which outlived the retention period.
+	RemoveExpired(context.Context) error
+}
+
+type service struct {
+	storage      Storage
+	transactions transaction.Service
+	logger       logging.Logger
+	clk          clock.Clock
+	dir          string
+	retention    time.Duration
+	wake         chan struct{}
+}
+
+func NewService(storage Storage, transactions transaction.Service, logger logging.Logger, clk clock.Clock, dir string, retention time.Duration) (Service, error) {
+	if retention <= 0 {
+		return nil, errors.New("report retention should be greater then 0")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, errors.Wrap(err, "error creating reports dir")
+	}
+	return &service{
+		storage:      storage,
+		transactions: transactions,
+		logger:       logger,
+		clk:          clk,
+		dir:          dir,
+		retention:    retention,
+		wake:         make(chan struct{}, 1),
+	}, nil
+}
+
+func (s *service) Create(ctx context.Context, dto *CreateReportDTO) (DTO, error) {
+	if err := s.transactions.ValidateFilter(&dto.Filter); err != nil {
+		s.logger.Errorf("error validating report filter: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error validating report filter")
+	}
+	job, err := newJob(dto, s.clk.Now())
+	if err != nil {
+		s.logger.Errorf("error creating report job model: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error creating report job model")
+	}
+	result, err := s.storage.Create(ctx, job.toDTO())
+	if err != nil {
+		s.logger.Errorf("error creating report job in db: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error creating report job in db")
+	}
+	if result.ID == 0 {
+		s.logger.Errorf("empty report job returned from db")
+		return DTO{}, errors.New("empty report job returned from db")
+	}
+	// an idle worker of this instance picks the job right away instead of waiting for the next poll
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return result, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id int64) (DTO, error) {
+	return s.storage.GetByID(ctx, id)
+}
+
+func (s *service) Open(ctx context.Context, id int64) (DTO, *os.File, error) {
+	dto, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Errorf("error getting report job from db: %s", err.Error())
+		return DTO{}, nil, errors.Wrap(err, "error getting report job from db")
+	}
+	if dto.ID == 0 {
+		return dto, nil, nil
+	}
+	if err := dto.toModel().Ready(); err != nil {
+		return dto, nil, err
+	}
+	file, err := os.Open(filepath.Join(s.dir, dto.File))
+	if err != nil {
+		s.logger.Errorf("error opening report file: %s", err.Error())
+		return dto, nil, errors.Wrap(err, "error opening report file")
+	}
+	return dto, file, nil
+}
+
+func (s *service) Run(ctx context.Context, workers int) {
+	for i := 0; i < workers; i++ {
+		go s.work(ctx)
+	}
+}
+
+func (s *service) work(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil && s.runNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// runNext exports one claimed job, it returns false when there was nothing to run.
+func (s *service) runNext(ctx context.Context) bool {
+	now := s.clk.Now()
+	dto, err := s.storage.Claim(ctx, now, now.Add(-staleAfter))
+	if err != nil {
+		s.logger.Errorf("error claiming report job: %s", err.Error())
+		return false
+	}
+	if dto.ID == 0 {
+		return false
+	}
+	job := dto.toModel()
+	err = s.export(ctx, job)
+	switch {
+	case errors.Is(err, ErrJobLost):
+		s.logger.Errorf("report job %d attempt %d: %s", job.ID, job.Attempt, err.Error())
+		return true
+	case ctx.Err() != nil:
+		// the job stays running and is taken over once it is stale
+		return false
+	case err != nil:
+		s.logger.Errorf("error exporting report job %d: %s", job.ID, err.Error())
+		job.Fail(err, s.clk.Now(), s.retention)
+	default:
+		job.Finish(s.clk.Now(), s.retention)
+	}
+	if err := s.storage.Update(ctx, job.toDTO()); err != nil {
+		s.logger.Errorf("error updating report job %d in db: %s", job.ID, err.Error())
+		if job.File != "" {
+			s.remove(job.File)
+		}
+	}
+	return true
+}
+
+// export writes the report into a part file which is renamed once it is complete,
+// so a file of a done report is never partial.
+func (s *service) export(ctx context.Context, job *Job) error {
+	loc, err := job.Location()
+	if err != nil {
+		return err
+	}
+	if job.Total, err = s.transactions.CountFiltered(ctx, &job.Filter); err != nil {
+		return errors.Wrap(err, "error counting transactions")
+	}
+	if err := s.storage.UpdateProgress(ctx, job.toDTO(), s.clk.Now()); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, job.FileName())
+	file, err := os.Create(path + partSuffix)
+	if err != nil {
+		return errors.Wrap(err, "error creating report file")
+	}
+	writer, err := NewWriter(job.Format, file, loc)
+	if err == nil {
+		err = s.transactions.StreamFiltered(ctx, &job.Filter, 0, 0, func(dto transaction.DTO) error {
+			if err := writer.Write(dto); err != nil {
+				return errors.Wrap(err, "error writing report row")
+			}
+			job.Rows++
+			if job.Rows%progressRows == 0 {
+				return s.storage.UpdateProgress(ctx, job.toDTO(), s.clk.Now())
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+partSuffix, path)
+	}
+	if err != nil {
+		s.remove(job.FileName() + partSuffix)
+		return err
+	}
+	return nil
+}
+
+func (s *service) RemoveExpired(ctx context.Context) error {
+	now := s.clk.Now()
+	expired, err := s.storage.GetExpired(ctx, now)
+	if err != nil {
+		s.logger.Errorf("error getting expired report jobs from db: %s", err.Error())
+		return errors.Wrap(err, "error getting expired report jobs from db")
+	}
+	var failed int
+	for _, dto := range expired {
+		job := dto.toModel()
+		file := job.File
+		if !job.Expire(now) {
+			continue
+		}
+		if file != "" && !s.remove(file) {
+			failed++
+			continue
+		}
+		if err := s.storage.Update(ctx, job.toDTO()); err != nil {
+			s.logger.Errorf("error updating report job %d in db: %s", job.ID, err.Error())
+			failed++
+		}
+	}
+	s.removeAbandonedParts(now)
+	if failed > 0 {
+		return errors.Errorf("%d of %d expired reports were not removed", failed, len(expired))
+	}
+	return nil
+}
+
+// removeAbandonedParts removes part files left by workers which stopped in the middle of a job.
+func (s *service) removeAbandonedParts(now time.Time) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		s.logger.Errorf("error reading reports dir: %s", err.Error())
+		return
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), partSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < s.retention {
+			continue
+		}
+		s.remove(entry.Name())
+	}
+}
+
+func (s *service) remove(name string) bool {
+	if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !os.IsNotExist(err) {
+		s.logger.Errorf("error removing report file %s: %s", name, err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,19 @@
+package report
+
+import (
+	"context"
+	"time"
+)
+
+type Storage interface {
+	Create(context.Context, *DTO) (DTO, error)
+	GetByID(context.Context, int64) (DTO, error)
+	// Claim marks the oldest queued job as running and returns it, a running job which has not
+	// reported progress since stale is claimed again. Empty DTO is returned when there is nothing to run.
+	Claim(ctx context.Context, now time.Time, stale time.Time) (DTO, error)
+	// UpdateProgress returns ErrJobLost when the attempt of the job is not the current one.
+	UpdateProgress(ctx context.Context, dto *DTO, now time.Time) error
+	// Update saves the finished job, it returns ErrJobLost when the attempt is not the current one.
+	Update(context.Context, *DTO) error
+	GetExpired(context.Context, time.Time) ([]DTO, error)
+}
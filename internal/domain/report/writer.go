@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+// Writer writes transactions into a report of one of the formats, rows are written while
+// they are read, so that neither the report nor the transactions are held in memory.
+type Writer interface {
+	Write(transaction.DTO) error
+	// Flush pushes the buffered rows to the underlying writer, the report is still not complete.
+	Flush() error
+	// Close completes the report, it does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns the writer of the format, timestamps are written in loc.
+func NewWriter(format Format, w io.Writer, loc *time.Location) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, loc)
+	case FormatXLSX:
+		return newXLSXWriter(w, loc)
+	case FormatJSONL:
+		return newJSONLWriter(w, loc), nil
+	case FormatParquet:
+		return newParquetWriter(w)
+	}
+	return nil, errors.Wrapf(ErrUnknownFormat, "%q", format)
+}
+
+// csvWriter keeps the column layout of the transactions report.
+type csvWriter struct {
+	csv *csv.Writer
+	loc *time.Location
+}
+
+func newCSVWriter(w io.Writer, loc *time.Location) (*csvWriter, error) {
+	writer := &csvWriter{csv: csv.NewWriter(w), loc: loc}
+	if err := writer.csv.Write(transaction.CSVHeaders); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *csvWriter) Write(dto transaction.DTO) error {
+	return w.csv.Write(dto.CSVRecord(w.loc))
+}
+
+func (w *csvWriter) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *csvWriter) Close() error {
+	return w.Flush()
+}
+
+// jsonlRecord has the fields of the transaction in the api, one record per line.
+type jsonlRecord struct {
+	ID              int64     `json:"id"`
+	SenderID        int64     `json:"sender_id"`
+	ReceiverID      int64     `json:"receiver_id"`
+	Amount          float64   `json:"amount"`
+	Timestamp       time.Time `json:"timestamp"`
+	Type            string    `json:"type"`
+	ParentPaymentID *int64    `json:"parent_payment_id,omitempty"`
+}
+
+type jsonlWriter struct {
+	out     *bufio.Writer
+	encoder *json.Encoder
+	loc     *time.Location
+}
+
+func newJSONLWriter(w io.Writer, loc *time.Location) *jsonlWriter {
+	out := bufio.NewWriter(w)
+	return &jsonlWriter{out: out, encoder: json.NewEncoder(out), loc: loc}
+}
+
+func (w *jsonlWriter) Write(dto transaction.DTO) error {
+	record := jsonlRecord{
+		ID:         dto.ID,
+		SenderID:   dto.SenderID,
+		ReceiverID: dto.ReceiverID,
+		Amount:     dto.Amount,
+		Timestamp:  dto.Timestamp,
+		Type:       string(dto.Type),
+	}
+	if w.loc != nil {
+		record.Timestamp = record.Timestamp.In(w.loc)
+	}
+	if dto.ParentPaymentID != 0 {
+		parentPaymentID := dto.ParentPaymentID
+		record.ParentPaymentID = &parentPaymentID
+	}
+	// Encode ends every record with a new line
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonlWriter) Flush() error {
+	return w.out.Flush()
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.Flush()
+}
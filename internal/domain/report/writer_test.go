@@ -0,0 +1,152 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+func TestNewWriter(t *testing.T) {
+	riga, err := time.LoadLocation("Europe/Riga")
+	if err != nil {
+		t.Skipf("missing time zone data: %s", err.Error())
+	}
+	rows := []transaction.DTO{
+		{ID: 1, SenderID: 2, ReceiverID: 3, Amount: 10.5, Timestamp: time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC), Type: transaction.TranTypeTransfer},
+		{ID: 2, SenderID: 2, ReceiverID: 4, Amount: 1, Timestamp: time.Date(2021, 10, 10, 12, 0, 0, 0, time.UTC), Type: transaction.TranTypeTransfer, ParentPaymentID: 7},
+	}
+	tests := []struct {
+		name    string
+		format  Format
+		check   func(t *testing.T, report []byte)
+		wantErr error
+	}{
+		{
+			name:   "test csv",
+			format: FormatCSV,
+			check: func(t *testing.T, report []byte) {
+				want := "Transaction ID,Sender ID,Receiver ID,Amount,Timestamp,Type\n" +
+					"1,2,3,10.500000,\"Sun, 10 Oct 2021 13:00:00 +0300\",transfer\n" +
+					"2,2,4,1.000000,\"Sun, 10 Oct 2021 15:00:00 +0300\",transfer\n"
+				if string(report) != want {
+					t.Errorf("report = %q, want %q", report, want)
+				}
+			},
+		},
+		{
+			name:   "test jsonl",
+			format: FormatJSONL,
+			check: func(t *testing.T, report []byte) {
+				want := `{"id":1,"sender_id":2,"receiver_id":3,"amount":10.5,"timestamp":"2021-10-10T13:00:00+03:00","type":"transfer"}` + "\n" +
+					`{"id":2,"sender_id":2,"receiver_id":4,"amount":1,"timestamp":"2021-10-10T15:00:00+03:00","type":"transfer","parent_payment_id":7}` + "\n"
+				if string(report) != want {
+					t.Errorf("report = %q, want %q", report, want)
+				}
+			},
+		},
+		{
+			name:   "test xlsx",
+			format: FormatXLSX,
+			check: func(t *testing.T, report []byte) {
+				archive, err := zip.NewReader(bytes.NewReader(report), int64(len(report)))
+				if err != nil {
+					t.Fatalf("error reading xlsx: %s", err.Error())
+				}
+				var sheet string
+				for _, file := range archive.File {
+					content := readZipFile(t, file)
+					if err := wellFormed(content); err != nil {
+						t.Errorf("%s is not well formed: %s", file.Name, err.Error())
+					}
+					if file.Name == "xl/worksheets/sheet1.xml" {
+						sheet = content
+					}
+				}
+				for _, want := range []string{
+					`state="frozen"`,
+					`<c r="A1" s="1" t="inlineStr"><is><t>Transaction ID</t></is></c>`,
+					`<c r="A2"><v>1</v></c>`,
+					`<c r="D2" s="2"><v>10.5</v></c>`,
+					// 2021-10-10 13:00 in Riga
+					`<c r="E2" s="3"><v>44479.541666666664</v></c>`,
+					`<c r="F3" t="inlineStr"><is><t>transfer</t></is></c>`,
+				} {
+					if !strings.Contains(sheet, want) {
+						t.Errorf("sheet is missing %s", want)
+					}
+				}
+			},
+		},
+		{
+			name:   "test parquet",
+			format: FormatParquet,
+			check: func(t *testing.T, report []byte) {
+				if !bytes.HasPrefix(report, []byte("PAR1")) || !bytes.HasSuffix(report, []byte("PAR1")) {
+					t.Errorf("report is not a parquet file")
+				}
+				// the footer is not compressed
+				if !bytes.Contains(report, []byte("parent_payment_id")) {
+					t.Errorf("report footer is missing the schema")
+				}
+			},
+		},
+		{
+			name:    "test unknown format",
+			format:  "pdf",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			writer, err := NewWriter(tt.format, &out, riga)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewWriter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for _, row := range rows {
+				if err := writer.Write(row); err != nil {
+					t.Fatalf("Write() unexpected error = %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close() unexpected error = %v", err)
+			}
+			tt.check(t, out.Bytes())
+		})
+	}
+}
+
+func readZipFile(t *testing.T, file *zip.File) string {
+	reader, err := file.Open()
+	if err != nil {
+		t.Fatalf("error opening %s: %s", file.Name, err.Error())
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("error reading %s: %s", file.Name, err.Error())
+	}
+	return string(content)
+}
+
+func wellFormed(content string) error {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
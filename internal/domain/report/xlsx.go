@@ -0,0 +1,195 @@
+package report
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/internal/domain/transaction"
+)
+
+// xlsxMaxRows is the row limit of a worksheet, the header row included.
+const xlsxMaxRows = 1048576
+
+// cell styles, indexes of cellXfs in xlsxStyles
+const (
+	xlsxStyleHeader = 1
+	xlsxStyleAmount = 2
+	xlsxStyleDate   = 3
+)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="Transactions" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="0.0000"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFD9E1F2"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// xlsxSheetStart freezes the header row, so it stays visible while rows are scrolled.
+const xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+	`<cols><col min="1" max="4" width="14" customWidth="1"/><col min="5" max="5" width="20" customWidth="1"/>` +
+	`<col min="6" max="6" width="12" customWidth="1"/></cols>` +
+	`<sheetData>`
+
+const xlsxSheetEnd = `</sheetData></worksheet>`
+
+// excelEpoch is the day 0 of excel date serials, dates are written as days since it.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWriter writes a single sheet workbook. The sheet is the only part which is streamed,
+// the rest of the parts are small and constant. Strings are inline, so no shared strings table is needed.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+	loc     *time.Location
+	rows    int
+}
+
+func newXLSXWriter(w io.Writer, loc *time.Location) (*xlsxWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating %s", part.name)
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, errors.Wrapf(err, "error writing %s", part.name)
+		}
+	}
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating sheet")
+	}
+	writer := &xlsxWriter{archive: archive, sheet: bufio.NewWriter(sheet), loc: loc}
+	if _, err := writer.sheet.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	writer.startRow()
+	for i, header := range transaction.CSVHeaders {
+		writer.stringCell(i, header, xlsxStyleHeader)
+	}
+	return writer, writer.endRow()
+}
+
+func (w *xlsxWriter) Write(dto transaction.DTO) error {
+	if w.rows >= xlsxMaxRows {
+		return errors.Wrapf(ErrTooManyRows, "xlsx sheet is limited to %d rows", xlsxMaxRows)
+	}
+	timestamp := dto.Timestamp
+	if w.loc != nil {
+		timestamp = timestamp.In(w.loc)
+	}
+	w.startRow()
+	w.numberCell(0, strconv.FormatInt(dto.ID, 10), 0)
+	w.numberCell(1, strconv.FormatInt(dto.SenderID, 10), 0)
+	w.numberCell(2, strconv.FormatInt(dto.ReceiverID, 10), 0)
+	w.numberCell(3, strconv.FormatFloat(dto.Amount, 'f', -1, 64), xlsxStyleAmount)
+	w.numberCell(4, strconv.FormatFloat(excelSerial(timestamp), 'f', -1, 64), xlsxStyleDate)
+	w.stringCell(5, string(dto.Type), 0)
+	return w.endRow()
+}
+
+func (w *xlsxWriter) Flush() error {
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.archive.Flush()
+}
+
+func (w *xlsxWriter) Close() error {
+	if _, err := w.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.archive.Close()
+}
+
+func (w *xlsxWriter) startRow() {
+	w.rows++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.rows)
+}
+
+// endRow returns the first error of the row, bufio keeps it until the row is complete.
+func (w *xlsxWriter) endRow() error {
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (w *xlsxWriter) numberCell(column int, value string, style int) {
+	fmt.Fprintf(w.sheet, `<c r="%s"%s><v>%s</v></c>`, cellRef(column, w.rows), styleAttr(style), value)
+}
+
+func (w *xlsxWriter) stringCell(column int, value string, style int) {
+	fmt.Fprintf(w.sheet, `<c r="%s"%s t="inlineStr"><is><t>`, cellRef(column, w.rows), styleAttr(style))
+	_ = xml.EscapeText(w.sheet, []byte(value))
+	_, _ = w.sheet.WriteString(`</t></is></c>`)
+}
+
+func styleAttr(style int) string {
+	if style == 0 {
+		return ""
+	}
+	return fmt.Sprintf(` s="%d"`, style)
+}
+
+// cellRef supports the first 26 columns, which is more than the report has.
+func cellRef(column, row int) string {
+	return fmt.Sprintf("%c%d", 'A'+column, row)
+}
+
+// excelSerial converts the wall clock time to days since excelEpoch, excel dates have no time zone.
+func excelSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
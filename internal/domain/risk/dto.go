@@ -0,0 +1,32 @@
+package risk
+
+import "time"
+
+// MovementDTO describes money movement about to happen, WalletID is the wallet money leaves,
+// or the wallet being deposited to.
+type MovementDTO struct {
+	Kind      Kind
+	WalletID  int64
+	Receivers []int64
+	Amount    float64
+}
+
+// DecisionDTO is a persisted outcome of the movement evaluation.
+type DecisionDTO struct {
+	ID        int64
+	Kind      Kind
+	WalletID  int64
+	Receivers []int64
+	Amount    float64
+	Decision  Decision
+	Score     float64
+	Hits      []HitDTO
+	CreatedAt time.Time
+}
+
+type HitDTO struct {
+	Rule    string   `json:"rule"`
+	Action  Decision `json:"action"`
+	Score   float64  `json:"score"`
+	Details string   `json:"details"`
+}
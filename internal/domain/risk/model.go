@@ -0,0 +1,410 @@
+package risk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DecisionAllow  Decision = "allow"
+	DecisionReview Decision = "review"
+	DecisionDeny   Decision = "deny"
+)
+
+const (
+	KindTransfer     Kind = "transfer"
+	KindSplitPayment Kind = "split_payment"
+	KindDeposit      Kind = "deposit"
+	KindWithdraw     Kind = "withdraw"
+	// KindEscrowRelease moves the escrowed money to the seller, KindReversal returns disputed money to the sender.
+	KindEscrowRelease Kind = "escrow_release"
+	KindReversal      Kind = "reversal"
+)
+
+const (
+	RuleVelocity           RuleType = "velocity"
+	RuleAboveAverage       RuleType = "amount_above_average"
+	RuleNewWalletHighValue RuleType = "new_wallet_high_value"
+	RuleFanOut             RuleType = "fan_out"
+)
+
+var (
+	ErrDenied            = errors.New("operation denied by risk rules")
+	ErrUnknownDecision   = errors.New("unknown risk decision")
+	ErrUnknownKind       = errors.New("unknown money movement kind")
+	ErrUnknownRuleType   = errors.New("unknown risk rule type")
+	ErrMissingRuleName   = errors.New("risk rule must have a name")
+	ErrDuplicateRuleName = errors.New("risk rule name is used more then once")
+	ErrInvalidRuleParam  = errors.New("risk rule parameter should be greater then 0")
+	ErrNegativeScore     = errors.New("risk score can not be less then 0")
+)
+
+type Decision string
+
+// severity orders decisions so that the strictest one wins.
+func (d Decision) severity() int {
+	switch d {
+	case DecisionReview:
+		return 1
+	case DecisionDeny:
+		return 2
+	}
+	return 0
+}
+
+func (d Decision) valid() bool {
+	switch d {
+	case DecisionAllow, DecisionReview, DecisionDeny:
+		return true
+	}
+	return false
+}
+
+// Kind of the money movement, transfer and split payment move money to other wallets.
+type Kind string
+
+func (k Kind) valid() bool {
+	switch k {
+	case KindTransfer, KindSplitPayment, KindDeposit, KindWithdraw, KindEscrowRelease, KindReversal:
+		return true
+	}
+	return false
+}
+
+func (k Kind) outgoing() bool {
+	return k == KindTransfer || k == KindSplitPayment
+}
+
+type RuleType string
+
+// Duration is a time.Duration read from json strings like "10m" or "24h".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return errors.Wrap(err, "duration should be a string like \"10m\"")
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Config is a set of rules with score thresholds, movement gets the strictest of the decisions
+// returned by the hit rules and the one implied by the total score. Zero threshold is not applied.
+type Config struct {
+	ReviewScore float64      `json:"review_score"`
+	DenyScore   float64      `json:"deny_score"`
+	Rules       []RuleConfig `json:"rules"`
+}
+
+// RuleConfig keeps parameters of all the rule types, every type reads only its own ones.
+type RuleConfig struct {
+	Name   string   `json:"name"`
+	Type   RuleType `json:"type"`
+	Action Decision `json:"action"`
+	Score  float64  `json:"score"`
+	// Count is the number of movements allowed within Window for velocity rule.
+	Count  int      `json:"count,omitempty"`
+	Window Duration `json:"window,omitempty"`
+	// Multiplier of the historical average amount, applied once wallet has MinHistory movements.
+	Multiplier float64 `json:"multiplier,omitempty"`
+	MinHistory int     `json:"min_history,omitempty"`
+	// Age of the wallet counted from its first transaction and Amount it is allowed to move.
+	Age    Duration `json:"age,omitempty"`
+	Amount float64  `json:"amount,omitempty"`
+	// Receivers is the number of new receivers allowed within Window for fan out rule.
+	Receivers int `json:"receivers,omitempty"`
+}
+
+// ParseConfig reads rules from json and builds the engine out of them.
+func ParseConfig(data []byte) (*Engine, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrap(err, "error parsing risk rules")
+	}
+	return NewEngine(config)
+}
+
+// LoadConfig reads rules from the file, so they can be changed without code.
+func LoadConfig(path string) (*Engine, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading risk rules file")
+	}
+	return ParseConfig(data)
+}
+
+// History answers questions about past money movements of the wallet.
+type History interface {
+	// Count returns number of movements of the kind made by the wallet since the time.
+	Count(walletID int64, kind Kind, since time.Time) (int, error)
+	// Average returns average amount of the wallet movements of the kind and their number.
+	Average(walletID int64, kind Kind) (float64, int, error)
+	// FirstActivity returns time of the first transaction of the wallet, zero when there is none.
+	FirstActivity(walletID int64) (time.Time, error)
+	// NewReceivers returns number of receivers paid since the time, including the given ones,
+	// that were never paid by the wallet before.
+	NewReceivers(walletID int64, receivers []int64, since time.Time) (int, error)
+}
+
+// Rule checks the movement, it returns nil when the movement does not hit it.
+type Rule interface {
+	Evaluate(Movement, History) (*Hit, error)
+}
+
+// RuleFactory builds the rule out of its config.
+type RuleFactory func(RuleConfig) (Rule, error)
+
+var factories = map[RuleType]RuleFactory{
+	RuleVelocity:           newVelocityRule,
+	RuleAboveAverage:       newAboveAverageRule,
+	RuleNewWalletHighValue: newNewWalletRule,
+	RuleFanOut:             newFanOutRule,
+}
+
+// RegisterRuleType plugs in a new rule type, it is expected to be called before config is parsed.
+func RegisterRuleType(ruleType RuleType, factory RuleFactory) {
+	factories[ruleType] = factory
+}
+
+type Movement struct {
+	Kind      Kind
+	WalletID  int64
+	Receivers []int64
+	Amount    float64
+	Timestamp time.Time
+}
+
+// Hit is a rule that matched the movement.
+type Hit struct {
+	Rule    string
+	Action  Decision
+	Score   float64
+	Details string
+}
+
+// Result is an outcome of the movement evaluation.
+type Result struct {
+	ID        int64
+	Movement  Movement
+	Decision  Decision
+	Score     float64
+	Hits      []Hit
+	CreatedAt time.Time
+}
+
+func (r Result) toDTO() *DecisionDTO {
+	hits := make([]HitDTO, len(r.Hits))
+	for i, hit := range r.Hits {
+		hits[i] = HitDTO(hit)
+	}
+	return &DecisionDTO{
+		ID:        r.ID,
+		Kind:      r.Movement.Kind,
+		WalletID:  r.Movement.WalletID,
+		Receivers: r.Movement.Receivers,
+		Amount:    r.Movement.Amount,
+		Decision:  r.Decision,
+		Score:     r.Score,
+		Hits:      hits,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+type namedRule struct {
+	config RuleConfig
+	rule   Rule
+}
+
+// Engine evaluates every configured rule against the movement.
+type Engine struct {
+	reviewScore float64
+	denyScore   float64
+	rules       []namedRule
+}
+
+func NewEngine(config Config) (*Engine, error) {
+	if config.ReviewScore < 0 || config.DenyScore < 0 {
+		return nil, ErrNegativeScore
+	}
+	engine := &Engine{reviewScore: config.ReviewScore, denyScore: config.DenyScore}
+	names := make(map[string]bool, len(config.Rules))
+	for _, ruleConfig := range config.Rules {
+		if ruleConfig.Name == "" {
+			return nil, ErrMissingRuleName
+		}
+		if names[ruleConfig.Name] {
+			return nil, errors.Wrapf(ErrDuplicateRuleName, "risk rule %q", ruleConfig.Name)
+		}
+		names[ruleConfig.Name] = true
+		if !ruleConfig.Action.valid() {
+			return nil, errors.Wrapf(ErrUnknownDecision, "risk rule %q", ruleConfig.Name)
+		}
+		if ruleConfig.Score < 0 {
+			return nil, errors.Wrapf(ErrNegativeScore, "risk rule %q", ruleConfig.Name)
+		}
+		factory, ok := factories[ruleConfig.Type]
+		if !ok {
+			return nil, errors.Wrapf(ErrUnknownRuleType, "risk rule %q", ruleConfig.Name)
+		}
+		rule, err := factory(ruleConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "risk rule %q", ruleConfig.Name)
+		}
+		engine.rules = append(engine.rules, namedRule{config: ruleConfig, rule: rule})
+	}
+	return engine, nil
+}
+
+// Enabled reports whether there is any rule to evaluate.
+func (e *Engine) Enabled() bool {
+	return e != nil && len(e.rules) > 0
+}
+
+func (e *Engine) Evaluate(movement Movement, history History) (Result, error) {
+	result := Result{Movement: movement, Decision: DecisionAllow, CreatedAt: movement.Timestamp}
+	if !movement.Kind.valid() {
+		return result, ErrUnknownKind
+	}
+	if !e.Enabled() {
+		return result, nil
+	}
+	for _, r := range e.rules {
+		hit, err := r.rule.Evaluate(movement, history)
+		if err != nil {
+			return result, errors.Wrapf(err, "error evaluating risk rule %q", r.config.Name)
+		}
+		if hit == nil {
+			continue
+		}
+		hit.Rule = r.config.Name
+		hit.Action = r.config.Action
+		hit.Score = r.config.Score
+		result.Hits = append(result.Hits, *hit)
+		result.Score += hit.Score
+		if hit.Action.severity() > result.Decision.severity() {
+			result.Decision = hit.Action
+		}
+	}
+	byScore := DecisionAllow
+	switch {
+	case e.denyScore > 0 && result.Score >= e.denyScore:
+		byScore = DecisionDeny
+	case e.reviewScore > 0 && result.Score >= e.reviewScore:
+		byScore = DecisionReview
+	}
+	if byScore.severity() > result.Decision.severity() {
+		result.Decision = byScore
+	}
+	return result, nil
+}
+
+// velocityRule is hit when the wallet makes more then Count movements of the same kind within Window.
+type velocityRule struct {
+	count  int
+	window time.Duration
+}
+
+func newVelocityRule(config RuleConfig) (Rule, error) {
+	if config.Count <= 0 || config.Window <= 0 {
+		return nil, ErrInvalidRuleParam
+	}
+	return velocityRule{count: config.Count, window: time.Duration(config.Window)}, nil
+}
+
+func (r velocityRule) Evaluate(movement Movement, history History) (*Hit, error) {
+	count, err := history.Count(movement.WalletID, movement.Kind, movement.Timestamp.Add(-r.window))
+	if err != nil {
+		return nil, err
+	}
+	if count+1 <= r.count {
+		return nil, nil
+	}
+	return &Hit{Details: fmt.Sprintf("%d %s movements within %s, allowed %d", count+1, movement.Kind, r.window, r.count)}, nil
+}
+
+// aboveAverageRule is hit when amount is Multiplier times higher then the wallet average.
+type aboveAverageRule struct {
+	multiplier float64
+	minHistory int
+}
+
+func newAboveAverageRule(config RuleConfig) (Rule, error) {
+	if config.Multiplier <= 0 || config.MinHistory <= 0 {
+		return nil, ErrInvalidRuleParam
+	}
+	return aboveAverageRule{multiplier: config.Multiplier, minHistory: config.MinHistory}, nil
+}
+
+func (r aboveAverageRule) Evaluate(movement Movement, history History) (*Hit, error) {
+	average, count, err := history.Average(movement.WalletID, movement.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if count < r.minHistory || movement.Amount <= average*r.multiplier {
+		return nil, nil
+	}
+	return &Hit{Details: fmt.Sprintf("amount %.4f is above %.1f times the average %.4f", movement.Amount, r.multiplier, average)}, nil
+}
+
+// newWalletRule is hit when wallet younger then Age moves Amount or more.
+type newWalletRule struct {
+	age    time.Duration
+	amount float64
+}
+
+func newNewWalletRule(config RuleConfig) (Rule, error) {
+	if config.Age <= 0 || config.Amount <= 0 {
+		return nil, ErrInvalidRuleParam
+	}
+	return newWalletRule{age: time.Duration(config.Age), amount: config.Amount}, nil
+}
+
+func (r newWalletRule) Evaluate(movement Movement, history History) (*Hit, error) {
+	if movement.Amount < r.amount {
+		return nil, nil
+	}
+	firstActivity, err := history.FirstActivity(movement.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	if !firstActivity.IsZero() && movement.Timestamp.Sub(firstActivity) >= r.age {
+		return nil, nil
+	}
+	return &Hit{Details: fmt.Sprintf("wallet younger then %s moves %.4f", r.age, movement.Amount)}, nil
+}
+
+// fanOutRule is hit when the wallet pays more then Receivers new receivers within Window.
+type fanOutRule struct {
+	receivers int
+	window    time.Duration
+}
+
+func newFanOutRule(config RuleConfig) (Rule, error) {
+	if config.Receivers <= 0 || config.Window <= 0 {
+		return nil, ErrInvalidRuleParam
+	}
+	return fanOutRule{receivers: config.Receivers, window: time.Duration(config.Window)}, nil
+}
+
+func (r fanOutRule) Evaluate(movement Movement, history History) (*Hit, error) {
+	if !movement.Kind.outgoing() {
+		return nil, nil
+	}
+	receivers, err := history.NewReceivers(movement.WalletID, movement.Receivers, movement.Timestamp.Add(-r.window))
+	if err != nil {
+		return nil, err
+	}
+	if receivers <= r.receivers {
+		return nil, nil
+	}
+	return &Hit{Details: fmt.Sprintf("%d new receivers within %s, allowed %d", receivers, r.window, r.receivers)}, nil
+}
@@ -0,0 +1,203 @@
+package risk
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+)
+
+type fakeHistory struct {
+	count         int
+	average       float64
+	averageCount  int
+	firstActivity time.Time
+	newReceivers  int
+}
+
+func (h fakeHistory) Count(int64, Kind, time.Time) (int, error) {
+	return h.count, nil
+}
+
+func (h fakeHistory) Average(int64, Kind) (float64, int, error) {
+	return h.average, h.averageCount, nil
+}
+
+func (h fakeHistory) FirstActivity(int64) (time.Time, error) {
+	return h.firstActivity, nil
+}
+
+func (h fakeHistory) NewReceivers(int64, []int64, time.Time) (int, error) {
+	return h.newReceivers, nil
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		rules   int
+		wantErr error
+	}{
+		{
+			name:  "test ok",
+			data:  `{"review_score": 50, "rules": [{"name": "burst", "type": "velocity", "action": "review", "score": 40, "count": 5, "window": "10m"}]}`,
+			rules: 1,
+		},
+		{
+			name:    "test unknown rule type",
+			data:    `{"rules": [{"name": "burst", "type": "moon_phase", "action": "review"}]}`,
+			wantErr: errors.New(`risk rule "burst": unknown risk rule type`),
+		},
+		{
+			name:    "test unknown action",
+			data:    `{"rules": [{"name": "burst", "type": "velocity", "action": "block", "count": 5, "window": "10m"}]}`,
+			wantErr: errors.New(`risk rule "burst": unknown risk decision`),
+		},
+		{
+			name:    "test missing rule param",
+			data:    `{"rules": [{"name": "burst", "type": "velocity", "action": "deny", "count": 5}]}`,
+			wantErr: errors.New(`risk rule "burst": risk rule parameter should be greater then 0`),
+		},
+		{
+			name:    "test duplicate rule name",
+			data:    `{"rules": [{"name": "burst", "type": "velocity", "action": "deny", "count": 5, "window": "1m"}, {"name": "burst", "type": "velocity", "action": "review", "count": 2, "window": "1m"}]}`,
+			wantErr: errors.New(`risk rule "burst": risk rule name is used more then once`),
+		},
+		{
+			name:    "test invalid duration",
+			data:    `{"rules": [{"name": "burst", "type": "velocity", "action": "deny", "count": 5, "window": "soon"}]}`,
+			wantErr: errors.New(`error parsing risk rules: time: invalid duration "soon"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConfig([]byte(tt.data))
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("ParseConfig() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConfig() unexpected error = %v", err)
+			}
+			if len(got.rules) != tt.rules {
+				t.Errorf("ParseConfig() rules = %d, want %d", len(got.rules), tt.rules)
+			}
+		})
+	}
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	engine, err := NewEngine(Config{
+		ReviewScore: 50,
+		DenyScore:   90,
+		Rules: []RuleConfig{
+			{Name: "burst", Type: RuleVelocity, Action: DecisionReview, Score: 40, Count: 3, Window: Duration(10 * time.Minute)},
+			{Name: "spike", Type: RuleAboveAverage, Action: DecisionAllow, Score: 30, Multiplier: 10, MinHistory: 3},
+			{Name: "fresh", Type: RuleNewWalletHighValue, Action: DecisionDeny, Score: 60, Age: Duration(24 * time.Hour), Amount: 1000},
+			{Name: "fan", Type: RuleFanOut, Action: DecisionReview, Score: 20, Receivers: 2, Window: Duration(time.Hour)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() unexpected error = %v", err)
+	}
+	oldWallet := clk.Now().Add(-30 * 24 * time.Hour)
+	transfer := Movement{Kind: KindTransfer, WalletID: 1, Receivers: []int64{2}, Amount: 100, Timestamp: clk.Now()}
+	tests := []struct {
+		name     string
+		movement Movement
+		history  fakeHistory
+		want     Decision
+		hits     []string
+		score    float64
+		wantErr  error
+	}{
+		{
+			name:     "test nothing hit",
+			movement: transfer,
+			history:  fakeHistory{count: 1, average: 90, averageCount: 10, firstActivity: oldWallet, newReceivers: 1},
+			want:     DecisionAllow,
+		},
+		{
+			name:     "test velocity hit is reviewed",
+			movement: transfer,
+			history:  fakeHistory{count: 3, firstActivity: oldWallet},
+			want:     DecisionReview,
+			hits:     []string{"burst"},
+			score:    40,
+		},
+		{
+			name:     "test allowed hits reach review score",
+			movement: transfer,
+			history:  fakeHistory{average: 5, averageCount: 3, firstActivity: oldWallet, newReceivers: 3},
+			want:     DecisionReview,
+			hits:     []string{"spike", "fan"},
+			score:    50,
+		},
+		{
+			name:     "test average ignored without history",
+			movement: transfer,
+			history:  fakeHistory{average: 5, averageCount: 2, firstActivity: oldWallet},
+			want:     DecisionAllow,
+		},
+		{
+			name:     "test new wallet high value is denied",
+			movement: Movement{Kind: KindWithdraw, WalletID: 1, Amount: 1000, Timestamp: clk.Now()},
+			history:  fakeHistory{firstActivity: clk.Now().Add(-time.Hour)},
+			want:     DecisionDeny,
+			hits:     []string{"fresh"},
+			score:    60,
+		},
+		{
+			name:     "test fan out ignored for deposit",
+			movement: Movement{Kind: KindDeposit, WalletID: 1, Amount: 10, Timestamp: clk.Now()},
+			history:  fakeHistory{firstActivity: oldWallet, newReceivers: 10},
+			want:     DecisionAllow,
+		},
+		{
+			name:     "test score reaches deny",
+			movement: Movement{Kind: KindSplitPayment, WalletID: 1, Receivers: []int64{2, 3, 4}, Amount: 5000, Timestamp: clk.Now()},
+			history:  fakeHistory{count: 5, average: 10, averageCount: 5, firstActivity: oldWallet, newReceivers: 3},
+			want:     DecisionDeny,
+			hits:     []string{"burst", "spike", "fan"},
+			score:    90,
+		},
+		{
+			name:     "test fan out ignored for reversal",
+			movement: Movement{Kind: KindReversal, WalletID: 1, Receivers: []int64{2}, Amount: 10, Timestamp: clk.Now()},
+			history:  fakeHistory{firstActivity: oldWallet, newReceivers: 10},
+			want:     DecisionAllow,
+		},
+		{
+			name:     "test unknown kind",
+			movement: Movement{Kind: "teleport", WalletID: 1, Amount: 10, Timestamp: clk.Now()},
+			wantErr:  errors.New("unknown money movement kind"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Evaluate(tt.movement, tt.history)
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("Engine.Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Engine.Evaluate() unexpected error = %v", err)
+			}
+			var hits []string
+			for _, hit := range got.Hits {
+				hits = append(hits, hit.Rule)
+			}
+			if got.Decision != tt.want || got.Score != tt.score || !reflect.DeepEqual(hits, tt.hits) {
+				t.Errorf("Engine.Evaluate() = %s %v %v, want %s %v %v", got.Decision, got.Score, hits, tt.want, tt.score, tt.hits)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package risk
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+type Service interface {
+	// Evaluate runs the rules against the movement, ErrDenied is returned with the persisted decision
+	// when the movement must not be executed. Otherwise the decision is left for the storage of the
+	// movement to write in its transaction, so that it is kept only when the money moves. Reviewed
+	// movements are executed and left for analysts to look at. It returns nil when there are no rules.
+	Evaluate(context.Context, *MovementDTO) (*DecisionDTO, error)
+	GetByDecision(ctx context.Context, decision Decision, limit int, offset int) ([]DecisionDTO, error)
+}
+
+type service struct {
+	storage Storage
+	logger  logging.Logger
+	clk     clock.Clock
+	engine  *Engine
+}
+
+// NewService creates the service, nothing is evaluated nor persisted when engine has no rules.
+func NewService(storage Storage, logger logging.Logger, clk clock.Clock, engine *Engine) (Service, error) {
+	return &service{storage: storage, logger: logger, clk: clk, engine: engine}, nil
+}
+
+func (s *service) Evaluate(ctx context.Context, dto *MovementDTO) (*DecisionDTO, error) {
+	movement := Movement{
+		Kind:      dto.Kind,
+		WalletID:  dto.WalletID,
+		Receivers: dto.Receivers,
+		Amount:    dto.Amount,
+		Timestamp: s.clk.Now(),
+	}
+	result, err := s.engine.Evaluate(movement, history{ctx: ctx, storage: s.storage})
+	if err != nil {
+		s.logger.Errorf("error evaluating risk rules: %s", err.Error())
+		return nil, errors.Wrap(err, "error evaluating risk rules")
+	}
+	if !s.engine.Enabled() {
+		return nil, nil
+	}
+	if result.Decision != DecisionDeny {
+		return result.toDTO(), nil
+	}
+	decision, err := s.storage.Create(ctx, result.toDTO())
+	if err != nil {
+		s.logger.Errorf("error creating risk decision in db: %s", err.Error())
+		return nil, errors.Wrap(err, "error creating risk decision in db")
+	}
+	s.logger.Errorf("%s of wallet %d denied by risk decision %d", dto.Kind, dto.WalletID, decision.ID)
+	return &decision, ErrDenied
+}
+
+func (s *service) GetByDecision(ctx context.Context, decision Decision, limit int, offset int) ([]DecisionDTO, error) {
+	if !decision.valid() {
+		return nil, ErrUnknownDecision
+	}
+	return s.storage.GetByDecision(ctx, decision, limit, offset)
+}
+
+// history binds storage to the context of the evaluated movement.
+type history struct {
+	ctx     context.Context
+	storage Storage
+}
+
+func (h history) Count(walletID int64, kind Kind, since time.Time) (int, error) {
+	return h.storage.CountMovements(h.ctx, walletID, kind, since)
+}
+
+func (h history) Average(walletID int64, kind Kind) (float64, int, error) {
+	return h.storage.AverageAmount(h.ctx, walletID, kind)
+}
+
+func (h history) FirstActivity(walletID int64) (time.Time, error) {
+	return h.storage.FirstActivity(h.ctx, walletID)
+}
+
+func (h history) NewReceivers(walletID int64, receivers []int64, since time.Time) (int, error) {
+	return h.storage.NewReceivers(h.ctx, walletID, receivers, since)
+}
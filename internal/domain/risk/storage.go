@@ -0,0 +1,15 @@
+package risk
+
+import (
+	"context"
+	"time"
+)
+
+type Storage interface {
+	CountMovements(ctx context.Context, walletID int64, kind Kind, since time.Time) (int, error)
+	AverageAmount(ctx context.Context, walletID int64, kind Kind) (float64, int, error)
+	FirstActivity(ctx context.Context, walletID int64) (time.Time, error)
+	NewReceivers(ctx context.Context, walletID int64, receivers []int64, since time.Time) (int, error)
+	Create(context.Context, *DecisionDTO) (DecisionDTO, error)
+	GetByDecision(ctx context.Context, decision Decision, limit int, offset int) ([]DecisionDTO, error)
+}
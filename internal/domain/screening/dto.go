@@ -0,0 +1,39 @@
+package screening
+
+import "time"
+
+type MatchDTO struct {
+	ID             int64
+	WalletID       int64
+	WalletName     string
+	EntryName      string
+	EntryReference string
+	Normalized     string
+	Score          float64
+	Status         Status
+	CreatedAt      time.Time
+	ReviewedAt     time.Time
+	Reviewer       string
+}
+
+func (d MatchDTO) toModel() *Match {
+	m := Match(d)
+	return &m
+}
+
+type EntryDTO struct {
+	ID         int64
+	Name       string
+	Normalized string
+	Reference  string
+}
+
+func (d EntryDTO) toModel() Entry {
+	return Entry(d)
+}
+
+// WalletDTO is a wallet to be screened.
+type WalletDTO struct {
+	ID   int64
+	Name string
+}
@@ -0,0 +1,343 @@
+package screening
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	StatusPending   Status = "pending"
+	StatusConfirmed Status = "confirmed"
+	StatusCleared   Status = "cleared"
+)
+
+// DefaultThreshold is a similarity score from which the name is considered a match.
+const DefaultThreshold = 0.85
+
+// tokenThreshold is a similarity from which two name tokens are considered the same word.
+const tokenThreshold = 0.8
+
+var (
+	ErrUnknownStatus     = errors.New("unknown screening match status")
+	ErrNotAnOutcome      = errors.New("match can only be reviewed as confirmed or cleared")
+	ErrAlreadyReviewed   = errors.New("screening match is already reviewed")
+	ErrMissingReviewer   = errors.New("missing reviewer")
+	ErrInvalidThreshold  = errors.New("screening threshold should be greater then 0 and not greater then 1")
+	ErrUnknownFormat     = errors.New("watchlist file should be either csv or xml")
+	ErrEmptyWatchlist    = errors.New("watchlist does not have any names")
+	ErrMissingEntryName  = errors.New("watchlist entry must have a name")
+	ErrMissingWalletName = errors.New("wallet must have a name to be screened")
+)
+
+type Status string
+
+func (s Status) valid() bool {
+	switch s {
+	case StatusPending, StatusConfirmed, StatusCleared:
+		return true
+	}
+	return false
+}
+
+// folds maps latin letters with diacritics to their base letters.
+var folds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l", 'ľ': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// Normalize lower cases the name, folds diacritics and replaces punctuation with spaces,
+// tokens are sorted so that word order does not matter.
+func Normalize(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case folds[r] != "":
+			b.WriteString(folds[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '\'' || r == '’':
+			// O'Brien and OBrien are the same name
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	tokens := strings.Fields(b.String())
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// levenshtein returns edit distance between two strings.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
+
+// editSimilarity is 1 for equal strings and 0 for completely different ones.
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// tokenSimilarity pairs every token with the most similar token of the other name, tokens
+// closer then tokenThreshold do not count, score is weighted by the number of tokens in both names.
+func tokenSimilarity(a, b string) float64 {
+	ta, tb := strings.Fields(a), strings.Fields(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	used := make([]bool, len(tb))
+	var sum float64
+	for _, tokenA := range ta {
+		best, bestIndex := 0.0, -1
+		for j, tokenB := range tb {
+			if used[j] {
+				continue
+			}
+			if s := editSimilarity(tokenA, tokenB); s > best {
+				best, bestIndex = s, j
+			}
+		}
+		if bestIndex >= 0 && best >= tokenThreshold {
+			used[bestIndex] = true
+			sum += best
+		}
+	}
+	return 2 * sum / float64(len(ta)+len(tb))
+}
+
+// Similarity compares two normalized names, it is the best of the token and edit distance scores.
+func Similarity(a, b string) float64 {
+	score := editSimilarity(a, b)
+	if s := tokenSimilarity(a, b); s > score {
+		score = s
+	}
+	return score
+}
+
+// Entry is a name from the watchlist, aliases are separate entries sharing the reference.
+type Entry struct {
+	ID         int64
+	Name       string
+	Normalized string
+	Reference  string
+}
+
+func newEntry(name, reference string) (Entry, error) {
+	name = strings.TrimSpace(name)
+	normalized := Normalize(name)
+	if normalized == "" {
+		return Entry{}, ErrMissingEntryName
+	}
+	return Entry{Name: name, Normalized: normalized, Reference: strings.TrimSpace(reference)}, nil
+}
+
+type xmlWatchlist struct {
+	Entries []struct {
+		Reference string   `xml:"reference,attr"`
+		Name      string   `xml:"name"`
+		Aliases   []string `xml:"alias"`
+	} `xml:"entry"`
+}
+
+// ParseCSV reads watchlist with the name in the first column and optional reference in the second,
+// header row starting with "name" is skipped.
+func ParseCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var entries []Entry
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading watchlist csv")
+		}
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "name") {
+			continue
+		}
+		var reference string
+		if len(record) > 1 {
+			reference = record[1]
+		}
+		entry, err := newEntry(record[0], reference)
+		if err != nil {
+			return nil, errors.Wrapf(err, "watchlist line %d", line)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmptyWatchlist
+	}
+	return entries, nil
+}
+
+// ParseXML reads watchlist like <watchlist><entry reference="1"><name/><alias/></entry></watchlist>.
+func ParseXML(r io.Reader) ([]Entry, error) {
+	var list xmlWatchlist
+	if err := xml.NewDecoder(r).Decode(&list); err != nil {
+		return nil, errors.Wrap(err, "error reading watchlist xml")
+	}
+	var entries []Entry
+	for i, e := range list.Entries {
+		for _, name := range append([]string{e.Name}, e.Aliases...) {
+			entry, err := newEntry(name, e.Reference)
+			if err != nil {
+				return nil, errors.Wrapf(err, "watchlist entry %d", i+1)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmptyWatchlist
+	}
+	return entries, nil
+}
+
+// LoadWatchlist reads the watchlist file, format is taken from the file extension.
+func LoadWatchlist(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening watchlist file")
+	}
+	defer file.Close()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCSV(file)
+	case ".xml":
+		return ParseXML(file)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Screener matches names against the loaded watchlist.
+type Screener struct {
+	entries   []Entry
+	threshold float64
+}
+
+func NewScreener(entries []Entry, threshold float64) (*Screener, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, ErrInvalidThreshold
+	}
+	return &Screener{entries: entries, threshold: threshold}, nil
+}
+
+// Screen returns matches of the wallet name at or above the threshold, the best scored first.
+func (s *Screener) Screen(walletID int64, name string, timestamp time.Time) ([]Match, error) {
+	normalized := Normalize(name)
+	if normalized == "" {
+		return nil, ErrMissingWalletName
+	}
+	var matches []Match
+	for _, entry := range s.entries {
+		score := Similarity(normalized, entry.Normalized)
+		if score < s.threshold {
+			continue
+		}
+		matches = append(matches, Match{
+			WalletID:       walletID,
+			WalletName:     name,
+			EntryName:      entry.Name,
+			EntryReference: entry.Reference,
+			Normalized:     entry.Normalized,
+			Score:          score,
+			Status:         StatusPending,
+			CreatedAt:      timestamp,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches, nil
+}
+
+// Match is a wallet name similar to the watchlist entry, the wallet stays frozen until
+// every match of it is reviewed and cleared.
+type Match struct {
+	ID             int64
+	WalletID       int64
+	WalletName     string
+	EntryName      string
+	EntryReference string
+	Normalized     string
+	Score          float64
+	Status         Status
+	CreatedAt      time.Time
+	ReviewedAt     time.Time
+	Reviewer       string
+}
+
+// Review confirms the match keeping the wallet frozen, or clears it as a false positive.
+func (m *Match) Review(outcome Status, reviewer string, timestamp time.Time) error {
+	if m.Status != StatusPending {
+		return ErrAlreadyReviewed
+	}
+	if outcome != StatusConfirmed && outcome != StatusCleared {
+		return ErrNotAnOutcome
+	}
+	if reviewer == "" {
+		return ErrMissingReviewer
+	}
+	m.Status = outcome
+	m.Reviewer = reviewer
+	m.ReviewedAt = timestamp
+	return nil
+}
+
+func (m *Match) toDTO() *MatchDTO {
+	dto := MatchDTO(*m)
+	return &dto
+}
@@ -0,0 +1,159 @@
+package screening
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "test case and word order", in: "SMITH, John", want: "john smith"},
+		{name: "test diacritics", in: "José Müller-Øster", want: "jose muller oster"},
+		{name: "test apostrophe", in: "Seán O'Brien", want: "obrien sean"},
+		{name: "test only punctuation", in: " -- ", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.in); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreener_Screen(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	entries, err := ParseCSV(strings.NewReader("name,reference\nIvan Petrovich Sidorov,SDN-1\nAcme Trading LLC,SDN-2\n"))
+	if err != nil {
+		t.Fatalf("ParseCSV() unexpected error = %v", err)
+	}
+	screener, err := NewScreener(entries, DefaultThreshold)
+	if err != nil {
+		t.Fatalf("NewScreener() unexpected error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		wallet  string
+		want    []string
+		wantErr error
+	}{
+		{name: "test exact match", wallet: "Acme Trading LLC", want: []string{"SDN-2"}},
+		{name: "test reordered and misspelled", wallet: "Sidorov, Ivan Petrovitch", want: []string{"SDN-1"}},
+		{name: "test missing middle name is not enough", wallet: "Ivan Sidorov", want: nil},
+		{name: "test unrelated name", wallet: "Jane Doe", want: nil},
+		{name: "test empty name", wallet: "!!!", wantErr: errors.New("wallet must have a name to be screened")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := screener.Screen(1, tt.wallet, clk.Now())
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("Screener.Screen() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Screener.Screen() unexpected error = %v", err)
+			}
+			var references []string
+			for _, match := range got {
+				if match.Status != StatusPending || match.WalletID != 1 || match.CreatedAt != clk.Now() {
+					t.Errorf("Screener.Screen() unexpected match %v", match)
+				}
+				references = append(references, match.EntryReference)
+			}
+			if !reflect.DeepEqual(references, tt.want) {
+				t.Errorf("Screener.Screen() = %v, want %v", references, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	data := `<watchlist>
+		<entry reference="EU-7"><name>Acme Trading LLC</name><alias>Acme Trade</alias></entry>
+		<entry reference="EU-8"><name>Jöhn Doe</name></entry>
+	</watchlist>`
+	got, err := ParseXML(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParseXML() unexpected error = %v", err)
+	}
+	want := []Entry{
+		{Name: "Acme Trading LLC", Normalized: "acme llc trading", Reference: "EU-7"},
+		{Name: "Acme Trade", Normalized: "acme trade", Reference: "EU-7"},
+		{Name: "Jöhn Doe", Normalized: "doe john", Reference: "EU-8"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseXML() = %v, want %v", got, want)
+	}
+	if _, err := ParseXML(strings.NewReader("<watchlist></watchlist>")); err == nil || err.Error() != "watchlist does not have any names" {
+		t.Errorf("ParseXML() error = %v, want empty watchlist", err)
+	}
+}
+
+func TestMatch_Review(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	type args struct {
+		outcome  Status
+		reviewer string
+	}
+	tests := []struct {
+		name    string
+		match   *Match
+		args    args
+		want    *Match
+		wantErr error
+	}{
+		{
+			name:    "test already reviewed",
+			match:   &Match{ID: 1, Status: StatusCleared},
+			args:    args{outcome: StatusConfirmed, reviewer: "alice"},
+			wantErr: errors.New("screening match is already reviewed"),
+		},
+		{
+			name:    "test not an outcome",
+			match:   &Match{ID: 1, Status: StatusPending},
+			args:    args{outcome: StatusPending, reviewer: "alice"},
+			wantErr: errors.New("match can only be reviewed as confirmed or cleared"),
+		},
+		{
+			name:    "test missing reviewer",
+			match:   &Match{ID: 1, Status: StatusPending},
+			args:    args{outcome: StatusCleared},
+			wantErr: errors.New("missing reviewer"),
+		},
+		{
+			name:  "test cleared",
+			match: &Match{ID: 1, Status: StatusPending},
+			args:  args{outcome: StatusCleared, reviewer: "alice"},
+			want:  &Match{ID: 1, Status: StatusCleared, Reviewer: "alice", ReviewedAt: clk.Now()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.match.Review(tt.args.outcome, tt.args.reviewer, clk.Now())
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("Match.Review() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Match.Review() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.match, tt.want) {
+				t.Errorf("Match.Review() = %v, want %v", tt.match, tt.want)
+			}
+		})
+	}
+}
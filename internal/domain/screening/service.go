@@ -0,0 +1,152 @@
+package screening
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/skwol/wallet/pkg/clock"
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+type Service interface {
+	// ScreenWallet matches the wallet name against the watchlist, wallet with new matches is frozen.
+	ScreenWallet(context.Context, WalletDTO) ([]MatchDTO, error)
+	// Rescreen screens every wallet that is not closed, it returns number of new matches.
+	Rescreen(context.Context) (int, error)
+	// LoadWatchlist replaces the watchlist with the file content, it returns number of entries.
+	LoadWatchlist(ctx context.Context, path string) (int, error)
+	GetMatches(ctx context.Context, status Status, limit int, offset int) ([]MatchDTO, error)
+	Review(ctx context.Context, id int64, outcome Status, reviewer string) (MatchDTO, error)
+}
+
+type service struct {
+	storage   Storage
+	logger    logging.Logger
+	clk       clock.Clock
+	threshold float64
+}
+
+func NewService(storage Storage, logger logging.Logger, clk clock.Clock, threshold float64) (Service, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, ErrInvalidThreshold
+	}
+	return &service{storage: storage, logger: logger, clk: clk, threshold: threshold}, nil
+}
+
+func (s *service) screener(ctx context.Context) (*Screener, error) {
+	entryDTOs, err := s.storage.GetEntries(ctx)
+	if err != nil {
+		s.logger.Errorf("error getting watchlist from db: %s", err.Error())
+		return nil, errors.Wrap(err, "error getting watchlist from db")
+	}
+	entries := make([]Entry, len(entryDTOs))
+	for i, dto := range entryDTOs {
+		entries[i] = dto.toModel()
+	}
+	return NewScreener(entries, s.threshold)
+}
+
+func (s *service) ScreenWallet(ctx context.Context, wallet WalletDTO) ([]MatchDTO, error) {
+	screener, err := s.screener(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.screen(ctx, screener, wallet)
+}
+
+func (s *service) screen(ctx context.Context, screener *Screener, wallet WalletDTO) ([]MatchDTO, error) {
+	matches, err := screener.Screen(wallet.ID, wallet.Name, s.clk.Now())
+	if err != nil {
+		s.logger.Errorf("error screening wallet %d: %s", wallet.ID, err.Error())
+		return nil, errors.Wrapf(err, "error screening wallet %d", wallet.ID)
+	}
+	if len(matches) == 0 {
+		if err := s.storage.Clear(ctx, wallet.ID); err != nil {
+			s.logger.Errorf("error clearing wallet %d in db: %s", wallet.ID, err.Error())
+			return nil, errors.Wrapf(err, "error clearing wallet %d in db", wallet.ID)
+		}
+		return nil, nil
+	}
+	matchDTOs := make([]MatchDTO, len(matches))
+	for i := range matches {
+		matchDTOs[i] = *matches[i].toDTO()
+	}
+	flagged, err := s.storage.Flag(ctx, wallet.ID, matchDTOs)
+	if err != nil {
+		s.logger.Errorf("error flagging wallet %d in db: %s", wallet.ID, err.Error())
+		return nil, errors.Wrapf(err, "error flagging wallet %d in db", wallet.ID)
+	}
+	if len(flagged) > 0 {
+		s.logger.Infof("wallet %d frozen with %d watchlist matches", wallet.ID, len(flagged))
+	}
+	return flagged, nil
+}
+
+func (s *service) Rescreen(ctx context.Context) (int, error) {
+	screener, err := s.screener(ctx)
+	if err != nil {
+		return 0, err
+	}
+	wallets, err := s.storage.GetWallets(ctx)
+	if err != nil {
+		s.logger.Errorf("error getting wallets from db: %s", err.Error())
+		return 0, errors.Wrap(err, "error getting wallets from db")
+	}
+	var flagged int
+	for _, wallet := range wallets {
+		matches, err := s.screen(ctx, screener, wallet)
+		if err != nil {
+			return flagged, err
+		}
+		flagged += len(matches)
+	}
+	return flagged, nil
+}
+
+func (s *service) LoadWatchlist(ctx context.Context, path string) (int, error) {
+	entries, err := LoadWatchlist(path)
+	if err != nil {
+		s.logger.Errorf("error loading watchlist: %s", err.Error())
+		return 0, errors.Wrap(err, "error loading watchlist")
+	}
+	entryDTOs := make([]EntryDTO, len(entries))
+	for i, entry := range entries {
+		entryDTOs[i] = EntryDTO(entry)
+	}
+	if err := s.storage.ReplaceEntries(ctx, entryDTOs); err != nil {
+		s.logger.Errorf("error replacing watchlist in db: %s", err.Error())
+		return 0, errors.Wrap(err, "error replacing watchlist in db")
+	}
+	return len(entries), nil
+}
+
+func (s *service) GetMatches(ctx context.Context, status Status, limit int, offset int) ([]MatchDTO, error) {
+	if !status.valid() {
+		return nil, ErrUnknownStatus
+	}
+	return s.storage.GetMatches(ctx, status, limit, offset)
+}
+
+func (s *service) Review(ctx context.Context, id int64, outcome Status, reviewer string) (MatchDTO, error) {
+	matchInDB, err := s.storage.GetMatch(ctx, id)
+	if err != nil {
+		s.logger.Errorf("error getting screening match from db: %s", err.Error())
+		return MatchDTO{}, errors.Wrap(err, "error getting screening match from db")
+	}
+	if matchInDB.ID == 0 {
+		s.logger.Errorf("missing screening match in db")
+		return MatchDTO{}, errors.New("missing screening match in db")
+	}
+	matchModel := matchInDB.toModel()
+	if err := matchModel.Review(outcome, reviewer, s.clk.Now()); err != nil {
+		s.logger.Errorf("error reviewing screening match: %s", err.Error())
+		return MatchDTO{}, errors.Wrap(err, "error reviewing screening match")
+	}
+	result := matchModel.toDTO()
+	if err := s.storage.Review(ctx, result); err != nil {
+		s.logger.Errorf("error updating screening match in db: %s", err.Error())
+		return MatchDTO{}, errors.Wrap(err, "error updating screening match in db")
+	}
+	return *result, nil
+}
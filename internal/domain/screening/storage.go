@@ -0,0 +1,20 @@
+package screening
+
+import "context"
+
+type Storage interface {
+	GetEntries(context.Context) ([]EntryDTO, error)
+	// ReplaceEntries swaps the whole watchlist, matches already found are kept.
+	ReplaceEntries(context.Context, []EntryDTO) error
+	// Flag stores new matches of the wallet and freezes it, matches of the wallet with
+	// the same entry name found before are skipped. It returns stored matches.
+	Flag(ctx context.Context, walletID int64, matches []MatchDTO) ([]MatchDTO, error)
+	// Clear unfreezes the wallet screened without matches unless it has pending or confirmed matches.
+	Clear(ctx context.Context, walletID int64) error
+	GetMatch(context.Context, int64) (MatchDTO, error)
+	GetMatches(ctx context.Context, status Status, limit int, offset int) ([]MatchDTO, error)
+	// Review stores the decision, wallet is unfrozen when it has no pending nor confirmed matches left.
+	Review(context.Context, *MatchDTO) error
+	// GetWallets returns wallets that are not closed.
+	GetWallets(context.Context) ([]WalletDTO, error)
+}
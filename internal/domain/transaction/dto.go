@@ -1,6 +1,11 @@
 package transaction
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/skwol/wallet/pkg/pagination"
+)
 
 type DTO struct {
 	ID         int64
@@ -9,14 +14,69 @@ type DTO struct {
 	Amount     float64
 	Timestamp  time.Time
 	Type       TranType
+	// ParentPaymentID links the leg of a split payment to the payment, 0 for regular transactions.
+	ParentPaymentID int64
+	Disputes        []DisputeDTO
+}
+
+// CSVHeaders is the column layout of transaction reports, CSVRecord follows the same order.
+var CSVHeaders = []string{"Transaction ID", "Sender ID", "Receiver ID", "Amount", "Timestamp", "Type"}
+
+// CSVRecord formats the transaction as a report row with the timestamp in loc,
+// nil loc keeps the timestamp as it is.
+func (d DTO) CSVRecord(loc *time.Location) []string {
+	timestamp := d.Timestamp
+	if loc != nil {
+		timestamp = timestamp.In(loc)
+	}
+	return []string{fmt.Sprintf("%d", d.ID), fmt.Sprintf("%d", d.SenderID), fmt.Sprintf("%d", d.ReceiverID), fmt.Sprintf("%f", d.Amount), timestamp.Format("Mon, 02 Jan 2006 15:04:05 -0700"), string(d.Type)}
 }
 
+// DisputeDTO is a short view of the dispute opened on the transaction.
+type DisputeDTO struct {
+	ID         int64
+	Reason     string
+	Status     string
+	HoldAmount float64
+	OpenedAt   time.Time
+	ResolvedAt time.Time
+}
+
+// FilterTransactionsDTO keeps the flat filter fields for existing clients, Where and Sort
+// are used for anything the flat fields can not express. Flat fields and Where are combined with AND.
 type FilterTransactionsDTO struct {
 	SenderIDs   []int64
 	ReceiverIDs []int64
 	Amount      FloatRangeFilter
 	Timestamp   DateRangeFilter
 	Types       []string
+	Where       *Expression
+	Sort        []Sort
+}
+
+// Expression is a node of the filter tree, exactly one of And, Or, Not and Condition is set.
+type Expression struct {
+	And       []Expression
+	Or        []Expression
+	Not       *Expression
+	Condition *Condition
+}
+
+// Condition compares a single field, IDs are used by id and wallet fields, Types by the type field,
+// ID, Amount and Date by comparison operators on the id, amount and date fields.
+type Condition struct {
+	Field  Field
+	Op     Operator
+	IDs    []int64
+	Types  []TranType
+	ID     int64
+	Amount float64
+	Date   time.Time
+}
+
+type Sort struct {
+	Field SortField
+	Desc  bool
 }
 
 type FloatRangeFilter struct {
@@ -28,3 +88,8 @@ type DateRangeFilter struct {
 	From time.Time
 	To   time.Time
 }
+
+type PageDTO struct {
+	Transactions []DTO
+	pagination.Result
+}
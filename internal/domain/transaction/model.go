@@ -1,6 +1,10 @@
 package transaction
 
-import "time"
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 type TranType string
 
@@ -8,8 +12,267 @@ const (
 	TranTypeDeposit  TranType = "deposit"
 	TranTypeWithdraw TranType = "withdraw"
 	TranTypeTransfer TranType = "transfer"
+	TranTypeReversal TranType = "reversal"
+)
+
+const (
+	// FieldID is the transaction id, it is compared by comparison operators as well as listed.
+	FieldID       Field = "id"
+	FieldSender   Field = "sender_id"
+	FieldReceiver Field = "receiver_id"
+	// FieldWallet matches the wallet on either side of the transaction.
+	FieldWallet Field = "wallet_id"
+	FieldAmount Field = "amount"
+	FieldDate   Field = "date"
+	FieldType   Field = "type"
+)
+
+const (
+	OpIn    Operator = "in"
+	OpNotIn Operator = "not_in"
+	OpGt    Operator = "gt"
+	OpGte   Operator = "gte"
+	OpLt    Operator = "lt"
+	OpLte   Operator = "lte"
+)
+
+const (
+	SortID     SortField = "id"
+	SortAmount SortField = "amount"
+	SortDate   SortField = "date"
+)
+
+// maxExpressionDepth and maxExpressionNodes keep generated queries reasonably small.
+const (
+	maxExpressionDepth = 8
+	maxExpressionNodes = 100
+)
+
+var (
+	ErrUnknownTranType     = errors.New("unknown transaction type")
+	ErrUnknownField        = errors.New("unknown filter field")
+	ErrUnsupportedOperator = errors.New("operator is not supported for the filter field")
+	ErrInvalidExpression   = errors.New("filter expression must have exactly one of and, or, not or condition")
+	ErrMissingValues       = errors.New("filter condition must have a value")
+	ErrFilterTooComplex    = errors.New("filter expression is too complex")
+	ErrUnknownSortField    = errors.New("unknown sort field")
+	ErrDuplicateSortField  = errors.New("sort field is used more then once")
+	ErrSortNeedsOffset     = errors.New("cursor pages are ordered by id, other sort needs offset")
 )
 
+type Field string
+
+type Operator string
+
+type SortField string
+
+func (o Operator) comparison() bool {
+	switch o {
+	case OpGt, OpGte, OpLt, OpLte:
+		return true
+	}
+	return false
+}
+
+func (o Operator) list() bool {
+	return o == OpIn || o == OpNotIn
+}
+
+func (s SortField) valid() bool {
+	switch s {
+	case SortID, SortAmount, SortDate:
+		return true
+	}
+	return false
+}
+
+func (t TranType) valid() bool {
+	switch t {
+	case TranTypeDeposit, TranTypeWithdraw, TranTypeTransfer, TranTypeReversal:
+		return true
+	}
+	return false
+}
+
+// validateFilter rejects filter values which can not be stored in the transaction table
+// and expressions which can not be translated into a query.
+func validateFilter(filter *FilterTransactionsDTO) error {
+	if filter == nil {
+		return nil
+	}
+	for _, t := range filter.Types {
+		if !TranType(t).valid() {
+			return errors.Wrapf(ErrUnknownTranType, "%q", t)
+		}
+	}
+	if filter.Where != nil {
+		var nodes int
+		if err := filter.Where.validate(1, &nodes); err != nil {
+			return err
+		}
+	}
+	used := make(map[SortField]bool, len(filter.Sort))
+	for _, sort := range filter.Sort {
+		if !sort.Field.valid() {
+			return errors.Wrapf(ErrUnknownSortField, "%q", sort.Field)
+		}
+		if used[sort.Field] {
+			return errors.Wrapf(ErrDuplicateSortField, "%q", sort.Field)
+		}
+		used[sort.Field] = true
+	}
+	return nil
+}
+
+// validatePageFilter checks the filter of keyset pages, they can only go in id ascending order.
+func validatePageFilter(filter *FilterTransactionsDTO) error {
+	if err := validateFilter(filter); err != nil {
+		return err
+	}
+	if filter == nil {
+		return nil
+	}
+	for _, sort := range filter.Sort {
+		if sort.Field != SortID || sort.Desc {
+			return errors.Wrapf(ErrSortNeedsOffset, "%q", sort.Field)
+		}
+	}
+	return nil
+}
+
+func (e *Expression) validate(depth int, nodes *int) error {
+	*nodes++
+	if depth > maxExpressionDepth || *nodes > maxExpressionNodes {
+		return ErrFilterTooComplex
+	}
+	var parts int
+	for _, set := range []bool{e.And != nil, e.Or != nil, e.Not != nil, e.Condition != nil} {
+		if set {
+			parts++
+		}
+	}
+	if parts != 1 {
+		return ErrInvalidExpression
+	}
+	switch {
+	case e.Condition != nil:
+		return e.Condition.validate()
+	case e.Not != nil:
+		return e.Not.validate(depth+1, nodes)
+	}
+	children := e.And
+	if e.Or != nil {
+		children = e.Or
+	}
+	if len(children) == 0 {
+		return ErrInvalidExpression
+	}
+	for i := range children {
+		if err := children[i].validate(depth+1, nodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Condition) validate() error {
+	switch c.Field {
+	case FieldSender, FieldReceiver, FieldWallet:
+		if !c.Op.list() {
+			return errors.Wrapf(ErrUnsupportedOperator, "%s %s", c.Field, c.Op)
+		}
+		if len(c.IDs) == 0 {
+			return errors.Wrapf(ErrMissingValues, "%s", c.Field)
+		}
+	case FieldType:
+		if !c.Op.list() {
+			return errors.Wrapf(ErrUnsupportedOperator, "%s %s", c.Field, c.Op)
+		}
+		if len(c.Types) == 0 {
+			return errors.Wrapf(ErrMissingValues, "%s", c.Field)
+		}
+		for _, t := range c.Types {
+			if !t.valid() {
+				return errors.Wrapf(ErrUnknownTranType, "%q", t)
+			}
+		}
+	case FieldID:
+		if c.Op.list() && len(c.IDs) == 0 {
+			return errors.Wrapf(ErrMissingValues, "%s", c.Field)
+		}
+		if !c.Op.list() && !c.Op.comparison() {
+			return errors.Wrapf(ErrUnsupportedOperator, "%s %s", c.Field, c.Op)
+		}
+	case FieldAmount:
+		if !c.Op.comparison() {
+			return errors.Wrapf(ErrUnsupportedOperator, "%s %s", c.Field, c.Op)
+		}
+	case FieldDate:
+		if !c.Op.comparison() {
+			return errors.Wrapf(ErrUnsupportedOperator, "%s %s", c.Field, c.Op)
+		}
+		if c.Date.IsZero() {
+			return errors.Wrapf(ErrMissingValues, "%s", c.Field)
+		}
+	default:
+		return errors.Wrapf(ErrUnknownField, "%q", c.Field)
+	}
+	return nil
+}
+
+// Expression returns the flat fields and Where as a single expression, nil when nothing is filtered.
+// Flat ranges keep their original meaning: 0 is unset, a half-open range is exclusive
+// and a range with both bounds set is inclusive.
+func (f *FilterTransactionsDTO) Expression() *Expression {
+	if f == nil {
+		return nil
+	}
+	var and []Expression
+	condition := func(c Condition) {
+		and = append(and, Expression{Condition: &c})
+	}
+	if len(f.SenderIDs) > 0 {
+		condition(Condition{Field: FieldSender, Op: OpIn, IDs: f.SenderIDs})
+	}
+	if len(f.ReceiverIDs) > 0 {
+		condition(Condition{Field: FieldReceiver, Op: OpIn, IDs: f.ReceiverIDs})
+	}
+	switch from, to := f.Amount.From, f.Amount.To; {
+	case from == 0 && to == 0:
+	case from > 0 && to == 0:
+		condition(Condition{Field: FieldAmount, Op: OpGt, Amount: from})
+	case from == 0 && to > 0:
+		condition(Condition{Field: FieldAmount, Op: OpLt, Amount: to})
+	default:
+		condition(Condition{Field: FieldAmount, Op: OpGte, Amount: from})
+		condition(Condition{Field: FieldAmount, Op: OpLte, Amount: to})
+	}
+	switch from, to := f.Timestamp.From, f.Timestamp.To; {
+	case from.IsZero() && to.IsZero():
+	case !from.IsZero() && to.IsZero():
+		condition(Condition{Field: FieldDate, Op: OpGt, Date: from})
+	case from.IsZero() && !to.IsZero():
+		condition(Condition{Field: FieldDate, Op: OpLt, Date: to})
+	default:
+		condition(Condition{Field: FieldDate, Op: OpGte, Date: from})
+		condition(Condition{Field: FieldDate, Op: OpLte, Date: to})
+	}
+	if len(f.Types) > 0 {
+		types := make([]TranType, 0, len(f.Types))
+		for _, t := range f.Types {
+			types = append(types, TranType(t))
+		}
+		condition(Condition{Field: FieldType, Op: OpIn, Types: types})
+	}
+	if f.Where != nil {
+		and = append(and, *f.Where)
+	}
+	if len(and) == 0 {
+		return nil
+	}
+	return &Expression{And: and}
+}
+
 type Transaction struct {
 	ID         int64
 	SenderID   int64
@@ -0,0 +1,201 @@
+package transaction
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_validateFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  *FilterTransactionsDTO
+		wantErr error
+	}{
+		{name: "test nil filter", filter: nil},
+		{name: "test empty types", filter: &FilterTransactionsDTO{SenderIDs: []int64{1}}},
+		{name: "test known types", filter: &FilterTransactionsDTO{Types: []string{"deposit", "withdraw", "transfer", "reversal"}}},
+		{
+			name:    "test unknown type",
+			filter:  &FilterTransactionsDTO{Types: []string{"deposit", "deposit') OR 1=1; --"}},
+			wantErr: errors.New(`"deposit') OR 1=1; --": unknown transaction type`),
+		},
+		{
+			name:    "test type is case sensitive",
+			filter:  &FilterTransactionsDTO{Types: []string{"Deposit"}},
+			wantErr: errors.New(`"Deposit": unknown transaction type`),
+		},
+		{
+			name: "test valid expression",
+			filter: &FilterTransactionsDTO{
+				Where: &Expression{Or: []Expression{
+					{Condition: &Condition{Field: FieldWallet, Op: OpIn, IDs: []int64{1}}},
+					{Not: &Expression{Condition: &Condition{Field: FieldAmount, Op: OpGte, Amount: 0}}},
+				}},
+				Sort: []Sort{{Field: SortAmount, Desc: true}, {Field: SortDate}},
+			},
+		},
+		{
+			name:    "test unknown type in condition",
+			filter:  &FilterTransactionsDTO{Where: &Expression{Condition: &Condition{Field: FieldType, Op: OpNotIn, Types: []TranType{"bonus"}}}},
+			wantErr: errors.New(`"bonus": unknown transaction type`),
+		},
+		{
+			name:    "test comparison on wallet",
+			filter:  &FilterTransactionsDTO{Where: &Expression{Condition: &Condition{Field: FieldSender, Op: OpGt, IDs: []int64{1}}}},
+			wantErr: errors.New("sender_id gt: operator is not supported for the filter field"),
+		},
+		{
+			name:    "test unknown field",
+			filter:  &FilterTransactionsDTO{Where: &Expression{Condition: &Condition{Field: "currency", Op: OpIn}}},
+			wantErr: errors.New(`"currency": unknown filter field`),
+		},
+		{
+			name:    "test empty values",
+			filter:  &FilterTransactionsDTO{Where: &Expression{Condition: &Condition{Field: FieldWallet, Op: OpNotIn}}},
+			wantErr: errors.New("wallet_id: filter condition must have a value"),
+		},
+		{
+			name:   "test id comparison",
+			filter: &FilterTransactionsDTO{Where: &Expression{Condition: &Condition{Field: FieldID, Op: OpGt, ID: 100}}},
+		},
+		{
+			name:    "test empty id list",
+			filter:  &FilterTransactionsDTO{Where: &Expression{Condition: &Condition{Field: FieldID, Op: OpIn}}},
+			wantErr: errors.New("id: filter condition must have a value"),
+		},
+		{
+			name:    "test empty group",
+			filter:  &FilterTransactionsDTO{Where: &Expression{Or: []Expression{}}},
+			wantErr: errors.New("filter expression must have exactly one of and, or, not or condition"),
+		},
+		{
+			name: "test group and condition in one node",
+			filter: &FilterTransactionsDTO{Where: &Expression{
+				And:       []Expression{{Condition: &Condition{Field: FieldWallet, Op: OpIn, IDs: []int64{1}}}},
+				Condition: &Condition{Field: FieldWallet, Op: OpIn, IDs: []int64{2}},
+			}},
+			wantErr: errors.New("filter expression must have exactly one of and, or, not or condition"),
+		},
+		{
+			name:    "test too deep",
+			filter:  &FilterTransactionsDTO{Where: nested(maxExpressionDepth)},
+			wantErr: errors.New("filter expression is too complex"),
+		},
+		{
+			name:    "test duplicate sort field",
+			filter:  &FilterTransactionsDTO{Sort: []Sort{{Field: SortDate}, {Field: SortDate, Desc: true}}},
+			wantErr: errors.New(`"date": sort field is used more then once`),
+		},
+		{
+			name:    "test unknown sort field",
+			filter:  &FilterTransactionsDTO{Sort: []Sort{{Field: "receiver_id"}}},
+			wantErr: errors.New(`"receiver_id": unknown sort field`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFilter(tt.filter)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("validateFilter() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || tt.wantErr.Error() != err.Error() {
+				t.Errorf("validateFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validatePageFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  *FilterTransactionsDTO
+		wantErr error
+	}{
+		{name: "test nil filter", filter: nil},
+		{name: "test default sort", filter: &FilterTransactionsDTO{SenderIDs: []int64{1}}},
+		{name: "test sort by id", filter: &FilterTransactionsDTO{Sort: []Sort{{Field: SortID}}}},
+		{
+			name:    "test sort by id descending",
+			filter:  &FilterTransactionsDTO{Sort: []Sort{{Field: SortID, Desc: true}}},
+			wantErr: errors.New(`"id": cursor pages are ordered by id, other sort needs offset`),
+		},
+		{
+			name:    "test sort by amount",
+			filter:  &FilterTransactionsDTO{Sort: []Sort{{Field: SortAmount}}},
+			wantErr: errors.New(`"amount": cursor pages are ordered by id, other sort needs offset`),
+		},
+		{
+			name:    "test invalid filter",
+			filter:  &FilterTransactionsDTO{Types: []string{"Deposit"}},
+			wantErr: errors.New(`"Deposit": unknown transaction type`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePageFilter(tt.filter)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("validatePageFilter() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || tt.wantErr.Error() != err.Error() {
+				t.Errorf("validatePageFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// nested returns a condition wrapped into the given number of not expressions.
+func nested(depth int) *Expression {
+	e := &Expression{Condition: &Condition{Field: FieldWallet, Op: OpIn, IDs: []int64{1}}}
+	for i := 0; i < depth; i++ {
+		e = &Expression{Not: e}
+	}
+	return e
+}
+
+func TestFilterTransactionsDTO_Expression(t *testing.T) {
+	from := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+	where := Expression{Condition: &Condition{Field: FieldWallet, Op: OpIn, IDs: []int64{3}}}
+	tests := []struct {
+		name   string
+		filter *FilterTransactionsDTO
+		want   *Expression
+	}{
+		{name: "test nil filter", filter: nil, want: nil},
+		{name: "test empty filter", filter: &FilterTransactionsDTO{}, want: nil},
+		{
+			name:   "test half open ranges are exclusive",
+			filter: &FilterTransactionsDTO{Amount: FloatRangeFilter{From: 10}, Timestamp: DateRangeFilter{To: from}},
+			want: &Expression{And: []Expression{
+				{Condition: &Condition{Field: FieldAmount, Op: OpGt, Amount: 10}},
+				{Condition: &Condition{Field: FieldDate, Op: OpLt, Date: from}},
+			}},
+		},
+		{
+			name:   "test flat fields and where",
+			filter: &FilterTransactionsDTO{SenderIDs: []int64{1}, Amount: FloatRangeFilter{From: 10, To: 20}, Types: []string{"deposit"}, Where: &where},
+			want: &Expression{And: []Expression{
+				{Condition: &Condition{Field: FieldSender, Op: OpIn, IDs: []int64{1}}},
+				{Condition: &Condition{Field: FieldAmount, Op: OpGte, Amount: 10}},
+				{Condition: &Condition{Field: FieldAmount, Op: OpLte, Amount: 20}},
+				{Condition: &Condition{Field: FieldType, Op: OpIn, Types: []TranType{TranTypeDeposit}}},
+				where,
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Expression(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterTransactionsDTO.Expression() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,13 +3,24 @@ package transaction
 import (
 	"context"
 
+	"github.com/pkg/errors"
+
 	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/pagination"
 )
 
 type Service interface {
 	GetByID(context.Context, int64) (DTO, error)
 	GetAll(ctx context.Context, limit int, offset int) ([]DTO, error)
+	GetPage(context.Context, pagination.Page) (PageDTO, error)
 	GetFiltered(ctx context.Context, filter *FilterTransactionsDTO, limit int, offset int) ([]DTO, error)
+	// GetFilteredPage returns a keyset page of filtered transactions, the filter can not sort them.
+	GetFilteredPage(context.Context, *FilterTransactionsDTO, pagination.Page) (PageDTO, error)
+	// ValidateFilter checks the filter the same way GetFiltered does, for filters applied later.
+	ValidateFilter(*FilterTransactionsDTO) error
+	CountFiltered(context.Context, *FilterTransactionsDTO) (int64, error)
+	// StreamFiltered calls fn for every filtered transaction, limit 0 streams all of them.
+	StreamFiltered(ctx context.Context, filter *FilterTransactionsDTO, limit int, offset int, fn func(DTO) error) error
 }
 
 type service struct {
@@ -29,6 +40,70 @@ func (s *service) GetAll(ctx context.Context, limit int, offset int) ([]DTO, err
 	return s.storage.GetAll(ctx, limit, offset)
 }
 
+func (s *service) GetPage(ctx context.Context, page pagination.Page) (PageDTO, error) {
+	transactions, err := s.storage.GetPage(ctx, page)
+	if err != nil {
+		return PageDTO{}, errors.Wrap(err, "error getting transactions")
+	}
+	ids := make([]int64, 0, len(transactions))
+	for _, t := range transactions {
+		ids = append(ids, t.ID)
+	}
+	start, end, result := page.Cut(ids)
+	if page.Total {
+		total, err := s.storage.Count(ctx)
+		if err != nil {
+			return PageDTO{}, errors.Wrap(err, "error counting transactions")
+		}
+		result.Total = &total
+	}
+	return PageDTO{Transactions: transactions[start:end], Result: result}, nil
+}
+
 func (s *service) GetFiltered(ctx context.Context, filter *FilterTransactionsDTO, limit int, offset int) ([]DTO, error) {
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
 	return s.storage.GetFiltered(ctx, filter, limit, offset)
 }
+
+func (s *service) GetFilteredPage(ctx context.Context, filter *FilterTransactionsDTO, page pagination.Page) (PageDTO, error) {
+	if err := validatePageFilter(filter); err != nil {
+		return PageDTO{}, err
+	}
+	transactions, err := s.storage.GetFilteredPage(ctx, filter, page)
+	if err != nil {
+		return PageDTO{}, errors.Wrap(err, "error getting filtered transactions")
+	}
+	ids := make([]int64, 0, len(transactions))
+	for _, t := range transactions {
+		ids = append(ids, t.ID)
+	}
+	start, end, result := page.Cut(ids)
+	if page.Total {
+		total, err := s.storage.CountFiltered(ctx, filter)
+		if err != nil {
+			return PageDTO{}, errors.Wrap(err, "error counting filtered transactions")
+		}
+		result.Total = &total
+	}
+	return PageDTO{Transactions: transactions[start:end], Result: result}, nil
+}
+
+func (s *service) ValidateFilter(filter *FilterTransactionsDTO) error {
+	return validateFilter(filter)
+}
+
+func (s *service) CountFiltered(ctx context.Context, filter *FilterTransactionsDTO) (int64, error) {
+	if err := validateFilter(filter); err != nil {
+		return 0, err
+	}
+	return s.storage.CountFiltered(ctx, filter)
+}
+
+func (s *service) StreamFiltered(ctx context.Context, filter *FilterTransactionsDTO, limit int, offset int, fn func(DTO) error) error {
+	if err := validateFilter(filter); err != nil {
+		return err
+	}
+	return s.storage.StreamFiltered(ctx, filter, limit, offset, fn)
+}
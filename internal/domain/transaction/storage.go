@@ -1,9 +1,21 @@
 package transaction
 
-import "context"
+import (
+	"context"
+
+	"github.com/skwol/wallet/pkg/pagination"
+)
 
 type Storage interface {
 	GetByID(context.Context, int64) (DTO, error)
 	GetAll(context.Context, int, int) ([]DTO, error)
+	// GetPage returns up to page.Fetch() transactions in id ascending order.
+	GetPage(context.Context, pagination.Page) ([]DTO, error)
+	Count(context.Context) (int64, error)
 	GetFiltered(context.Context, *FilterTransactionsDTO, int, int) ([]DTO, error)
+	// GetFilteredPage returns up to page.Fetch() filtered transactions in id ascending order.
+	GetFilteredPage(context.Context, *FilterTransactionsDTO, pagination.Page) ([]DTO, error)
+	CountFiltered(context.Context, *FilterTransactionsDTO) (int64, error)
+	// StreamFiltered calls fn for every filtered transaction in order and stops on the first error.
+	StreamFiltered(ctx context.Context, filter *FilterTransactionsDTO, limit, offset int, fn func(DTO) error) error
 }
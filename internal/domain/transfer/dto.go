@@ -1,6 +1,10 @@
 package transfer
 
-import "time"
+import (
+	"time"
+
+	"github.com/skwol/wallet/internal/domain/risk"
+)
 
 type DTO struct {
 	ID int64
@@ -12,6 +16,8 @@ type CreateTransferDTO struct {
 	Timestamp time.Time
 	Sender    WalletDTO
 	Receiver  WalletDTO
+	// Risk is the decision on the transfer, written together with it
+	Risk *risk.DecisionDTO
 }
 
 func (d CreateTransferDTO) validate() error {
@@ -27,7 +33,16 @@ func (d CreateTransferDTO) validate() error {
 	if d.Amount <= 0 {
 		return ErrNonPositiveAmount
 	}
-	if d.Sender.Balance-d.Amount < 0 {
+	if d.Sender.Closed || d.Receiver.Closed {
+		return ErrClosedWallet
+	}
+	if d.Sender.Frozen || d.Receiver.Frozen {
+		return ErrFrozenWallet
+	}
+	if d.Sender.Reserved || d.Receiver.Reserved {
+		return ErrReservedWallet
+	}
+	if d.Sender.Balance-d.Sender.Held-d.Amount < 0 {
 		return ErrNotEnoughMoney
 	}
 	return nil
@@ -43,10 +58,88 @@ func (d CreateTransferDTO) toModel() *Transfer {
 }
 
 type WalletDTO struct {
-	ID      int64
-	Balance float64
+	ID       int64
+	Balance  float64
+	Held     float64
+	Closed   bool
+	Frozen   bool
+	Reserved bool
 }
 
 func (d WalletDTO) toModel() Wallet {
 	return Wallet(d)
 }
+
+// CreateSplitPaymentDTO describes a payment from the sender distributed across several receivers.
+type CreateSplitPaymentDTO struct {
+	Amount float64
+	Sender WalletDTO
+	Legs   []SplitLegDTO
+}
+
+// SplitLegDTO is a share of the payment, either fixed amount or percent of what is left after fixed legs.
+type SplitLegDTO struct {
+	Receiver WalletDTO
+	Percent  float64
+	Fixed    float64
+}
+
+func (d CreateSplitPaymentDTO) validate() error {
+	if d.Sender.ID == 0 {
+		return ErrMissingSender
+	}
+	if d.Amount <= 0 {
+		return ErrNonPositiveAmount
+	}
+	if len(d.Legs) == 0 {
+		return ErrMissingSplitLegs
+	}
+	receivers := make(map[int64]struct{}, len(d.Legs))
+	for _, leg := range d.Legs {
+		if leg.Receiver.ID == 0 {
+			return ErrMissingReceiver
+		}
+		if leg.Receiver.ID == d.Sender.ID {
+			return ErrSameSenderAndReceiver
+		}
+		if leg.Receiver.Closed {
+			return ErrClosedWallet
+		}
+		if leg.Receiver.Frozen {
+			return ErrFrozenWallet
+		}
+		if leg.Receiver.Reserved {
+			return ErrReservedWallet
+		}
+		if _, ok := receivers[leg.Receiver.ID]; ok {
+			return ErrDuplicateReceiver
+		}
+		receivers[leg.Receiver.ID] = struct{}{}
+		if leg.Percent < 0 || leg.Fixed < 0 || (leg.Percent > 0) == (leg.Fixed > 0) {
+			return ErrInvalidSplitLeg
+		}
+	}
+	if d.Sender.Closed {
+		return ErrClosedWallet
+	}
+	if d.Sender.Frozen {
+		return ErrFrozenWallet
+	}
+	if d.Sender.Reserved {
+		return ErrReservedWallet
+	}
+	if d.Sender.Balance-d.Sender.Held-d.Amount < 0 {
+		return ErrNotEnoughMoney
+	}
+	return nil
+}
+
+type PaymentDTO struct {
+	ID        int64
+	Amount    float64
+	Timestamp time.Time
+	Sender    WalletDTO
+	Legs      []DTO
+	// Risk is the decision on the whole payment, written together with its legs
+	Risk *risk.DecisionDTO
+}
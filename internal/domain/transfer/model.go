@@ -1,6 +1,8 @@
 package transfer
 
 import (
+	"math"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
@@ -12,8 +14,23 @@ var (
 	ErrSameSenderAndReceiver = errors.New("sender and receiver is the same wallet")
 	ErrNonPositiveAmount     = errors.New("amount should be greater then 0")
 	ErrNotEnoughMoney        = errors.New("sender does not have enough 'money' for transfer")
+	ErrClosedWallet          = errors.New("transfer can not be performed with closed wallet")
+	ErrFrozenWallet          = errors.New("transfer can not be performed with wallet frozen pending screening review")
+	ErrReservedWallet        = errors.New("transfer can not be performed with wallet reserved by the service")
+	ErrMissingSplitLegs      = errors.New("split payment must have at least one leg")
+	ErrDuplicateReceiver     = errors.New("receiver can be used only once in split payment")
+	ErrInvalidSplitLeg       = errors.New("split leg must have either positive percent or positive fixed amount")
+	ErrSplitPercentNot100    = errors.New("split percentages must add up to 100")
+	ErrSplitAmountMismatch   = errors.New("split legs do not add up to the payment amount")
+	ErrSplitLegTooSmall      = errors.New("split leg amount is less then minimal unit")
 )
 
+// amountUnits is a number of minimal money units in 1, it matches numeric(8,4) of the db.
+const amountUnits = 10000
+
+// percentUnits is 100% expressed in the same precision as amounts.
+const percentUnits = 100 * amountUnits
+
 type Transfer struct {
 	Amount    float64
 	Timestamp time.Time
@@ -32,15 +49,25 @@ func (t *Transfer) toDTO() *DTO {
 	}
 }
 
+// Wallet keeps Held amount that is reserved by active disputes and can not be spent,
+// Frozen wallet has a watchlist match waiting for review, Reserved wallet belongs to the service.
 type Wallet struct {
-	ID      int64
-	Balance float64
+	ID       int64
+	Balance  float64
+	Held     float64
+	Closed   bool
+	Frozen   bool
+	Reserved bool
 }
 
 func (w *Wallet) toDTO() WalletDTO {
 	return WalletDTO{
-		ID:      w.ID,
-		Balance: w.Balance,
+		ID:       w.ID,
+		Balance:  w.Balance,
+		Held:     w.Held,
+		Closed:   w.Closed,
+		Frozen:   w.Frozen,
+		Reserved: w.Reserved,
 	}
 }
 
@@ -53,3 +80,105 @@ func createTransfer(dto *CreateTransferDTO, timestamp time.Time) (*Transfer, err
 	dto.Timestamp = timestamp
 	return dto.toModel(), nil
 }
+
+// Payment is a single payment of the sender executed as one transfer per split leg.
+type Payment struct {
+	ID        int64
+	Amount    float64
+	Timestamp time.Time
+	Sender    Wallet
+	Legs      []Transfer
+}
+
+func (p *Payment) toDTO() *PaymentDTO {
+	legs := make([]DTO, len(p.Legs))
+	for i := range p.Legs {
+		legs[i] = *p.Legs[i].toDTO()
+	}
+	return &PaymentDTO{
+		ID:        p.ID,
+		Amount:    p.Amount,
+		Timestamp: p.Timestamp,
+		Sender:    p.Sender.toDTO(),
+		Legs:      legs,
+	}
+}
+
+func createSplitPayment(dto *CreateSplitPaymentDTO, timestamp time.Time) (*Payment, error) {
+	if err := dto.validate(); err != nil {
+		return nil, err
+	}
+	amounts, err := split(dto.Amount, dto.Legs)
+	if err != nil {
+		return nil, err
+	}
+	p := &Payment{
+		Amount:    float64(toUnits(dto.Amount)) / amountUnits,
+		Timestamp: timestamp,
+		Sender:    dto.Sender.toModel(),
+	}
+	p.Sender.Balance -= p.Amount
+	for i, leg := range dto.Legs {
+		receiver := leg.Receiver.toModel()
+		receiver.Balance += amounts[i]
+		p.Legs = append(p.Legs, Transfer{Amount: amounts[i], Timestamp: timestamp, Sender: p.Sender, Receiver: receiver})
+	}
+	return p, nil
+}
+
+// split distributes amount across the legs. Fixed legs are taken first and the rest is divided by percent.
+// Percent shares are rounded down to the minimal unit and units left after rounding go one by one
+// to the legs with the largest dropped remainder, ties are resolved by leg order,
+// so parts always sum exactly to the amount and the same input always gives the same result.
+func split(amount float64, legs []SplitLegDTO) ([]float64, error) {
+	total := toUnits(amount)
+	units := make([]int64, len(legs))
+	var (
+		rest        = total
+		percentLegs []int
+		percentSum  int64
+	)
+	for i, leg := range legs {
+		if leg.Fixed > 0 {
+			units[i] = toUnits(leg.Fixed)
+			rest -= units[i]
+			continue
+		}
+		percentLegs = append(percentLegs, i)
+		percentSum += toUnits(leg.Percent)
+	}
+	if rest < 0 || (len(percentLegs) == 0 && rest != 0) {
+		return nil, ErrSplitAmountMismatch
+	}
+	if len(percentLegs) > 0 && percentSum != percentUnits {
+		return nil, ErrSplitPercentNot100
+	}
+
+	remainders := make(map[int]int64, len(percentLegs))
+	left := rest
+	for _, i := range percentLegs {
+		share := rest * toUnits(legs[i].Percent)
+		units[i] = share / percentUnits
+		remainders[i] = share % percentUnits
+		left -= units[i]
+	}
+	sort.SliceStable(percentLegs, func(a, b int) bool {
+		return remainders[percentLegs[a]] > remainders[percentLegs[b]]
+	})
+	for j := int64(0); j < left; j++ {
+		units[percentLegs[j]]++
+	}
+
+	amounts := make([]float64, len(legs))
+	for i, u := range units {
+		if u <= 0 {
+			return nil, ErrSplitLegTooSmall
+		}
+		amounts[i] = float64(u) / amountUnits
+	}
+	return amounts, nil
+}
+
+func toUnits(amount float64) int64 {
+	return int64(math.Round(amount * amountUnits))
+}
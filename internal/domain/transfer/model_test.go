@@ -57,6 +57,18 @@ func Test_createTransfer(t *testing.T) {
 			want:    nil,
 			wantErr: errors.New("sender does not have enough 'money' for transfer"),
 		},
+		{
+			name:    "test frozen receiver",
+			args:    args{dto: &CreateTransferDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 150}, Receiver: WalletDTO{ID: 2, Frozen: true}}},
+			want:    nil,
+			wantErr: errors.New("transfer can not be performed with wallet frozen pending screening review"),
+		},
+		{
+			name:    "test reserved sender",
+			args:    args{dto: &CreateTransferDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 150, Reserved: true}, Receiver: WalletDTO{ID: 2}}},
+			want:    nil,
+			wantErr: errors.New("transfer can not be performed with wallet reserved by the service"),
+		},
 		{
 			name:    "test ok",
 			args:    args{dto: &CreateTransferDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 150}, Receiver: WalletDTO{ID: 2, Balance: 50}}},
@@ -78,3 +90,112 @@ func Test_createTransfer(t *testing.T) {
 		})
 	}
 }
+
+func Test_createSplitPayment(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	type args struct {
+		dto *CreateSplitPaymentDTO
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Payment
+		wantErr error
+	}{
+		{
+			name:    "test missing legs",
+			args:    args{dto: &CreateSplitPaymentDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 100}}},
+			want:    nil,
+			wantErr: errors.New("split payment must have at least one leg"),
+		},
+		{
+			name: "test duplicate receiver",
+			args: args{dto: &CreateSplitPaymentDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 100}, Legs: []SplitLegDTO{
+				{Receiver: WalletDTO{ID: 2}, Percent: 50}, {Receiver: WalletDTO{ID: 2}, Percent: 50},
+			}}},
+			want:    nil,
+			wantErr: errors.New("receiver can be used only once in split payment"),
+		},
+		{
+			name: "test reserved receiver",
+			args: args{dto: &CreateSplitPaymentDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 100}, Legs: []SplitLegDTO{
+				{Receiver: WalletDTO{ID: 2, Reserved: true}, Percent: 100},
+			}}},
+			want:    nil,
+			wantErr: errors.New("transfer can not be performed with wallet reserved by the service"),
+		},
+		{
+			name: "test leg with both percent and fixed amount",
+			args: args{dto: &CreateSplitPaymentDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 100}, Legs: []SplitLegDTO{
+				{Receiver: WalletDTO{ID: 2}, Percent: 50, Fixed: 10},
+			}}},
+			want:    nil,
+			wantErr: errors.New("split leg must have either positive percent or positive fixed amount"),
+		},
+		{
+			name: "test percentages do not add up to 100",
+			args: args{dto: &CreateSplitPaymentDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 100}, Legs: []SplitLegDTO{
+				{Receiver: WalletDTO{ID: 2}, Percent: 50}, {Receiver: WalletDTO{ID: 3}, Percent: 40},
+			}}},
+			want:    nil,
+			wantErr: errors.New("split percentages must add up to 100"),
+		},
+		{
+			name: "test fixed amounts do not add up to the payment amount",
+			args: args{dto: &CreateSplitPaymentDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 100}, Legs: []SplitLegDTO{
+				{Receiver: WalletDTO{ID: 2}, Fixed: 50}, {Receiver: WalletDTO{ID: 3}, Fixed: 40},
+			}}},
+			want:    nil,
+			wantErr: errors.New("split legs do not add up to the payment amount"),
+		},
+		{
+			name: "test sender does not have enough money",
+			args: args{dto: &CreateSplitPaymentDTO{Amount: 100, Sender: WalletDTO{ID: 1, Balance: 100, Held: 1}, Legs: []SplitLegDTO{
+				{Receiver: WalletDTO{ID: 2}, Percent: 100},
+			}}},
+			want:    nil,
+			wantErr: errors.New("sender does not have enough 'money' for transfer"),
+		},
+		{
+			name: "test ok remainder goes to largest fraction",
+			args: args{dto: &CreateSplitPaymentDTO{Amount: 0.001, Sender: WalletDTO{ID: 1, Balance: 150}, Legs: []SplitLegDTO{
+				{Receiver: WalletDTO{ID: 2}, Percent: 33.3333},
+				{Receiver: WalletDTO{ID: 3}, Percent: 33.3333},
+				{Receiver: WalletDTO{ID: 4}, Percent: 33.3334},
+			}}},
+			want: &Payment{Amount: 0.001, Timestamp: clk.Now(), Sender: Wallet{ID: 1, Balance: 149.999}, Legs: []Transfer{
+				{Amount: 0.0003, Timestamp: clk.Now(), Sender: Wallet{ID: 1, Balance: 149.999}, Receiver: Wallet{ID: 2, Balance: 0.0003}},
+				{Amount: 0.0003, Timestamp: clk.Now(), Sender: Wallet{ID: 1, Balance: 149.999}, Receiver: Wallet{ID: 3, Balance: 0.0003}},
+				{Amount: 0.0004, Timestamp: clk.Now(), Sender: Wallet{ID: 1, Balance: 149.999}, Receiver: Wallet{ID: 4, Balance: 0.0004}},
+			}},
+			wantErr: nil,
+		},
+		{
+			name: "test ok fixed commission and percent of the rest with tie in leg order",
+			args: args{dto: &CreateSplitPaymentDTO{Amount: 10, Sender: WalletDTO{ID: 1, Balance: 10}, Legs: []SplitLegDTO{
+				{Receiver: WalletDTO{ID: 2}, Fixed: 0.0001},
+				{Receiver: WalletDTO{ID: 3}, Percent: 50},
+				{Receiver: WalletDTO{ID: 4, Balance: 1}, Percent: 50},
+			}}},
+			want: &Payment{Amount: 10, Timestamp: clk.Now(), Sender: Wallet{ID: 1, Balance: 0}, Legs: []Transfer{
+				{Amount: 0.0001, Timestamp: clk.Now(), Sender: Wallet{ID: 1, Balance: 0}, Receiver: Wallet{ID: 2, Balance: 0.0001}},
+				{Amount: 5, Timestamp: clk.Now(), Sender: Wallet{ID: 1, Balance: 0}, Receiver: Wallet{ID: 3, Balance: 5}},
+				{Amount: 4.9999, Timestamp: clk.Now(), Sender: Wallet{ID: 1, Balance: 0}, Receiver: Wallet{ID: 4, Balance: 5.9999}},
+			}},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := createSplitPayment(tt.args.dto, clk.Now())
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("createSplitPayment() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("createSplitPayment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,25 +2,36 @@ package transfer
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/pkg/errors"
 
 	"github.com/skwol/wallet/pkg/clock"
 	"github.com/skwol/wallet/pkg/logging"
+
+	"github.com/skwol/wallet/internal/domain/approval"
+	"github.com/skwol/wallet/internal/domain/risk"
 )
 
 type Service interface {
 	Create(context.Context, *CreateTransferDTO) (DTO, error)
+	CreateSplitPayment(context.Context, *CreateSplitPaymentDTO) (PaymentDTO, error)
 }
 
 type service struct {
-	storage Storage
-	logger  logging.Logger
-	clk     clock.Clock
+	storage  Storage
+	approval approval.Service
+	risk     risk.Service
+	logger   logging.Logger
+	clk      clock.Clock
 }
 
-func NewService(storage Storage, logger logging.Logger, clk clock.Clock) (Service, error) {
-	return &service{storage: storage, logger: logger, clk: clk}, nil
+// NewService registers executors of transfer operations that wait for approval.
+func NewService(storage Storage, approvalService approval.Service, riskService risk.Service, logger logging.Logger, clk clock.Clock) (Service, error) {
+	s := &service{storage: storage, approval: approvalService, risk: riskService, logger: logger, clk: clk}
+	approvalService.RegisterExecutor(approval.OperationTransfer, s.executeTransfer)
+	approvalService.RegisterExecutor(approval.OperationSplitPayment, s.executeSplitPayment)
+	return s, nil
 }
 
 func (s *service) Create(ctx context.Context, dto *CreateTransferDTO) (DTO, error) {
@@ -55,7 +66,25 @@ func (s *service) Create(ctx context.Context, dto *CreateTransferDTO) (DTO, erro
 		s.logger.Errorf("transfer model was not created")
 		return DTO{}, errors.New("transfer model was not created")
 	}
-	result, err := s.storage.Create(ctx, &transferModel.toDTO().CreateTransferDTO)
+	// payload keeps the request only, wallets are read again when the approved transfer is executed
+	payload := CreateTransferDTO{Sender: WalletDTO{ID: dto.Sender.ID}, Receiver: WalletDTO{ID: dto.Receiver.ID}, Amount: dto.Amount}
+	if err := s.approval.Gate(ctx, approval.OperationTransfer, transferModel.Amount, payload); err != nil {
+		return DTO{}, err
+	}
+	movement := &risk.MovementDTO{
+		Kind:      risk.KindTransfer,
+		WalletID:  transferModel.Sender.ID,
+		Receivers: []int64{transferModel.Receiver.ID},
+		Amount:    transferModel.Amount,
+	}
+	decision, err := s.risk.Evaluate(ctx, movement)
+	if err != nil {
+		s.logger.Errorf("error evaluating transfer risk: %s", err.Error())
+		return DTO{}, errors.Wrap(err, "error evaluating transfer risk")
+	}
+	transferDTO := transferModel.toDTO().CreateTransferDTO
+	transferDTO.Risk = decision
+	result, err := s.storage.Create(ctx, &transferDTO)
 	if err != nil {
 		s.logger.Errorf("error creating transfer in db: %s", err.Error())
 		return DTO{}, errors.Wrap(err, "error creating wallet in db")
@@ -66,3 +95,80 @@ func (s *service) Create(ctx context.Context, dto *CreateTransferDTO) (DTO, erro
 	}
 	return result, nil
 }
+
+func (s *service) CreateSplitPayment(ctx context.Context, dto *CreateSplitPaymentDTO) (PaymentDTO, error) {
+	walletSender, err := s.storage.GetWallet(ctx, dto.Sender.ID)
+	if err != nil {
+		s.logger.Errorf("error getting sender wallet from db: %s", err.Error())
+		return PaymentDTO{}, errors.Wrap(err, "error getting sender wallet from db")
+	}
+	if walletSender.ID == 0 {
+		s.logger.Errorf("missing sender wallet in db")
+		return PaymentDTO{}, errors.New("missing sender wallet in db")
+	}
+	dto.Sender = walletSender
+	for i, leg := range dto.Legs {
+		walletReceiver, err := s.storage.GetWallet(ctx, leg.Receiver.ID)
+		if err != nil {
+			s.logger.Errorf("error getting receiver wallet from db: %s", err.Error())
+			return PaymentDTO{}, errors.Wrap(err, "error getting receiver wallet from db")
+		}
+		if walletReceiver.ID == 0 {
+			s.logger.Errorf("missing receiver wallet %d in db", leg.Receiver.ID)
+			return PaymentDTO{}, errors.Errorf("missing receiver wallet %d in db", leg.Receiver.ID)
+		}
+		dto.Legs[i].Receiver = walletReceiver
+	}
+	paymentModel, err := createSplitPayment(dto, s.clk.Now())
+	if err != nil {
+		s.logger.Errorf("error creating split payment model: %s", err.Error())
+		return PaymentDTO{}, errors.Wrap(err, "error creating split payment model")
+	}
+	payload := CreateSplitPaymentDTO{Sender: WalletDTO{ID: dto.Sender.ID}, Amount: dto.Amount}
+	for _, leg := range dto.Legs {
+		leg.Receiver = WalletDTO{ID: leg.Receiver.ID}
+		payload.Legs = append(payload.Legs, leg)
+	}
+	if err := s.approval.Gate(ctx, approval.OperationSplitPayment, paymentModel.Amount, payload); err != nil {
+		return PaymentDTO{}, err
+	}
+	movement := &risk.MovementDTO{Kind: risk.KindSplitPayment, WalletID: paymentModel.Sender.ID, Amount: paymentModel.Amount}
+	for _, leg := range paymentModel.Legs {
+		movement.Receivers = append(movement.Receivers, leg.Receiver.ID)
+	}
+	decision, err := s.risk.Evaluate(ctx, movement)
+	if err != nil {
+		s.logger.Errorf("error evaluating split payment risk: %s", err.Error())
+		return PaymentDTO{}, errors.Wrap(err, "error evaluating split payment risk")
+	}
+	paymentDTO := paymentModel.toDTO()
+	paymentDTO.Risk = decision
+	result, err := s.storage.CreateSplitPayment(ctx, paymentDTO)
+	if err != nil {
+		s.logger.Errorf("error creating split payment in db: %s", err.Error())
+		return PaymentDTO{}, errors.Wrap(err, "error creating split payment in db")
+	}
+	if result.ID == 0 {
+		s.logger.Errorf("empty split payment returned from db")
+		return PaymentDTO{}, errors.New("empty split payment returned from db")
+	}
+	return result, nil
+}
+
+func (s *service) executeTransfer(ctx context.Context, payload []byte) error {
+	var dto CreateTransferDTO
+	if err := json.Unmarshal(payload, &dto); err != nil {
+		return errors.Wrap(err, "error unmarshaling transfer")
+	}
+	_, err := s.Create(ctx, &dto)
+	return err
+}
+
+func (s *service) executeSplitPayment(ctx context.Context, payload []byte) error {
+	var dto CreateSplitPaymentDTO
+	if err := json.Unmarshal(payload, &dto); err != nil {
+		return errors.Wrap(err, "error unmarshaling split payment")
+	}
+	_, err := s.CreateSplitPayment(ctx, &dto)
+	return err
+}
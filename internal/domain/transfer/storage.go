@@ -4,5 +4,6 @@ import "context"
 
 type Storage interface {
 	Create(context.Context, *CreateTransferDTO) (DTO, error)
+	CreateSplitPayment(context.Context, *PaymentDTO) (PaymentDTO, error)
 	GetWallet(context.Context, int64) (WalletDTO, error)
 }
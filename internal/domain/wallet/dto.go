@@ -1,22 +1,35 @@
 package wallet
 
 import (
+	"strings"
 	"time"
+
+	"github.com/skwol/wallet/pkg/pagination"
+
+	"github.com/skwol/wallet/internal/domain/risk"
 )
 
 type DTO struct {
 	ID                  int64
 	Name                string
 	Balance             float64
+	Held                float64
+	Status              Status
+	Reserved            bool
 	TransactionsToApply []TransactionDTO
-	Transactions        []TransactionDTO
+	// RiskDecisions are the decisions on TransactionsToApply, written together with them
+	RiskDecisions []*risk.DecisionDTO
+	Transactions  []TransactionDTO
 }
 
 func (d DTO) toModel() Wallet {
 	return Wallet{
-		ID:      d.ID,
-		Name:    d.Name,
-		Balance: d.Balance,
+		ID:       d.ID,
+		Name:     d.Name,
+		Balance:  d.Balance,
+		Held:     d.Held,
+		Status:   d.Status,
+		Reserved: d.Reserved,
 	}
 }
 
@@ -32,11 +45,16 @@ func (d CreateWalletDTO) validate() error {
 	if d.Name == "" {
 		return ErrMissingName
 	}
+	if strings.HasPrefix(d.Name, ReservedNamePrefix) {
+		return ErrReservedName
+	}
 	return nil
 }
 
 type UpdateWalletDTO struct {
 	CreateWalletDTO
+	// ExpectedBalance is the balance the update was requested against, nil when the current one is accepted
+	ExpectedBalance *float64
 }
 
 type TransactionDTO struct {
@@ -46,4 +64,68 @@ type TransactionDTO struct {
 	Amount     float64
 	Timestamp  time.Time
 	Type       TranType
+	// ParentPaymentID is the split payment of the transfer, 0 for regular transactions
+	ParentPaymentID int64
+	// Balance is the running balance of the wallet right after the transaction, it is only set when asked for
+	Balance *float64
+}
+
+func (d TransactionDTO) toModel() Transaction {
+	return Transaction(d)
+}
+
+type PageDTO struct {
+	Wallets []DTO
+	pagination.Result
+}
+
+// TransactionsPageDTO is a wallet with a page of its transactions in Transactions.
+type TransactionsPageDTO struct {
+	Wallet DTO
+	pagination.Result
+}
+
+// StatementDTO is the account statement of Wallet for the period [From, To).
+type StatementDTO struct {
+	Wallet         DTO
+	From           time.Time
+	To             time.Time
+	CreatedAt      time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	TotalIn        float64
+	TotalOut       float64
+	Lines          []StatementLineDTO
+}
+
+type StatementLineDTO struct {
+	Transaction    TransactionDTO
+	CounterpartyID int64
+	Amount         float64
+	Balance        float64
+}
+
+// BalanceDTO is the balance of Wallet at the moment At computed from its transactions.
+type BalanceDTO struct {
+	Wallet  DTO
+	At      time.Time
+	Balance float64
+}
+
+// BalanceHistoryDTO is the balance of Wallet at the end of every interval of the period [From, To).
+type BalanceHistoryDTO struct {
+	Wallet         DTO
+	From           time.Time
+	To             time.Time
+	Interval       Interval
+	OpeningBalance float64
+	ClosingBalance float64
+	Points         []BalancePointDTO
+}
+
+type BalancePointDTO struct {
+	From    time.Time
+	To      time.Time
+	Change  float64
+	Balance float64
 }
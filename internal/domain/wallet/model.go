@@ -11,21 +11,63 @@ const (
 	TranTypeDeposit  TranType = "deposit"
 	TranTypeWithdraw TranType = "withdraw"
 	TranTypeTransfer TranType = "transfer"
+	TranTypeReversal TranType = "reversal"
+)
+
+const (
+	StatusActive Status = "active"
+	StatusClosed Status = "closed"
+	StatusFrozen Status = "frozen"
 )
 
 var (
 	ErrNegativeBalance            = errors.New("balance can not be less then 0")
 	ErrMissingName                = errors.New("wallet must have a name")
 	ErrUpdateWithoutBalanceChange = errors.New("balance must be updated")
+	ErrBalanceBelowHeld           = errors.New("balance can not be less then amount held by active disputes")
+	ErrWalletClosed               = errors.New("wallet is closed")
+	ErrWalletFrozen               = errors.New("wallet is frozen pending screening review")
+	ErrCloseWithBalance           = errors.New("only wallet with zero balance can be closed")
+	ErrInvalidPeriod              = errors.New("statement period should end after it starts")
+	ErrUnknownInterval            = errors.New("unknown balance history interval")
+	ErrTooManyPoints              = errors.New("balance history has too many points")
+	ErrReservedName               = errors.New("wallet name is reserved")
+	ErrWalletReserved             = errors.New("wallet is reserved by the service")
+	ErrConcurrentUpdate           = errors.New("wallet balance was changed by another request")
 )
 
+// ReservedNamePrefix starts names of wallets the service keeps for itself, like the escrow holding wallet.
+const ReservedNamePrefix = "__"
+
+const (
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+)
+
+// MaxHistoryPoints limits balance history, longer periods are asked with a longer interval.
+const MaxHistoryPoints = 1000
+
 type TranType string
 
-// Wallet is an aggregate entity
+type Status string
+
+// Interval is the length of a balance history point.
+type Interval string
+
+func (i Interval) valid() bool {
+	return i == IntervalDay || i == IntervalWeek || i == IntervalMonth
+}
+
+// Wallet is an aggregate entity, Held is reserved by active disputes and can not be withdrawn.
+// Reserved wallet belongs to the service and can not be updated or closed.
 type Wallet struct {
 	ID                  int64
 	Name                string
 	Balance             float64
+	Held                float64
+	Status              Status
+	Reserved            bool
 	TransactionsToApply []Transaction
 }
 
@@ -37,9 +79,11 @@ func newWallet(dto *CreateWalletDTO, timestamp time.Time) (*Wallet, error) {
 	if dto.Balance > 0 {
 		transactionsToApply = append(transactionsToApply, Transaction{Amount: dto.Balance, Timestamp: timestamp, Type: TranTypeDeposit})
 	}
+	// new wallet is frozen until its name is screened against the watchlist
 	return &Wallet{
 		Name:                dto.Name,
 		Balance:             dto.Balance,
+		Status:              StatusFrozen,
 		TransactionsToApply: transactionsToApply,
 	}, nil
 }
@@ -53,6 +97,9 @@ func (w Wallet) toDTO() DTO {
 		ID:                  w.ID,
 		Name:                w.Name,
 		Balance:             w.Balance,
+		Held:                w.Held,
+		Status:              w.Status,
+		Reserved:            w.Reserved,
 		TransactionsToApply: transactionsToApply,
 	}
 }
@@ -61,9 +108,24 @@ func (w *Wallet) Update(walletDTO *UpdateWalletDTO, timestamp time.Time) (*Walle
 	if err := walletDTO.validate(); err != nil {
 		return nil, err
 	}
+	if w.Reserved {
+		return nil, ErrWalletReserved
+	}
+	if w.Status == StatusClosed {
+		return nil, ErrWalletClosed
+	}
+	if w.Status == StatusFrozen {
+		return nil, ErrWalletFrozen
+	}
+	if walletDTO.ExpectedBalance != nil && *walletDTO.ExpectedBalance != w.Balance {
+		return nil, ErrConcurrentUpdate
+	}
 	if walletDTO.Balance == w.Balance {
 		return nil, ErrUpdateWithoutBalanceChange
 	}
+	if walletDTO.Balance < w.Balance && walletDTO.Balance < w.Held {
+		return nil, ErrBalanceBelowHeld
+	}
 	var tType TranType
 	if walletDTO.Balance > w.Balance {
 		tType = TranTypeDeposit
@@ -82,15 +144,250 @@ func (w *Wallet) Update(walletDTO *UpdateWalletDTO, timestamp time.Time) (*Walle
 	return w, nil
 }
 
+// Close deactivates the wallet, closed wallet can not be updated or take part in transfers.
+func (w *Wallet) Close() (*Wallet, error) {
+	if w.Reserved {
+		return nil, ErrWalletReserved
+	}
+	if w.Status == StatusClosed {
+		return nil, ErrWalletClosed
+	}
+	if w.Status == StatusFrozen {
+		return nil, ErrWalletFrozen
+	}
+	if w.Balance != 0 || w.Held != 0 {
+		return nil, ErrCloseWithBalance
+	}
+	w.Status = StatusClosed
+	return w, nil
+}
+
 type Transaction struct {
-	ID         int64
-	SenderID   int64
-	ReceiverID int64
-	Amount     float64
-	Timestamp  time.Time
-	Type       TranType
+	ID              int64
+	SenderID        int64
+	ReceiverID      int64
+	Amount          float64
+	Timestamp       time.Time
+	Type            TranType
+	ParentPaymentID int64
+	Balance         *float64
 }
 
 func (t Transaction) toDTO() TransactionDTO {
 	return TransactionDTO(t)
 }
+
+// signedAmount is the movement of the transaction for the wallet, negative when money leaves it.
+func (t Transaction) signedAmount(walletID int64) float64 {
+	switch {
+	case t.Type == TranTypeDeposit:
+		return t.Amount
+	case t.Type == TranTypeWithdraw:
+		return -t.Amount
+	case t.ReceiverID == walletID:
+		return t.Amount
+	default:
+		return -t.Amount
+	}
+}
+
+// counterpartyID is the other wallet of a transfer or reversal, deposits and withdrawals have none.
+func (t Transaction) counterpartyID(walletID int64) int64 {
+	switch {
+	case t.Type == TranTypeDeposit || t.Type == TranTypeWithdraw:
+		return 0
+	case t.SenderID == walletID:
+		return t.ReceiverID
+	default:
+		return t.SenderID
+	}
+}
+
+// Statement is the account statement of the wallet for the period [From, To),
+// every line has the balance of the wallet right after its transaction.
+type Statement struct {
+	Wallet         Wallet
+	From           time.Time
+	To             time.Time
+	CreatedAt      time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	TotalIn        float64
+	TotalOut       float64
+	Lines          []StatementLine
+}
+
+type StatementLine struct {
+	Transaction    Transaction
+	CounterpartyID int64
+	// Amount is negative when money leaves the wallet
+	Amount  float64
+	Balance float64
+}
+
+// newStatement builds the statement from the balance at from and the transactions of the period in date order.
+func newStatement(wallet Wallet, from, to time.Time, openingBalance float64, transactions []Transaction, timestamp time.Time) (*Statement, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+	statement := &Statement{
+		Wallet:         wallet,
+		From:           from,
+		To:             to,
+		CreatedAt:      timestamp,
+		OpeningBalance: roundAmount(openingBalance),
+		Lines:          make([]StatementLine, 0, len(transactions)),
+	}
+	balance := statement.OpeningBalance
+	for _, tran := range transactions {
+		amount := tran.signedAmount(wallet.ID)
+		if amount >= 0 {
+			statement.TotalIn = roundAmount(statement.TotalIn + amount)
+		} else {
+			statement.TotalOut = roundAmount(statement.TotalOut - amount)
+		}
+		balance = roundAmount(balance + amount)
+		statement.Lines = append(statement.Lines, StatementLine{
+			Transaction:    tran,
+			CounterpartyID: tran.counterpartyID(wallet.ID),
+			Amount:         amount,
+			Balance:        balance,
+		})
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
+
+func (s Statement) toDTO() StatementDTO {
+	lines := make([]StatementLineDTO, len(s.Lines))
+	for i, line := range s.Lines {
+		lines[i] = StatementLineDTO{
+			Transaction:    line.Transaction.toDTO(),
+			CounterpartyID: line.CounterpartyID,
+			Amount:         line.Amount,
+			Balance:        line.Balance,
+		}
+	}
+	return StatementDTO{
+		Wallet:         s.Wallet.toDTO(),
+		From:           s.From,
+		To:             s.To,
+		CreatedAt:      s.CreatedAt,
+		OpeningBalance: s.OpeningBalance,
+		ClosingBalance: s.ClosingBalance,
+		TotalIn:        s.TotalIn,
+		TotalOut:       s.TotalOut,
+		Lines:          lines,
+	}
+}
+
+// withRunningBalance sets the balance of the wallet after every transaction, transactions are in id order
+// and openingBalance is the balance before the first of them.
+func withRunningBalance(walletID int64, openingBalance float64, transactions []Transaction) []Transaction {
+	balance := roundAmount(openingBalance)
+	for i := range transactions {
+		balance = roundAmount(balance + transactions[i].signedAmount(walletID))
+		running := balance
+		transactions[i].Balance = &running
+	}
+	return transactions
+}
+
+// BalanceHistory is the balance of the wallet at the end of every interval of the period [From, To).
+type BalanceHistory struct {
+	Wallet         Wallet
+	From           time.Time
+	To             time.Time
+	Interval       Interval
+	OpeningBalance float64
+	ClosingBalance float64
+	Points         []BalancePoint
+}
+
+// BalancePoint is the interval [From, To), Change is the sum of movements within it and Balance is the balance at To.
+type BalancePoint struct {
+	From    time.Time
+	To      time.Time
+	Change  float64
+	Balance float64
+}
+
+// historyStarts splits [from, to) into intervals which start at from, days, weeks and months are those of the time zone,
+// the last interval ends at to and can be shorter.
+func historyStarts(from, to time.Time, interval Interval, loc *time.Location) ([]time.Time, error) {
+	if !interval.valid() {
+		return nil, ErrUnknownInterval
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+	start := from.In(loc)
+	var starts []time.Time
+	for i := 0; ; i++ {
+		var next time.Time
+		switch interval {
+		case IntervalDay:
+			next = start.AddDate(0, 0, i)
+		case IntervalWeek:
+			next = start.AddDate(0, 0, 7*i)
+		case IntervalMonth:
+			// months are counted from the start, so that a short month does not move the following ones
+			next = start.AddDate(0, i, 0)
+		}
+		if !next.Before(to) {
+			return starts, nil
+		}
+		if len(starts) == MaxHistoryPoints {
+			return nil, ErrTooManyPoints
+		}
+		starts = append(starts, next)
+	}
+}
+
+// newBalanceHistory builds the history from the balance at the first start and the sum of movements within every interval.
+func newBalanceHistory(wallet Wallet, starts []time.Time, to time.Time, interval Interval, openingBalance float64, changes []float64) (*BalanceHistory, error) {
+	if len(starts) == 0 || len(changes) != len(starts) {
+		return nil, errors.New("every interval of balance history needs its change")
+	}
+	history := &BalanceHistory{
+		Wallet:         wallet,
+		From:           starts[0],
+		To:             to,
+		Interval:       interval,
+		OpeningBalance: roundAmount(openingBalance),
+		Points:         make([]BalancePoint, len(starts)),
+	}
+	balance := history.OpeningBalance
+	for i, start := range starts {
+		end := to
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		change := roundAmount(changes[i])
+		balance = roundAmount(balance + change)
+		history.Points[i] = BalancePoint{From: start, To: end, Change: change, Balance: balance}
+	}
+	history.ClosingBalance = balance
+	return history, nil
+}
+
+func (h BalanceHistory) toDTO() BalanceHistoryDTO {
+	points := make([]BalancePointDTO, len(h.Points))
+	for i, p := range h.Points {
+		points[i] = BalancePointDTO(p)
+	}
+	return BalanceHistoryDTO{
+		Wallet:         h.Wallet.toDTO(),
+		From:           h.From,
+		To:             h.To,
+		Interval:       h.Interval,
+		OpeningBalance: h.OpeningBalance,
+		ClosingBalance: h.ClosingBalance,
+		Points:         points,
+	}
+}
+
+// roundAmount drops the float error below the precision amounts are stored with.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*1e4) / 1e4
+}
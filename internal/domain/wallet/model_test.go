@@ -13,9 +13,11 @@ import (
 func TestWallet_Update(t *testing.T) {
 	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
 	type fields struct {
-		ID      int64
-		Name    string
-		Balance float64
+		ID       int64
+		Name     string
+		Balance  float64
+		Held     float64
+		Reserved bool
 	}
 	type args struct {
 		wallet *UpdateWalletDTO
@@ -41,6 +43,30 @@ func TestWallet_Update(t *testing.T) {
 			want:    nil,
 			wantErr: errors.New("balance should be updated"),
 		},
+		{
+			name:   "test balance changed since request",
+			fields: fields{ID: 1, Balance: 10},
+			args: args{wallet: &UpdateWalletDTO{
+				CreateWalletDTO: CreateWalletDTO{Name: "wallet", Balance: 20},
+				ExpectedBalance: func(balance float64) *float64 { return &balance }(15),
+			}},
+			want:    nil,
+			wantErr: errors.New("wallet balance was changed by another request"),
+		},
+		{
+			name:    "test withdraw below held amount",
+			fields:  fields{ID: 1, Balance: 10, Held: 5},
+			args:    args{wallet: &UpdateWalletDTO{CreateWalletDTO: CreateWalletDTO{Name: "wallet", Balance: 4}}},
+			want:    nil,
+			wantErr: errors.New("balance can not be less then amount held by active disputes"),
+		},
+		{
+			name:    "test reserved wallet",
+			fields:  fields{ID: 1, Balance: 10, Reserved: true},
+			args:    args{wallet: &UpdateWalletDTO{CreateWalletDTO: CreateWalletDTO{Name: "wallet", Balance: 4}}},
+			want:    nil,
+			wantErr: errors.New("wallet is reserved by the service"),
+		},
 		{
 			name:   "test OK set balance to zero",
 			fields: fields{ID: 1, Balance: 1},
@@ -63,9 +89,11 @@ func TestWallet_Update(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			w := &Wallet{
-				ID:      tt.fields.ID,
-				Name:    tt.fields.Name,
-				Balance: tt.fields.Balance,
+				ID:       tt.fields.ID,
+				Name:     tt.fields.Name,
+				Balance:  tt.fields.Balance,
+				Held:     tt.fields.Held,
+				Reserved: tt.fields.Reserved,
 			}
 			got, err := w.Update(tt.args.wallet, clk.Now())
 			if tt.wantErr != nil {
@@ -97,16 +125,22 @@ func Test_newWallet(t *testing.T) {
 			want:    nil,
 			wantErr: errors.New("balance can not be less then zero"),
 		},
+		{
+			name:    "test reserved name",
+			args:    args{&CreateWalletDTO{Balance: 0, Name: "__escrow__"}},
+			want:    nil,
+			wantErr: errors.New("wallet name is reserved"),
+		},
 		{
 			name:    "test ok",
 			args:    args{&CreateWalletDTO{Balance: 0, Name: "test name"}},
-			want:    &Wallet{Name: "test name", Balance: 0},
+			want:    &Wallet{Name: "test name", Balance: 0, Status: StatusFrozen},
 			wantErr: nil,
 		},
 		{
 			name:    "test ok with balance",
 			args:    args{&CreateWalletDTO{Balance: 1, Name: "test name"}},
-			want:    &Wallet{Name: "test name", Balance: 1, TransactionsToApply: []Transaction{{Amount: 1, Timestamp: clk.Now(), Type: TranTypeDeposit}}},
+			want:    &Wallet{Name: "test name", Balance: 1, Status: StatusFrozen, TransactionsToApply: []Transaction{{Amount: 1, Timestamp: clk.Now(), Type: TranTypeDeposit}}},
 			wantErr: nil,
 		},
 	}
@@ -125,3 +159,240 @@ func Test_newWallet(t *testing.T) {
 		})
 	}
 }
+
+func TestWallet_Close(t *testing.T) {
+	tests := []struct {
+		name    string
+		wallet  *Wallet
+		want    *Wallet
+		wantErr error
+	}{
+		{
+			name:    "test already closed",
+			wallet:  &Wallet{ID: 1, Status: StatusClosed},
+			want:    nil,
+			wantErr: errors.New("wallet is closed"),
+		},
+		{
+			name:    "test frozen",
+			wallet:  &Wallet{ID: 1, Status: StatusFrozen},
+			want:    nil,
+			wantErr: errors.New("wallet is frozen pending screening review"),
+		},
+		{
+			name:    "test with balance",
+			wallet:  &Wallet{ID: 1, Balance: 10, Status: StatusActive},
+			want:    nil,
+			wantErr: errors.New("only wallet with zero balance can be closed"),
+		},
+		{
+			name:    "test reserved",
+			wallet:  &Wallet{ID: 1, Status: StatusActive, Reserved: true},
+			want:    nil,
+			wantErr: errors.New("wallet is reserved by the service"),
+		},
+		{
+			name:    "test ok",
+			wallet:  &Wallet{ID: 1, Status: StatusActive},
+			want:    &Wallet{ID: 1, Status: StatusClosed},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.wallet.Close()
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("Wallet.Close() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Wallet.Close() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newStatement(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	from, to := clk.Now(), clk.Now().AddDate(0, 1, 0)
+	wallet := Wallet{ID: 1, Name: "wallet", Balance: 30}
+	deposit := Transaction{ID: 1, SenderID: 1, ReceiverID: 1, Amount: 10.1, Timestamp: from, Type: TranTypeDeposit}
+	sent := Transaction{ID: 2, SenderID: 1, ReceiverID: 2, Amount: 0.2, Timestamp: from.Add(time.Hour), Type: TranTypeTransfer}
+	received := Transaction{ID: 3, SenderID: 3, ReceiverID: 1, Amount: 5, Timestamp: from.Add(2 * time.Hour), Type: TranTypeTransfer, ParentPaymentID: 7}
+	withdraw := Transaction{ID: 4, SenderID: 1, ReceiverID: 1, Amount: 4.9, Timestamp: from.Add(3 * time.Hour), Type: TranTypeWithdraw}
+	reversal := Transaction{ID: 5, SenderID: 1, ReceiverID: 3, Amount: 5, Timestamp: from.Add(4 * time.Hour), Type: TranTypeReversal}
+	tests := []struct {
+		name           string
+		from, to       time.Time
+		openingBalance float64
+		transactions   []Transaction
+		want           *Statement
+		wantErr        error
+	}{
+		{
+			name:    "test period ends before it starts",
+			from:    to,
+			to:      from,
+			wantErr: ErrInvalidPeriod,
+		},
+		{
+			name:    "test empty period",
+			from:    from,
+			to:      from,
+			wantErr: ErrInvalidPeriod,
+		},
+		{
+			name:           "test no transactions",
+			from:           from,
+			to:             to,
+			openingBalance: 25,
+			want: &Statement{
+				Wallet: wallet, From: from, To: to, CreatedAt: clk.Now(), OpeningBalance: 25, ClosingBalance: 25, Lines: []StatementLine{},
+			},
+		},
+		{
+			name:           "test running balance",
+			from:           from,
+			to:             to,
+			openingBalance: 25,
+			transactions:   []Transaction{deposit, sent, received, withdraw, reversal},
+			want: &Statement{
+				Wallet: wallet, From: from, To: to, CreatedAt: clk.Now(), OpeningBalance: 25, ClosingBalance: 30, TotalIn: 15.1, TotalOut: 10.1,
+				Lines: []StatementLine{
+					{Transaction: deposit, Amount: 10.1, Balance: 35.1},
+					{Transaction: sent, CounterpartyID: 2, Amount: -0.2, Balance: 34.9},
+					{Transaction: received, CounterpartyID: 3, Amount: 5, Balance: 39.9},
+					{Transaction: withdraw, Amount: -4.9, Balance: 35},
+					{Transaction: reversal, CounterpartyID: 3, Amount: -5, Balance: 30},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newStatement(wallet, tt.from, tt.to, tt.openingBalance, tt.transactions, clk.Now())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("newStatement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newStatement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_withRunningBalance(t *testing.T) {
+	clk := clock.NewFake(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+	deposit := Transaction{ID: 4, SenderID: 1, ReceiverID: 1, Amount: 10.1, Timestamp: clk.Now(), Type: TranTypeDeposit}
+	sent := Transaction{ID: 6, SenderID: 1, ReceiverID: 2, Amount: 0.2, Timestamp: clk.Now(), Type: TranTypeTransfer}
+	received := Transaction{ID: 9, SenderID: 3, ReceiverID: 1, Amount: 5, Timestamp: clk.Now(), Type: TranTypeTransfer}
+	got := withRunningBalance(1, 2.5, []Transaction{deposit, sent, received})
+	want := []float64{12.6, 12.4, 17.4}
+	if len(got) != len(want) {
+		t.Fatalf("withRunningBalance() returned %d transactions, want %d", len(got), len(want))
+	}
+	for i, tran := range got {
+		if tran.Balance == nil || *tran.Balance != want[i] {
+			t.Errorf("withRunningBalance() transaction %d balance = %v, want %v", tran.ID, tran.Balance, want[i])
+		}
+	}
+}
+
+func Test_historyStarts(t *testing.T) {
+	riga, err := time.LoadLocation("Europe/Riga")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2022, 3, 25, 0, 0, 0, 0, riga)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		interval Interval
+		want     []time.Time
+		wantErr  error
+	}{
+		{
+			name:     "test unknown interval",
+			from:     from,
+			to:       from.AddDate(0, 0, 1),
+			interval: "hour",
+			wantErr:  ErrUnknownInterval,
+		},
+		{
+			name:     "test empty period",
+			from:     from,
+			to:       from,
+			interval: IntervalDay,
+			wantErr:  ErrInvalidPeriod,
+		},
+		{
+			name:     "test days over daylight saving change",
+			from:     from,
+			to:       time.Date(2022, 3, 28, 12, 0, 0, 0, riga),
+			interval: IntervalDay,
+			want: []time.Time{
+				from, time.Date(2022, 3, 26, 0, 0, 0, 0, riga), time.Date(2022, 3, 27, 0, 0, 0, 0, riga), time.Date(2022, 3, 28, 0, 0, 0, 0, riga),
+			},
+		},
+		{
+			name:     "test weeks",
+			from:     from,
+			to:       from.AddDate(0, 0, 14),
+			interval: IntervalWeek,
+			want:     []time.Time{from, from.AddDate(0, 0, 7)},
+		},
+		{
+			name:     "test months",
+			from:     time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:       time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC),
+			interval: IntervalMonth,
+			want: []time.Time{
+				time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "test too many points",
+			from:     from,
+			to:       from.AddDate(0, 0, MaxHistoryPoints+1),
+			interval: IntervalDay,
+			wantErr:  ErrTooManyPoints,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := historyStarts(tt.from, tt.to, tt.interval, tt.from.Location())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("historyStarts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("historyStarts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newBalanceHistory(t *testing.T) {
+	wallet := Wallet{ID: 1, Name: "wallet", Balance: 30}
+	day := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	starts := []time.Time{day, day.AddDate(0, 0, 1), day.AddDate(0, 0, 2)}
+	to := day.AddDate(0, 0, 2).Add(12 * time.Hour)
+	got, err := newBalanceHistory(wallet, starts, to, IntervalDay, 20, []float64{10.1, 0, -0.2})
+	if err != nil {
+		t.Fatalf("newBalanceHistory() error = %v", err)
+	}
+	want := &BalanceHistory{
+		Wallet: wallet, From: day, To: to, Interval: IntervalDay, OpeningBalance: 20, ClosingBalance: 29.9,
+		Points: []BalancePoint{
+			{From: starts[0], To: starts[1], Change: 10.1, Balance: 30.1},
+			{From: starts[1], To: starts[2], Change: 0, Balance: 30.1},
+			{From: starts[2], To: to, Change: -0.2, Balance: 29.9},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newBalanceHistory() = %v, want %v", got, want)
+	}
+	if _, err := newBalanceHistory(wallet, starts, to, IntervalDay, 20, []float64{1}); err == nil {
+		t.Errorf("newBalanceHistory() without change of every interval should fail")
+	}
+}
@@ -2,29 +2,63 @@ package wallet
 
 import (
 	"context"
+	"encoding/json"
+	"math"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/skwol/wallet/pkg/clock"
 	"github.com/skwol/wallet/pkg/logging"
+	"github.com/skwol/wallet/pkg/pagination"
+
+	"github.com/skwol/wallet/internal/domain/approval"
+	"github.com/skwol/wallet/internal/domain/risk"
+	"github.com/skwol/wallet/internal/domain/screening"
 )
 
 type Service interface {
 	Create(context.Context, *CreateWalletDTO) (DTO, error)
 	GetByID(context.Context, int64) (DTO, error)
-	GetByIDWithTransactions(context.Context, int64, int, int) (DTO, error)
+	// GetByIDWithTransactions and GetTransactionsPage set the running balance of every transaction when runningBalance is true.
+	GetByIDWithTransactions(ctx context.Context, id int64, limit, offset int, runningBalance bool) (DTO, error)
 	GetAll(ctx context.Context, limit int, offset int) ([]DTO, error)
+	GetPage(context.Context, pagination.Page) (PageDTO, error)
+	GetTransactionsPage(ctx context.Context, id int64, page pagination.Page, runningBalance bool) (TransactionsPageDTO, error)
+	// GetStatement returns the statement of the wallet for [from, to), Wallet.ID is 0 when there is no such wallet.
+	GetStatement(ctx context.Context, id int64, from, to time.Time) (StatementDTO, error)
+	// GetBalanceAt and GetBalanceHistory compute balances from the transactions, Wallet.ID is 0 when there is no such wallet.
+	// Balance is taken at the moment when at is zero.
+	GetBalanceAt(ctx context.Context, id int64, at time.Time) (BalanceDTO, error)
+	GetBalanceHistory(ctx context.Context, id int64, from, to time.Time, interval Interval, loc *time.Location) (BalanceHistoryDTO, error)
 	Update(context.Context, int64, *UpdateWalletDTO) (DTO, error)
+	Close(context.Context, int64) (DTO, error)
 }
 
 type service struct {
-	storage Storage
-	logger  logging.Logger
-	clk     clock.Clock
+	storage   Storage
+	approval  approval.Service
+	risk      risk.Service
+	screening screening.Service
+	logger    logging.Logger
+	clk       clock.Clock
+}
+
+// operationPayload is captured for approval of the operation on existing wallet.
+type operationPayload struct {
+	ID     int64            `json:"id"`
+	Update *UpdateWalletDTO `json:"update,omitempty"`
 }
 
-func NewService(storage Storage, logger logging.Logger, clk clock.Clock) (Service, error) {
-	return &service{storage: storage, logger: logger, clk: clk}, nil
+// NewService registers executors of wallet operations that wait for approval, services that are nil are
+// left out by the tools which only read wallets.
+func NewService(storage Storage, approvalService approval.Service, riskService risk.Service, screeningService screening.Service, logger logging.Logger, clk clock.Clock) (Service, error) {
+	s := &service{storage: storage, approval: approvalService, risk: riskService, screening: screeningService, logger: logger, clk: clk}
+	if approvalService != nil {
+		approvalService.RegisterExecutor(approval.OperationWalletUpdate, s.executeUpdate)
+		approvalService.RegisterExecutor(approval.OperationWalletClose, s.executeClose)
+	}
+	return s, nil
 }
 
 func (s *service) Create(ctx context.Context, dto *CreateWalletDTO) (DTO, error) {
@@ -47,7 +81,12 @@ func (s *service) Create(ctx context.Context, dto *CreateWalletDTO) (DTO, error)
 		s.logger.Errorf("wallet model was not created")
 		return result, errors.Wrap(err, "wallet model was not created")
 	}
-	result, err = s.storage.Create(ctx, walletModel.toDTO())
+	walletDTO := walletModel.toDTO()
+	// the initial deposit is evaluated for the wallet which has no id yet, storage sets it
+	if walletDTO.RiskDecisions, err = s.evaluate(ctx, walletDTO); err != nil {
+		return result, err
+	}
+	result, err = s.storage.Create(ctx, walletDTO)
 	if err != nil {
 		s.logger.Errorf("error creating wallet in db: %s", err.Error())
 		return result, errors.Wrap(err, "error creating wallet in db")
@@ -56,6 +95,16 @@ func (s *service) Create(ctx context.Context, dto *CreateWalletDTO) (DTO, error)
 		s.logger.Errorf("empty wallet returned from db")
 		return result, errors.Wrap(err, "empty wallet returned from db")
 	}
+	// wallet is created frozen and the clean screen unfreezes it, wallet left unscreened
+	// because of an error stays frozen until the next rescreen
+	matches, err := s.screening.ScreenWallet(ctx, screening.WalletDTO{ID: result.ID, Name: result.Name})
+	if err != nil {
+		s.logger.Errorf("error screening wallet: %s", err.Error())
+		return result, errors.Wrap(err, "error screening wallet")
+	}
+	if len(matches) == 0 {
+		result.Status = StatusActive
+	}
 	return result, nil
 }
 
@@ -63,14 +112,152 @@ func (s *service) GetByID(ctx context.Context, id int64) (DTO, error) {
 	return s.storage.GetByID(ctx, id)
 }
 
-func (s *service) GetByIDWithTransactions(ctx context.Context, id int64, limit, offset int) (DTO, error) {
-	return s.storage.GetByIDWithTransactions(ctx, id, limit, offset)
+func (s *service) GetByIDWithTransactions(ctx context.Context, id int64, limit, offset int, runningBalance bool) (DTO, error) {
+	wallet, err := s.storage.GetByIDWithTransactions(ctx, id, limit, offset)
+	if err != nil || wallet.ID == 0 || !runningBalance {
+		return wallet, err
+	}
+	if wallet.Transactions, err = s.withRunningBalance(ctx, id, wallet.Transactions); err != nil {
+		return DTO{}, err
+	}
+	return wallet, nil
+}
+
+func (s *service) withRunningBalance(ctx context.Context, id int64, transactions []TransactionDTO) ([]TransactionDTO, error) {
+	if len(transactions) == 0 {
+		return transactions, nil
+	}
+	openingBalance, err := s.storage.GetBalanceBefore(ctx, id, transactions[0].ID)
+	if err != nil {
+		s.logger.Errorf("error getting wallet balance from db: %s", err.Error())
+		return nil, errors.Wrap(err, "error getting wallet balance from db")
+	}
+	models := make([]Transaction, len(transactions))
+	for i, tran := range transactions {
+		models[i] = tran.toModel()
+	}
+	result := make([]TransactionDTO, len(transactions))
+	for i, tran := range withRunningBalance(id, openingBalance, models) {
+		result[i] = tran.toDTO()
+	}
+	return result, nil
 }
 
 func (s *service) GetAll(ctx context.Context, limit int, offset int) ([]DTO, error) {
 	return s.storage.GetAll(ctx, limit, offset)
 }
 
+func (s *service) GetPage(ctx context.Context, page pagination.Page) (PageDTO, error) {
+	wallets, err := s.storage.GetPage(ctx, page)
+	if err != nil {
+		return PageDTO{}, errors.Wrap(err, "error getting wallets")
+	}
+	ids := make([]int64, 0, len(wallets))
+	for _, w := range wallets {
+		ids = append(ids, w.ID)
+	}
+	start, end, result := page.Cut(ids)
+	if page.Total {
+		total, err := s.storage.Count(ctx)
+		if err != nil {
+			return PageDTO{}, errors.Wrap(err, "error counting wallets")
+		}
+		result.Total = &total
+	}
+	return PageDTO{Wallets: wallets[start:end], Result: result}, nil
+}
+
+func (s *service) GetTransactionsPage(ctx context.Context, id int64, page pagination.Page, runningBalance bool) (TransactionsPageDTO, error) {
+	wallet, err := s.storage.GetByID(ctx, id)
+	if err != nil || wallet.ID == 0 {
+		return TransactionsPageDTO{}, err
+	}
+	transactions, err := s.storage.GetTransactionsPage(ctx, id, page)
+	if err != nil {
+		return TransactionsPageDTO{}, errors.Wrap(err, "error getting wallet transactions")
+	}
+	ids := make([]int64, 0, len(transactions))
+	for _, t := range transactions {
+		ids = append(ids, t.ID)
+	}
+	start, end, result := page.Cut(ids)
+	if page.Total {
+		total, err := s.storage.CountTransactions(ctx, id)
+		if err != nil {
+			return TransactionsPageDTO{}, errors.Wrap(err, "error counting wallet transactions")
+		}
+		result.Total = &total
+	}
+	wallet.Transactions = transactions[start:end]
+	if runningBalance {
+		if wallet.Transactions, err = s.withRunningBalance(ctx, id, wallet.Transactions); err != nil {
+			return TransactionsPageDTO{}, err
+		}
+	}
+	return TransactionsPageDTO{Wallet: wallet, Result: result}, nil
+}
+
+func (s *service) GetStatement(ctx context.Context, id int64, from, to time.Time) (StatementDTO, error) {
+	if !from.Before(to) {
+		return StatementDTO{}, ErrInvalidPeriod
+	}
+	wallet, err := s.storage.GetByID(ctx, id)
+	if err != nil || wallet.ID == 0 {
+		return StatementDTO{}, err
+	}
+	openingBalance, transactions, err := s.storage.GetStatement(ctx, id, from, to)
+	if err != nil {
+		s.logger.Errorf("error getting wallet statement from db: %s", err.Error())
+		return StatementDTO{}, errors.Wrap(err, "error getting wallet statement from db")
+	}
+	models := make([]Transaction, len(transactions))
+	for i, tran := range transactions {
+		models[i] = tran.toModel()
+	}
+	statement, err := newStatement(wallet.toModel(), from, to, openingBalance, models, s.clk.Now())
+	if err != nil {
+		return StatementDTO{}, errors.Wrap(err, "error creating statement model")
+	}
+	return statement.toDTO(), nil
+}
+
+func (s *service) GetBalanceAt(ctx context.Context, id int64, at time.Time) (BalanceDTO, error) {
+	wallet, err := s.storage.GetByID(ctx, id)
+	if err != nil || wallet.ID == 0 {
+		return BalanceDTO{}, err
+	}
+	if at.IsZero() {
+		at = s.clk.Now()
+	}
+	balance, err := s.storage.GetBalanceAt(ctx, id, at)
+	if err != nil {
+		s.logger.Errorf("error getting wallet balance from db: %s", err.Error())
+		return BalanceDTO{}, errors.Wrap(err, "error getting wallet balance from db")
+	}
+	return BalanceDTO{Wallet: wallet, At: at, Balance: roundAmount(balance)}, nil
+}
+
+func (s *service) GetBalanceHistory(ctx context.Context, id int64, from, to time.Time, interval Interval, loc *time.Location) (BalanceHistoryDTO, error) {
+	starts, err := historyStarts(from, to, interval, loc)
+	if err != nil {
+		return BalanceHistoryDTO{}, err
+	}
+	wallet, err := s.storage.GetByID(ctx, id)
+	if err != nil || wallet.ID == 0 {
+		return BalanceHistoryDTO{}, err
+	}
+	openingBalance, changes, err := s.storage.GetBalanceHistory(ctx, id, starts, to)
+	if err != nil {
+		s.logger.Errorf("error getting wallet balance history from db: %s", err.Error())
+		return BalanceHistoryDTO{}, errors.Wrap(err, "error getting wallet balance history from db")
+	}
+	history, err := newBalanceHistory(wallet.toModel(), starts, to, interval, openingBalance, changes)
+	if err != nil {
+		return BalanceHistoryDTO{}, errors.Wrap(err, "error creating balance history model")
+	}
+	return history.toDTO(), nil
+}
+
 func (s *service) Update(ctx context.Context, id int64, walletDTO *UpdateWalletDTO) (DTO, error) {
 	var result DTO
 
@@ -83,17 +270,93 @@ func (s *service) Update(ctx context.Context, id int64, walletDTO *UpdateWalletD
 		s.logger.Errorf("missing wallet in db")
 		return result, errors.New("missing wallet db")
 	}
+	// approval is granted for the adjustment of the balance the update is requested against
+	if walletDTO.ExpectedBalance == nil {
+		walletDTO.ExpectedBalance = &walletInDB.Balance
+	}
 	walletModel := walletInDB.toModel()
 	wallet, err := walletModel.Update(walletDTO, s.clk.Now())
 	if err != nil {
 		s.logger.Errorf("error updating wallet model: %s", err.Error())
 		return result, errors.Wrap(err, "error updating wallet model")
 	}
+	adjustment := math.Abs(walletDTO.Balance - walletInDB.Balance)
+	if err := s.approval.Gate(ctx, approval.OperationWalletUpdate, adjustment, operationPayload{ID: id, Update: walletDTO}); err != nil {
+		return result, err
+	}
+	result = wallet.toDTO()
+	if result.RiskDecisions, err = s.evaluate(ctx, result); err != nil {
+		return result, err
+	}
+	if err := s.storage.Update(ctx, result, walletInDB); err != nil {
+		s.logger.Errorf("error updating wallet in db: %s", err.Error())
+		return result, errors.Wrap(err, "error updating wallet in db")
+	}
+	return result, nil
+}
+
+// evaluate returns the risk decisions on the deposits and withdrawals of the wallet.
+func (s *service) evaluate(ctx context.Context, wallet DTO) ([]*risk.DecisionDTO, error) {
+	decisions := make([]*risk.DecisionDTO, 0, len(wallet.TransactionsToApply))
+	for _, tran := range wallet.TransactionsToApply {
+		movement := &risk.MovementDTO{Kind: risk.Kind(tran.Type), WalletID: wallet.ID, Amount: tran.Amount}
+		decision, err := s.risk.Evaluate(ctx, movement)
+		if err != nil {
+			s.logger.Errorf("error evaluating %s risk: %s", tran.Type, err.Error())
+			return nil, errors.Wrapf(err, "error evaluating %s risk", tran.Type)
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, nil
+}
+
+func (s *service) Close(ctx context.Context, id int64) (DTO, error) {
+	var result DTO
+
+	walletInDB, err := s.storage.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Errorf("error getting wallet from db: %s", err.Error())
+		return result, errors.Wrap(err, "error getting wallet from db")
+	}
+	if walletInDB.ID == 0 {
+		s.logger.Errorf("missing wallet in db")
+		return result, errors.New("missing wallet db")
+	}
+	walletModel := walletInDB.toModel()
+	wallet, err := walletModel.Close()
+	if err != nil {
+		s.logger.Errorf("error closing wallet model: %s", err.Error())
+		return result, errors.Wrap(err, "error closing wallet model")
+	}
+	if err := s.approval.Gate(ctx, approval.OperationWalletClose, 0, operationPayload{ID: id}); err != nil {
+		return result, err
+	}
 
 	result = wallet.toDTO()
-	if err := s.storage.Update(ctx, result); err != nil {
+	if err := s.storage.Update(ctx, result, walletInDB); err != nil {
 		s.logger.Errorf("error updating wallet in db: %s", err.Error())
 		return result, errors.Wrap(err, "error updating wallet in db")
 	}
 	return result, nil
 }
+
+func (s *service) executeUpdate(ctx context.Context, payload []byte) error {
+	var operation operationPayload
+	if err := json.Unmarshal(payload, &operation); err != nil {
+		return errors.Wrap(err, "error unmarshaling wallet update")
+	}
+	if operation.Update == nil || operation.Update.ExpectedBalance == nil {
+		return errors.New("missing wallet update in payload")
+	}
+	_, err := s.Update(ctx, operation.ID, operation.Update)
+	return err
+}
+
+func (s *service) executeClose(ctx context.Context, payload []byte) error {
+	var operation operationPayload
+	if err := json.Unmarshal(payload, &operation); err != nil {
+		return errors.Wrap(err, "error unmarshaling wallet closure")
+	}
+	_, err := s.Close(ctx, operation.ID)
+	return err
+}
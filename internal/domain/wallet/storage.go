@@ -1,6 +1,11 @@
 package wallet
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/skwol/wallet/pkg/pagination"
+)
 
 type Storage interface {
 	Create(context.Context, DTO) (DTO, error)
@@ -8,5 +13,23 @@ type Storage interface {
 	GetByIDWithTransactions(context.Context, int64, int, int) (DTO, error)
 	GetByName(context.Context, string) (DTO, error)
 	GetAll(context.Context, int, int) ([]DTO, error)
-	Update(context.Context, DTO) error
+	// GetPage and GetTransactionsPage return up to page.Fetch() records in id ascending order.
+	GetPage(context.Context, pagination.Page) ([]DTO, error)
+	Count(context.Context) (int64, error)
+	GetTransactionsPage(ctx context.Context, walletID int64, page pagination.Page) ([]TransactionDTO, error)
+	CountTransactions(ctx context.Context, walletID int64) (int64, error)
+	// GetStatement returns the balance of the wallet before from and its transactions within [from, to)
+	// in date order, both are read from the same snapshot, so they add up.
+	GetStatement(ctx context.Context, walletID int64, from, to time.Time) (float64, []TransactionDTO, error)
+	// GetBalanceBefore returns the balance of the wallet made by its transactions with id lower than transactionID.
+	GetBalanceBefore(ctx context.Context, walletID, transactionID int64) (float64, error)
+	// GetBalanceAt returns the balance of the wallet made by its transactions before at,
+	// it starts from the latest balance snapshot of a day which has ended by then.
+	GetBalanceAt(ctx context.Context, walletID int64, at time.Time) (float64, error)
+	// GetBalanceHistory returns the balance of the wallet at starts[0] and the sum of its movements within every interval
+	// from a start to the next one, the last interval ends at to. Both are read from the same snapshot.
+	GetBalanceHistory(ctx context.Context, walletID int64, starts []time.Time, to time.Time) (float64, []float64, error)
+	// Update moves the balance by the difference between dto and prev, it fails with ErrConcurrentUpdate
+	// when the balance is no longer the one of prev.
+	Update(ctx context.Context, dto DTO, prev DTO) error
 }
@@ -0,0 +1,17 @@
+// Package actor carries identity of the authenticated caller through the context.
+package actor
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries name of the authenticated caller.
+func NewContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// FromContext returns name of the authenticated caller, empty name means the caller is anonymous.
+func FromContext(ctx context.Context) string {
+	name, _ := ctx.Value(contextKey{}).(string)
+	return name
+}
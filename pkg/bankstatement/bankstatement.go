@@ -0,0 +1,97 @@
+package bankstatement
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCurrency is the currency of statements when nothing else is configured.
+const DefaultCurrency = "EUR"
+
+var (
+	ErrInvalidCurrency = errors.New("currency should be ISO 4217 code of 3 capital letters")
+	ErrInvalidPeriod   = errors.New("statement period should end after it starts")
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidateCurrency checks the code has the form of ISO 4217 code, it is not checked against the list of currencies.
+func ValidateCurrency(code string) error {
+	if !currencyPattern.MatchString(code) {
+		return errors.Wrapf(ErrInvalidCurrency, "%q", code)
+	}
+	return nil
+}
+
+type Kind int
+
+const (
+	// KindTransfer moves money between two accounts.
+	KindTransfer Kind = iota
+	// KindCash is a deposit or withdrawal, it has no counterparty.
+	KindCash
+)
+
+// Statement is an account statement for the period [From, To). Times are written in their own location,
+// so the caller puts them in the time zone of the statement.
+type Statement struct {
+	// ID identifies the statement, it is used as the message and statement reference
+	ID             string
+	Account        string
+	AccountName    string
+	Currency       string
+	Created        time.Time
+	From           time.Time
+	To             time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	Entries        []Entry
+}
+
+// Entry is a booked movement of the account, Amount is negative for debits.
+type Entry struct {
+	// Reference is the reference of the account servicer, unique within the account
+	Reference string
+	// ExternalReference is the reference of the account owner, empty when there is none
+	ExternalReference string
+	Amount            float64
+	Booked            time.Time
+	Kind              Kind
+	// Reversal is set for entries which reverse an earlier entry of the opposite direction
+	Reversal bool
+	// Counterparty is the other account of a transfer
+	Counterparty string
+	// Info is free text shown next to the entry
+	Info string
+}
+
+func (s Statement) validate() error {
+	if err := ValidateCurrency(s.Currency); err != nil {
+		return err
+	}
+	if !s.From.Before(s.To) {
+		return ErrInvalidPeriod
+	}
+	return nil
+}
+
+// lastDay is the date of the closing balance, To itself is not part of the period.
+func (s Statement) lastDay() time.Time {
+	return s.To.Add(-time.Nanosecond)
+}
+
+// totals returns the number and sums of credit and debit entries.
+func (s Statement) totals() (credits, debits int, creditSum, debitSum float64) {
+	for _, entry := range s.Entries {
+		if entry.Amount >= 0 {
+			credits++
+			creditSum += entry.Amount
+		} else {
+			debits++
+			debitSum -= entry.Amount
+		}
+	}
+	return credits, debits, creditSum, debitSum
+}
@@ -0,0 +1,267 @@
+package bankstatement
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	camtDateLayout     = "2006-01-02"
+	camtDateTimeLayout = time.RFC3339
+	// camtNotProvided is the reference of the account owner when there is none, as the usage rules ask
+	camtNotProvided = "NOTPROVIDED"
+)
+
+// camt.053.001.02 elements, only the ones which are written. Fields are in the order of the schema sequences.
+type camtDocument struct {
+	XMLName   xml.Name      `xml:"urn:iso:std:iso:20022:tech:xsd:camt.053.001.02 Document"`
+	Statement camtBkToCstmr `xml:"BkToCstmrStmt"`
+}
+
+type camtBkToCstmr struct {
+	GroupHeader camtGroupHeader `xml:"GrpHdr"`
+	Statement   camtStatement   `xml:"Stmt"`
+}
+
+type camtGroupHeader struct {
+	MessageID string `xml:"MsgId"`
+	Created   string `xml:"CreDtTm"`
+}
+
+type camtStatement struct {
+	ID       string        `xml:"Id"`
+	Created  string        `xml:"CreDtTm"`
+	Period   camtPeriod    `xml:"FrToDt"`
+	Account  camtAccount   `xml:"Acct"`
+	Balances []camtBalance `xml:"Bal"`
+	Summary  camtSummary   `xml:"TxsSummry"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtPeriod struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	ID       camtAccountID `xml:"Id"`
+	Currency string        `xml:"Ccy,omitempty"`
+	Name     string        `xml:"Nm,omitempty"`
+}
+
+type camtAccountID struct {
+	Other struct {
+		ID string `xml:"Id"`
+	} `xml:"Othr"`
+}
+
+type camtBalance struct {
+	Type struct {
+		Code struct {
+			Code string `xml:"Cd"`
+		} `xml:"CdOrPrtry"`
+	} `xml:"Tp"`
+	Amount      camtAmount `xml:"Amt"`
+	CreditDebit string     `xml:"CdtDbtInd"`
+	Date        camtDate   `xml:"Dt"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt,omitempty"`
+	DateTime string `xml:"DtTm,omitempty"`
+}
+
+type camtSummary struct {
+	Total   camtTotal    `xml:"TtlNtries"`
+	Credits camtSubtotal `xml:"TtlCdtNtries"`
+	Debits  camtSubtotal `xml:"TtlDbtNtries"`
+}
+
+type camtTotal struct {
+	Count       string `xml:"NbOfNtries"`
+	Sum         string `xml:"Sum"`
+	Net         string `xml:"TtlNetNtryAmt"`
+	CreditDebit string `xml:"CdtDbtInd"`
+}
+
+type camtSubtotal struct {
+	Count string `xml:"NbOfNtries"`
+	Sum   string `xml:"Sum"`
+}
+
+type camtEntry struct {
+	Reference       string           `xml:"NtryRef"`
+	Amount          camtAmount       `xml:"Amt"`
+	CreditDebit     string           `xml:"CdtDbtInd"`
+	Reversal        bool             `xml:"RvslInd,omitempty"`
+	Status          string           `xml:"Sts"`
+	Booked          camtDate         `xml:"BookgDt"`
+	Value           camtDate         `xml:"ValDt"`
+	ServicerRef     string           `xml:"AcctSvcrRef"`
+	TransactionCode camtBankCode     `xml:"BkTxCd"`
+	Details         camtEntryDetails `xml:"NtryDtls"`
+	Info            string           `xml:"AddtlNtryInf,omitempty"`
+}
+
+type camtBankCode struct {
+	Domain struct {
+		Code   string `xml:"Cd"`
+		Family struct {
+			Code      string `xml:"Cd"`
+			SubFamily string `xml:"SubFmlyCd"`
+		} `xml:"Fmly"`
+	} `xml:"Domn"`
+}
+
+type camtEntryDetails struct {
+	Transaction struct {
+		References struct {
+			ServicerRef string `xml:"AcctSvcrRef"`
+			EndToEndID  string `xml:"EndToEndId"`
+		} `xml:"Refs"`
+		Parties *camtParties `xml:"RltdPties,omitempty"`
+	} `xml:"TxDtls"`
+}
+
+type camtParties struct {
+	DebtorAccount   *camtAccount `xml:"DbtrAcct,omitempty"`
+	CreditorAccount *camtAccount `xml:"CdtrAcct,omitempty"`
+}
+
+// WriteCAMT053 writes the statement as ISO 20022 BankToCustomerStatement message camt.053.001.02.
+func WriteCAMT053(w io.Writer, s Statement) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	credits, debits, creditSum, debitSum := s.totals()
+	net := creditSum - debitSum
+	statement := camtStatement{
+		ID:      maxText(s.ID, 35),
+		Created: s.Created.Format(camtDateTimeLayout),
+		Period:  camtPeriod{From: s.From.Format(camtDateTimeLayout), To: s.lastDay().Format(camtDateTimeLayout)},
+		Account: newCAMTAccount(s.Account, s.Currency, s.AccountName),
+		Balances: []camtBalance{
+			newCAMTBalance("OPBD", s.OpeningBalance, s.Currency, s.From),
+			newCAMTBalance("CLBD", s.ClosingBalance, s.Currency, s.lastDay()),
+		},
+		Summary: camtSummary{
+			Total: camtTotal{
+				Count:       strconv.Itoa(credits + debits),
+				Sum:         formatAmount(creditSum + debitSum),
+				Net:         formatAmount(math.Abs(net)),
+				CreditDebit: creditDebit(net),
+			},
+			Credits: camtSubtotal{Count: strconv.Itoa(credits), Sum: formatAmount(creditSum)},
+			Debits:  camtSubtotal{Count: strconv.Itoa(debits), Sum: formatAmount(debitSum)},
+		},
+		Entries: make([]camtEntry, 0, len(s.Entries)),
+	}
+	for _, entry := range s.Entries {
+		statement.Entries = append(statement.Entries, newCAMTEntry(entry, s.Currency))
+	}
+	document := camtDocument{Statement: camtBkToCstmr{
+		GroupHeader: camtGroupHeader{MessageID: maxText(s.ID, 35), Created: s.Created.Format(camtDateTimeLayout)},
+		Statement:   statement,
+	}}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return errors.Wrap(err, "error encoding camt.053 document")
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newCAMTAccount(id, currency, name string) camtAccount {
+	account := camtAccount{Currency: currency, Name: maxText(name, 70)}
+	account.ID.Other.ID = maxText(id, 34)
+	return account
+}
+
+func newCAMTBalance(code string, amount float64, currency string, date time.Time) camtBalance {
+	balance := camtBalance{
+		Amount:      camtAmount{Currency: currency, Value: formatAmount(math.Abs(amount))},
+		CreditDebit: creditDebit(amount),
+		Date:        camtDate{Date: date.Format(camtDateLayout)},
+	}
+	balance.Type.Code.Code = code
+	return balance
+}
+
+func newCAMTEntry(e Entry, currency string) camtEntry {
+	entry := camtEntry{
+		Reference:   maxText(e.Reference, 35),
+		Amount:      camtAmount{Currency: currency, Value: formatAmount(math.Abs(e.Amount))},
+		CreditDebit: creditDebit(e.Amount),
+		Reversal:    e.Reversal,
+		Status:      "BOOK",
+		Booked:      camtDate{DateTime: e.Booked.Format(camtDateTimeLayout)},
+		Value:       camtDate{Date: e.Booked.Format(camtDateLayout)},
+		ServicerRef: maxText(e.Reference, 35),
+		Info:        maxText(e.Info, 500),
+	}
+	// bank transaction codes of the ISO external code list, cash is a counter transaction
+	domain := &entry.TransactionCode.Domain
+	domain.Code = "PMNT"
+	switch {
+	case e.Kind == KindCash && e.Amount >= 0:
+		domain.Family.Code, domain.Family.SubFamily = "CNTR", "CDPT"
+	case e.Kind == KindCash:
+		domain.Family.Code, domain.Family.SubFamily = "CNTR", "CWDL"
+	case e.Amount >= 0:
+		domain.Family.Code, domain.Family.SubFamily = "RCDT", "DMCT"
+	default:
+		domain.Family.Code, domain.Family.SubFamily = "ICDT", "DMCT"
+	}
+
+	details := &entry.Details.Transaction
+	details.References.ServicerRef = maxText(e.Reference, 35)
+	details.References.EndToEndID = camtNotProvided
+	if e.ExternalReference != "" {
+		details.References.EndToEndID = maxText(e.ExternalReference, 35)
+	}
+	if e.Counterparty != "" {
+		counterparty := newCAMTAccount(e.Counterparty, "", "")
+		if e.Amount >= 0 {
+			details.Parties = &camtParties{DebtorAccount: &counterparty}
+		} else {
+			details.Parties = &camtParties{CreditorAccount: &counterparty}
+		}
+	}
+	return entry
+}
+
+func creditDebit(amount float64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// formatAmount writes the amount with a dot and without trailing zeros, amounts are kept with 4 decimals.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(math.Round(amount*1e4)/1e4, 'f', -1, 64)
+}
+
+// maxText cuts text to the max length of the element in characters.
+func maxText(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length])
+}
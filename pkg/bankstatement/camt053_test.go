@@ -0,0 +1,125 @@
+package bankstatement
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func testStatement() Statement {
+	from := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	return Statement{
+		ID:             "STMT-501-20220501",
+		Account:        "501",
+		AccountName:    "Jörg & Co <main>",
+		Currency:       "EUR",
+		Created:        from.AddDate(0, 1, 0).Add(time.Hour),
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 100,
+		ClosingBalance: 104.875,
+		Entries: []Entry{
+			{Reference: "1", Amount: 10.125, Booked: from.Add(time.Hour), Kind: KindCash, Info: "deposit"},
+			{Reference: "2", ExternalReference: "PAYMENT-7", Amount: -0.25, Booked: from.Add(26 * time.Hour), Counterparty: "502", Info: "transfer to 502"},
+			{Reference: "3", Amount: 5, Booked: from.Add(50 * time.Hour), Counterparty: "503", Info: "transfer from 503"},
+			{Reference: "4", Amount: -5, Booked: from.Add(74 * time.Hour), Reversal: true, Counterparty: "503", Info: "reversal of 3"},
+			{Reference: "5", Amount: -5, Booked: from.AddDate(0, 1, 0).Add(-time.Minute), Kind: KindCash, Info: "withdraw"},
+		},
+	}
+}
+
+func TestWriteCAMT053(t *testing.T) {
+	empty := testStatement()
+	empty.Entries, empty.ClosingBalance = nil, empty.OpeningBalance
+	negative := testStatement()
+	negative.OpeningBalance, negative.ClosingBalance = -5, -0.125
+	tests := []struct {
+		name         string
+		statement    Statement
+		wantContains []string
+		wantErr      error
+	}{
+		{
+			name:      "test statement",
+			statement: testStatement(),
+			wantContains: []string{
+				`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">`,
+				"<Nm>Jörg &amp; Co &lt;main&gt;</Nm>",
+				"<Cd>OPBD</Cd>",
+				`<Amt Ccy="EUR">100</Amt>`,
+				"<Dt>2022-05-01</Dt>",
+				"<Cd>CLBD</Cd>",
+				`<Amt Ccy="EUR">104.875</Amt>`,
+				"<Dt>2022-05-31</Dt>",
+				"<TtlNetNtryAmt>4.875</TtlNetNtryAmt>",
+				"<RvslInd>true</RvslInd>",
+				"<SubFmlyCd>CDPT</SubFmlyCd>",
+				"<SubFmlyCd>CWDL</SubFmlyCd>",
+				"<EndToEndId>PAYMENT-7</EndToEndId>",
+				"<EndToEndId>NOTPROVIDED</EndToEndId>",
+			},
+		},
+		{
+			name:         "test without entries",
+			statement:    empty,
+			wantContains: []string{"<NbOfNtries>0</NbOfNtries>"},
+		},
+		{
+			name:         "test negative balances",
+			statement:    negative,
+			wantContains: []string{`<Amt Ccy="EUR">5</Amt>`, "<CdtDbtInd>DBIT</CdtDbtInd>"},
+		},
+		{
+			name:      "test invalid currency",
+			statement: Statement{Currency: "eur", From: time.Unix(0, 0), To: time.Unix(1, 0)},
+			wantErr:   ErrInvalidCurrency,
+		},
+		{
+			name:      "test invalid period",
+			statement: Statement{Currency: "EUR", From: time.Unix(1, 0), To: time.Unix(1, 0)},
+			wantErr:   ErrInvalidPeriod,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := WriteCAMT053(&out, tt.statement)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteCAMT053() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("WriteCAMT053() does not contain %s", want)
+				}
+			}
+			validateSchema(t, out.Bytes())
+		})
+	}
+}
+
+// validateSchema validates the document against the camt.053 schema with xmllint,
+// the test is skipped where xmllint is not installed.
+func validateSchema(t *testing.T, document []byte) {
+	t.Helper()
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint is not installed")
+	}
+	file := filepath.Join(t.TempDir(), "statement.xml")
+	if err := os.WriteFile(file, document, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output, err := exec.Command(xmllint, "--noout", "--schema", "testdata/camt.053.001.02.xsd", file).CombinedOutput()
+	if err != nil {
+		t.Errorf("document is not valid: %s\n%s", output, document)
+	}
+}
@@ -0,0 +1,137 @@
+package bankstatement
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	mt940DateLayout  = "060102"
+	mt940EntryLayout = "0102"
+	// mt940NoReference is the reference of the account owner when there is none
+	mt940NoReference = "NONREF"
+	mt940InfoLines   = 6
+	mt940LineLength  = 65
+)
+
+// WriteMT940 writes the statement as the text block of SWIFT MT940 message, the way statements are downloaded
+// from banks: fields on their own lines ended with CRLF and the message ended with a line of '-'.
+// Statements are made on request, so they all have the number 1 and fit in a single message.
+func WriteMT940(w io.Writer, s Statement) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	field := func(tag, value string) {
+		out.WriteString(":" + tag + ":" + value + "\r\n")
+	}
+	field("20", swiftReference(s.ID))
+	field("25", swiftText(s.Account, 35))
+	field("28C", "1")
+	field("60F", mt940Balance(s.OpeningBalance, s.From.Format(mt940DateLayout), s.Currency))
+	for _, entry := range s.Entries {
+		field("61", mt940Entry(entry))
+		if info := mt940Info(entry.Info); info != "" {
+			field("86", info)
+		}
+	}
+	field("62F", mt940Balance(s.ClosingBalance, s.lastDay().Format(mt940DateLayout), s.Currency))
+	out.WriteString("-\r\n")
+	return out.Flush()
+}
+
+// mt940Balance is the balance of 60F and 62F fields, e.g. C220501EUR100,5
+func mt940Balance(amount float64, date, currency string) string {
+	mark := "C"
+	if amount < 0 {
+		mark = "D"
+	}
+	return mark + date + currency + mt940Amount(math.Abs(amount))
+}
+
+// mt940Entry is the statement line of 61 field: value date, entry date, mark, amount, transaction type,
+// the reference of the account owner and the reference of the account servicer after '//'.
+func mt940Entry(e Entry) string {
+	// reversal marks tell which direction is reversed, so a credit reverses a debit
+	var mark string
+	switch {
+	case e.Reversal && e.Amount >= 0:
+		mark = "RD"
+	case e.Reversal:
+		mark = "RC"
+	case e.Amount >= 0:
+		mark = "C"
+	default:
+		mark = "D"
+	}
+	code := "NTRF"
+	if e.Kind == KindCash {
+		code = "NMSC"
+	}
+	reference := mt940NoReference
+	if e.ExternalReference != "" {
+		reference = swiftReference(e.ExternalReference)
+	}
+	return e.Booked.Format(mt940DateLayout) + e.Booked.Format(mt940EntryLayout) + mark + mt940Amount(math.Abs(e.Amount)) +
+		code + reference + "//" + swiftReference(e.Reference)
+}
+
+// mt940Info splits the text into the lines of 86 field.
+func mt940Info(text string) string {
+	text = swiftText(text, mt940InfoLines*mt940LineLength)
+	var lines []string
+	for len(text) > 0 && len(lines) < mt940InfoLines {
+		line := text
+		if len(line) > mt940LineLength {
+			line = line[:mt940LineLength]
+		}
+		text = text[len(line):]
+		// a line starting with ':' or '-' would be read as the next field or the end of the message
+		if line[0] == ':' || line[0] == '-' {
+			line = "." + line[1:]
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// mt940Amount uses a comma as the decimal separator, the comma is there even without decimals.
+func mt940Amount(amount float64) string {
+	value := strings.Replace(formatAmount(amount), ".", ",", 1)
+	if !strings.Contains(value, ",") {
+		value += ","
+	}
+	return value
+}
+
+// swiftText keeps the characters of SWIFT x character set, the rest are replaced with '.'.
+func swiftText(text string, length int) string {
+	var b strings.Builder
+	for _, r := range text {
+		if b.Len() == length {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("/-?:().,'+ ", r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
+
+// swiftReference is a 16x reference, which neither starts nor ends with '/' nor has '//' in it.
+func swiftReference(text string) string {
+	reference := swiftText(text, 16)
+	for strings.Contains(reference, "//") {
+		reference = strings.ReplaceAll(reference, "//", "/")
+	}
+	reference = strings.Trim(reference, "/")
+	if reference == "" {
+		return mt940NoReference
+	}
+	return reference
+}
@@ -0,0 +1,79 @@
+package bankstatement
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestWriteMT940(t *testing.T) {
+	unsafe := testStatement()
+	unsafe.ID = "//STMT_501//2022//"
+	unsafe.Entries = []Entry{{Reference: "/", Amount: 1, Booked: unsafe.From, Info: ":61:" + strings.Repeat("a", 70) + "\n-é"}}
+	tests := []struct {
+		name      string
+		statement Statement
+		want      []string
+		wantErr   error
+	}{
+		{
+			name:      "test statement",
+			statement: testStatement(),
+			want: []string{
+				":20:STMT-501-2022050",
+				":25:501",
+				":28C:1",
+				":60F:C220501EUR100,",
+				":61:2205010501C10,125NMSCNONREF//1",
+				":86:deposit",
+				":61:2205020502D0,25NTRFPAYMENT-7//2",
+				":86:transfer to 502",
+				":61:2205030503C5,NTRFNONREF//3",
+				":86:transfer from 503",
+				":61:2205040504RC5,NTRFNONREF//4",
+				":86:reversal of 3",
+				":61:2205310531D5,NMSCNONREF//5",
+				":86:withdraw",
+				":62F:C220531EUR104,875",
+				"-",
+			},
+		},
+		{
+			name:      "test references and info are made safe",
+			statement: unsafe,
+			want: []string{
+				":20:STMT.501/2022",
+				":25:501",
+				":28C:1",
+				":60F:C220501EUR100,",
+				":61:2205010501C1,NTRFNONREF//NONREF",
+				":86:.61:" + strings.Repeat("a", 61),
+				strings.Repeat("a", 9) + ".-.",
+				":62F:C220531EUR104,875",
+				"-",
+			},
+		},
+		{
+			name:      "test invalid currency",
+			statement: Statement{Currency: "EURO"},
+			wantErr:   ErrInvalidCurrency,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := WriteMT940(&out, tt.statement)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteMT940() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want := strings.Join(tt.want, "\r\n") + "\r\n"; out.String() != want {
+				t.Errorf("WriteMT940() =\n%s\nwant\n%s", out.String(), want)
+			}
+		})
+	}
+}
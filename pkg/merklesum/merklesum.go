@@ -0,0 +1,231 @@
+// Package merklesum builds Merkle sum trees, where every node commits to the sum of the leaves below it,
+// and verifies inclusion proofs of leaves against the root.
+package merklesum
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Scale is how many units of a sum make one, sums keep the four decimals of amounts.
+const Scale = 10000
+
+// prefixes keep leaves, parents and padding apart, so that one can not be passed off as another
+const (
+	leafPrefix   byte = 0
+	parentPrefix byte = 1
+	emptyPrefix  byte = 2
+)
+
+var (
+	ErrRootMismatch = errors.New("proof does not lead to the root")
+	ErrInvalidSum   = errors.New("proof has a negative or overflowing sum")
+	ErrInvalidProof = errors.New("proof is too long")
+)
+
+// maxDepth is enough for any tree which fits into int64 positions.
+const maxDepth = 63
+
+// Hash is hex encoded in json.
+type Hash []byte
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h)
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	if err != nil {
+		return errors.Wrap(err, "error decoding hash")
+	}
+	*h = decoded
+	return nil
+}
+
+// Sum is in units of 1/Scale, it is a decimal string with four decimals in json.
+type Sum int64
+
+func (s Sum) String() string {
+	sign := ""
+	value := int64(s)
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	return sign + strconv.FormatInt(value/Scale, 10) + "." + strconv.FormatInt(Scale+value%Scale, 10)[1:]
+}
+
+// NewSum rounds the amount to the units of a sum.
+func NewSum(amount float64) Sum {
+	return Sum(math.Round(amount * Scale))
+}
+
+// ParseSum reads the decimal exactly, it has at most four decimals.
+func ParseSum(value string) (Sum, error) {
+	whole, fraction := value, ""
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		whole, fraction = value[:i], value[i+1:]
+	}
+	if len(fraction) > 4 || strings.ContainsAny(fraction, "+-") {
+		return 0, errors.Errorf("invalid sum %q", value)
+	}
+	units, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", 4-len(fraction)), 10, 64)
+	if err != nil || whole == "" || whole == "-" || whole == "+" {
+		return 0, errors.Errorf("invalid sum %q", value)
+	}
+	return Sum(units), nil
+}
+
+func (s Sum) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Sum) UnmarshalText(text []byte) error {
+	sum, err := ParseSum(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sum
+	return nil
+}
+
+type Node struct {
+	Hash Hash `json:"hash"`
+	Sum  Sum  `json:"sum"`
+}
+
+// Empty pads levels with an odd number of nodes.
+var Empty = Node{Hash: hash([]byte{emptyPrefix}), Sum: 0}
+
+func hash(parts ...[]byte) Hash {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+func uint64Bytes(v int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	return b
+}
+
+// Leaf commits to the id and the sum of the leaf, the salt keeps the sums of neighbours in a proof from revealing whose they are.
+func Leaf(id int64, sum Sum, salt []byte) Node {
+	return Node{Hash: hash([]byte{leafPrefix}, uint64Bytes(id), uint64Bytes(int64(sum)), salt), Sum: sum}
+}
+
+// Parent commits to both children and their sums, so that a sum can not be moved between them.
+func Parent(left, right Node) (Node, error) {
+	if left.Sum < 0 || right.Sum < 0 || left.Sum > math.MaxInt64-right.Sum {
+		return Node{}, ErrInvalidSum
+	}
+	return Node{
+		Hash: hash([]byte{parentPrefix}, left.Hash, uint64Bytes(int64(left.Sum)), right.Hash, uint64Bytes(int64(right.Sum))),
+		Sum:  left.Sum + right.Sum,
+	}, nil
+}
+
+// Build returns levels of the tree from leaves up to the root, the root of no leaves is Empty.
+func Build(leaves []Node) ([][]Node, error) {
+	if len(leaves) == 0 {
+		return [][]Node{{Empty}}, nil
+	}
+	levels := [][]Node{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([]Node, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := Empty
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			parent, err := Parent(level[i], right)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, parent)
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels, nil
+}
+
+// Step is the sibling of the node on the path to the root, Left when it is the left one.
+type Step struct {
+	Node
+	Left bool `json:"left"`
+}
+
+// SiblingPosition is the position of the sibling on the level of the node at position on it.
+func SiblingPosition(position int64) int64 {
+	return position ^ 1
+}
+
+// Path returns siblings of the leaf at position from the leaves up to the root.
+func Path(levels [][]Node, position int64) []Step {
+	var path []Step
+	for _, level := range levels[:len(levels)-1] {
+		sibling := Empty
+		if p := SiblingPosition(position); p < int64(len(level)) {
+			sibling = level[p]
+		}
+		path = append(path, Step{Node: sibling, Left: position%2 == 1})
+		position /= 2
+	}
+	return path
+}
+
+// Proof is the inclusion proof of the leaf.
+type Proof struct {
+	ID   int64  `json:"wallet_id"`
+	Sum  Sum    `json:"balance"`
+	Salt Hash   `json:"salt"`
+	Path []Step `json:"path"`
+}
+
+// Root is the root the proof leads to.
+func (p Proof) Root() (Node, error) {
+	if len(p.Path) > maxDepth {
+		return Node{}, ErrInvalidProof
+	}
+	if p.Sum < 0 {
+		return Node{}, ErrInvalidSum
+	}
+	node := Leaf(p.ID, p.Sum, p.Salt)
+	for _, step := range p.Path {
+		var err error
+		if step.Left {
+			node, err = Parent(step.Node, node)
+		} else {
+			node, err = Parent(node, step.Node)
+		}
+		if err != nil {
+			return Node{}, err
+		}
+	}
+	return node, nil
+}
+
+// Verify checks that the leaf is included in the tree of the root, so its sum is part of the total of the root.
+func Verify(p Proof, root Hash) (Node, error) {
+	node, err := p.Root()
+	if err != nil {
+		return Node{}, err
+	}
+	if !bytes.Equal(node.Hash, root) {
+		return Node{}, ErrRootMismatch
+	}
+	return node, nil
+}
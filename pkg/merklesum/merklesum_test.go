@@ -0,0 +1,130 @@
+package merklesum
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func newLeaves(n int) []Node {
+	leaves := make([]Node, n)
+	for i := range leaves {
+		leaves[i] = Leaf(int64(i+1), Sum((i+1)*Scale), bytes.Repeat([]byte{byte(i)}, 32))
+	}
+	return leaves
+}
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Sum
+		format  string
+		wantErr bool
+	}{
+		{name: "test whole", value: "12", want: 120000, format: "12.0000"},
+		{name: "test decimals", value: "12.34", want: 123400, format: "12.3400"},
+		{name: "test four decimals", value: "0.0001", want: 1, format: "0.0001"},
+		{name: "test negative", value: "-1.5", want: -15000, format: "-1.5000"},
+		{name: "test too precise", value: "1.00001", wantErr: true},
+		{name: "test no whole", value: ".5", wantErr: true},
+		{name: "test sign in decimals", value: "1.-5", wantErr: true},
+		{name: "test exponent", value: "1e3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSum(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseSum() = %d, want %d", got, tt.want)
+			}
+			if got.String() != tt.format {
+				t.Errorf("String() = %s, want %s", got.String(), tt.format)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		levels, err := Build(newLeaves(n))
+		if err != nil {
+			t.Fatalf("Build() unexpected error = %v", err)
+		}
+		root := levels[len(levels)-1][0]
+		if want := Sum(n * (n + 1) / 2 * Scale); root.Sum != want {
+			t.Errorf("%d leaves: root sum = %s, want %s", n, root.Sum, want)
+		}
+		for position := range levels[0] {
+			proof := Proof{ID: int64(position + 1), Sum: Sum((position + 1) * Scale), Salt: bytes.Repeat([]byte{byte(position)}, 32),
+				Path: Path(levels, int64(position))}
+			got, err := Verify(proof, root.Hash)
+			if err != nil {
+				t.Errorf("%d leaves: Verify() of leaf %d unexpected error = %v", n, position, err)
+				continue
+			}
+			if got.Sum != root.Sum {
+				t.Errorf("%d leaves: Verify() of leaf %d sum = %s, want %s", n, position, got.Sum, root.Sum)
+			}
+		}
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	levels, err := Build(newLeaves(5))
+	if err != nil {
+		t.Fatalf("Build() unexpected error = %v", err)
+	}
+	root := levels[len(levels)-1][0]
+	tests := []struct {
+		name    string
+		change  func(*Proof)
+		wantErr error
+	}{
+		{name: "test intact", change: func(*Proof) {}},
+		{name: "test smaller balance", change: func(p *Proof) { p.Sum-- }, wantErr: ErrRootMismatch},
+		{name: "test other wallet", change: func(p *Proof) { p.ID = 4 }, wantErr: ErrRootMismatch},
+		{name: "test sum moved between siblings", change: func(p *Proof) {
+			p.Path[0].Sum++
+			p.Sum--
+		}, wantErr: ErrRootMismatch},
+		{name: "test negative sibling", change: func(p *Proof) { p.Path[1].Sum = -1 }, wantErr: ErrInvalidSum},
+		{name: "test negative balance", change: func(p *Proof) { p.Sum = -1 }, wantErr: ErrInvalidSum},
+		{name: "test flipped side", change: func(p *Proof) { p.Path[0].Left = !p.Path[0].Left }, wantErr: ErrRootMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof := Proof{ID: 3, Sum: 3 * Scale, Salt: bytes.Repeat([]byte{2}, 32), Path: Path(levels, 2)}
+			tt.change(&proof)
+			if _, err := Verify(proof, root.Hash); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProofJSON(t *testing.T) {
+	levels, err := Build(newLeaves(3))
+	if err != nil {
+		t.Fatalf("Build() unexpected error = %v", err)
+	}
+	proof := Proof{ID: 1, Sum: Scale, Salt: bytes.Repeat([]byte{0}, 32), Path: Path(levels, 0)}
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error = %v", err)
+	}
+	var decoded Proof
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() unexpected error = %v", err)
+	}
+	if _, err := Verify(decoded, levels[len(levels)-1][0].Hash); err != nil {
+		t.Errorf("Verify() of decoded proof unexpected error = %v", err)
+	}
+}
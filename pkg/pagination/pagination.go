@@ -0,0 +1,122 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+const (
+	afterPrefix  = "after:"
+	beforePrefix = "before:"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid page cursor")
+	ErrInvalidLimit  = errors.New("page limit should be greater then 0")
+)
+
+// Page is a keyset page over records ordered by id ascending. First page has neither After nor Before,
+// next pages go after the last id of the previous one and previous pages go before the first id.
+type Page struct {
+	After  int64
+	Before int64
+	Limit  int
+	// Total asks to count all records, it costs a full count so it is optional.
+	Total bool
+}
+
+// New reads the page from the limit and the opaque cursor, zero limit means DefaultLimit
+// and limit above MaxLimit is cut to MaxLimit.
+func New(limit int, cursor string, total bool) (Page, error) {
+	switch {
+	case limit < 0:
+		return Page{}, ErrInvalidLimit
+	case limit == 0:
+		limit = DefaultLimit
+	case limit > MaxLimit:
+		limit = MaxLimit
+	}
+	page := Page{Limit: limit, Total: total}
+	if cursor == "" {
+		return page, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return Page{}, ErrInvalidCursor
+	}
+	value := string(decoded)
+	before := strings.HasPrefix(value, beforePrefix)
+	if !before && !strings.HasPrefix(value, afterPrefix) {
+		return Page{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimPrefix(value, afterPrefix), beforePrefix), 10, 64)
+	// after 0 is the first page, while there is nothing before 0
+	if err != nil || id < 0 || before && id == 0 {
+		return Page{}, ErrInvalidCursor
+	}
+	if before {
+		page.Before = id
+	} else {
+		page.After = id
+	}
+	return page, nil
+}
+
+// Backward is true for pages going before the cursor, such page is read by id descending.
+func (p Page) Backward() bool {
+	return p.Before > 0
+}
+
+// Fetch is the number of records to read, one more then the limit to know if there is a further page.
+func (p Page) Fetch() int {
+	return p.Limit + 1
+}
+
+// Result is the position of the read page, cursors are empty when there is nowhere to go.
+type Result struct {
+	Next  string
+	Prev  string
+	Total *int64
+}
+
+// Cut takes ids of fetched records in ascending order and returns the bounds of the page within them.
+func (p Page) Cut(ids []int64) (start, end int, result Result) {
+	start, end = 0, len(ids)
+	more := len(ids) > p.Limit
+	if more && p.Backward() {
+		start = len(ids) - p.Limit
+	} else if more {
+		end = p.Limit
+	}
+	hasNext := more && !p.Backward() || p.Backward()
+	hasPrev := more && p.Backward() || p.After > 0
+	if start == end {
+		// empty page past the end or before the start still leads back to the records it came from
+		if p.After > 0 {
+			result.Prev = encode(beforePrefix, p.After+1)
+		}
+		if p.Backward() {
+			result.Next = encode(afterPrefix, p.Before-1)
+		}
+		return start, end, result
+	}
+	if hasNext {
+		result.Next = encode(afterPrefix, ids[end-1])
+	}
+	if hasPrev {
+		result.Prev = encode(beforePrefix, ids[start])
+	}
+	return start, end, result
+}
+
+func encode(prefix string, id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(prefix + strconv.FormatInt(id, 10)))
+}
@@ -0,0 +1,89 @@
+package pagination
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		cursor  string
+		want    Page
+		wantErr error
+	}{
+		{name: "test default limit", want: Page{Limit: DefaultLimit}},
+		{name: "test max limit", limit: MaxLimit + 1, want: Page{Limit: MaxLimit}},
+		{name: "test negative limit", limit: -1, wantErr: errors.New("page limit should be greater then 0")},
+		{name: "test after cursor", limit: 10, cursor: encode(afterPrefix, 42), want: Page{After: 42, Limit: 10}},
+		{name: "test before cursor", limit: 10, cursor: encode(beforePrefix, 42), want: Page{Before: 42, Limit: 10}},
+		{name: "test before zero", limit: 10, cursor: encode(beforePrefix, 0), wantErr: errors.New("invalid page cursor")},
+		{name: "test not base64", limit: 10, cursor: "after:1", wantErr: errors.New("invalid page cursor")},
+		{name: "test unknown direction", limit: 10, cursor: encode("around:", 1), wantErr: errors.New("invalid page cursor")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.limit, tt.cursor, false)
+			if tt.wantErr != nil {
+				if err == nil || tt.wantErr.Error() != err.Error() {
+					t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("New() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPage_Cut(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      Page
+		ids       []int64
+		wantStart int
+		wantEnd   int
+		want      Result
+	}{
+		{name: "test single page", page: Page{Limit: 3}, ids: []int64{1, 2}, wantStart: 0, wantEnd: 2},
+		{
+			name: "test first page", page: Page{Limit: 2}, ids: []int64{1, 2, 3}, wantStart: 0, wantEnd: 2,
+			want: Result{Next: encode(afterPrefix, 2)},
+		},
+		{
+			name: "test middle page", page: Page{After: 2, Limit: 2}, ids: []int64{3, 4, 5}, wantStart: 0, wantEnd: 2,
+			want: Result{Next: encode(afterPrefix, 4), Prev: encode(beforePrefix, 3)},
+		},
+		{
+			name: "test last page", page: Page{After: 4, Limit: 2}, ids: []int64{5}, wantStart: 0, wantEnd: 1,
+			want: Result{Prev: encode(beforePrefix, 5)},
+		},
+		{
+			name: "test backward page", page: Page{Before: 5, Limit: 2}, ids: []int64{2, 3, 4}, wantStart: 1, wantEnd: 3,
+			want: Result{Next: encode(afterPrefix, 4), Prev: encode(beforePrefix, 3)},
+		},
+		{
+			name: "test backward to the first page", page: Page{Before: 3, Limit: 2}, ids: []int64{1, 2}, wantStart: 0, wantEnd: 2,
+			want: Result{Next: encode(afterPrefix, 2)},
+		},
+		{
+			name: "test empty page after the end", page: Page{After: 9, Limit: 2}, wantStart: 0, wantEnd: 0,
+			want: Result{Prev: encode(beforePrefix, 10)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, got := tt.page.Cut(tt.ids)
+			if start != tt.wantStart || end != tt.wantEnd || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Page.Cut() = %d %d %+v, want %d %d %+v", start, end, got, tt.wantStart, tt.wantEnd, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Package parquet writes Apache Parquet files of flat schemas with the parquet-go writer, values are checked
+// against the columns and converted to their physical types here, so that a bad row is rejected as a whole.
+package parquet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	format "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// DefaultRowGroupRows is the number of rows buffered before they are written as a row group.
+const DefaultRowGroupRows = 64 * 1024
+
+// decimalPrecision is the most decimal digits an INT64 holds
+const decimalPrecision = 18
+
+var ErrInvalidValue = errors.New("value does not match the column")
+
+// Kind is the type of column values, it picks the physical and the logical type of the column.
+type Kind int
+
+const (
+	// KindInt64 takes int64 values.
+	KindInt64 Kind = iota
+	// KindDecimal takes float64 values, they are rounded to Scale decimals and kept as INT64 DECIMAL(18, Scale).
+	KindDecimal
+	// KindTimestamp takes time.Time values, they are kept as microseconds of INT64 TIMESTAMP adjusted to UTC.
+	KindTimestamp
+	// KindEnum takes string values, they are kept as BYTE_ARRAY ENUM.
+	KindEnum
+	// KindString takes string values, they are kept as BYTE_ARRAY STRING.
+	KindString
+)
+
+// Column is a top level column, nil values are nulls of Optional columns.
+type Column struct {
+	Name     string
+	Kind     Kind
+	Optional bool
+	Scale    int
+}
+
+// metadata is the column in the schema notation of the parquet-go writer.
+func (c Column) metadata() (string, error) {
+	var types string
+	switch c.Kind {
+	case KindInt64:
+		types = "type=INT64"
+	case KindDecimal:
+		if c.Scale < 0 || c.Scale > decimalPrecision {
+			return "", errors.Errorf("decimal column %s should have scale from 0 to %d", c.Name, decimalPrecision)
+		}
+		types = fmt.Sprintf("type=INT64, convertedtype=DECIMAL, scale=%d, precision=%d, logicaltype=DECIMAL, logicaltype.scale=%d, logicaltype.precision=%d",
+			c.Scale, decimalPrecision, c.Scale, decimalPrecision)
+	case KindTimestamp:
+		types = "type=INT64, convertedtype=TIMESTAMP_MICROS, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=MICROS"
+	case KindEnum:
+		// parquet-go has no statistics of ENUM, it is written as UTF8 of the same byte order and marked ENUM on Close
+		types = "type=BYTE_ARRAY, convertedtype=UTF8, logicaltype=STRING"
+	case KindString:
+		types = "type=BYTE_ARRAY, convertedtype=UTF8, logicaltype=STRING"
+	default:
+		return "", errors.Errorf("unknown kind %d of column %s", c.Kind, c.Name)
+	}
+	repetition := "REQUIRED"
+	if c.Optional {
+		repetition = "OPTIONAL"
+	}
+	return fmt.Sprintf("name=%s, %s, repetitiontype=%s", c.Name, types, repetition), nil
+}
+
+// value converts v to the physical type of the column, nil stays a null.
+func (c Column) value(v interface{}) (interface{}, error) {
+	if v == nil {
+		if !c.Optional {
+			return nil, errors.Wrap(ErrInvalidValue, "null in required column")
+		}
+		return nil, nil
+	}
+	switch c.Kind {
+	case KindInt64:
+		if i, ok := v.(int64); ok {
+			return i, nil
+		}
+	case KindDecimal:
+		if f, ok := v.(float64); ok {
+			unscaled := math.Round(f * math.Pow10(c.Scale))
+			if math.IsNaN(unscaled) || math.Abs(unscaled) >= math.Pow10(decimalPrecision) {
+				return nil, errors.Wrapf(ErrInvalidValue, "%v does not fit decimal(%d, %d)", f, decimalPrecision, c.Scale)
+			}
+			return int64(unscaled), nil
+		}
+	case KindTimestamp:
+		if t, ok := v.(time.Time); ok {
+			return t.Unix()*1e6 + int64(t.Nanosecond()/1e3), nil
+		}
+	case KindEnum, KindString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	}
+	return nil, errors.Wrapf(ErrInvalidValue, "%T value", v)
+}
+
+// Writer writes rows into row groups, a row group is held in memory until it is written.
+type Writer struct {
+	out     *bufio.Writer
+	file    *writer.CSVWriter
+	columns []Column
+	rows    int64
+	// nulls of every column in the buffered rows
+	nulls        []int64
+	RowGroupRows int64
+}
+
+// NewWriter writes the file header, the file is complete only after Close.
+func NewWriter(w io.Writer, columns []Column) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("parquet schema should have columns")
+	}
+	metadata := make([]string, len(columns))
+	for i, column := range columns {
+		var err error
+		if metadata[i], err = column.metadata(); err != nil {
+			return nil, err
+		}
+	}
+	out := bufio.NewWriter(w)
+	file, err := writer.NewCSVWriterFromWriter(metadata, out, 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating parquet writer")
+	}
+	// row groups are cut by RowGroupRows only
+	file.RowGroupSize = math.MaxInt64 / 2
+	return &Writer{out: out, file: file, columns: columns, nulls: make([]int64, len(columns)), RowGroupRows: DefaultRowGroupRows}, nil
+}
+
+// Write adds a row with a value for every column, the row group is written when it is full.
+func (w *Writer) Write(values ...interface{}) error {
+	if len(values) != len(w.columns) {
+		return errors.Wrapf(ErrInvalidValue, "%d values for %d columns", len(values), len(w.columns))
+	}
+	row := make([]interface{}, len(values))
+	for i, value := range values {
+		var err error
+		if row[i], err = w.columns[i].value(value); err != nil {
+			return errors.Wrapf(err, "column %s", w.columns[i].Name)
+		}
+	}
+	if err := w.file.Write(row); err != nil {
+		return errors.Wrap(err, "error writing parquet row")
+	}
+	for i, value := range row {
+		if value == nil {
+			w.nulls[i]++
+		}
+	}
+	w.rows++
+	if w.rows >= w.RowGroupRows {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered rows as a row group.
+func (w *Writer) Flush() error {
+	if w.rows > 0 {
+		if err := w.file.Flush(true); err != nil {
+			return errors.Wrap(err, "error writing parquet row group")
+		}
+		// parquet-go does not count the null in the first row of a page, chunks get the counts of the row group
+		groups := w.file.Footer.RowGroups
+		for i, chunk := range groups[len(groups)-1].Columns {
+			nulls := w.nulls[i]
+			chunk.MetaData.Statistics.NullCount = &nulls
+			w.nulls[i] = 0
+		}
+		w.rows = 0
+	}
+	return w.out.Flush()
+}
+
+// Close writes the buffered rows and the footer, it does not close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	// schema elements of the writer are the ones of the footer
+	for i, column := range w.columns {
+		if column.Kind == KindEnum {
+			element := w.file.SchemaHandler.SchemaElements[i+1]
+			element.ConvertedType = format.ConvertedTypePtr(format.ConvertedType_ENUM)
+			element.LogicalType = &format.LogicalType{ENUM: format.NewEnumType()}
+		}
+	}
+	if err := w.file.WriteStop(); err != nil {
+		return errors.Wrap(err, "error writing parquet footer")
+	}
+	return w.out.Flush()
+}
@@ -0,0 +1,219 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	format "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+var testColumns = []Column{
+	{Name: "id", Kind: KindInt64},
+	{Name: "amount", Kind: KindDecimal, Scale: 4},
+	{Name: "timestamp", Kind: KindTimestamp},
+	{Name: "type", Kind: KindEnum},
+	{Name: "parent_id", Kind: KindInt64, Optional: true},
+}
+
+func TestWriter(t *testing.T) {
+	timestamp := time.Date(2022, 5, 1, 10, 0, 0, 123456789, time.UTC)
+	var out bytes.Buffer
+	writer, err := NewWriter(&out, testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.RowGroupRows = 2
+	rows := [][]interface{}{
+		{int64(1), 10.12345, timestamp, "deposit", nil},
+		{int64(2), -0.5, timestamp.Add(time.Hour), "transfer", int64(7)},
+		{int64(3), 1.0, timestamp.AddDate(-400, 0, 0), "deposit", nil},
+	}
+	for _, row := range rows {
+		if err := writer.Write(row...); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := writer.Write(int64(4), 1.0, timestamp, "deposit"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Write() of short row error = %v, want %v", err, ErrInvalidValue)
+	}
+	if err := writer.Write(int64(4), "1", timestamp, "deposit", nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Write() of string amount error = %v, want %v", err, ErrInvalidValue)
+	}
+	if err := writer.Write(nil, 1.0, timestamp, "deposit", nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Write() of null id error = %v, want %v", err, ErrInvalidValue)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file := readFile(t, out.Bytes())
+	if file.GetNumRows() != 3 {
+		t.Errorf("num_rows = %d, want 3", file.GetNumRows())
+	}
+	if len(file.Footer.RowGroups) != 2 {
+		t.Errorf("row groups = %d, want 2", len(file.Footer.RowGroups))
+	}
+	amount := file.Footer.Schema[2]
+	if amount.GetConvertedType() != format.ConvertedType_DECIMAL || amount.GetScale() != 4 || amount.GetPrecision() != 18 {
+		t.Errorf("amount schema = %v", amount)
+	}
+	if file.Footer.Schema[3].GetLogicalType().GetTIMESTAMP().GetUnit().GetMICROS() == nil {
+		t.Errorf("timestamp schema = %v", file.Footer.Schema[3])
+	}
+
+	want := [][]interface{}{
+		{int64(1), int64(2), int64(3)},
+		{int64(101235), int64(-5000), int64(10000)},
+		{timestamp.UnixNano() / 1e3, timestamp.Add(time.Hour).UnixNano() / 1e3, timestamp.AddDate(-400, 0, 0).Unix()*1e6 + 123456},
+		{"deposit", "transfer", "deposit"},
+		{nil, int64(7), nil},
+	}
+	for i, column := range testColumns {
+		values, _, _, err := file.ReadColumnByIndex(int64(i), 3)
+		if err != nil {
+			t.Fatalf("ReadColumnByIndex(%s) error = %v", column.Name, err)
+		}
+		if !reflect.DeepEqual(values, want[i]) {
+			t.Errorf("%s = %v, want %v", column.Name, values, want[i])
+		}
+	}
+
+	parentID := file.Footer.RowGroups[0].Columns[4].MetaData.Statistics
+	if parentID.GetNullCount() != 1 || !bytes.Equal(parentID.MinValue, int64Bytes(7)) || !bytes.Equal(parentID.MaxValue, int64Bytes(7)) {
+		t.Errorf("parent_id statistics = %v", parentID)
+	}
+	if nulls := file.Footer.RowGroups[1].Columns[4].MetaData.Statistics.GetNullCount(); nulls != 1 {
+		t.Errorf("parent_id nulls of the second row group = %d, want 1", nulls)
+	}
+	if file.Footer.Schema[4].GetConvertedType() != format.ConvertedType_ENUM || file.Footer.Schema[4].GetLogicalType().GetENUM() == nil {
+		t.Errorf("type schema = %v", file.Footer.Schema[4])
+	}
+	amounts := file.Footer.RowGroups[0].Columns[1].MetaData.Statistics
+	if !bytes.Equal(amounts.MinValue, int64Bytes(-5000)) || !bytes.Equal(amounts.MaxValue, int64Bytes(101235)) {
+		t.Errorf("amount statistics = %v", amounts)
+	}
+	types := file.Footer.RowGroups[0].Columns[3].MetaData.Statistics
+	if string(types.MinValue) != "deposit" || string(types.MaxValue) != "transfer" {
+		t.Errorf("type statistics = %v", types)
+	}
+}
+
+func TestWriter_NullsAcrossRowGroups(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(&out, []Column{{Name: "parent_id", Kind: KindInt64, Optional: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.RowGroupRows = 3
+	rows := []interface{}{nil, nil, int64(1), int64(2), nil, nil, nil}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file := readFile(t, out.Bytes())
+	wantNulls := []int64{2, 2, 1}
+	if len(file.Footer.RowGroups) != len(wantNulls) {
+		t.Fatalf("row groups = %d, want %d", len(file.Footer.RowGroups), len(wantNulls))
+	}
+	for i, group := range file.Footer.RowGroups {
+		if nulls := group.Columns[0].MetaData.Statistics.GetNullCount(); nulls != wantNulls[i] {
+			t.Errorf("nulls of row group %d = %d, want %d", i, nulls, wantNulls[i])
+		}
+	}
+	values, _, _, err := file.ReadColumnByIndex(0, int64(len(rows)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, rows) {
+		t.Errorf("parent_id = %v, want %v", values, rows)
+	}
+}
+
+func TestWriter_Empty(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(&out, testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file := readFile(t, out.Bytes())
+	if file.GetNumRows() != 0 || len(file.Footer.RowGroups) != 0 || len(file.Footer.Schema) != len(testColumns)+1 {
+		t.Errorf("footer = %v", file.Footer)
+	}
+}
+
+func TestWriter_Compressed(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(&out, []Column{{Name: "type", Kind: KindEnum}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10000; i++ {
+		if err := writer.Write("deposit"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() > 10000*len("deposit")/10 {
+		t.Errorf("repeated values are written in %d bytes", out.Len())
+	}
+	values, _, _, err := readFile(t, out.Bytes()).ReadColumnByIndex(0, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 10000 || values[9999] != "deposit" {
+		t.Errorf("read %d values", len(values))
+	}
+}
+
+// readFile opens the file with the column reader of parquet-go.
+func readFile(t *testing.T, file []byte) *reader.ParquetReader {
+	t.Helper()
+	parquetReader, err := reader.NewParquetColumnReader(&memoryFile{Reader: bytes.NewReader(file), data: file}, 1)
+	if err != nil {
+		t.Fatalf("NewParquetColumnReader() error = %v", err)
+	}
+	return parquetReader
+}
+
+func int64Bytes(v int64) []byte {
+	var plain [8]byte
+	binary.LittleEndian.PutUint64(plain[:], uint64(v))
+	return plain[:]
+}
+
+// memoryFile is a read only source.ParquetFile of a file in memory.
+type memoryFile struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (f *memoryFile) Write([]byte) (int, error) {
+	return 0, errors.New("memory file is read only")
+}
+
+func (f *memoryFile) Close() error {
+	return nil
+}
+
+func (f *memoryFile) Open(string) (source.ParquetFile, error) {
+	return &memoryFile{Reader: bytes.NewReader(f.data), data: f.data}, nil
+}
+
+func (f *memoryFile) Create(string) (source.ParquetFile, error) {
+	return nil, errors.New("memory file is read only")
+}
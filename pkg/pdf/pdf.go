@@ -0,0 +1,185 @@
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// A4 page size in points, the origin of page coordinates is the bottom left corner.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+// Fonts are the standard Type1 fonts, every viewer has them, so nothing is embedded.
+const (
+	Regular Font = iota
+	Bold
+)
+
+var baseFonts = []string{Regular: "Helvetica", Bold: "Helvetica-Bold"}
+
+// Document is a text only pdf with A4 pages. Text is encoded with WinAnsiEncoding,
+// characters it does not have are written as '?'.
+type Document struct {
+	pages   []*bytes.Buffer
+	current int
+}
+
+// New returns the document with one empty page.
+func New() *Document {
+	d := &Document{}
+	d.AddPage()
+	return d
+}
+
+// AddPage adds a page at the end of the document and makes it the current one.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.current = len(d.pages) - 1
+}
+
+// SetPage makes the page with zero based index current, e.g. to put page numbers once all pages are added.
+func (d *Document) SetPage(index int) {
+	if index >= 0 && index < len(d.pages) {
+		d.current = index
+	}
+}
+
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Text draws text on the current page starting at x, y is the baseline.
+func (d *Document) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(d.pages[d.current], "BT /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		font+1, number(size), number(x), number(y), escape(encode(text)))
+}
+
+// TextRight draws text on the current page ending at x, it is used for columns of numbers.
+func (d *Document) TextRight(x, y float64, font Font, size float64, text string) {
+	d.Text(x-TextWidth(text, size), y, font, size, text)
+}
+
+// Line draws a thin line on the current page.
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.pages[d.current], "0.5 w %s %s m %s %s l S\n", number(x1), number(y1), number(x2), number(y2))
+}
+
+// WriteTo writes the complete document, objects are numbered as
+// 1 catalog, 2 page tree, 3 and 4 fonts, then a page and its content for every page.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	buffered := bufio.NewWriter(w)
+	out := &countingWriter{w: buffered}
+	var offsets []int64
+	object := func(body string) {
+		offsets = append(offsets, out.n)
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// the binary comment tells transfer tools the file is not plain text
+	io.WriteString(out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := &bytes.Buffer{}
+	for i := range d.pages {
+		fmt.Fprintf(kids, "%d 0 R ", 5+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids.Bytes()), len(d.pages)))
+	for _, font := range baseFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font))
+	}
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			number(PageWidth), number(PageHeight), 6+2*i))
+		// the line end before endstream is not part of the stream length
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", page.Len(), page.Bytes()))
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	if out.err != nil {
+		return out.n, out.err
+	}
+	return out.n, buffered.Flush()
+}
+
+// TextWidth is the width of text in points with the Regular font, digits and
+// the punctuation of numbers have the same width in Bold.
+func TextWidth(text string, size float64) float64 {
+	var width int
+	for _, b := range encode(text) {
+		if b >= 32 && int(b-32) < len(helveticaWidths) {
+			width += helveticaWidths[b-32]
+		} else {
+			width += 556
+		}
+	}
+	return float64(width) * size / 1000
+}
+
+// helveticaWidths are the widths of characters 32 to 126 in thousandths of the font size.
+var helveticaWidths = []int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// encode converts text to WinAnsiEncoding, which matches latin-1 for the characters it keeps.
+func encode(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= 32 && r < 127, r >= 160 && r <= 255:
+			encoded = append(encoded, byte(r))
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+func escape(text []byte) []byte {
+	escaped := make([]byte, 0, len(text))
+	for _, b := range text {
+		if b == '(' || b == ')' || b == '\\' {
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, b)
+	}
+	return escaped
+}
+
+// number keeps two decimals, that is far below what can be seen on the page.
+func number(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// countingWriter keeps the offset of written bytes for the cross reference table and the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocument_WriteTo(t *testing.T) {
+	tests := []struct {
+		name         string
+		build        func(*Document)
+		wantPages    int
+		wantContains []string
+	}{
+		{
+			name:      "test empty document has a page",
+			build:     func(*Document) {},
+			wantPages: 1,
+		},
+		{
+			name: "test text is escaped and encoded",
+			build: func(d *Document) {
+				d.Text(10, 20, Bold, 12, `Total (in) \ café €`)
+			},
+			wantPages:    1,
+			wantContains: []string{"BT /F2 12 Tf 10 20 Td (Total \\(in\\) \\\\ caf\xe9 ?) Tj ET"},
+		},
+		{
+			name: "test pages and lines",
+			build: func(d *Document) {
+				d.Line(0, 0, 100.125, 0)
+				d.AddPage()
+				d.AddPage()
+				d.SetPage(0)
+				d.TextRight(100, 10, Regular, 10, "10")
+			},
+			wantPages:    3,
+			wantContains: []string{"0.5 w 0 0 m 100.13 0 l S", "BT /F1 10 Tf 88.88 10 Td (10) Tj ET"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New()
+			tt.build(doc)
+			var out bytes.Buffer
+			n, err := doc.WriteTo(&out)
+			if err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if n != int64(out.Len()) {
+				t.Errorf("WriteTo() = %d, written %d", n, out.Len())
+			}
+			file := out.Bytes()
+			if !bytes.HasPrefix(file, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(file, []byte("%%EOF\n")) {
+				t.Fatalf("WriteTo() is not a pdf file")
+			}
+			if got := doc.PageCount(); got != tt.wantPages {
+				t.Errorf("PageCount() = %d, want %d", got, tt.wantPages)
+			}
+			if !bytes.Contains(file, []byte(fmt.Sprintf("/Count %d >>", tt.wantPages))) {
+				t.Errorf("WriteTo() page tree does not have %d pages", tt.wantPages)
+			}
+			for _, want := range tt.wantContains {
+				if !bytes.Contains(file, []byte(want)) {
+					t.Errorf("WriteTo() does not contain %q", want)
+				}
+			}
+			checkXref(t, file, 4+2*tt.wantPages)
+		})
+	}
+}
+
+// checkXref checks that every entry of the cross reference table points to its object.
+func checkXref(t *testing.T, file []byte, objects int) {
+	t.Helper()
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(file)
+	if match == nil {
+		t.Fatalf("missing startxref")
+	}
+	start, _ := strconv.Atoi(string(match[1]))
+	lines := strings.Split(string(file[start:]), "\n")
+	if lines[0] != "xref" || lines[1] != fmt.Sprintf("0 %d", objects+1) {
+		t.Fatalf("xref header = %q %q, want %d objects", lines[0], lines[1], objects)
+	}
+	for i := 1; i <= objects; i++ {
+		entry := lines[2+i]
+		if len(entry) != 19 {
+			t.Fatalf("xref entry %d = %q, entries are 20 bytes", i, entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		if want := fmt.Sprintf("%d 0 obj\n", i); !bytes.HasPrefix(file[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points to %q", i, file[offset:offset+10])
+		}
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		size float64
+		want float64
+	}{
+		{name: "test digits", text: "1234.50", size: 10, want: 36.14},
+		{name: "test letters", text: "Wi", size: 10, want: 11.66},
+		{name: "test unknown character", text: "€", size: 10, want: 5.56},
+		{name: "test empty", text: "", size: 10, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TextWidth(tt.text, tt.size); fmt.Sprintf("%.2f", got) != fmt.Sprintf("%.2f", tt.want) {
+				t.Errorf("TextWidth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// keyExt is the extension of key files, the key id is the name of the file without it.
+const keyExt = ".pem"
+
+// KeySet is the JWKS (RFC 7517) of public keys receipts are signed with.
+type KeySet struct {
+	Keys []Key `json:"keys"`
+}
+
+// Key is the Ed25519 public key as JWK (RFC 8037), X is base64url encoded without padding.
+type Key struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	KeyID     string `json:"kid"`
+	X         string `json:"x"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
+
+func newKey(id string, public ed25519.PublicKey) Key {
+	return Key{KeyType: "OKP", Curve: "Ed25519", KeyID: id, X: base64.RawURLEncoding.EncodeToString(public), Use: "sig", Algorithm: "EdDSA"}
+}
+
+func (k Key) publicKey() (ed25519.PublicKey, error) {
+	if k.KeyType != "OKP" || k.Curve != "Ed25519" {
+		return nil, errors.Errorf("key %q is not an Ed25519 key", k.KeyID)
+	}
+	public, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil || len(public) != ed25519.PublicKeySize {
+		return nil, errors.Errorf("key %q is not a valid Ed25519 key", k.KeyID)
+	}
+	return public, nil
+}
+
+func (s KeySet) find(id string) (Key, bool) {
+	for _, key := range s.Keys {
+		if key.KeyID == id {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+func ParseKeySet(data []byte) (KeySet, error) {
+	var keys KeySet
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return KeySet{}, errors.Wrap(err, "error parsing key set")
+	}
+	return keys, nil
+}
+
+// Keyring signs receipts with its active key and keeps retired keys, so that receipts signed before a rotation still verify.
+type Keyring struct {
+	activeID string
+	active   ed25519.PrivateKey
+	keys     KeySet
+}
+
+func NewKeyring(activeID string, active ed25519.PrivateKey, retired map[string]ed25519.PublicKey) *Keyring {
+	k := &Keyring{activeID: activeID, active: active}
+	k.keys.Keys = append(k.keys.Keys, newKey(activeID, active.Public().(ed25519.PublicKey)))
+	ids := make([]string, 0, len(retired))
+	for id := range retired {
+		if id != activeID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		k.keys.Keys = append(k.keys.Keys, newKey(id, retired[id]))
+	}
+	return k
+}
+
+// LoadKeyring reads the active key from the PKCS #8 PEM file at path, other key files next to it are retired keys.
+// Key ids are the names of the files, so a key is rotated by adding the file of the new one and pointing path to it.
+func LoadKeyring(path string) (*Keyring, error) {
+	active, err := loadKey(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*"+keyExt))
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing key files")
+	}
+	retired := make(map[string]ed25519.PublicKey, len(files))
+	for _, file := range files {
+		key, err := loadKey(file)
+		if err != nil {
+			return nil, err
+		}
+		retired[keyID(file)] = key.Public().(ed25519.PublicKey)
+	}
+	return NewKeyring(keyID(path), active, retired), nil
+}
+
+func keyID(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), keyExt)
+}
+
+func loadKey(path string) (ed25519.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading key file")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("no pem block in key file %s", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing key file %s", path)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("key file %s is not an Ed25519 key", path)
+	}
+	return private, nil
+}
+
+// Sign signs the receipt with the active key.
+func (k *Keyring) Sign(r Receipt) (Signed, error) {
+	message, err := r.Canonical()
+	if err != nil {
+		return Signed{}, errors.Wrap(err, "error encoding receipt")
+	}
+	return Signed{Receipt: r, KeyID: k.activeID, Signature: base64.RawURLEncoding.EncodeToString(ed25519.Sign(k.active, message))}, nil
+}
+
+// KeySet is the active key followed by retired ones.
+func (k *Keyring) KeySet() KeySet {
+	return k.keys
+}
@@ -0,0 +1,86 @@
+// Package receipt signs receipts of transfers with Ed25519 keys and verifies them offline.
+package receipt
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrUnknownKey       = errors.New("receipt is signed with unknown key")
+	ErrInvalidSignature = errors.New("receipt signature is invalid")
+)
+
+// Receipt is the signed part of the receipt. Fields are declared in the order of their names,
+// so that the json encoding is the canonical one (RFC 8785) and can be reproduced by any verifier.
+type Receipt struct {
+	// Amount has the four decimals amounts are stored with
+	Amount string `json:"amount"`
+	// Date is RFC 3339 in UTC
+	Date string `json:"date"`
+	// Hash is the hex encoded hash of the transaction in the chain of the transaction log
+	Hash            string `json:"hash,omitempty"`
+	ParentPaymentID *int64 `json:"parent_payment_id,omitempty"`
+	ReceiverID      int64  `json:"receiver_id"`
+	SenderID        int64  `json:"sender_id"`
+	TransactionID   int64  `json:"transaction_id"`
+	Type            string `json:"type"`
+}
+
+// Canonical is what the signature is made over.
+func (r Receipt) Canonical() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Signed is the receipt document, Signature is base64url encoded without padding.
+type Signed struct {
+	Receipt   Receipt `json:"receipt"`
+	KeyID     string  `json:"key_id"`
+	Signature string  `json:"signature"`
+}
+
+// Parse reads the receipt document, fields the receipt does not have are rejected as they are not signed.
+func Parse(r io.Reader) (Signed, error) {
+	var signed Signed
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&signed); err != nil {
+		return Signed{}, errors.Wrap(err, "error parsing receipt")
+	}
+	return signed, nil
+}
+
+// Verify checks the signature of the receipt with the key of the set it is signed with.
+func Verify(signed Signed, keys KeySet) error {
+	key, ok := keys.find(signed.KeyID)
+	if !ok {
+		return errors.Wrapf(ErrUnknownKey, "key %q", signed.KeyID)
+	}
+	public, err := key.publicKey()
+	if err != nil {
+		return err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(signed.Signature)
+	if err != nil {
+		return errors.Wrap(ErrInvalidSignature, err.Error())
+	}
+	message, err := signed.Receipt.Canonical()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(public, message, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package receipt
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func newPrivateKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+}
+
+func writeKey(t *testing.T, path string, key ed25519.PrivateKey) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+var testReceipt = Receipt{
+	Amount:        "12.5000",
+	Date:          "2022-05-01T10:00:00.123456Z",
+	Hash:          "00ff",
+	ReceiverID:    2,
+	SenderID:      1,
+	TransactionID: 42,
+	Type:          "transfer",
+}
+
+func TestCanonical(t *testing.T) {
+	parent := int64(7)
+	withParent := testReceipt
+	withParent.ParentPaymentID = &parent
+	tests := []struct {
+		name    string
+		receipt Receipt
+		want    string
+	}{
+		{
+			name:    "test transfer",
+			receipt: testReceipt,
+			want:    `{"amount":"12.5000","date":"2022-05-01T10:00:00.123456Z","hash":"00ff","receiver_id":2,"sender_id":1,"transaction_id":42,"type":"transfer"}`,
+		},
+		{
+			name:    "test payment leg",
+			receipt: withParent,
+			want:    `{"amount":"12.5000","date":"2022-05-01T10:00:00.123456Z","hash":"00ff","parent_payment_id":7,"receiver_id":2,"sender_id":1,"transaction_id":42,"type":"transfer"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.receipt.Canonical()
+			if err != nil {
+				t.Fatalf("Canonical() unexpected error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Canonical() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	old := NewKeyring("2022-04", newPrivateKey(1), nil)
+	current := NewKeyring("2022-05", newPrivateKey(2), map[string]ed25519.PublicKey{"2022-04": newPrivateKey(1).Public().(ed25519.PublicKey)})
+	tests := []struct {
+		name    string
+		signer  *Keyring
+		change  func(*Signed)
+		keys    KeySet
+		wantErr error
+	}{
+		{name: "test active key", signer: current, change: func(*Signed) {}, keys: current.KeySet()},
+		{name: "test retired key", signer: old, change: func(*Signed) {}, keys: current.KeySet()},
+		{name: "test key not published", signer: current, change: func(*Signed) {}, keys: old.KeySet(), wantErr: ErrUnknownKey},
+		{name: "test changed amount", signer: current, change: func(s *Signed) { s.Receipt.Amount = "125.0000" }, keys: current.KeySet(), wantErr: ErrInvalidSignature},
+		{name: "test changed key id", signer: current, change: func(s *Signed) { s.KeyID = "2022-04" }, keys: current.KeySet(), wantErr: ErrInvalidSignature},
+		{name: "test malformed signature", signer: current, change: func(s *Signed) { s.Signature = "not base64!" }, keys: current.KeySet(), wantErr: ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := tt.signer.Sign(testReceipt)
+			if err != nil {
+				t.Fatalf("Sign() unexpected error = %v", err)
+			}
+			tt.change(&signed)
+			if err := Verify(signed, tt.keys); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		wantErr  bool
+	}{
+		{name: "test receipt", document: `{"receipt":{"amount":"12.5000","transaction_id":42},"key_id":"2022-05","signature":"c2ln"}`},
+		{name: "test unsigned field", document: `{"receipt":{"amount":"12.5000","note":"paid"},"key_id":"2022-05","signature":"c2ln"}`, wantErr: true},
+		{name: "test not json", document: `receipt`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.document)); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, filepath.Join(dir, "2022-04.pem"), newPrivateKey(1))
+	writeKey(t, filepath.Join(dir, "2022-05.pem"), newPrivateKey(2))
+
+	keyring, err := LoadKeyring(filepath.Join(dir, "2022-05.pem"))
+	if err != nil {
+		t.Fatalf("LoadKeyring() unexpected error = %v", err)
+	}
+	keys := keyring.KeySet().Keys
+	if len(keys) != 2 || keys[0].KeyID != "2022-05" || keys[1].KeyID != "2022-04" {
+		t.Fatalf("KeySet() = %+v, want active 2022-05 and retired 2022-04", keys)
+	}
+	signed, err := keyring.Sign(testReceipt)
+	if err != nil {
+		t.Fatalf("Sign() unexpected error = %v", err)
+	}
+	if signed.KeyID != "2022-05" {
+		t.Errorf("KeyID = %s, want 2022-05", signed.KeyID)
+	}
+	if err := Verify(signed, keyring.KeySet()); err != nil {
+		t.Errorf("Verify() unexpected error = %v", err)
+	}
+
+	if _, err := LoadKeyring(filepath.Join(dir, "2022-06.pem")); err == nil {
+		t.Errorf("LoadKeyring() of missing file, want error")
+	}
+}
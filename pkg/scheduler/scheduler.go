@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/skwol/wallet/pkg/logging"
+)
+
+// Job is a unit of periodic work, it decides by itself what "now" is using its own clock.
+type Job func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+type Scheduler struct {
+	logger  logging.Logger
+	entries []entry
+	wg      sync.WaitGroup
+}
+
+func New(logger logging.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Every registers job to be run with the given interval, it has to be called before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.entries = append(s.entries, entry{name: name, interval: interval, job: job})
+}
+
+// Start runs every registered job in its own goroutine until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.run(ctx, e)
+	}
+}
+
+// Wait blocks until all jobs are stopped.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context, e entry) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.logger.Debugf("running job %s", e.name)
+			if err := e.job(ctx); err != nil {
+				s.logger.Errorf("job %s failed: %s", e.name, err.Error())
+			}
+		}
+	}
+}